@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"time"
 
+	"github.com/lcgerke/githelper/internal/config"
 	"github.com/lcgerke/githelper/internal/git"
+	ghclient "github.com/lcgerke/githelper/internal/github"
 	"github.com/lcgerke/githelper/internal/remote"
+	"github.com/lcgerke/githelper/internal/remote/bitbucket"
+	remoteclient "github.com/lcgerke/githelper/internal/remote/github"
+	"github.com/lcgerke/githelper/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
@@ -14,10 +22,13 @@ var authCmd = &cobra.Command{
 	Long: `Diagnose GitHub API authentication and permissions.
 
 This command checks:
-  - GitHub token availability from multiple sources
+  - GitHub token availability from multiple sources (GITHUB_TOKEN, GH_TOKEN,
+    a GitHub App installation, gh CLI config, git config github.token)
   - Token validation
   - Repository permissions (push/admin)
-  - Default branch and protection status`,
+  - Default branch and protection status
+
+Use 'githelper auth audit' to check every token for expiry and scope problems.`,
 	RunE: runAuth,
 }
 
@@ -25,6 +36,13 @@ func init() {
 	rootCmd.AddCommand(authCmd)
 }
 
+// authSourcer is implemented by platform clients that can say where their
+// credentials came from
+type authSourcer interface {
+	AuthSource() string
+	AuthExpiry() (time.Time, error)
+}
+
 func runAuth(cmd *cobra.Command, args []string) error {
 	gitClient := git.NewClient(".")
 
@@ -52,6 +70,15 @@ func runAuth(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		fmt.Println("Currently supported platforms:")
 		fmt.Println("  - GitHub (github.com)")
+		fmt.Println("  - GitLab (gitlab.com)")
+		fmt.Println("  - Gitea/Forgejo (gitea.com, codeberg.org)")
+		fmt.Println("  - Bitbucket Cloud (bitbucket.org) and Bitbucket Server/Data Center")
+		fmt.Println()
+		fmt.Println("Self-hosted servers can be mapped in ~/.githelper/config.yaml:")
+		fmt.Println("  platforms:")
+		fmt.Println("    git.corp.example:2222:")
+		fmt.Println("      type: gitea")
+		fmt.Println("      api_url: https://git.corp.example/api/v1")
 		return nil
 	}
 
@@ -66,6 +93,15 @@ func runAuth(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Token found and client created\n")
+	if src, ok := client.(authSourcer); ok && src.AuthSource() != "" {
+		fmt.Printf("  Credentials: %s\n", src.AuthSource())
+		if expiry, err := src.AuthExpiry(); err != nil {
+			fmt.Printf("❌ Failed to obtain token: %v\n", err)
+			return nil
+		} else if !expiry.IsZero() {
+			fmt.Printf("  Token expires: %s (refreshed automatically)\n", expiry.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
 	fmt.Printf("  Platform: %s\n", client.GetPlatform())
 	fmt.Printf("  Owner: %s\n", client.GetOwner())
 	fmt.Printf("  Repo: %s\n", client.GetRepo())
@@ -75,7 +111,9 @@ func runAuth(cmd *cobra.Command, args []string) error {
 	fmt.Println("🔍 Checking repository permissions...")
 
 	canPush, err := client.CanPush()
-	if err != nil {
+	if stderrors.Is(err, bitbucket.ErrPermissionsUnknown) {
+		fmt.Println("  ? Push: unknown (not reported for access tokens)")
+	} else if err != nil {
 		fmt.Printf("❌ Failed to check push permission: %v\n", err)
 	} else {
 		if canPush {
@@ -86,7 +124,9 @@ func runAuth(cmd *cobra.Command, args []string) error {
 	}
 
 	canAdmin, err := client.CanAdmin()
-	if err != nil {
+	if stderrors.Is(err, bitbucket.ErrPermissionsUnknown) {
+		fmt.Println("  ? Admin: unknown (not reported for access tokens)")
+	} else if err != nil {
 		fmt.Printf("❌ Failed to check admin permission: %v\n", err)
 	} else {
 		if canAdmin {
@@ -130,3 +170,20 @@ func runAuth(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// newRepoGitHubClient creates an API client for a managed repository. It uses
+// the repository's PAT from the secrets backend if there is one, and otherwise
+// the GitHub token chain (environment, GitHub App, gh CLI, git config), so that
+// organisations that forbid long-lived PATs can use an app installation.
+func newRepoGitHubClient(ctx context.Context, cfgMgr *config.Manager, vars secrets.Vars) (*ghclient.Client, error) {
+	pat, patErr := cfgMgr.GetPAT(vars)
+	if patErr == nil {
+		return ghclient.NewClient(ctx, pat), nil
+	}
+
+	ts, _, err := remoteclient.ResolveTokenSource()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PAT from %s (%v), and no other GitHub credentials are available: %w", cfgMgr.Secrets().Name(), patErr, err)
+	}
+	return ghclient.NewClientWithTokenSource(ctx, ts), nil
+}
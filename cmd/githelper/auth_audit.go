@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/constants"
+	ghclient "github.com/lcgerke/githelper/internal/github"
+	remotegithub "github.com/lcgerke/githelper/internal/remote/github"
+	"github.com/lcgerke/githelper/internal/secrets"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditExpiryWarning  time.Duration
+	auditRequiredScopes []string
+)
+
+var authAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit GitHub PATs for expiry and scopes",
+	Long: `Inspects every GitHub token githelper can resolve and reports problems.
+
+Tokens are gathered from:
+  - GITHUB_TOKEN, GH_TOKEN, the gh CLI config, and git config github.token
+  - The default PAT in the secrets backend
+  - Repository-specific PATs in the secrets backend
+
+Each token is checked against the GitHub API for:
+  - Expiry (expired, or expiring within --expiry-warning)
+  - Missing scopes githelper needs (--require-scope, default: repo)
+  - Broad scopes githelper never needs (e.g. delete_repo, admin:org)
+
+Exits with an error if any token has an error or critical finding.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthAudit,
+}
+
+func init() {
+	authAuditCmd.Flags().DurationVar(&auditExpiryWarning, "expiry-warning", ghclient.DefaultExpiryWarning, "Warn about tokens expiring within this long")
+	authAuditCmd.Flags().StringSliceVar(&auditRequiredScopes, "require-scope", ghclient.DefaultRequiredScopes, "Scopes every token must have")
+	authCmd.AddCommand(authAuditCmd)
+}
+
+// auditedToken is one distinct token and everywhere it was found
+type auditedToken struct {
+	token    string
+	Token    string              `json:"token"` // Redacted
+	Sources  []string            `json:"sources"`
+	Info     *ghclient.TokenInfo `json:"info,omitempty"`
+	Severity string              `json:"severity,omitempty"`
+	Findings []ghclient.Finding  `json:"findings"`
+}
+
+func runAuthAudit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Set up output
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	tokens, warnings := collectAuditTokens(ctx)
+	if !out.IsJSON() {
+		out.Header("🔐 GitHub Token Audit")
+		out.Separator()
+		for _, w := range warnings {
+			out.Warning(w)
+		}
+	}
+
+	opts := ghclient.AuditOptions{ExpiryWarning: auditExpiryWarning, RequiredScopes: auditRequiredScopes}
+	failed := 0
+	for _, t := range tokens {
+		auditCtx, cancel := context.WithTimeout(ctx, constants.DefaultOperationTimeout)
+		t.Info, t.Findings = ghclient.NewClient(auditCtx, t.token).Audit(opts)
+		cancel()
+
+		t.Severity = ghclient.WorstSeverity(t.Findings)
+		if t.Severity == ghclient.SeverityError || t.Severity == ghclient.SeverityCritical {
+			failed++
+		}
+		if t.Findings == nil {
+			t.Findings = []ghclient.Finding{}
+		}
+
+		if !out.IsJSON() {
+			printTokenAudit(out, t)
+		}
+	}
+
+	if out.IsJSON() {
+		if tokens == nil {
+			tokens = []*auditedToken{}
+		}
+		if warnings == nil {
+			warnings = []string{}
+		}
+		out.JSON(map[string]interface{}{
+			"tokens":   tokens,
+			"warnings": warnings,
+			"failed":   failed,
+		})
+	} else {
+		fmt.Println()
+		switch {
+		case len(tokens) == 0:
+			out.Warning("No GitHub tokens found")
+		case failed > 0:
+			out.Error(fmt.Sprintf("%d of %d token(s) need attention", failed, len(tokens)))
+		default:
+			out.Success(fmt.Sprintf("%d token(s) audited, no errors", len(tokens)))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d token(s) failed the audit", failed)
+	}
+	return nil
+}
+
+func printTokenAudit(out *ui.Output, t *auditedToken) {
+	fmt.Println()
+	fmt.Printf("%s (%s)\n", t.Token, strings.Join(t.Sources, ", "))
+	if t.Info != nil {
+		fmt.Printf("  User: %s\n", t.Info.Login)
+		if t.Info.ScopesKnown {
+			fmt.Printf("  Scopes: %s\n", strings.Join(t.Info.Scopes, ", "))
+		}
+		if !t.Info.Expires.IsZero() {
+			fmt.Printf("  Expires: %s\n", t.Info.Expires.Format("2006-01-02"))
+		}
+	}
+
+	if len(t.Findings) == 0 {
+		out.Success("  ✓ No problems found")
+		return
+	}
+	for _, f := range t.Findings {
+		line := fmt.Sprintf("  [%s] %s", f.Severity, f.Message)
+		switch f.Severity {
+		case ghclient.SeverityError, ghclient.SeverityCritical:
+			out.Error(line)
+		case ghclient.SeverityWarning:
+			out.Warning(line)
+		default:
+			out.Info(line)
+		}
+	}
+}
+
+// collectAuditTokens gathers every distinct token from the GitHub token chain
+// and the secrets backend. Problems reaching the secrets backend are returned
+// as warnings; the chain's tokens are still audited.
+func collectAuditTokens(ctx context.Context) ([]*auditedToken, []string) {
+	var tokens []*auditedToken
+	var warnings []string
+	byValue := make(map[string]*auditedToken)
+	add := func(token, source string) {
+		if t, ok := byValue[token]; ok {
+			t.Sources = append(t.Sources, source)
+			return
+		}
+		t := &auditedToken{token: token, Token: ghclient.RedactToken(token), Sources: []string{source}}
+		byValue[token] = t
+		tokens = append(tokens, t)
+	}
+
+	for _, c := range remotegithub.TokenCandidates() {
+		// GitHub App installation tokens are short-lived and have no scopes to audit
+		if c.Token == "" {
+			continue
+		}
+		add(c.Token, string(c.Source))
+	}
+
+	cfgMgr, err := config.NewManager(ctx, "")
+	if err != nil {
+		return tokens, append(warnings, fmt.Sprintf("Secrets backend unavailable, skipping stored PATs: %v", err))
+	}
+	provider := cfgMgr.Secrets()
+	layout := cfgMgr.Layout()
+
+	if path, err := secrets.ResolvePATPath(provider, layout, secrets.Vars{}); err == nil {
+		if pat, err := secrets.GetPAT(provider, layout, secrets.Vars{}); err == nil {
+			add(pat, fmt.Sprintf("%s: %s", provider.Name(), path))
+		}
+	}
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return tokens, append(warnings, fmt.Sprintf("State unavailable, skipping repository PATs: %v", err))
+	}
+	repos, err := stateMgr.ListRepositories()
+	if err != nil {
+		return tokens, append(warnings, fmt.Sprintf("State unavailable, skipping repository PATs: %v", err))
+	}
+
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		repo := repos[name]
+		if repo.GitHub == nil || !repo.GitHub.Enabled {
+			continue
+		}
+
+		// Only repository-specific PATs; the default one is already included
+		vars := repoSecretVars(name, repo)
+		candidates, err := layout.PATPaths(vars)
+		if err != nil || len(candidates) < 2 {
+			continue
+		}
+		if path, err := secrets.ResolvePATPath(provider, layout, vars); err == nil && path == candidates[0] {
+			if pat, err := secrets.GetPAT(provider, layout, vars); err == nil {
+				add(pat, fmt.Sprintf("%s: %s", provider.Name(), path))
+			}
+		}
+	}
+
+	return tokens, warnings
+}
@@ -22,15 +22,16 @@ var doctorCmd = &cobra.Command{
 	Long: `Performs a comprehensive health check of githelper configuration.
 
 Checks:
-- Vault connectivity and configuration
+- Secrets backend connectivity and configuration
 - Git installation and version
 - Repository configurations
 - GitHub integration status
-- SSH keys and credentials
+- SSH keys and credentials, and that each repository's secrets resolve
 - Sync status
 - Hook installations
 
-Use --credentials to show detailed credential inventory.
+Use --credentials to show detailed credential inventory, including the
+default PAT's expiry and scopes.
 Use --repo <name> to check a specific repository.
 Use --auto-fix to automatically fix common issues.`,
 	RunE: runDoctor,
@@ -67,11 +68,11 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	}
 	checkGitInstallation(out, results)
 
-	// Check 2: Vault connectivity
+	// Check 2: Secrets backend connectivity
 	if !out.IsJSON() {
-		fmt.Println("\nVault Configuration:")
+		fmt.Println("\nSecrets Configuration:")
 	}
-	vaultClient := checkVault(out, results)
+	cfgMgr := checkSecrets(out, results)
 
 	// Check 3: State file
 	if !out.IsJSON() {
@@ -83,18 +84,24 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	if !out.IsJSON() {
 		fmt.Println("\nRepositories:")
 	}
-	checkRepositories(out, results, stateMgr, vaultClient)
+	checkRepositories(out, results, stateMgr)
 
-	// Check 5: Credentials (if requested)
+	// Check 5: Secret paths for each repository
+	if !out.IsJSON() {
+		fmt.Println("\nSecret Resolution:")
+	}
+	checkSecretResolution(out, results, cfgMgr, stateMgr)
+
+	// Check 6: Credentials (if requested)
 	if showCredentials {
 		if !out.IsJSON() {
 			fmt.Println("\n" + strings.Repeat("━", 60))
 			fmt.Println("\n📋 Credential Inventory:")
 		}
-		checkCredentials(out, results, vaultClient, stateMgr)
+		checkCredentials(out, results, cfgMgr, stateMgr)
 	}
 
-	// Check 6: Auto-fix (if requested)
+	// Check 7: Auto-fix (if requested)
 	if autoFix && stateMgr != nil {
 		if !out.IsJSON() {
 			fmt.Println("\n" + strings.Repeat("━", 60))
@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/lcgerke/githelper/internal/autofix"
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/constants"
 	"github.com/lcgerke/githelper/internal/git"
+	ghclient "github.com/lcgerke/githelper/internal/github"
+	"github.com/lcgerke/githelper/internal/secrets"
 	"github.com/lcgerke/githelper/internal/state"
 	"github.com/lcgerke/githelper/internal/ui"
-	"github.com/lcgerke/githelper/internal/vault"
 )
 
 // handleCheckError is a helper to reduce duplication in error handling
@@ -35,49 +39,155 @@ func checkGitInstallation(out *ui.Output, results *DiagnosticResults) {
 	results.AddCheck("git_installation", "ok", "Git installed and accessible", nil)
 }
 
-func checkVault(out *ui.Output, results *DiagnosticResults) *vault.Client {
+func checkSecrets(out *ui.Output, results *DiagnosticResults) *config.Manager {
 	ctx := context.Background()
 
-	// Try to create vault client
-	vaultClient, err := vault.NewClient(ctx)
+	// Try to create the configured provider
+	cfgMgr, err := config.NewManager(ctx, "")
 	if err != nil {
-		handleCheckError(out, results, "vault_connectivity", fmt.Sprintf("  ✗ Vault client creation failed: %v", err), err)
+		handleCheckError(out, results, "secrets_connectivity", fmt.Sprintf("  ✗ Secrets provider creation failed: %v", err), err)
 		return nil
 	}
+	provider := cfgMgr.Secrets()
+	name := provider.Name()
 
-	// Test connectivity by trying to get config
-	if !vaultClient.IsReachable() {
+	// Test connectivity
+	if !provider.IsReachable() {
 		if !out.IsJSON() {
-			out.Warning("  ⚠ Vault not reachable (will use cache if available)")
+			out.Warning(fmt.Sprintf("  ⚠ %s not reachable (will use cache if available)", name))
 		}
-		results.AddCheck("vault_connectivity", "warning", "Vault not reachable", nil)
-		return vaultClient
+		results.AddCheck("secrets_connectivity", "warning", name+" not reachable", map[string]interface{}{
+			"backend": name,
+		})
+		return cfgMgr
 	}
 
 	// Try to get config
-	cfg, err := vaultClient.GetConfig()
+	cfg, err := secrets.GetConfig(provider, cfgMgr.Layout())
 	if err != nil {
 		if !out.IsJSON() {
-			out.Warning(fmt.Sprintf("  ⚠ Vault reachable but config not found: %v", err))
+			out.Warning(fmt.Sprintf("  ⚠ %s reachable but config not found: %v", name, err))
 		}
-		results.AddCheck("vault_connectivity", "warning", "Vault reachable but config not found", nil)
-		return vaultClient
+		results.AddCheck("secrets_connectivity", "warning", name+" reachable but config not found", map[string]interface{}{
+			"backend": name,
+		})
+		return cfgMgr
 	}
 
-	vaultAddr := os.Getenv("VAULT_ADDR")
-	if vaultAddr == "" {
-		vaultAddr = "default"
+	details := map[string]interface{}{
+		"backend":           name,
+		"bare_repo_pattern": cfg.BareRepoPattern,
+	}
+	if vp, ok := provider.(*secrets.VaultProvider); ok {
+		vaultAddr := os.Getenv("VAULT_ADDR")
+		if vaultAddr == "" {
+			vaultAddr = "default"
+		}
+		mount, kvVersion := vp.Mount()
+		name = fmt.Sprintf("%s: %s (%s, KV v%d)", name, vaultAddr, mount, kvVersion)
+		details["address"] = vaultAddr
+		details["mount"] = mount
+		details["kv_version"] = kvVersion
 	}
 
 	if !out.IsJSON() {
-		out.Success(fmt.Sprintf("  ✓ Vault connected: %s", vaultAddr))
+		out.Success(fmt.Sprintf("  ✓ Secrets available from %s", name))
 	}
-	results.AddCheck("vault_connectivity", "ok", "Vault connected", map[string]interface{}{
-		"address":           vaultAddr,
-		"bare_repo_pattern": cfg.BareRepoPattern,
-	})
+	results.AddCheck("secrets_connectivity", "ok", "Secrets available", details)
+
+	return cfgMgr
+}
+
+// repoSecretVars fills the secret layout placeholders for a repository
+func repoSecretVars(name string, repo *state.Repository) secrets.Vars {
+	vars := secrets.Vars{Repo: name}
+	if repo.GitHub != nil {
+		vars.Org = repo.GitHub.User
+	}
+	return vars
+}
+
+// checkSecretResolution verifies that every managed repository's SSH key and
+// PAT resolve under the configured layout
+func checkSecretResolution(out *ui.Output, results *DiagnosticResults, cfgMgr *config.Manager, stateMgr *state.Manager) {
+	if cfgMgr == nil || stateMgr == nil {
+		if !out.IsJSON() {
+			out.Warning("  ⚠ Cannot check secret paths (secrets or state unavailable)")
+		}
+		return
+	}
+	if !cfgMgr.IsReachable() {
+		if !out.IsJSON() {
+			out.Warning(fmt.Sprintf("  ⚠ Cannot check secret paths (%s not reachable)", cfgMgr.Secrets().Name()))
+		}
+		results.AddCheck("secret_resolution", "warning", "Secrets backend not reachable", nil)
+		return
+	}
+
+	st, err := stateMgr.Load()
+	if err != nil {
+		return
+	}
+
+	provider := cfgMgr.Secrets()
+	layout := cfgMgr.Layout()
+
+	names := make([]string, 0, len(st.Repositories))
+	for name := range st.Repositories {
+		if repoFilter == "" || name == repoFilter {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		results.AddCheck("secret_resolution", "ok", "No repositories configured", nil)
+		return
+	}
+
+	repoResults := make(map[string]interface{})
+	unresolved := 0
 
-	return vaultClient
+	for _, name := range names {
+		vars := repoSecretVars(name, st.Repositories[name])
+		result := make(map[string]interface{})
+		var problems []string
+
+		if path, err := secrets.ResolveSSHKeyPath(provider, layout, vars); err != nil {
+			problems = append(problems, fmt.Sprintf("SSH key: %v", err))
+		} else {
+			result["ssh_key"] = path
+		}
+		if path, err := secrets.ResolvePATPath(provider, layout, vars); err != nil {
+			problems = append(problems, fmt.Sprintf("PAT: %v", err))
+		} else {
+			result["pat"] = path
+		}
+
+		if len(problems) > 0 {
+			unresolved++
+			result["status"] = "warning"
+			result["issues"] = problems
+			if !out.IsJSON() {
+				out.Warning(fmt.Sprintf("  ⚠ %s:", name))
+				for _, p := range problems {
+					fmt.Printf("      - %s\n", p)
+				}
+			}
+		} else {
+			result["status"] = "ok"
+			if !out.IsJSON() {
+				out.Success(fmt.Sprintf("  ✓ %s: SSH key %s, PAT %s", name, result["ssh_key"], result["pat"]))
+			}
+		}
+		repoResults[name] = result
+	}
+
+	if unresolved == 0 {
+		results.AddCheck("secret_resolution", "ok", fmt.Sprintf("Secrets resolve for all %d repositories", len(names)), repoResults)
+	} else {
+		results.AddCheck("secret_resolution", "warning", fmt.Sprintf("Secrets missing for %d/%d repositories", unresolved, len(names)), repoResults)
+	}
 }
 
 func checkStateFile(out *ui.Output, results *DiagnosticResults) *state.Manager {
@@ -104,7 +214,7 @@ func checkStateFile(out *ui.Output, results *DiagnosticResults) *state.Manager {
 	return stateMgr
 }
 
-func checkRepositories(out *ui.Output, results *DiagnosticResults, stateMgr *state.Manager, vaultClient *vault.Client) {
+func checkRepositories(out *ui.Output, results *DiagnosticResults, stateMgr *state.Manager) {
 	if stateMgr == nil {
 		if !out.IsJSON() {
 			out.Warning("  ⚠ Cannot check repositories (state manager unavailable)")
@@ -134,7 +244,7 @@ func checkRepositories(out *ui.Output, results *DiagnosticResults, stateMgr *sta
 			continue
 		}
 
-		repoHealth := checkRepository(out, name, repo, vaultClient)
+		repoHealth := checkRepository(out, name, repo)
 		repoResults[name] = repoHealth
 
 		if repoHealth["status"] == "ok" {
@@ -156,7 +266,7 @@ func checkRepositories(out *ui.Output, results *DiagnosticResults, stateMgr *sta
 	}
 }
 
-func checkRepository(out *ui.Output, name string, repo *state.Repository, vaultClient *vault.Client) map[string]interface{} {
+func checkRepository(out *ui.Output, name string, repo *state.Repository) map[string]interface{} {
 	result := make(map[string]interface{})
 	result["name"] = name
 	result["path"] = repo.Path
@@ -273,18 +383,21 @@ func checkHooks(out *ui.Output, repoPath string) map[string]bool {
 	return status
 }
 
-func checkCredentials(out *ui.Output, results *DiagnosticResults, vaultClient *vault.Client, stateMgr *state.Manager) {
-	if vaultClient == nil {
+func checkCredentials(out *ui.Output, results *DiagnosticResults, cfgMgr *config.Manager, stateMgr *state.Manager) {
+	if cfgMgr == nil {
 		if !out.IsJSON() {
-			out.Warning("\n  Vault unavailable - cannot check credentials")
+			out.Warning("\n  Secrets backend unavailable - cannot check credentials")
 		}
 		return
 	}
 
+	provider := cfgMgr.Secrets()
+	layout := cfgMgr.Layout()
 	credInventory := make(map[string]interface{})
+	patWarnings := 0
 
 	// Check default SSH key
-	_, err := vaultClient.GetSSHKey("default")
+	_, err := secrets.GetSSHKey(provider, layout, secrets.Vars{})
 	if err != nil {
 		if !out.IsJSON() {
 			out.Warning(fmt.Sprintf("  ⚠ Default SSH key not found: %v", err))
@@ -307,7 +420,7 @@ func checkCredentials(out *ui.Output, results *DiagnosticResults, vaultClient *v
 	}
 
 	// Check default PAT
-	defaultPAT, err := vaultClient.GetPAT("default")
+	defaultPAT, err := secrets.GetPAT(provider, layout, secrets.Vars{})
 	if err != nil {
 		if !out.IsJSON() {
 			out.Warning(fmt.Sprintf("  ⚠ Default PAT not found: %v", err))
@@ -317,24 +430,42 @@ func checkCredentials(out *ui.Output, results *DiagnosticResults, vaultClient *v
 			out.Success(fmt.Sprintf("  ✓ Default PAT found (length: %d)", len(defaultPAT)))
 		}
 		credInventory["default_pat"] = "configured"
+
+		// Check expiry and scopes (see 'githelper auth audit' for every token)
+		ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultOperationTimeout)
+		_, findings := ghclient.NewClient(ctx, defaultPAT).Audit(ghclient.AuditOptions{})
+		cancel()
+		for _, f := range findings {
+			if f.Severity == ghclient.SeverityInfo {
+				continue
+			}
+			patWarnings++
+			if !out.IsJSON() {
+				out.Warning(fmt.Sprintf("    ⚠ Default PAT: %s", f.Message))
+			}
+		}
+		credInventory["default_pat_findings"] = findings
 	}
 
-	// Check repo-specific credentials
+	// Check repo-specific credentials: an override is a key found at the
+	// repository's own path rather than the default one
 	if stateMgr != nil {
 		st, err := stateMgr.Load()
 		if err == nil {
 			repoCredentials := make(map[string]interface{})
-			for name := range st.Repositories {
-				repoSSH, err := vaultClient.GetSSHKey(name)
-				if err == nil {
+			for name, repo := range st.Repositories {
+				vars := repoSecretVars(name, repo)
+				candidates, err := layout.SSHKeyPaths(vars)
+				if err != nil || len(candidates) < 2 {
+					continue
+				}
+				if path, err := secrets.ResolveSSHKeyPath(provider, layout, vars); err == nil && path == candidates[0] {
 					repoCredentials[name] = map[string]string{
 						"ssh": "configured",
 					}
 					if !out.IsJSON() {
 						out.Success(fmt.Sprintf("  ✓ %s: SSH key override found", name))
 					}
-				} else {
-					_ = repoSSH // Avoid unused variable
 				}
 			}
 			if len(repoCredentials) > 0 {
@@ -343,6 +474,10 @@ func checkCredentials(out *ui.Output, results *DiagnosticResults, vaultClient *v
 		}
 	}
 
+	if patWarnings > 0 {
+		results.AddCheck("credentials", "warning", fmt.Sprintf("Default PAT has %d problem(s); run 'githelper auth audit'", patWarnings), credInventory)
+		return
+	}
 	results.AddCheck("credentials", "ok", "Credential inventory complete", credInventory)
 }
 
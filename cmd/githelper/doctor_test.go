@@ -575,7 +575,7 @@ func TestRunDoctor_OutputFormat(t *testing.T) {
 	// Verify expected sections appear
 	expectedSections := []string{
 		"Git Installation",
-		"Vault Configuration",
+		"Secrets Configuration",
 		"State Management",
 		"Repositories",
 	}
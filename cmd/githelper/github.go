@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/lcgerke/githelper/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -16,4 +19,16 @@ func init() {
 	githubCmd.AddCommand(githubStatusCmd)
 	githubCmd.AddCommand(githubCheckCmd)
 	githubCmd.AddCommand(githubSyncCmd)
+	githubCmd.AddCommand(githubProtectCmd)
+	githubCmd.AddCommand(githubReconcileCmd)
+}
+
+// githubPushURL returns the URL of a repository's GitHub mirror
+func githubPushURL(repo *state.Repository) string {
+	for _, m := range repo.MirrorSet() {
+		if m.Role == state.MirrorRoleGitHub {
+			return m.URL
+		}
+	}
+	return fmt.Sprintf("git@github.com:%s/%s.git", repo.GitHub.User, repo.GitHub.Repo)
 }
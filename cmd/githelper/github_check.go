@@ -47,15 +47,15 @@ func runGitHubCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("GitHub integration not configured. Run: githelper github setup %s", repoName)
 	}
 
-	// Get PAT from Vault
+	// Get PAT from the secrets backend
 	cfgMgr, err := config.NewManager(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to initialize config: %w", err)
 	}
 
-	pat, err := cfgMgr.GetPAT(repoName)
+	pat, err := cfgMgr.GetPAT(repoSecretVars(repoName, repo))
 	if err != nil {
-		return fmt.Errorf("failed to get PAT from Vault: %w", err)
+		return fmt.Errorf("failed to get PAT from %s: %w", cfgMgr.Secrets().Name(), err)
 	}
 
 	// Test GitHub API connection
@@ -0,0 +1,228 @@
+package main
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/remote"
+	"github.com/lcgerke/githelper/internal/remote/bitbucket"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	protectCheck bool
+	protectApply bool
+)
+
+var githubProtectCmd = &cobra.Command{
+	Use:   "protect [repo-name] --check|--apply",
+	Short: "Check or enforce the branch protection policy",
+	Long: `Compares the protection of each repository's default branch with the branch
+protection policy, and with --apply tightens it to match.
+
+The policy is read from the protection section of ~/.githelper/config.yaml:
+
+  protection:
+    require_reviews: true
+    require_status_checks: false
+    enforce_admins: false
+    forbid_force_push: true
+
+Without that section, reviews are required and force pushes are forbidden.
+A policy only ever adds protection; settings it doesn't mention are kept.
+Applying needs admin permission on the repository.
+
+Without a repository name, every GitHub-enabled repository is checked.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if protectCheck && protectApply {
+			return fmt.Errorf("give --check or --apply, not both")
+		}
+		return cobra.MaximumNArgs(1)(cmd, args)
+	},
+	RunE: runGitHubProtect,
+}
+
+func init() {
+	githubProtectCmd.Flags().BoolVar(&protectCheck, "check", false, "Report policy violations without changing anything (default)")
+	githubProtectCmd.Flags().BoolVar(&protectApply, "apply", false, "Update branch protection to satisfy the policy")
+}
+
+// protectResult is the outcome of checking one repository
+type protectResult struct {
+	Repo       string                       `json:"repo"`
+	Branch     string                       `json:"branch,omitempty"`
+	Status     string                       `json:"status"` // compliant, noncompliant, applied, error
+	Violations []remote.ProtectionViolation `json:"violations,omitempty"`
+	Error      string                       `json:"error,omitempty"`
+}
+
+func runGitHubProtect(cmd *cobra.Command, args []string) error {
+	// Set up output
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	localCfg, err := config.LoadLocalConfig("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeConfig, "failed to load config", err)
+	}
+	policy := localCfg.ProtectionPolicy()
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeState, "failed to initialize state manager", err)
+	}
+
+	var names []string
+	repos := make(map[string]*state.Repository)
+	if len(args) == 0 {
+		all, err := stateMgr.ListRepositories()
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeState, "failed to list repositories", err)
+		}
+		for name, repo := range all {
+			if repo.GitHub != nil && repo.GitHub.Enabled {
+				names = append(names, name)
+				repos[name] = repo
+			}
+		}
+		sort.Strings(names)
+	} else {
+		repo, err := stateMgr.GetRepository(args[0])
+		if err != nil {
+			return errors.RepositoryNotFound(args[0])
+		}
+		if repo.GitHub == nil || !repo.GitHub.Enabled {
+			return fmt.Errorf("GitHub integration not configured. Run: githelper github setup %s", args[0])
+		}
+		names = []string{args[0]}
+		repos[args[0]] = repo
+	}
+
+	var results []protectResult
+	noncompliant, failed := 0, 0
+	for _, name := range names {
+		result := protectRepo(policy, name, repos[name], protectApply)
+		results = append(results, result)
+
+		switch result.Status {
+		case "error":
+			failed++
+		case "noncompliant":
+			noncompliant++
+		}
+
+		if out.IsJSON() {
+			continue
+		}
+		switch result.Status {
+		case "compliant":
+			out.Success(fmt.Sprintf("%s (%s): complies with policy", name, result.Branch))
+		case "applied":
+			out.Success(fmt.Sprintf("%s (%s): protection updated", name, result.Branch))
+		case "noncompliant":
+			out.Warning(fmt.Sprintf("%s (%s): does not comply with policy", name, result.Branch))
+		default:
+			out.Error(fmt.Sprintf("%s: %s", name, result.Error))
+		}
+		for _, v := range result.Violations {
+			out.Info(fmt.Sprintf("  - %s", v))
+		}
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"policy":       policy,
+			"repositories": results,
+			"noncompliant": noncompliant,
+			"failed":       failed,
+		})
+	} else if len(names) == 0 {
+		out.Info("No GitHub-enabled repositories found.")
+	} else if noncompliant > 0 {
+		out.Info("Run with --apply to update branch protection.")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("branch protection could not be checked or applied for %d of %d repositories", failed, len(names))
+	}
+	if noncompliant > 0 {
+		return fmt.Errorf("%d of %d repositories do not comply with the branch protection policy", noncompliant, len(names))
+	}
+	return nil
+}
+
+// protectRepo checks one repository's default branch against policy and, if
+// apply is set, updates its protection to comply
+func protectRepo(policy remote.ProtectionPolicy, name string, repo *state.Repository, apply bool) protectResult {
+	result := protectResult{Repo: name}
+	fail := func(err error) protectResult {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	client, err := remote.NewClient(githubPushURL(repo))
+	if err != nil {
+		return fail(err)
+	}
+
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		return fail(err)
+	}
+	result.Branch = branch
+
+	actual, err := client.GetBranchProtection(branch)
+	if err != nil {
+		return fail(err)
+	}
+
+	result.Violations = policy.Check(actual)
+	if len(result.Violations) == 0 {
+		result.Status = "compliant"
+		return result
+	}
+	if !apply {
+		result.Status = "noncompliant"
+		return result
+	}
+
+	// With unknown permissions, try the change and let the platform refuse it
+	canAdmin, err := client.CanAdmin()
+	if stderrors.Is(err, bitbucket.ErrPermissionsUnknown) {
+		canAdmin = true
+	} else if err != nil {
+		return fail(err)
+	}
+	if !canAdmin {
+		return fail(fmt.Errorf("admin permission on %s/%s is required to change branch protection", client.GetOwner(), client.GetRepo()))
+	}
+
+	if err := client.SetBranchProtection(branch, policy.Apply(actual)); err != nil {
+		return fail(err)
+	}
+
+	// Read the protection back: a platform may not support every setting
+	updated, err := client.GetBranchProtection(branch)
+	if err != nil {
+		return fail(err)
+	}
+	if remaining := policy.Check(updated); len(remaining) > 0 {
+		result.Violations = remaining
+		return fail(fmt.Errorf("protection was updated but still does not comply"))
+	}
+
+	result.Status = "applied"
+	return result
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/errors"
+	ghclient "github.com/lcgerke/githelper/internal/github"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var reconcileApply bool
+
+var githubReconcileCmd = &cobra.Command{
+	Use:   "reconcile [repo-name]",
+	Short: "Bring GitHub repository settings in line with config",
+	Long: `Compares each repository's GitHub settings with the settings section of
+~/.githelper/config.yaml and shows what would change. With --apply the
+changes are made through the GitHub API.
+
+  settings:
+    defaults:
+      has_wiki: false
+      has_projects: false
+      merge_methods: [squash]
+    repos:
+      myproject:
+        description: Unified Git management tool
+        homepage: https://example.com
+        topics: [git, cli]
+        visibility: public
+
+Settings a block doesn't name are left alone. Per-repository entries
+override the defaults. Without a repository name, every GitHub-enabled
+repository is reconciled.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGitHubReconcile,
+}
+
+func init() {
+	githubReconcileCmd.Flags().BoolVar(&reconcileApply, "apply", false, "Apply the planned changes")
+}
+
+// reconcileResult is the plan (and outcome) for one repository
+type reconcileResult struct {
+	Repo    string                   `json:"repo"`
+	GitHub  string                   `json:"github,omitempty"`
+	Status  string                   `json:"status"` // unmanaged, in_sync, planned, applied, error
+	Changes []ghclient.SettingChange `json:"changes,omitempty"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+func runGitHubReconcile(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Set up output
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	localCfg, err := config.LoadLocalConfig("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeConfig, "failed to load config", err)
+	}
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeState, "failed to initialize state manager", err)
+	}
+
+	var names []string
+	repos := make(map[string]*state.Repository)
+	if len(args) == 0 {
+		all, err := stateMgr.ListRepositories()
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeState, "failed to list repositories", err)
+		}
+		for name, repo := range all {
+			if repo.GitHub != nil && repo.GitHub.Enabled {
+				names = append(names, name)
+				repos[name] = repo
+			}
+		}
+		sort.Strings(names)
+	} else {
+		repo, err := stateMgr.GetRepository(args[0])
+		if err != nil {
+			return errors.RepositoryNotFound(args[0])
+		}
+		if repo.GitHub == nil || !repo.GitHub.Enabled {
+			return fmt.Errorf("GitHub integration not configured. Run: githelper github setup %s", args[0])
+		}
+		names = []string{args[0]}
+		repos[args[0]] = repo
+	}
+
+	cfgMgr, err := config.NewManager(ctx, "")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeConfig, "failed to initialize config manager", err)
+	}
+
+	var results []reconcileResult
+	pending, failed := 0, 0
+	for _, name := range names {
+		repo := repos[name]
+		result := reconcileRepo(cmd, cfgMgr, localCfg.RepoSettings(name), name, repo, reconcileApply)
+		results = append(results, result)
+
+		switch result.Status {
+		case "planned":
+			pending++
+		case "error":
+			failed++
+		}
+
+		if out.IsJSON() {
+			continue
+		}
+		switch result.Status {
+		case "unmanaged":
+			if verbose {
+				out.Info(fmt.Sprintf("%s: no settings configured", name))
+			}
+		case "in_sync":
+			out.Success(fmt.Sprintf("%s (%s): settings up to date", name, result.GitHub))
+		case "planned":
+			out.Warning(fmt.Sprintf("%s (%s): %d setting(s) to change", name, result.GitHub, len(result.Changes)))
+		case "applied":
+			out.Success(fmt.Sprintf("%s (%s): %d setting(s) changed", name, result.GitHub, len(result.Changes)))
+		default:
+			out.Error(fmt.Sprintf("%s: %s", name, result.Error))
+		}
+		for _, c := range result.Changes {
+			out.Info(fmt.Sprintf("  ~ %s", c))
+		}
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"repositories": results,
+			"applied":      reconcileApply,
+			"pending":      pending,
+			"failed":       failed,
+		})
+	} else if len(names) == 0 {
+		out.Info("No GitHub-enabled repositories found.")
+	} else if pending > 0 {
+		out.Info("Run with --apply to make these changes.")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("settings could not be reconciled for %d of %d repositories", failed, len(names))
+	}
+	return nil
+}
+
+// reconcileRepo plans, and if apply is set makes, one repository's settings
+// changes using its own credentials
+func reconcileRepo(cmd *cobra.Command, cfgMgr *config.Manager, desired ghclient.RepoSettings, name string, repo *state.Repository, apply bool) reconcileResult {
+	result := reconcileResult{Repo: name, GitHub: repo.GitHub.User + "/" + repo.GitHub.Repo}
+	if desired.IsEmpty() {
+		result.Status = "unmanaged"
+		return result
+	}
+	fail := func(err error) reconcileResult {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	client, err := newRepoGitHubClient(cmd.Context(), cfgMgr, repoSecretVars(name, repo))
+	if err != nil {
+		return fail(err)
+	}
+
+	current, err := client.GetRepository(repo.GitHub.User, repo.GitHub.Repo)
+	if err != nil {
+		return fail(err)
+	}
+
+	result.Changes = ghclient.PlanSettings(current, desired)
+	switch {
+	case len(result.Changes) == 0:
+		result.Status = "in_sync"
+	case !apply:
+		result.Status = "planned"
+	default:
+		if err := client.ApplySettings(repo.GitHub.User, repo.GitHub.Repo, result.Changes); err != nil {
+			return fail(err)
+		}
+		result.Status = "applied"
+	}
+	return result
+}
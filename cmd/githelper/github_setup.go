@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/lcgerke/githelper/internal/config"
 	"github.com/lcgerke/githelper/internal/constants"
@@ -13,9 +14,10 @@ import (
 	ghclient "github.com/lcgerke/githelper/internal/github"
 	remoteclient "github.com/lcgerke/githelper/internal/remote/github"
 	"github.com/lcgerke/githelper/internal/hooks"
+	"github.com/lcgerke/githelper/internal/remote"
+	"github.com/lcgerke/githelper/internal/secrets"
 	"github.com/lcgerke/githelper/internal/state"
 	"github.com/lcgerke/githelper/internal/ui"
-	"github.com/lcgerke/githelper/internal/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +27,7 @@ var (
 	createRepo       bool
 	privateRepo      bool
 	skipHooks        bool
+	setupMirrors     []string
 )
 
 var githubSetupCmd = &cobra.Command{
@@ -33,12 +36,15 @@ var githubSetupCmd = &cobra.Command{
 	Long: `Configures dual-push for a repository to sync with GitHub.
 
 This command:
-1. Retrieves SSH key from Vault
+1. Retrieves SSH key from the secrets backend (Vault by default)
 2. Configures repository-local SSH
 3. Creates GitHub repository (if requested)
-4. Sets up dual-push remotes (bare repo + GitHub)
+4. Sets up dual-push remotes (bare repo + GitHub + any --mirror)
 5. Installs hooks (with backup)
-6. Verifies configuration`,
+6. Verifies configuration
+
+Additional mirrors are given as name=url, e.g. --mirror backup=ssh://backup/repo.git.
+Each one becomes a push URL on origin and a fetch remote named after it.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGitHubSetup,
 }
@@ -49,6 +55,32 @@ func init() {
 	githubSetupCmd.Flags().BoolVar(&createRepo, "create", false, "Create GitHub repository if it doesn't exist")
 	githubSetupCmd.Flags().BoolVar(&privateRepo, "private", true, "Create private repository (used with --create)")
 	githubSetupCmd.Flags().BoolVar(&skipHooks, "skip-hooks", false, "Skip hook installation")
+	githubSetupCmd.Flags().StringArrayVar(&setupMirrors, "mirror", nil, "Additional mirror as name=url (repeatable)")
+}
+
+// parseMirrorFlags parses --mirror name=url values
+func parseMirrorFlags(values []string) ([]state.Mirror, error) {
+	var mirrors []state.Mirror
+	seen := map[string]bool{
+		constants.DefaultCoreRemote:   true,
+		constants.DefaultGitHubRemote: true,
+	}
+
+	for _, value := range values {
+		name, url, ok := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		url = strings.TrimSpace(url)
+		if !ok || name == "" || url == "" {
+			return nil, errors.InvalidConfiguration("mirror", fmt.Sprintf("%q is not in name=url form", value))
+		}
+		if seen[name] {
+			return nil, errors.InvalidConfiguration("mirror", fmt.Sprintf("remote name %q is already used", name))
+		}
+		seen[name] = true
+		mirrors = append(mirrors, state.Mirror{Name: name, URL: url})
+	}
+
+	return mirrors, nil
 }
 
 func runGitHubSetup(cmd *cobra.Command, args []string) error {
@@ -75,23 +107,30 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(errors.ErrorTypeConfig, "failed to initialize config manager", err)
 	}
 
+	backend := cfgMgr.Secrets().Name()
+
 	// Get configuration
 	cfg, fromCache, err := cfgMgr.GetConfig()
 	if err != nil {
-		return errors.Wrap(errors.ErrorTypeConfig, "failed to get configuration from Vault", err)
+		return errors.Wrap(errors.ErrorTypeConfig, fmt.Sprintf("failed to get configuration from %s", backend), err)
 	}
 
 	// Show config status
 	if !out.IsJSON() {
 		if fromCache {
 			age := cfgMgr.GetCacheAge()
-			out.Warningf("Configuration: Vault (cached %s ago) ⚡", formatDuration(age))
+			out.Warningf("Configuration: %s (cached %s ago) ⚡", backend, formatDuration(age))
 		} else {
-			out.Success("Configuration: Vault (live) ✓")
+			out.Success(fmt.Sprintf("Configuration: %s (live) ✓", backend))
 		}
 		fmt.Println()
 	}
 
+	extraMirrors, err := parseMirrorFlags(setupMirrors)
+	if err != nil {
+		return err
+	}
+
 	// Get repository from state
 	stateMgr, err := state.NewManager("")
 	if err != nil {
@@ -108,7 +147,7 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 		githubUser = cfg.GitHubUsername
 	}
 	if githubUser == "" {
-		return errors.InvalidConfiguration("github_username", "not specified (use --user flag or configure in Vault)")
+		return errors.InvalidConfiguration("github_username", "not specified (use --user flag or configure in the secrets backend)")
 	}
 
 	// Use repo name if GitHub repo name not specified
@@ -116,8 +155,8 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 		githubRepo = repoName
 	}
 
-	// Download SSH key from Vault
-	out.Info("Retrieving SSH key from Vault...")
+	// Download SSH key from the secrets backend
+	out.Info(fmt.Sprintf("Retrieving SSH key from %s...", backend))
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return errors.Wrap(errors.ErrorTypeFileSystem, "failed to get home directory", err)
@@ -126,14 +165,10 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 	sshDir := filepath.Join(homeDir, ".ssh")
 
 	// Download key to disk
-	vaultClient, err := vault.NewClient(ctx)
-	if err != nil {
-		return errors.Wrap(errors.ErrorTypeVault, "failed to create Vault client", err)
-	}
-
-	privateKeyPath, err := vaultClient.DownloadSSHKey(repoName, sshDir)
+	secretVars := secrets.Vars{Repo: repoName, Org: githubUser}
+	privateKeyPath, err := cfgMgr.DownloadSSHKey(secretVars, sshDir)
 	if err != nil {
-		return errors.Wrap(errors.ErrorTypeVault, "failed to download SSH key from Vault", err)
+		return errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("failed to download SSH key from %s", backend), err)
 	}
 	out.Success(fmt.Sprintf("SSH key downloaded to %s", privateKeyPath))
 
@@ -145,20 +180,22 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 	}
 	out.Success("Configured repository-local SSH")
 
-	// Get PAT from Vault and set as environment variable for new client
-	out.Info("Retrieving GitHub PAT from Vault...")
-	pat, err := cfgMgr.GetPAT(repoName)
-	if err != nil {
-		return errors.Wrap(errors.ErrorTypeVault, "failed to retrieve GitHub PAT from Vault", err)
+	// Get PAT from the secrets backend and set as environment variable for new
+	// client. Without one the client falls back to the rest of the token chain,
+	// e.g. a GitHub App installation.
+	out.Info(fmt.Sprintf("Retrieving GitHub PAT from %s...", backend))
+	if pat, err := cfgMgr.GetPAT(secretVars); err == nil {
+		os.Setenv("GITHUB_TOKEN", pat)
+		defer os.Unsetenv("GITHUB_TOKEN")
+	} else if _, source, chainErr := remoteclient.ResolveTokenSource(); chainErr == nil {
+		out.Info(fmt.Sprintf("No GitHub PAT in %s; using credentials from %s", backend, source))
+	} else {
+		return errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("failed to retrieve GitHub PAT from %s", backend), err)
 	}
 
-	// Set token as environment variable for new remote client
-	os.Setenv("GITHUB_TOKEN", pat)
-	defer os.Unsetenv("GITHUB_TOKEN")
-
 	// Create new GitHub client using remote package
 	ghRepoURL := fmt.Sprintf("git@github.com:%s/%s.git", githubUser, githubRepo)
-	ghClient, err := remoteclient.NewClient(ghRepoURL)
+	ghClient, err := remoteclient.NewClientWithTransport(ghRepoURL, remote.DefaultTransport())
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
@@ -180,11 +217,11 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 		if createRepo {
 			out.Infof("Creating GitHub repository: %s/%s...", githubUser, githubRepo)
 
-			// Prefer gh CLI if available (doesn't require PAT from Vault)
+			// Prefer gh CLI if available (doesn't require a stored PAT)
 			if ghclient.CheckGHCLIAvailable() && ghclient.CheckGHAuthenticated() {
 				out.Info("Using gh CLI for repository creation")
 				// Use old client for gh CLI support (backward compatibility)
-				oldClient := ghclient.NewClient(context.Background(), pat)
+				oldClient := ghclient.NewClient(context.Background(), "")
 				err := oldClient.CreateRepositoryViaGH(githubRepo, fmt.Sprintf("%s repository", repoName), privateRepo)
 				if err != nil {
 					return errors.Wrap(errors.ErrorTypeGitHub, "failed to create GitHub repository via gh CLI", err)
@@ -212,23 +249,21 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 	out.Info("Configuring dual-push remotes...")
 	bareRepoURL := repo.Remote
 
-	// Setup dual-push: one git push → pushes to both bare and GitHub
-	if err := gitClient.SetupDualPush(constants.DefaultCoreRemote, bareRepoURL, bareRepoURL, ghRepoURL); err != nil {
-		return errors.Wrap(errors.ErrorTypeGit, "failed to setup dual-push configuration", err)
+	mirrors := []state.Mirror{
+		{Name: constants.DefaultCoreRemote, URL: bareRepoURL, Role: state.MirrorRoleCore},
+		{Name: constants.DefaultGitHubRemote, URL: ghRepoURL, Role: state.MirrorRoleGitHub},
 	}
+	mirrors = append(mirrors, extraMirrors...)
 
-	// Verify dual-push configuration
-	verified, err := gitClient.VerifyDualPush(constants.DefaultCoreRemote, bareRepoURL, ghRepoURL)
+	pushURLs, err := configureMirrorRemotes(gitClient, mirrors)
 	if err != nil {
-		return errors.Wrap(errors.ErrorTypeGit, "failed to verify dual-push configuration", err)
-	}
-	if !verified {
-		return errors.New(errors.ErrorTypeGit, "dual-push verification failed - remote URLs do not match expected configuration")
+		return err
 	}
 
 	out.Success("Configured dual-push remotes")
-	out.Infof("  Push URL 1: %s", bareRepoURL)
-	out.Infof("  Push URL 2: %s", ghRepoURL)
+	for i, url := range pushURLs {
+		out.Infof("  Push URL %d: %s", i+1, url)
+	}
 
 	// Install hooks (unless skipped)
 	if !skipHooks {
@@ -260,6 +295,7 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 	repo.GitHub.User = githubUser
 	repo.GitHub.Repo = githubRepo
 	repo.GitHub.SyncStatus = "synced"
+	repo.Mirrors = mirrors
 
 	if err := stateMgr.AddRepository(repoName, repo); err != nil {
 		return errors.Wrap(errors.ErrorTypeState, "failed to update repository state", err)
@@ -270,7 +306,11 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 		out.Separator()
 		out.Success("GitHub integration complete!")
 		out.Info("Test with: git push")
-		out.Info("This will push to both remotes automatically")
+		if len(extraMirrors) > 0 {
+			out.Infof("This will push to all %d mirrors automatically", len(mirrors))
+		} else {
+			out.Info("This will push to both remotes automatically")
+		}
 	} else {
 		out.JSON(map[string]interface{}{
 			"status":       "success",
@@ -279,9 +319,45 @@ func runGitHubSetup(cmd *cobra.Command, args []string) error {
 			"github_repo":  githubRepo,
 			"github_url":   ghRepoURL,
 			"bare_url":     bareRepoURL,
+			"mirrors":      pushURLs,
 			"hooks_installed": !skipHooks,
 		})
 	}
 
 	return nil
 }
+
+// configureMirrorRemotes makes one push to the core remote reach every mirror,
+// and gives each other mirror (GitHub included) a fetch remote of its own so
+// status, sync and watch can compare against it by name. mirrors[0] is core.
+// It returns the configured push URLs.
+func configureMirrorRemotes(gitClient *git.Client, mirrors []state.Mirror) ([]string, error) {
+	core := mirrors[0]
+
+	pushURLs := make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		pushURLs = append(pushURLs, m.URL)
+	}
+
+	// Setup mirror push: one git push → pushes to bare, GitHub and any extra mirrors
+	if err := gitClient.ConfigureMirrorPush(core.Name, core.URL, pushURLs); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeGit, "failed to setup dual-push configuration", err)
+	}
+
+	// Verify push configuration
+	verified, err := gitClient.VerifyMirrorPush(core.Name, pushURLs)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeGit, "failed to verify dual-push configuration", err)
+	}
+	if !verified {
+		return nil, errors.New(errors.ErrorTypeGit, "dual-push verification failed - remote URLs do not match expected configuration")
+	}
+
+	for _, m := range mirrors[1:] {
+		if err := gitClient.EnsureRemote(m.Name, m.URL); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeGit, fmt.Sprintf("failed to configure mirror remote %s", m.Name), err)
+		}
+	}
+
+	return pushURLs, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/fleet"
+	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/watch"
+)
+
+// TestConfigureMirrorRemotes_WatchAndStatus checks that the remotes setup
+// configures are enough for watch and status, with no remotes added by hand
+func TestConfigureMirrorRemotes_WatchAndStatus(t *testing.T) {
+	tmp := t.TempDir()
+	core := filepath.Join(tmp, "core.git")
+	github := filepath.Join(tmp, "github.git")
+	backup := filepath.Join(tmp, "backup.git")
+	local := filepath.Join(tmp, "local")
+
+	for _, bare := range []string{core, github, backup} {
+		if err := runGitCommand(tmp, "init", "--bare", "-q", "-b", "main", bare); err != nil {
+			t.Fatalf("git init --bare: %v", err)
+		}
+	}
+	if err := runGitCommand(tmp, "clone", "-q", core, local); err != nil {
+		t.Fatalf("git clone: %v", err)
+	}
+	if err := runGitCommand(local, "-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "first"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	if err := runGitCommand(local, "push", "-q", "origin", "HEAD:main"); err != nil {
+		t.Fatalf("git push: %v", err)
+	}
+
+	mirrors := []state.Mirror{
+		{Name: "origin", URL: core, Role: state.MirrorRoleCore},
+		{Name: "github", URL: github, Role: state.MirrorRoleGitHub},
+		{Name: "backup", URL: backup},
+	}
+
+	gitClient := git.NewClient(local)
+	pushURLs, err := configureMirrorRemotes(gitClient, mirrors)
+	if err != nil {
+		t.Fatalf("configureMirrorRemotes() error = %v", err)
+	}
+	if len(pushURLs) != 3 {
+		t.Errorf("push URLs = %v, want one per mirror", pushURLs)
+	}
+	for _, m := range mirrors[1:] {
+		url, err := gitClient.GetRemoteURL(m.Name)
+		if err != nil || url != m.URL {
+			t.Errorf("fetch remote %s = %q, %v, want %s", m.Name, url, err, m.URL)
+		}
+	}
+
+	// Running setup again leaves the configuration unchanged
+	if _, err := configureMirrorRemotes(gitClient, mirrors); err != nil {
+		t.Fatalf("second configureMirrorRemotes() error = %v", err)
+	}
+
+	stateMgr, err := state.NewManager(filepath.Join(tmp, "state"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	err = stateMgr.AddRepository("demo", &state.Repository{
+		Path:    local,
+		Remote:  core,
+		GitHub:  &state.GitHub{Enabled: true, User: "user", Repo: "demo"},
+		Mirrors: mirrors,
+	})
+	if err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	// watch pushes core's main to both mirrors
+	results, err := watch.NewWatcher(stateMgr, watch.DefaultOptions()).RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != watch.ResultPushed {
+		t.Fatalf("watch results = %+v, want pushed", results)
+	}
+	want, _ := exec.Command("git", "--git-dir", core, "rev-parse", "main").Output()
+	for _, bare := range []string{github, backup} {
+		got, err := exec.Command("git", "--git-dir", bare, "rev-parse", "main").Output()
+		if err != nil || strings.TrimSpace(string(got)) != strings.TrimSpace(string(want)) {
+			t.Errorf("%s main = %q, %v, want %q", bare, got, err, want)
+		}
+	}
+
+	// status --all classifies the repository through the same remotes
+	repos, err := stateMgr.ListRepositories()
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	report := fleet.Classify(context.Background(), fleet.TargetsFromState(repos), 1, scenarios.DefaultDetectionOptions())
+	if len(report.Repositories) != 1 || report.Repositories[0].Error != "" {
+		t.Fatalf("fleet report = %+v", report.Repositories)
+	}
+}
@@ -77,19 +77,24 @@ func runGitHubStatus(cmd *cobra.Command, args []string) error {
 		status, err := gitClient.CheckDivergence(constants.DefaultCoreRemote, constants.DefaultCoreRemote, constants.DefaultBranch)
 		if err == nil {
 			// Update state based on actual status
-			if status.InSync {
-				repo.GitHub.SyncStatus = "synced"
-				repo.GitHub.NeedsRetry = false
-				repo.GitHub.LastError = ""
-			} else if status.GitHubAhead > 0 {
-				repo.GitHub.SyncStatus = "diverged"
-			} else if status.BareAhead > 0 {
-				repo.GitHub.SyncStatus = "behind"
-				repo.GitHub.NeedsRetry = true
-			}
-
-			// Save updated state
-			if err := stateMgr.AddRepository(repoName, repo); err != nil {
+			err := stateMgr.UpdateRepository(repoName, func(stored *state.Repository) error {
+				if stored.GitHub == nil {
+					return nil
+				}
+				if status.InSync {
+					stored.GitHub.SyncStatus = "synced"
+					stored.GitHub.NeedsRetry = false
+					stored.GitHub.LastError = ""
+				} else if status.GitHubAhead > 0 {
+					stored.GitHub.SyncStatus = "diverged"
+				} else if status.BareAhead > 0 {
+					stored.GitHub.SyncStatus = "behind"
+					stored.GitHub.NeedsRetry = true
+				}
+				repo.GitHub = stored.GitHub
+				return nil
+			})
+			if err != nil {
 				if !quiet {
 					out.Warning(fmt.Sprintf("Failed to update state: %v", err))
 				}
@@ -107,6 +112,7 @@ func runGitHubStatus(cmd *cobra.Command, args []string) error {
 			"needs_retry":  repo.GitHub.NeedsRetry,
 			"last_error":   repo.GitHub.LastError,
 			"push_urls":    pushURLs,
+			"mirrors":      repo.MirrorSet(),
 		})
 	} else {
 		out.Header(fmt.Sprintf("GitHub Status: %s", repoName))
@@ -132,6 +138,17 @@ func runGitHubStatus(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %d. %s\n", i+1, url)
 		}
 
+		if mirrors := repo.MirrorSet(); len(mirrors) > 0 {
+			fmt.Println("\nMirrors:")
+			for _, m := range mirrors {
+				if m.Role != "" {
+					fmt.Printf("  %s (%s): %s\n", m.Name, m.Role, m.URL)
+				} else {
+					fmt.Printf("  %s: %s\n", m.Name, m.URL)
+				}
+			}
+		}
+
 		out.Separator()
 	}
 
@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/lcgerke/githelper/internal/constants"
 	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/scenarios"
 	"github.com/lcgerke/githelper/internal/state"
 	"github.com/lcgerke/githelper/internal/ui"
 	"github.com/spf13/cobra"
@@ -14,6 +17,7 @@ import (
 var (
 	retryGitHub bool
 	branch      string
+	syncDryRun  bool
 )
 
 var githubSyncCmd = &cobra.Command{
@@ -23,10 +27,16 @@ var githubSyncCmd = &cobra.Command{
 
 This command:
 1. Fetches from both remotes
-2. Detects divergence between bare and GitHub
-3. Pushes missing commits from bare to GitHub
-4. Updates sync status in state file
+2. Plans every branch and tag: in sync, fast-forwardable, missing on
+   GitHub, GitHub ahead, diverged, or only on GitHub
+3. Pushes the safe updates (fast-forwards and refs missing on GitHub)
+4. Reports a per-ref result and updates sync status in state file
 
+Nothing is ever force-pushed. Diverged refs and GitHub-ahead refs are
+reported for manual resolution.
+
+Use --branch to limit the sync to a single branch.
+Use --dry-run to show the plan without pushing.
 Use --retry-github to force sync even after partial push failures.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGitHubSync,
@@ -34,7 +44,8 @@ Use --retry-github to force sync even after partial push failures.`,
 
 func init() {
 	githubSyncCmd.Flags().BoolVar(&retryGitHub, "retry-github", false, "Retry syncing to GitHub after partial failure")
-	githubSyncCmd.Flags().StringVar(&branch, "branch", constants.DefaultBranch, fmt.Sprintf("Branch to sync (default: %s)", constants.DefaultBranch))
+	githubSyncCmd.Flags().StringVar(&branch, "branch", "", "Only sync this branch (default: all branches and tags)")
+	githubSyncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the sync plan without pushing")
 }
 
 func runGitHubSync(cmd *cobra.Command, args []string) error {
@@ -115,10 +126,16 @@ func runGitHubSync(cmd *cobra.Command, args []string) error {
 		githubRemoteName = "github-temp"
 	}
 
-	// Check divergence
-	out.Info(fmt.Sprintf("Checking divergence between bare and GitHub (branch: %s)...", branch))
+	// Build per-ref plan
+	var branches []string
+	if branch != "" {
+		branches = []string{branch}
+		out.Info(fmt.Sprintf("Planning sync between bare and GitHub (branch: %s)...", branch))
+	} else {
+		out.Info("Planning sync between bare and GitHub (all branches and tags)...")
+	}
 
-	status, err := gitClient.CheckDivergence(bareRemote, githubRemoteName, branch)
+	plan, err := scenarios.BuildSyncPlan(gitClient, bareRemote, githubRemoteName, branches)
 	if err != nil {
 		if out.IsJSON() {
 			out.JSON(map[string]interface{}{
@@ -126,132 +143,84 @@ func runGitHubSync(cmd *cobra.Command, args []string) error {
 				"error":  err.Error(),
 			})
 		} else {
-			out.Error(fmt.Sprintf("Failed to check divergence: %v", err))
+			out.Error(fmt.Sprintf("Failed to plan sync: %v", err))
 		}
 		return err
 	}
 
-	// Display status
-	if status.InSync {
+	// A requested branch that exists on neither remote is a mistake, not a sync
+	if missing := missingBranches(plan); len(missing) > 0 {
+		err := fmt.Errorf("branch not found on %s or %s: %s", bareRemote, githubRemoteName, strings.Join(missing, ", "))
 		if out.IsJSON() {
 			out.JSON(map[string]interface{}{
-				"status":       "success",
-				"in_sync":      true,
-				"bare_commit":  status.BareRef,
-				"github_commit": status.GitHubRef,
+				"status": "error",
+				"error":  err.Error(),
 			})
 		} else {
-			out.Success("Remotes are in sync!")
-			out.Infof("  Bare commit:   %s", status.BareRef[:8])
-			out.Infof("  GitHub commit: %s", status.GitHubRef[:8])
+			out.Error(err.Error())
 		}
-
-		// Update state
-		repo.GitHub.SyncStatus = "synced"
-		repo.GitHub.NeedsRetry = false
-		repo.GitHub.LastError = ""
-		if err := stateMgr.AddRepository(repoName, repo); err != nil {
-			return fmt.Errorf("failed to update state: %w", err)
-		}
-
-		return nil
+		return err
 	}
 
-	// Display divergence
-	if !out.IsJSON() {
-		out.Warning("Divergence detected:")
-		if status.BareAhead > 0 {
-			out.Infof("  Bare is ahead by %d commit(s)", status.BareAhead)
-		}
-		if status.GitHubAhead > 0 {
-			out.Infof("  GitHub is ahead by %d commit(s)", status.GitHubAhead)
-		}
+	// Apply safe updates (fast-forwards and refs missing on GitHub)
+	var applyErr error
+	if !syncDryRun && len(plan.Pending()) > 0 {
+		out.Infof("Pushing %d ref(s) to GitHub...", len(plan.Pending()))
+		applyErr = scenarios.ApplySyncPlan(gitClient, plan)
 	}
 
-	// Check for conflicting situation
-	if status.GitHubAhead > 0 {
-		errMsg := "GitHub has commits that bare repository doesn't have - manual resolution required"
-		if out.IsJSON() {
-			out.JSON(map[string]interface{}{
-				"status":        "error",
-				"in_sync":       false,
-				"bare_ahead":    status.BareAhead,
-				"github_ahead":  status.GitHubAhead,
-				"error":         errMsg,
-			})
-		} else {
-			out.Error(errMsg)
-			out.Info("This typically means commits were pushed directly to GitHub.")
-			out.Info("Resolve manually by:")
-			out.Info("  1. git fetch github")
-			out.Info("  2. git merge github/main (or rebase)")
-			out.Info("  3. git push")
-		}
+	summary := plan.Summary()
 
-		// Update state
-		repo.GitHub.SyncStatus = "diverged"
-		repo.GitHub.NeedsRetry = false
-		repo.GitHub.LastError = errMsg
-		stateMgr.AddRepository(repoName, repo)
-
-		return fmt.Errorf("%s", errMsg)
+	if out.IsJSON() {
+		status := "success"
+		if applyErr != nil || summary.Manual > 0 {
+			status = "error"
+		}
+		out.JSON(map[string]interface{}{
+			"status":  status,
+			"dry_run": syncDryRun,
+			"refs":    plan.Refs,
+			"summary": summary,
+		})
+	} else {
+		printSyncPlan(out, plan, syncDryRun)
 	}
 
-	// GitHub is behind - we can sync
-	if status.BareAhead > 0 {
-		out.Infof("Syncing %d commit(s) to GitHub...", status.BareAhead)
-
-		if err := gitClient.SyncToGitHub(bareRemote, githubRemoteName, branch); err != nil {
-			if out.IsJSON() {
-				out.JSON(map[string]interface{}{
-					"status":      "error",
-					"bare_ahead":  status.BareAhead,
-					"error":       err.Error(),
-				})
-			} else {
-				out.Error(fmt.Sprintf("Failed to sync to GitHub: %v", err))
-			}
+	if syncDryRun {
+		return nil
+	}
 
-			// Update state
+	// Update state
+	err = stateMgr.UpdateRepository(repoName, func(repo *state.Repository) error {
+		if repo.GitHub == nil {
+			repo.GitHub = &state.GitHub{}
+		}
+		switch {
+		case summary.Manual > 0:
+			repo.GitHub.SyncStatus = "diverged"
+			repo.GitHub.NeedsRetry = false
+			repo.GitHub.LastError = fmt.Sprintf("%d ref(s) need manual resolution", summary.Manual)
+		case applyErr != nil:
 			repo.GitHub.SyncStatus = "behind"
 			repo.GitHub.NeedsRetry = true
-			repo.GitHub.LastError = err.Error()
-			stateMgr.AddRepository(repoName, repo)
-
-			return err
-		}
-
-		// Verify sync
-		out.Info("Verifying sync...")
-		verifyStatus, err := gitClient.CheckDivergence(bareRemote, githubRemoteName, branch)
-		if err != nil {
-			return fmt.Errorf("failed to verify sync: %w", err)
-		}
-
-		if !verifyStatus.InSync {
-			return fmt.Errorf("sync verification failed - remotes still diverged")
-		}
-
-		if out.IsJSON() {
-			out.JSON(map[string]interface{}{
-				"status":         "success",
-				"synced":         true,
-				"commits_synced": status.BareAhead,
-				"bare_commit":    verifyStatus.BareRef,
-				"github_commit":  verifyStatus.GitHubRef,
-			})
-		} else {
-			out.Success(fmt.Sprintf("Synced %d commit(s) to GitHub", status.BareAhead))
-			out.Infof("  Commit: %s", verifyStatus.BareRef[:8])
+			repo.GitHub.LastError = applyErr.Error()
+		default:
+			repo.GitHub.SyncStatus = "synced"
+			repo.GitHub.NeedsRetry = false
+			repo.GitHub.LastError = ""
 		}
+		repo.GitHub.LastSync = time.Now()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
 
-		// Update state
-		repo.GitHub.SyncStatus = "synced"
-		repo.GitHub.NeedsRetry = false
-		repo.GitHub.LastError = ""
-		if err := stateMgr.AddRepository(repoName, repo); err != nil {
-			return fmt.Errorf("failed to update state: %w", err)
-		}
+	if applyErr != nil {
+		return applyErr
+	}
+	if summary.Manual > 0 {
+		return fmt.Errorf("%d ref(s) need manual resolution", summary.Manual)
 	}
 
 	if !out.IsJSON() {
@@ -261,3 +230,70 @@ func runGitHubSync(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// missingBranches returns the planned branches found on neither remote
+func missingBranches(plan *scenarios.SyncPlan) []string {
+	var missing []string
+	for _, ref := range plan.Refs {
+		if ref.Kind == scenarios.RefKindBranch && ref.CoreHash == "" && ref.GitHubHash == "" {
+			missing = append(missing, ref.Name)
+		}
+	}
+	return missing
+}
+
+// printSyncPlan prints the per-ref plan and results in human-readable form
+func printSyncPlan(out *ui.Output, plan *scenarios.SyncPlan, dryRun bool) {
+	fmt.Println()
+	for _, ref := range plan.Refs {
+		label := fmt.Sprintf("%-6s %s", ref.Kind, ref.Name)
+
+		switch ref.Status {
+		case scenarios.RefSynced:
+			out.Success(fmt.Sprintf("%s: in sync", label))
+		case scenarios.RefFastForward, scenarios.RefMissingGitHub:
+			detail := "missing on GitHub"
+			if ref.Status == scenarios.RefFastForward {
+				detail = fmt.Sprintf("bare ahead by %d commit(s)", ref.CoreAhead)
+			}
+			switch ref.Result {
+			case scenarios.RefResultPushed:
+				out.Success(fmt.Sprintf("%s: pushed (%s)", label, detail))
+			case scenarios.RefResultFailed:
+				out.Error(fmt.Sprintf("%s: push failed (%s): %s", label, detail, ref.Error))
+			default:
+				if dryRun {
+					out.Infof("%s: would push (%s)", label, detail)
+				} else {
+					out.Infof("%s: %s", label, detail)
+				}
+			}
+		case scenarios.RefGitHubAhead:
+			out.Warning(fmt.Sprintf("%s: GitHub ahead by %d commit(s) - manual resolution required", label, ref.GitHubAhead))
+		case scenarios.RefDiverged:
+			if ref.Kind == scenarios.RefKindTag {
+				out.Warning(fmt.Sprintf("%s: tag differs between bare and GitHub - manual resolution required", label))
+			} else {
+				out.Warning(fmt.Sprintf("%s: diverged (bare +%d, GitHub +%d) - manual resolution required", label, ref.CoreAhead, ref.GitHubAhead))
+			}
+		case scenarios.RefMissingCore:
+			if ref.Error != "" {
+				out.Warning(fmt.Sprintf("%s: %s", label, ref.Error))
+			} else {
+				out.Infof("%s: only on GitHub (left untouched)", label)
+			}
+		}
+	}
+	fmt.Println()
+
+	summary := plan.Summary()
+	out.Infof("Refs: %d total, %d in sync, %d pushed, %d failed, %d need manual resolution",
+		summary.Total, summary.ByStatus[scenarios.RefSynced], summary.Pushed, summary.Failed, summary.Manual)
+
+	if summary.Manual > 0 {
+		out.Info("Resolve manual refs by:")
+		out.Info("  1. git fetch github")
+		out.Info("  2. git merge github/<branch> (or rebase)")
+		out.Info("  3. git push")
+	}
+}
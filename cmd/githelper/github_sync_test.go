@@ -523,3 +523,60 @@ func TestSyncDivergenceScenarios(t *testing.T) {
 }
 
 // Helper functions are in test_helpers.go
+
+// TestRunGitHubSync_UnknownBranch checks that --branch naming a branch on
+// neither remote fails instead of reporting the repository as synced
+func TestRunGitHubSync_UnknownBranch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	core := filepath.Join(home, "core.git")
+	github := filepath.Join(home, "github.git")
+	local := filepath.Join(home, "local")
+	for _, bare := range []string{core, github} {
+		if err := runGitCommand(home, "init", "--bare", "-q", "-b", "main", bare); err != nil {
+			t.Fatalf("git init --bare: %v", err)
+		}
+	}
+	if err := runGitCommand(home, "clone", "-q", core, local); err != nil {
+		t.Fatalf("git clone: %v", err)
+	}
+	if err := runGitCommand(local, "remote", "add", "github", github); err != nil {
+		t.Fatalf("git remote add: %v", err)
+	}
+
+	stateMgr, err := state.NewManager(filepath.Join(home, ".githelper"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	err = stateMgr.AddRepository("demo", &state.Repository{
+		Path:   local,
+		Remote: core,
+		GitHub: &state.GitHub{Enabled: true, User: "user", Repo: "demo", SyncStatus: "unknown"},
+	})
+	if err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	format = ""
+	noColor = true
+	branch = "nosuch"
+	defer func() { branch = "" }()
+
+	err = runGitHubSync(&cobra.Command{}, []string{"demo"})
+	if err == nil || !strings.Contains(err.Error(), "nosuch") {
+		t.Fatalf("runGitHubSync() error = %v, want the missing branch reported", err)
+	}
+
+	stateMgr, err = state.NewManager(filepath.Join(home, ".githelper"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	repo, err := stateMgr.GetRepository("demo")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if repo.GitHub.SyncStatus == "synced" {
+		t.Error("repository marked synced after syncing a missing branch")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/history"
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history <repo-name>",
+	Short: "Show classification history and sync trends",
+	Long: `Shows how a repository's sync state has changed over time.
+
+History is recorded whenever 'githelper status', 'status --all', or
+'githelper watch' classifies a managed repository.
+
+Shows:
+- Sync scenario transitions (e.g. S1 → S4 → S1)
+- Time spent out of sync since the first snapshot
+- The last time each remote was reachable
+
+Use --limit to control how many recent transitions are listed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Number of recent transitions to show (0 = all)")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	repoName := args[0]
+
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return fmt.Errorf("failed to initialize state manager: %w", err)
+	}
+
+	repo, err := stateMgr.GetRepository(repoName)
+	if err != nil {
+		return fmt.Errorf("repository not found: %w", err)
+	}
+
+	trend := history.Analyze(repo.History, time.Now())
+
+	transitions := trend.Transitions
+	if historyLimit > 0 && len(transitions) > historyLimit {
+		transitions = transitions[len(transitions)-historyLimit:]
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"repository":        repoName,
+			"trend":             trend,
+			"out_of_sync_ratio": trend.OutOfSyncRatio(),
+			"history":           repo.History,
+		})
+		return nil
+	}
+
+	out.Header(fmt.Sprintf("History: %s", repoName))
+
+	if trend.Snapshots == 0 {
+		out.Info("No history recorded yet.")
+		out.Info("Run 'githelper status' in the repository or 'githelper status --all' to start recording.")
+		return nil
+	}
+
+	fmt.Printf("Snapshots: %d since %s\n", trend.Snapshots, trend.Since.Format(time.RFC3339))
+	fmt.Printf("Current:   %s\n", describeScenario(trend.Current))
+	fmt.Println()
+
+	// Transitions
+	if len(trend.Transitions) == 0 {
+		out.Success(fmt.Sprintf("No transitions - always %s", trend.Current))
+	} else {
+		fmt.Printf("Transitions (%d total):\n", len(trend.Transitions))
+		for _, t := range transitions {
+			line := fmt.Sprintf("  %s  %s → %s", t.Time.Format("2006-01-02 15:04"), t.From, t.To)
+			if t.To == "S1" {
+				out.Success(line)
+			} else {
+				out.Warning(line)
+			}
+		}
+	}
+	fmt.Println()
+
+	// Out-of-sync time
+	fmt.Printf("Out of sync: %s of %s (%.1f%%)\n",
+		trend.OutOfSync.Round(time.Second), trend.Observed.Round(time.Second), trend.OutOfSyncRatio()*100)
+
+	// Reachability
+	fmt.Printf("Core last reachable:   %s\n", formatLastSeen(trend.LastCoreReachable))
+	fmt.Printf("GitHub last reachable: %s\n", formatLastSeen(trend.LastGitHubReachable))
+
+	return nil
+}
+
+// describeScenario returns "ID - Name" for a scenario ID
+func describeScenario(id string) string {
+	if def, ok := scenarios.LookupScenario(id); ok {
+		return fmt.Sprintf("%s - %s", id, def.Name)
+	}
+	return id
+}
+
+// formatLastSeen formats a last-seen time, or "never" if zero
+func formatLastSeen(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), time.Since(t).Round(time.Second))
+}
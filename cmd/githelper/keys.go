@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/keys"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var keysRotateAll bool
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage per-repository SSH deploy keys",
+	Long:  "Rotate the SSH deploy keys githelper uses to push to GitHub.",
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate <repo-name> | --all",
+	Short: "Replace a repository's SSH deploy key",
+	Long: `Replaces the SSH deploy key of a repository with a freshly generated one.
+
+This command:
+1. Generates a new ed25519 key pair
+2. Registers it as a deploy key on the GitHub repository
+3. Stores it in the secrets backend, replacing the old key
+4. Verifies the new key can push (dry run)
+5. Installs it under ~/.ssh and points core.sshCommand at it
+6. Removes the old deploy key from GitHub
+
+If any step up to 5 fails, the completed steps are undone and the old key
+keeps working. Old deploy keys that can't be removed in step 6 are reported
+as warnings; the new key stays in place.
+The secrets layout must give each repository its own SSH key path.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if keysRotateAll && len(args) > 0 {
+			return fmt.Errorf("give a repository name or --all, not both")
+		}
+		if !keysRotateAll && len(args) != 1 {
+			return fmt.Errorf("requires a repository name or --all")
+		}
+		return nil
+	},
+	RunE: runKeysRotate,
+}
+
+func init() {
+	keysRotateCmd.Flags().BoolVar(&keysRotateAll, "all", false, "Rotate the keys of every GitHub-enabled repository")
+	keysCmd.AddCommand(keysRotateCmd)
+}
+
+func runKeysRotate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Set up output
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeState, "failed to initialize state manager", err)
+	}
+
+	var names []string
+	repos := make(map[string]*state.Repository)
+	if keysRotateAll {
+		all, err := stateMgr.ListRepositories()
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeState, "failed to list repositories", err)
+		}
+		for name, repo := range all {
+			if repo.GitHub != nil && repo.GitHub.Enabled {
+				names = append(names, name)
+				repos[name] = repo
+			}
+		}
+		sort.Strings(names)
+	} else {
+		repo, err := stateMgr.GetRepository(args[0])
+		if err != nil {
+			return errors.RepositoryNotFound(args[0])
+		}
+		if repo.GitHub == nil || !repo.GitHub.Enabled {
+			return fmt.Errorf("GitHub integration not configured. Run: githelper github setup %s", args[0])
+		}
+		names = []string{args[0]}
+		repos[args[0]] = repo
+	}
+
+	cfgMgr, err := config.NewManager(ctx, "")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeConfig, "failed to initialize config manager", err)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeFileSystem, "failed to get home directory", err)
+	}
+	sshDir := filepath.Join(homeDir, ".ssh")
+
+	var results []map[string]interface{}
+	failed := 0
+	for _, name := range names {
+		repo := repos[name]
+		if !out.IsJSON() && !quiet {
+			out.Info(fmt.Sprintf("Rotating deploy key for %s (%s/%s)...", name, repo.GitHub.User, repo.GitHub.Repo))
+		}
+
+		result, err := rotateRepoKey(cmd, cfgMgr, name, repo, sshDir)
+		if err != nil {
+			failed++
+			results = append(results, map[string]interface{}{"repo": name, "status": "error", "error": err.Error()})
+			if !out.IsJSON() {
+				out.Error(fmt.Sprintf("%s: %v", name, err))
+			}
+			continue
+		}
+
+		results = append(results, map[string]interface{}{"repo": name, "status": "rotated", "result": result})
+		if !out.IsJSON() {
+			out.Success(fmt.Sprintf("%s: new key %s (deploy key %d)", name, result.Fingerprint, result.DeployKeyID))
+			if verbose {
+				out.Info(fmt.Sprintf("  Stored at %s, installed at %s, removed deploy keys %v", result.SecretPath, result.KeyPath, result.RemovedKeyIDs))
+			}
+			for _, warning := range result.Warnings {
+				out.Warning(fmt.Sprintf("%s: %s; remove it by hand", name, warning))
+			}
+		}
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"repositories": results,
+			"rotated":      len(names) - failed,
+			"failed":       failed,
+		})
+	} else if len(names) == 0 {
+		out.Info("No GitHub-enabled repositories found.")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("key rotation failed for %d of %d repositories", failed, len(names))
+	}
+	return nil
+}
+
+// rotateRepoKey rotates one repository's deploy key using its own credentials
+func rotateRepoKey(cmd *cobra.Command, cfgMgr *config.Manager, name string, repo *state.Repository, sshDir string) (*keys.Result, error) {
+	client, err := newRepoGitHubClient(cmd.Context(), cfgMgr, repoSecretVars(name, repo))
+	if err != nil {
+		return nil, err
+	}
+
+	rotator := &keys.Rotator{
+		Secrets: cfgMgr.Secrets(),
+		Layout:  cfgMgr.Layout(),
+		GitHub:  client,
+		SSHDir:  sshDir,
+	}
+
+	return rotator.Rotate(keys.Target{
+		Name:    name,
+		Path:    repo.Path,
+		Owner:   repo.GitHub.User,
+		Repo:    repo.GitHub.Repo,
+		PushURL: githubPushURL(repo),
+	})
+}
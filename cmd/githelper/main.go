@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/lcgerke/githelper/internal/config"
 	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/remote"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +30,11 @@ seamless GitHub backup integration.`,
 			if err := git.CheckGitVersion(); err != nil {
 				return fmt.Errorf("git check failed: %w", err)
 			}
+
+			// Map self-hosted servers to their platform backends
+			if err := registerPlatformHosts(); err != nil {
+				return err
+			}
 			return nil
 		},
 	}
@@ -45,11 +52,38 @@ func init() {
 	rootCmd.AddCommand(githubCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(stateCmd)
+	rootCmd.AddCommand(keysCmd)
+}
+
+// registerPlatformHosts loads host-to-platform mappings from the local config
+func registerPlatformHosts() error {
+	cfg, err := config.LoadLocalConfig("")
+	if err != nil {
+		return err
+	}
+
+	for host, p := range cfg.Platforms {
+		if err := remote.RegisterHost(host, remote.HostConfig{Platform: p.Type, APIURL: p.APIURL}); err != nil {
+			return fmt.Errorf("invalid platforms entry in config: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func main() {
 	ctx := context.Background()
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	err := rootCmd.ExecuteContext(ctx)
+
+	// Show how the GitHub API treated us, including rate limits and retries
+	if verbose && remote.DefaultMetrics.TotalCalls > 0 {
+		fmt.Fprintln(os.Stderr, remote.DefaultMetrics.Report())
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
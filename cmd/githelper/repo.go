@@ -14,4 +14,5 @@ func init() {
 	// Add repo subcommands
 	repoCmd.AddCommand(repoCreateCmd)
 	repoCmd.AddCommand(repoListCmd)
+	repoCmd.AddCommand(repoTemplatesCmd)
 }
@@ -12,13 +12,20 @@ import (
 	"github.com/lcgerke/githelper/internal/errors"
 	"github.com/lcgerke/githelper/internal/git"
 	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/templates"
 	"github.com/lcgerke/githelper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repoType   string
-	cloneDir   string
+	repoType          string
+	cloneDir          string
+	repoDefaultBranch string
+	repoShared        string
+	repoGroup         string
+	repoOwner         string
+	repoModulePath    string
+	repoSkipScripts   bool
 )
 
 var repoCreateCmd = &cobra.Command{
@@ -26,16 +33,34 @@ var repoCreateCmd = &cobra.Command{
 	Short: "Create a new bare repository and local clone",
 	Long: `Creates a bare repository on the remote server and clones it locally.
 
-The bare repository is created according to the pattern configured in Vault
+The bare repository is created according to the pattern configured in the secrets backend
 (e.g., gitmanager@lcgasgit:/srv/git/{repo}.git) and then cloned to a local
-working directory.`,
+working directory.
+
+Remote patterns (user@host:path or ssh://) are created over SSH using
+GIT_SSH_COMMAND or ssh. Creation is idempotent: an existing bare repository is
+kept, its sharing settings are reapplied, and the result is verified.
+
+Examples:
+  githelper repo create myproject
+  githelper repo create myproject --default-branch trunk
+  githelper repo create myproject --shared group --group developers
+  githelper repo create mytool --type go --owner myorg
+
+--type selects a template for the initial files (see 'githelper repo templates').`,
 	Args: cobra.ExactArgs(1),
 	RunE: runRepoCreate,
 }
 
 func init() {
-	repoCreateCmd.Flags().StringVar(&repoType, "type", "", "Repository type (go, python, etc.)")
+	repoCreateCmd.Flags().StringVar(&repoType, "type", "", "Template for the initial files (see 'githelper repo templates list')")
 	repoCreateCmd.Flags().StringVar(&cloneDir, "clone-dir", "", "Directory to clone into (default: ~/repos/<name>)")
+	repoCreateCmd.Flags().StringVar(&repoDefaultBranch, "default-branch", constants.DefaultBranch, "Branch HEAD points at in the new bare repository")
+	repoCreateCmd.Flags().StringVar(&repoShared, "shared", "", "Sharing mode for the bare repository (group, all, umask, or an octal mode)")
+	repoCreateCmd.Flags().StringVar(&repoGroup, "group", "", "Unix group that should own the bare repository")
+	repoCreateCmd.Flags().StringVar(&repoOwner, "owner", "", "Owner used in template placeholders (default: configured GitHub username)")
+	repoCreateCmd.Flags().StringVar(&repoModulePath, "module-path", "", "Module path used in template placeholders (default: github.com/<owner>/<name>)")
+	repoCreateCmd.Flags().BoolVar(&repoSkipScripts, "skip-scripts", false, "Don't run the template's post-create commands")
 }
 
 func runRepoCreate(cmd *cobra.Command, args []string) error {
@@ -65,30 +90,47 @@ func runRepoCreate(cmd *cobra.Command, args []string) error {
 	// Get configuration
 	cfg, fromCache, err := cfgMgr.GetConfig()
 	if err != nil {
-		return errors.Wrap(errors.ErrorTypeConfig, "failed to get configuration from Vault", err)
+		return errors.Wrap(errors.ErrorTypeConfig, fmt.Sprintf("failed to get configuration from %s", cfgMgr.Secrets().Name()), err)
 	}
 
 	// Show config status
 	if !out.IsJSON() {
 		if fromCache {
 			age := cfgMgr.GetCacheAge()
-			out.Warningf("Configuration: Vault (cached %s ago) ⚡", formatDuration(age))
+			out.Warningf("Configuration: %s (cached %s ago) ⚡", cfgMgr.Secrets().Name(), formatDuration(age))
 		} else {
-			out.Success("Configuration: Vault (live) ✓")
+			out.Success(fmt.Sprintf("Configuration: %s (live) ✓", cfgMgr.Secrets().Name()))
 		}
 		fmt.Println()
 	}
 
+	// Resolve the template before creating anything
+	var tmpl *templates.Template
+	if repoType != "" {
+		registry, err := discoverTemplates()
+		if err != nil {
+			return err
+		}
+		for _, warning := range registry.Warnings {
+			out.Warning(warning)
+		}
+
+		var ok bool
+		tmpl, ok = registry.Get(repoType)
+		if !ok {
+			return errors.WithHint(
+				errors.New(errors.ErrorTypeValidation, fmt.Sprintf("unknown repository template: %s", repoType)),
+				fmt.Sprintf("Available templates: %s", strings.Join(registry.Names(), ", ")),
+			)
+		}
+	}
+
 	// Construct bare repo URL from pattern
 	bareRepoURL := strings.ReplaceAll(cfg.BareRepoPattern, "{repo}", repoName)
 	if !strings.HasSuffix(bareRepoURL, ".git") {
 		bareRepoURL += ".git"
 	}
 
-	// For Phase 1, we'll assume the bare repo is on a remote server via SSH
-	// In a real implementation, we'd handle different URL schemes
-	// For now, let's simulate creating it locally for testing
-
 	// Determine clone directory
 	if cloneDir == "" {
 		home, err := os.UserHomeDir()
@@ -106,47 +148,64 @@ func runRepoCreate(cmd *cobra.Command, args []string) error {
 		)
 	}
 
-	// Parse bare repo URL to determine if it's local or remote
-	// For Phase 1 demo, we'll handle file:// and local paths
-	var bareRepoPath string
-
-	if strings.HasPrefix(bareRepoURL, "file://") {
-		bareRepoPath = strings.TrimPrefix(bareRepoURL, "file://")
-	} else if !strings.Contains(bareRepoURL, "@") && !strings.Contains(bareRepoURL, "://") {
-		// Local path
-		bareRepoPath = bareRepoURL
-	} else {
-		// Remote SSH URL - for Phase 1, we'll error out
-		// In Phase 2+, we'd handle this via SSH
+	// Create (or verify) the bare repository, locally or over SSH
+	out.Infof("Creating bare repository at %s...", bareRepoURL)
+	bareStatus, err := git.CreateBareRepo(bareRepoURL, git.BareRepoOptions{
+		DefaultBranch: repoDefaultBranch,
+		Shared:        repoShared,
+		Group:         repoGroup,
+	})
+	if err != nil {
 		return errors.WithHint(
-			errors.New(errors.ErrorTypeValidation, "remote bare repository creation not yet implemented"),
-			"Use a local file path or file:// URL for now. Remote SSH support coming in Phase 2",
+			errors.Wrap(errors.ErrorTypeGit, "failed to create bare repository", err),
+			"Check that the host is reachable over SSH and the parent directory is writable",
 		)
 	}
-
-	// Create bare repository
-	out.Infof("Creating bare repository at %s...", bareRepoURL)
-	if err := git.InitBareRepo(bareRepoPath); err != nil {
-		return errors.Wrap(errors.ErrorTypeGit, "failed to create bare repository", err)
+	if bareStatus.Created {
+		out.Success(fmt.Sprintf("Created bare repository: %s (HEAD → %s)", bareRepoURL, bareStatus.Head))
+	} else {
+		out.Info(fmt.Sprintf("Bare repository already exists: %s (HEAD → %s)", bareRepoURL, bareStatus.Head))
 	}
-	out.Success(fmt.Sprintf("Created bare repository: %s", bareRepoURL))
 
 	// Clone the bare repository
 	out.Infof("Cloning to %s...", cloneDir)
-	if err := git.Clone(bareRepoPath, cloneDir); err != nil {
+	if err := git.Clone(bareRepoURL, cloneDir); err != nil {
 		return errors.Wrap(errors.ErrorTypeGit, "failed to clone repository", err)
 	}
 	out.Success(fmt.Sprintf("Cloned to: %s", cloneDir))
 
-	// Initialize repository with appropriate files based on type
-	if repoType != "" {
-		switch repoType {
-		case "go":
-			if err := initGoRepo(cloneDir, repoName, out); err != nil {
-				return errors.Wrap(errors.ErrorTypeFileSystem, "failed to initialize Go repository", err)
+	// Write the template's files
+	if tmpl != nil {
+		owner := repoOwner
+		if owner == "" {
+			owner = cfg.GitHubUsername
+		}
+		data := templates.NewData(repoName, owner, repoModulePath)
+
+		out.Infof("Applying template %s (%s)...", tmpl.Name, tmpl.Source)
+		files, err := tmpl.Render(cloneDir, data)
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeFileSystem, "failed to apply template", err)
+		}
+		out.Success(fmt.Sprintf("Applied template %s (%d files)", tmpl.Name, len(files)))
+
+		if len(tmpl.Manifest.PostCreate) > 0 {
+			if repoSkipScripts {
+				out.Warningf("Skipped %d post-create command(s)", len(tmpl.Manifest.PostCreate))
+			} else {
+				// Keep stdout clean for JSON output
+				scriptOut := os.Stdout
+				if out.IsJSON() {
+					scriptOut = os.Stderr
+				}
+				if err := tmpl.RunPostCreate(cloneDir, data, scriptOut, os.Stderr); err != nil {
+					return errors.WithHint(
+						errors.Wrap(errors.ErrorTypeFileSystem, "template post-create command failed", err),
+						fmt.Sprintf("Fix the template or rerun with --skip-scripts; the clone is left in %s", cloneDir),
+					)
+				}
+				out.Success("Ran post-create commands")
 			}
-		default:
-			out.Warningf("Unknown repository type '%s', skipping initialization", repoType)
 		}
 	}
 
@@ -156,7 +215,11 @@ func runRepoCreate(cmd *cobra.Command, args []string) error {
 	// Check if we need an initial commit (if no commits exist yet)
 	currentBranch, err := client.GetCurrentBranch()
 	if err != nil || currentBranch == "" {
-		// No commits yet, create initial commit
+		// No commits yet: commit on the bare repository's HEAD branch
+		if err := client.SetHEADBranch(bareStatus.Head); err != nil {
+			return errors.Wrap(errors.ErrorTypeGit, "failed to select initial branch", err)
+		}
+
 		readmePath := filepath.Join(cloneDir, "README.md")
 		if _, err := os.Stat(readmePath); os.IsNotExist(err) {
 			// Create README if it doesn't exist
@@ -178,11 +241,8 @@ func runRepoCreate(cmd *cobra.Command, args []string) error {
 		out.Success("Created initial commit")
 
 		// Push to bare repo
-		if err := client.PushSetUpstream(constants.DefaultCoreRemote, constants.MasterBranch); err != nil {
-			// Try main branch if master fails
-			if err := client.PushSetUpstream(constants.DefaultCoreRemote, constants.DefaultBranch); err != nil {
-				return errors.Wrap(errors.ErrorTypeGit, "failed to push initial commit", err)
-			}
+		if err := client.PushSetUpstream(constants.DefaultCoreRemote, bareStatus.Head); err != nil {
+			return errors.Wrap(errors.ErrorTypeGit, "failed to push initial commit", err)
 		}
 		out.Success("Pushed initial commit to bare repository")
 	}
@@ -210,59 +270,18 @@ func runRepoCreate(cmd *cobra.Command, args []string) error {
 		out.Success(fmt.Sprintf("Repository ready! cd %s", cloneDir))
 	} else {
 		out.JSON(map[string]interface{}{
-			"status":        "success",
-			"repository":    repoName,
-			"bare_url":      bareRepoURL,
-			"clone_dir":     cloneDir,
-			"type":          repoType,
+			"status":     "success",
+			"repository": repoName,
+			"bare_url":   bareRepoURL,
+			"clone_dir":  cloneDir,
+			"type":       repoType,
+			"bare":       bareStatus,
 		})
 	}
 
 	return nil
 }
 
-func initGoRepo(workdir, repoName string, out *ui.Output) error {
-	out.Info("Initializing Go module...")
-
-	// Create go.mod
-	goModContent := fmt.Sprintf("module github.com/lcgerke/%s\n\ngo 1.21\n", repoName)
-	goModPath := filepath.Join(workdir, "go.mod")
-	if err := os.WriteFile(goModPath, []byte(goModContent), 0644); err != nil {
-		return err
-	}
-
-	// Create basic .gitignore
-	gitignoreContent := `# Binaries
-*.exe
-*.dll
-*.so
-*.dylib
-
-# Test binary
-*.test
-
-# Output
-*.out
-
-# Vendor
-vendor/
-
-# IDE
-.idea/
-.vscode/
-*.swp
-*.swo
-*~
-`
-	gitignorePath := filepath.Join(workdir, ".gitignore")
-	if err := os.WriteFile(gitignorePath, []byte(gitignoreContent), 0644); err != nil {
-		return err
-	}
-
-	out.Success("Initialized Go repository")
-	return nil
-}
-
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%ds", int(d.Seconds()))
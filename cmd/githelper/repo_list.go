@@ -3,17 +3,37 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lcgerke/githelper/internal/state"
 	"github.com/lcgerke/githelper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listNeedsRetry bool
+	listSyncStatus string
+	listStale      time.Duration
+)
+
 var repoListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all managed repositories",
-	Long:  "Lists all repositories managed by githelper.",
-	RunE:  runRepoList,
+	Long: `Lists all repositories managed by githelper.
+
+Filters narrow the list by GitHub sync state, e.g. repositories needing a
+retry that haven't synced for a day:
+
+  githelper repo list --needs-retry --stale 24h
+
+With the SQLite state backend these filters use indexed queries.`,
+	RunE: runRepoList,
+}
+
+func init() {
+	repoListCmd.Flags().BoolVar(&listNeedsRetry, "needs-retry", false, "Only repositories whose GitHub sync needs a retry")
+	repoListCmd.Flags().StringVar(&listSyncStatus, "sync-status", "", "Only repositories with this GitHub sync status")
+	repoListCmd.Flags().DurationVar(&listStale, "stale", 0, "Only repositories not synced within this duration (or never)")
 }
 
 func runRepoList(cmd *cobra.Command, args []string) error {
@@ -31,8 +51,14 @@ func runRepoList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize state manager: %w", err)
 	}
+	defer stateMgr.Close()
+
+	query := state.Query{NeedsRetry: listNeedsRetry, SyncStatus: listSyncStatus}
+	if listStale > 0 {
+		query.LastSyncBefore = time.Now().Add(-listStale)
+	}
 
-	repos, err := stateMgr.ListRepositories()
+	repos, err := stateMgr.Query(query)
 	if err != nil {
 		return fmt.Errorf("failed to list repositories: %w", err)
 	}
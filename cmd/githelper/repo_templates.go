@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lcgerke/githelper/internal/config"
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/templates"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var repoTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage repository templates",
+	Long: `Templates provide the starter files for 'githelper repo create --type'.
+
+Templates are looked up, later ones overriding earlier ones with the same name:
+  builtin   Templates shipped with githelper (go)
+  remote    A shared git repository, one template per top-level directory
+  local     ~/.githelper/templates/<name>/
+
+Files ending in .tmpl are rendered with Go text/template and lose the suffix;
+other files are copied unchanged. Available placeholders are {{.Name}},
+{{.ModulePath}}, {{.Owner}} and {{.Year}}. An optional template.yaml gives a
+description and post-create commands:
+
+  description: Python package
+  post_create:
+    - python3 -m venv .venv
+
+The shared repository is set in ~/.githelper/config.yaml:
+
+  templates:
+    remote: gitmanager@lcgasgit:/srv/git/templates.git
+    ref: main`,
+}
+
+var repoTemplatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available repository templates",
+	Args:  cobra.NoArgs,
+	RunE:  runRepoTemplatesList,
+}
+
+func init() {
+	repoTemplatesCmd.AddCommand(repoTemplatesListCmd)
+}
+
+// discoverTemplates loads the templates available under the local config
+func discoverTemplates() (*templates.Registry, error) {
+	local, err := config.LoadLocalConfig("")
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeConfig, "failed to load local config", err)
+	}
+
+	registry, err := templates.Discover(local.Templates, "")
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeFileSystem, "failed to discover templates", err)
+	}
+	return registry, nil
+}
+
+func runRepoTemplatesList(cmd *cobra.Command, args []string) error {
+	// Set up output
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	registry, err := discoverTemplates()
+	if err != nil {
+		return err
+	}
+
+	if out.IsJSON() {
+		list := make([]map[string]interface{}, 0)
+		for _, t := range registry.List() {
+			list = append(list, map[string]interface{}{
+				"name":        t.Name,
+				"source":      t.Source,
+				"location":    t.Location,
+				"description": t.Manifest.Description,
+				"post_create": t.Manifest.PostCreate,
+			})
+		}
+		out.JSON(map[string]interface{}{
+			"templates": list,
+			"warnings":  registry.Warnings,
+		})
+		return nil
+	}
+
+	out.Header("Repository Templates")
+	fmt.Println()
+
+	for _, warning := range registry.Warnings {
+		out.Warning(warning)
+	}
+
+	for _, t := range registry.List() {
+		fmt.Printf("📄 %s (%s)\n", t.Name, t.Source)
+		if t.Manifest.Description != "" {
+			fmt.Printf("   %s\n", t.Manifest.Description)
+		}
+		if t.Location != "" {
+			fmt.Printf("   Location:    %s\n", t.Location)
+		}
+		if len(t.Manifest.PostCreate) > 0 {
+			fmt.Printf("   Post-create: %d command(s)\n", len(t.Manifest.PostCreate))
+		}
+		fmt.Println()
+	}
+
+	out.Separator()
+	out.Info("Use one with: githelper repo create <name> --type <template>")
+
+	return nil
+}
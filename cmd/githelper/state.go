@@ -0,0 +1,362 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	migrateDryRun bool
+	exportOutput  string
+	importBackend string
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and maintain the githelper state file",
+	Long: `Manage the record of repositories githelper manages.
+
+State lives in ~/.githelper/state.yaml by default, or in ~/.githelper/state.db
+when the SQLite backend is in use. Use 'state export' and 'state import' to
+move between the two.`,
+}
+
+var stateMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the state file to the current schema version",
+	Long: `Upgrades ~/.githelper/state.yaml to the schema version this githelper writes.
+
+Older files are also upgraded automatically the first time they are loaded.
+Either way the original is kept as state.yaml.v<N>-<timestamp>.bak.
+
+Use --dry-run to show the changes without writing anything.`,
+	Args: cobra.NoArgs,
+	RunE: runStateMigrate,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the current state as YAML",
+	Long: `Writes the current state, from whichever backend is in use, as a YAML
+document in the state.yaml format. Writes to stdout unless --output is given.`,
+	Args: cobra.NoArgs,
+	RunE: runStateExport,
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Replace the current state with an exported YAML document",
+	Long: `Replaces the current state with the contents of an exported YAML file.
+
+With --backend the state is written to that backend instead of the current
+one, and the previous backend's file is moved aside as a .bak, so
+
+  githelper state export -o state-export.yaml
+  githelper state import state-export.yaml --backend sqlite
+
+switches from state.yaml to the SQLite database (and --backend yaml back).`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStateImport,
+}
+
+func init() {
+	stateMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migration diff without writing")
+	stateExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write (default stdout)")
+	stateImportCmd.Flags().StringVar(&importBackend, "backend", "", "Backend to import into: yaml or sqlite (default: current)")
+
+	stateCmd.AddCommand(stateMigrateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+}
+
+func runStateExport(cmd *cobra.Command, args []string) error {
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return fmt.Errorf("failed to initialize state manager: %w", err)
+	}
+	defer stateMgr.Close()
+
+	st, err := stateMgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	data, err := yaml.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if exportOutput == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+	}
+
+	out := newStateOutput()
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"backend":      stateMgr.Backend(),
+			"output":       exportOutput,
+			"repositories": len(st.Repositories),
+		})
+	} else {
+		out.Success(fmt.Sprintf("Exported %d repositories from %s backend to %s", len(st.Repositories), stateMgr.Backend(), exportOutput))
+	}
+	return nil
+}
+
+func runStateImport(cmd *cobra.Command, args []string) error {
+	out := newStateOutput()
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	st, err := state.ParseState(data)
+	if err != nil {
+		return fmt.Errorf("invalid state file %s: %w", args[0], err)
+	}
+
+	stateDir, err := state.DefaultDir()
+	if err != nil {
+		return err
+	}
+
+	current := state.DetectBackend(stateDir)
+	target := importBackend
+	if target == "" {
+		target = current
+	}
+
+	stateMgr, err := state.OpenManager(stateDir, target)
+	if err != nil {
+		return fmt.Errorf("failed to open %s backend: %w", target, err)
+	}
+	defer stateMgr.Close()
+
+	if err := stateMgr.Save(st); err != nil {
+		return fmt.Errorf("failed to import state: %w", err)
+	}
+
+	// Move the old backend aside so it is no longer detected
+	var retired string
+	if target != current {
+		retired, err = state.RetireBackend(stateDir, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"backend":      target,
+			"repositories": len(st.Repositories),
+			"retired":      retired,
+		})
+		return nil
+	}
+
+	out.Success(fmt.Sprintf("Imported %d repositories into %s backend", len(st.Repositories), target))
+	if retired != "" {
+		out.Info(fmt.Sprintf("Previous %s state moved to %s", current, retired))
+	}
+	return nil
+}
+
+// newStateOutput creates output honoring the global format flags
+func newStateOutput() *ui.Output {
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+	return out
+}
+
+func runStateMigrate(cmd *cobra.Command, args []string) error {
+	out := newStateOutput()
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return fmt.Errorf("failed to initialize state manager: %w", err)
+	}
+	defer stateMgr.Close()
+
+	report, err := stateMgr.Migrate(migrateDryRun)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"dry_run":   migrateDryRun,
+			"needed":    report.Needed(),
+			"migration": report,
+		})
+		return nil
+	}
+
+	if !report.Needed() {
+		out.Success(fmt.Sprintf("State file is already at schema version %d", report.ToVersion))
+		return nil
+	}
+
+	out.Header(fmt.Sprintf("State migration: v%d → v%d", report.FromVersion, report.ToVersion))
+	for _, step := range report.Applied {
+		fmt.Printf("  • %s\n", step)
+	}
+	fmt.Println()
+
+	for _, line := range lineDiff(report.Before, report.After, 2) {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			out.Success(line)
+		case strings.HasPrefix(line, "-"):
+			out.Error(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+
+	if migrateDryRun {
+		out.Info("Dry run - nothing written. Run without --dry-run to apply.")
+		return nil
+	}
+
+	out.Success("State file migrated")
+	out.Info(fmt.Sprintf("Backup: %s", report.BackupPath))
+	return nil
+}
+
+// lineDiff returns a line-based diff of before and after, with "-" and "+"
+// prefixes for removed and added lines and up to context unchanged lines
+// around each change. Skipped unchanged runs are shown as "...".
+func lineDiff(before, after string, context int) []string {
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+
+	// Unchanged leading and trailing lines need no diffing
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []string
+	for _, line := range a[:prefix] {
+		lines = append(lines, "  "+line)
+	}
+	lines = diffLines(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], lines)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, "  "+line)
+	}
+
+	// Keep only changes and their context
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+
+	var result []string
+	skipped := false
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(result) > 0 {
+			result = append(result, "  ...")
+		}
+		skipped = false
+		result = append(result, line)
+	}
+	return result
+}
+
+// diffLines appends the diff of a and b to lines using Hirschberg's
+// algorithm, which finds a longest common subsequence in linear space.
+func diffLines(a, b, lines []string) []string {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			lines = append(lines, "+ "+line)
+		}
+		return lines
+	case len(b) == 0:
+		for _, line := range a {
+			lines = append(lines, "- "+line)
+		}
+		return lines
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				lines = diffLines(nil, b[:j], lines)
+				lines = append(lines, "  "+line)
+				return diffLines(nil, b[j+1:], lines)
+			}
+		}
+		lines = append(lines, "- "+a[0])
+		return diffLines(nil, b, lines)
+	}
+
+	// Split b where the halves of a share the most lines with it
+	mid := len(a) / 2
+	forward := lcsLengths(a[:mid], b, false)
+	backward := lcsLengths(a[mid:], b, true)
+	split, best := 0, -1
+	for j := range forward {
+		if n := forward[j] + backward[len(b)-j]; n > best {
+			split, best = j, n
+		}
+	}
+
+	lines = diffLines(a[:mid], b[:split], lines)
+	return diffLines(a[mid:], b[split:], lines)
+}
+
+// lcsLengths returns the longest common subsequence lengths of a and each
+// prefix of b, or of a and each suffix of b (by length) when reverse is set.
+func lcsLengths(a, b []string, reverse bool) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		ai := a[i]
+		if reverse {
+			ai = a[len(a)-1-i]
+		}
+		for j := 1; j <= len(b); j++ {
+			bj := b[j-1]
+			if reverse {
+				bj = b[len(b)-j]
+			}
+			if ai == bj {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
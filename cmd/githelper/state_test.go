@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\n"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\n"
+
+	got := lineDiff(before, after, 1)
+	want := []string{
+		"  c",
+		"- d",
+		"+ D",
+		"  e",
+		"  ...",
+		"  g",
+		"+ h",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineDiff() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := lineDiff("same\n", "same\n", 2); len(got) != 0 {
+		t.Errorf("lineDiff() of identical input = %q, want none", got)
+	}
+}
+
+func TestLineDiff_LargeInput(t *testing.T) {
+	var before, after strings.Builder
+	for i := 0; i < 200000; i++ {
+		line := "line " + strconv.Itoa(i) + "\n"
+		before.WriteString(line)
+		if i == 100000 {
+			after.WriteString("changed\n")
+			continue
+		}
+		after.WriteString(line)
+	}
+
+	got := lineDiff(before.String(), after.String(), 0)
+	want := []string{"- line 100000", "+ changed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestLineDiff_Moved(t *testing.T) {
+	got := lineDiff("a\nb\nc\nd\n", "b\nc\na\nd\n", 0)
+	want := []string{"- a", "  ...", "+ a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+}
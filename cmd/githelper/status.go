@@ -7,8 +7,11 @@ import (
 
 	"github.com/lcgerke/githelper/internal/constants"
 	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/fleet"
 	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/history"
 	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
 	"github.com/lcgerke/githelper/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -19,10 +22,13 @@ var (
 	statusShowFixes    bool
 	statusCoreRemote   string
 	statusGitHubRemote string
+	statusMirrors      []string
+	statusAll          bool
+	statusWorkers      int
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status [path]",
+	Use:   "status [path] | --all",
 	Short: "Check repository sync status",
 	Long: `Quickly check the sync status of a repository.
 
@@ -31,11 +37,18 @@ Shows:
 - Sync state (S1-S13)
 - Working tree state (W1-W5)
 - Corruption state (C1-C8)
+- Tag state between Core and GitHub (T1-T4)
+- Default branch agreement between Core, GitHub, and init.defaultBranch (D1-D4)
 - Suggested fixes
 
 Use --quick to skip corruption checks.
 Use --no-fetch to use cached remote data (faster but may be stale).
-Use --show-fixes to display suggested fixes.`,
+Use --show-fixes to display suggested fixes.
+Use --mirror <remote> (repeatable) to include additional mirrors beyond Core and GitHub.
+Use --all to check every managed repository concurrently and group them by
+scenario, using the mirrors recorded for each one in the state file (so it
+can't be combined with --core-remote, --github-remote or --mirror); exits
+non-zero if any repository is in an error or critical scenario.`,
 	RunE: runStatus,
 }
 
@@ -45,6 +58,9 @@ func init() {
 	statusCmd.Flags().BoolVar(&statusShowFixes, "show-fixes", false, "Show suggested fixes")
 	statusCmd.Flags().StringVar(&statusCoreRemote, "core-remote", constants.DefaultCoreRemote, "Name of Core remote")
 	statusCmd.Flags().StringVar(&statusGitHubRemote, "github-remote", constants.DefaultGitHubRemote, "Name of GitHub remote")
+	statusCmd.Flags().StringSliceVar(&statusMirrors, "mirror", nil, "Additional mirror remote to check (repeatable)")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "Check every repository in the state file")
+	statusCmd.Flags().IntVar(&statusWorkers, "workers", fleet.DefaultWorkers, "Repositories to check concurrently with --all")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -56,6 +72,20 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		out.SetColorEnabled(false)
 	}
 
+	if statusAll {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot combine a path with --all")
+		}
+		// Each repository is checked against the mirrors recorded for it in
+		// the state file, so remote names given here would be ignored
+		for _, name := range []string{"core-remote", "github-remote", "mirror"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("cannot combine --%s with --all; remotes come from each repository's mirrors in the state file", name)
+			}
+		}
+		return runStatusAll(cmd, out)
+	}
+
 	// Get repository path
 	repoPath := "."
 	if len(args) > 0 {
@@ -84,7 +114,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	options.SkipCorruption = statusQuick
 
 	// Create classifier
-	classifier := scenarios.NewClassifier(gitClient, statusCoreRemote, statusGitHubRemote, options)
+	mirrors := append([]string{statusCoreRemote, statusGitHubRemote}, statusMirrors...)
+	classifier := scenarios.NewMirrorClassifier(gitClient, mirrors, options)
 
 	// Detect state
 	if !out.IsJSON() {
@@ -97,6 +128,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(errors.ErrorTypeGit, "failed to detect repository state", err)
 	}
 
+	// Record history for managed repositories (best effort)
+	recordStatusHistory(repoPath, state)
+
 	// Output results
 	if out.IsJSON() {
 		// JSON output
@@ -209,6 +243,45 @@ func printStatusReport(out *ui.Output, state *scenarios.RepositoryState, showFix
 		fmt.Println()
 	}
 
+	// Additional mirrors (Core and GitHub are shown above)
+	if len(state.Sync.Mirrors) > 2 {
+		fmt.Println("🪞 Mirrors:")
+		for _, mirror := range state.Sync.Mirrors {
+			printMirrorState(out, mirror)
+		}
+		fmt.Println()
+	}
+
+	// Tags (only mismatches are listed)
+	if len(state.Tags) > 0 {
+		fmt.Println("🏷️  Tags:")
+		inSync := 0
+		for _, tag := range state.Tags {
+			switch tag.ID {
+			case "T1":
+				inSync++
+			case "T4":
+				out.Error(fmt.Sprintf("  %s - %s: %s", tag.ID, tag.Tag, tag.Description))
+			default:
+				out.Warning(fmt.Sprintf("  %s - %s: %s", tag.ID, tag.Tag, tag.Description))
+			}
+		}
+		fmt.Printf("  %d of %d tags in sync\n", inSync, len(state.Tags))
+		fmt.Println()
+	}
+
+	// Default branch (only shown when something disagrees)
+	if ds := state.DefaultBranchState; ds != nil && ds.ID != "D1" {
+		fmt.Println("🌿 Default Branch:")
+		if ds.ID == "D4" {
+			out.Info(fmt.Sprintf("  %s - %s", ds.ID, ds.Description))
+		} else {
+			out.Warning(fmt.Sprintf("  %s - %s", ds.ID, ds.Description))
+		}
+		fmt.Printf("  Core: %s, GitHub: %s, init.defaultBranch: %s\n", orNone(ds.Core), orNone(ds.GitHub), ds.Local)
+		fmt.Println()
+	}
+
 	// Working Tree
 	if state.Existence.LocalExists {
 		fmt.Println("📝 Working Tree:")
@@ -274,7 +347,7 @@ func printStatusReport(out *ui.Output, state *scenarios.RepositoryState, showFix
 	}
 
 	// Summary
-	if state.Sync.ID == "S1" && state.WorkingTree.Clean && state.Corruption.Healthy {
+	if state.Sync.ID == "S1" && mirrorsInSync(state) && tagsInSync(state) && defaultBranchConsistent(state) && state.WorkingTree.Clean && state.Corruption.Healthy {
 		out.Success("✅ Repository is healthy and in sync")
 	} else {
 		if showFixes {
@@ -284,3 +357,71 @@ func printStatusReport(out *ui.Output, state *scenarios.RepositoryState, showFix
 		}
 	}
 }
+
+// printMirrorState prints one line describing a mirror relative to local
+func printMirrorState(out *ui.Output, mirror scenarios.MirrorState) {
+	switch {
+	case mirror.Error != "":
+		out.Warning(fmt.Sprintf("  %s: ✗ %s", mirror.Remote, mirror.Error))
+	case mirror.Diverged:
+		out.Warning(fmt.Sprintf("  %s: ⚠️  diverged (%d ahead, %d behind)", mirror.Remote, mirror.LocalAhead, mirror.LocalBehind))
+	case mirror.LocalAhead > 0:
+		fmt.Printf("  %s: local ahead by %d commits\n", mirror.Remote, mirror.LocalAhead)
+	case mirror.LocalBehind > 0:
+		fmt.Printf("  %s: local behind by %d commits\n", mirror.Remote, mirror.LocalBehind)
+	default:
+		fmt.Printf("  %s: ✓ in sync\n", mirror.Remote)
+	}
+}
+
+// mirrorsInSync reports whether every mirror beyond Core and GitHub matches local
+func mirrorsInSync(state *scenarios.RepositoryState) bool {
+	for _, mirror := range state.Sync.Mirrors {
+		if mirror.Remote == state.CoreRemote || mirror.Remote == state.GitHubRemote {
+			continue
+		}
+		if !mirror.InSync() {
+			return false
+		}
+	}
+	return true
+}
+
+// tagsInSync reports whether every tag matches between Core and GitHub
+func tagsInSync(state *scenarios.RepositoryState) bool {
+	for _, tag := range state.Tags {
+		if tag.ID != "T1" {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultBranchConsistent reports whether Core and GitHub agree on the default
+// branch; a differing init.defaultBranch (D4) only affects new repositories
+func defaultBranchConsistent(state *scenarios.RepositoryState) bool {
+	ds := state.DefaultBranchState
+	return ds == nil || (ds.ID != "D2" && ds.ID != "D3")
+}
+
+// orNone returns s, or "(none)" if it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// recordStatusHistory appends a snapshot if repoPath is a managed repository.
+// Unmanaged repositories and state errors are ignored; status must still work.
+func recordStatusHistory(repoPath string, repoState *scenarios.RepositoryState) {
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return
+	}
+	name, err := stateMgr.FindByPath(repoPath)
+	if err != nil {
+		return
+	}
+	_ = stateMgr.RecordSnapshot(name, history.NewSnapshot(repoState))
+}
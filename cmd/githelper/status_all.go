@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/fleet"
+	"github.com/lcgerke/githelper/internal/history"
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// runStatusAll classifies every repository in the state file
+func runStatusAll(cmd *cobra.Command, out *ui.Output) error {
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeState, "failed to initialize state manager", err)
+	}
+
+	repos, err := stateMgr.ListRepositories()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeState, "failed to list repositories", err)
+	}
+
+	if len(repos) == 0 {
+		if out.IsJSON() {
+			out.JSON(map[string]interface{}{
+				"repositories": []interface{}{},
+			})
+		} else {
+			out.Info("No repositories found.")
+		}
+		return nil
+	}
+
+	options := scenarios.DefaultDetectionOptions()
+	options.SkipFetch = statusNoFetch
+	options.SkipCorruption = statusQuick
+
+	if !out.IsJSON() {
+		fmt.Printf("🔍 Analyzing %d repositories (%d workers)...\n", len(repos), statusWorkers)
+		fmt.Println()
+	}
+
+	report := fleet.Classify(cmd.Context(), fleet.TargetsFromState(repos), statusWorkers, options)
+
+	// Record history for every classified repository in one write
+	snapshots := make(map[string]state.Snapshot)
+	for _, r := range report.Repositories {
+		if r.State != nil {
+			snapshots[r.Name] = history.NewSnapshot(r.State)
+		}
+	}
+	if err := stateMgr.RecordSnapshots(snapshots); err != nil && !out.IsJSON() {
+		out.Warning(fmt.Sprintf("Failed to record history: %v", err))
+	}
+
+	if out.IsJSON() {
+		out.JSON(report)
+	} else {
+		printFleetReport(out, report)
+	}
+
+	if failing := report.Failing(); len(failing) > 0 {
+		return fmt.Errorf("%d of %d repositories in error or critical scenarios", len(failing), len(report.Repositories))
+	}
+	return nil
+}
+
+// printFleetReport prints the scenario table and per-repository errors
+func printFleetReport(out *ui.Output, report *fleet.Report) {
+	fmt.Printf("%-16s %-9s %-36s %5s  %s\n", "SCENARIO", "SEVERITY", "NAME", "REPOS", "REPOSITORIES")
+	for _, g := range report.Groups {
+		line := fmt.Sprintf("%-16s %-9s %-36s %5d  %s", g.ID, g.Severity, truncate(g.Name, 36), len(g.Repos), strings.Join(g.Repos, ", "))
+		switch g.Severity {
+		case scenarios.SeverityCritical, scenarios.SeverityError:
+			out.Error(line)
+		case scenarios.SeverityWarning:
+			out.Warning(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+
+	var failed []fleet.RepoStatus
+	for _, r := range report.Repositories {
+		if r.Error != "" {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Println("⚠️  Could not classify:")
+		for _, r := range failed {
+			out.Error(fmt.Sprintf("  %s (%s): %s", r.Name, r.Path, r.Error))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Repositories: %d total, %d info, %d warning, %d error, %d critical (%dms)\n",
+		len(report.Repositories),
+		report.BySeverity[scenarios.SeverityInfo],
+		report.BySeverity[scenarios.SeverityWarning],
+		report.BySeverity[scenarios.SeverityError],
+		report.BySeverity[scenarios.SeverityCritical],
+		report.Duration.Milliseconds())
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -78,6 +78,28 @@ func TestRunStatus_NonGitDirectory(t *testing.T) {
 	}
 }
 
+// TestRunStatus_AllRejectsRemoteFlags tests that --all refuses remote names
+// it would otherwise ignore
+func TestRunStatus_AllRejectsRemoteFlags(t *testing.T) {
+	statusAll = true
+	defer func() { statusAll = false }()
+	format = ""
+	noColor = false
+
+	for _, flag := range []string{"core-remote", "github-remote", "mirror"} {
+		cmd := &cobra.Command{}
+		cmd.Flags().String(flag, "", "")
+		if err := cmd.Flags().Set(flag, "backup"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := runStatus(cmd, nil)
+		if err == nil || !strings.Contains(err.Error(), "--"+flag) {
+			t.Errorf("runStatus(--all --%s) error = %v, want one naming the flag", flag, err)
+		}
+	}
+}
+
 // TestRunStatus_GitVersionCheck tests that git version is validated
 func TestRunStatus_GitVersionCheck(t *testing.T) {
 	// This test verifies the git version check is called
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/state"
+	"github.com/lcgerke/githelper/internal/ui"
+	"github.com/lcgerke/githelper/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval   time.Duration
+	watchMaxBackoff time.Duration
+	watchOnce       bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep mirrors in sync in the background",
+	Long: `Periodically checks every managed repository and pushes new commits
+and tags from the Core remote to its other mirrors.
+
+Each cycle:
+1. Classifies the repository (fetching every mirror)
+2. Plans every branch and tag from Core to each mirror
+3. Pushes only safe updates (fast-forwards and refs missing on the mirror)
+4. Records the result in the state file
+
+Nothing is ever force-pushed. Diverged refs are reported for manual resolution.
+Repositories that fail are retried with exponential backoff.
+
+Stops gracefully on SIGINT or SIGTERM after finishing the current repository.
+Use --once to run a single cycle (e.g. from cron).`,
+	RunE: runWatch,
+}
+
+func init() {
+	defaults := watch.DefaultOptions()
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", defaults.Interval, "Time between sync cycles")
+	watchCmd.Flags().DurationVar(&watchMaxBackoff, "max-backoff", defaults.MaxBackoff, "Maximum delay before retrying a failing repository")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Run a single cycle and exit")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	out := ui.NewOutput(os.Stdout)
+	if format != "" {
+		out.SetFormat(ui.OutputFormat(format))
+	}
+	if noColor {
+		out.SetColorEnabled(false)
+	}
+
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	stateMgr, err := state.NewManager("")
+	if err != nil {
+		return fmt.Errorf("failed to initialize state manager: %w", err)
+	}
+
+	options := watch.DefaultOptions()
+	options.Interval = watchInterval
+	options.MaxBackoff = watchMaxBackoff
+	watcher := watch.NewWatcher(stateMgr, options)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report := func(results []watch.RepoResult) {
+		printWatchResults(out, results)
+	}
+
+	if watchOnce {
+		results, err := watcher.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+		report(results)
+		return nil
+	}
+
+	if !out.IsJSON() {
+		out.Infof("Watching repositories every %s (Ctrl-C to stop)", watchInterval)
+	}
+
+	if err := watcher.Run(ctx, report); err != nil {
+		return err
+	}
+
+	if !out.IsJSON() {
+		out.Info("Watch stopped")
+	}
+	return nil
+}
+
+// printWatchResults prints one cycle's results
+func printWatchResults(out *ui.Output, results []watch.RepoResult) {
+	if out.IsJSON() {
+		out.JSON(map[string]interface{}{
+			"time":         time.Now(),
+			"repositories": results,
+		})
+		return
+	}
+
+	out.Infof("[%s] Checked %d repositories", time.Now().Format(time.RFC3339), len(results))
+	for _, r := range results {
+		switch r.Status {
+		case watch.ResultSynced:
+			if verbose {
+				out.Success(fmt.Sprintf("  %s: in sync", r.Name))
+			}
+		case watch.ResultPushed:
+			pushed := 0
+			for _, m := range r.Mirrors {
+				pushed += m.Summary.Pushed
+			}
+			out.Success(fmt.Sprintf("  %s: pushed %d ref(s)", r.Name, pushed))
+		case watch.ResultDiverged:
+			out.Warning(fmt.Sprintf("  %s: %s", r.Name, r.Error))
+		case watch.ResultFailed:
+			out.Error(fmt.Sprintf("  %s: %s (retry after %s)", r.Name, r.Error, r.NextCheck.Format(time.Kitchen)))
+		case watch.ResultSkipped:
+			if verbose {
+				out.Infof("  %s: skipped (%s)", r.Name, r.Error)
+			}
+		}
+	}
+}
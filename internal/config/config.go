@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/lcgerke/githelper/internal/secrets"
 	"github.com/lcgerke/githelper/internal/vault"
 )
 
@@ -16,11 +17,12 @@ const (
 	configCacheFile = "config.json"
 )
 
-// Manager handles configuration from Vault with local caching
+// Manager handles configuration from the secrets backend with local caching
 type Manager struct {
-	vaultClient *vault.Client
-	cacheDir    string
-	cacheTTL    time.Duration
+	secrets  secrets.Provider
+	layout   secrets.Layout
+	cacheDir string
+	cacheTTL time.Duration
 }
 
 // CachedConfig represents cached configuration with timestamp
@@ -29,8 +31,25 @@ type CachedConfig struct {
 	FetchedAt time.Time     `json:"fetched_at"`
 }
 
-// NewManager creates a new config manager
+// NewManager creates a new config manager using the secrets backend selected
+// in ~/.githelper/config.yaml (Vault if none is configured)
 func NewManager(ctx context.Context, cacheDir string) (*Manager, error) {
+	local, err := LoadLocalConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := secrets.New(ctx, local.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secrets provider: %w", err)
+	}
+
+	return NewManagerWithProvider(provider, local.Secrets.Layout, cacheDir)
+}
+
+// NewManagerWithProvider creates a config manager reading secrets from
+// provider at the paths given by layout
+func NewManagerWithProvider(provider secrets.Provider, layout secrets.Layout, cacheDir string) (*Manager, error) {
 	if cacheDir == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -44,35 +63,41 @@ func NewManager(ctx context.Context, cacheDir string) (*Manager, error) {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	vaultClient, err := vault.NewClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create vault client: %w", err)
-	}
-
 	return &Manager{
-		vaultClient: vaultClient,
-		cacheDir:    cacheDir,
-		cacheTTL:    defaultCacheTTL,
+		secrets:  provider,
+		layout:   layout.WithDefaults(),
+		cacheDir: cacheDir,
+		cacheTTL: defaultCacheTTL,
 	}, nil
 }
 
-// GetConfig retrieves config from Vault or cache
+// Secrets returns the secrets provider
+func (m *Manager) Secrets() secrets.Provider {
+	return m.secrets
+}
+
+// Layout returns the secret path layout
+func (m *Manager) Layout() secrets.Layout {
+	return m.layout
+}
+
+// GetConfig retrieves config from the secrets backend or cache
 func (m *Manager) GetConfig() (*vault.Config, bool, error) {
-	// Try to get from Vault first
-	if m.vaultClient.IsReachable() {
-		cfg, err := m.vaultClient.GetConfig()
+	// Try to get from the secrets backend first
+	if m.secrets.IsReachable() {
+		cfg, err := secrets.GetConfig(m.secrets, m.layout)
 		if err == nil {
 			// Cache it for later
 			_ = m.cacheConfig(cfg)
 			return cfg, false, nil // false = not from cache
 		}
-		// Vault error - fall through to cache
+		// Backend error - fall through to cache
 	}
 
 	// Try cache
 	cached, err := m.loadCache()
 	if err != nil {
-		return nil, false, fmt.Errorf("vault unreachable and no valid cache: %w", err)
+		return nil, false, fmt.Errorf("%s unreachable and no valid cache: %w", m.secrets.Name(), err)
 	}
 
 	// Check if cache is stale
@@ -84,22 +109,31 @@ func (m *Manager) GetConfig() (*vault.Config, bool, error) {
 	return cached.Config, true, nil // true = from cache
 }
 
-// GetSSHKey retrieves SSH key from Vault (never cached)
-func (m *Manager) GetSSHKey(repoName string) (*vault.SSHKey, error) {
-	if !m.vaultClient.IsReachable() {
-		return nil, fmt.Errorf("vault unreachable (SSH keys are never cached)")
+// GetSSHKey retrieves the SSH key for a repository from the secrets backend (never cached)
+func (m *Manager) GetSSHKey(vars secrets.Vars) (*vault.SSHKey, error) {
+	if !m.secrets.IsReachable() {
+		return nil, fmt.Errorf("%s unreachable (SSH keys are never cached)", m.secrets.Name())
+	}
+
+	return secrets.GetSSHKey(m.secrets, m.layout, vars)
+}
+
+// DownloadSSHKey writes the repository's SSH key into destDir (never cached)
+func (m *Manager) DownloadSSHKey(vars secrets.Vars, destDir string) (string, error) {
+	if !m.secrets.IsReachable() {
+		return "", fmt.Errorf("%s unreachable (SSH keys are never cached)", m.secrets.Name())
 	}
 
-	return m.vaultClient.GetSSHKey(repoName)
+	return secrets.DownloadSSHKey(m.secrets, m.layout, vars, destDir)
 }
 
-// GetPAT retrieves PAT from Vault (never cached)
-func (m *Manager) GetPAT(repoName string) (string, error) {
-	if !m.vaultClient.IsReachable() {
-		return "", fmt.Errorf("vault unreachable (PATs are never cached)")
+// GetPAT retrieves the PAT for a repository from the secrets backend (never cached)
+func (m *Manager) GetPAT(vars secrets.Vars) (string, error) {
+	if !m.secrets.IsReachable() {
+		return "", fmt.Errorf("%s unreachable (PATs are never cached)", m.secrets.Name())
 	}
 
-	return m.vaultClient.GetPAT(repoName)
+	return secrets.GetPAT(m.secrets, m.layout, vars)
 }
 
 // cacheConfig saves config to cache
@@ -148,7 +182,7 @@ func (m *Manager) GetCacheAge() time.Duration {
 	return time.Since(cached.FetchedAt)
 }
 
-// IsVaultReachable checks if Vault is reachable
-func (m *Manager) IsVaultReachable() bool {
-	return m.vaultClient.IsReachable()
+// IsReachable checks if the secrets backend is reachable
+func (m *Manager) IsReachable() bool {
+	return m.secrets.IsReachable()
 }
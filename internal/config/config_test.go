@@ -149,9 +149,9 @@ func TestNewManager_CacheDirectory(t *testing.T) {
 			}
 
 			mgr := &Manager{
-				vaultClient: nil, // We won't use it
-				cacheDir:    tt.cacheDir,
-				cacheTTL:    defaultCacheTTL,
+				secrets:  nil, // We won't use it
+				cacheDir: tt.cacheDir,
+				cacheTTL: defaultCacheTTL,
 			}
 
 			// Create a test manager wrapper
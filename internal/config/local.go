@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/github"
+	"github.com/lcgerke/githelper/internal/remote"
+	"github.com/lcgerke/githelper/internal/secrets"
+	"github.com/lcgerke/githelper/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+const localConfigFile = "config.yaml"
+
+// LocalConfig is machine-local configuration read from ~/.githelper/config.yaml.
+// Unlike the Config held in the secrets backend it never contains secrets itself.
+type LocalConfig struct {
+	// Platforms maps a hostname (optionally host:port) to a platform backend,
+	// for self-hosted servers that can't be recognised from the hostname alone.
+	Platforms map[string]PlatformHost `yaml:"platforms"`
+
+	// Secrets selects where credentials are read from (default: Vault)
+	Secrets secrets.Options `yaml:"secrets"`
+
+	// Protection is the branch protection policy `githelper github protect`
+	// enforces on default branches (default: remote.DefaultProtectionPolicy)
+	Protection *remote.ProtectionPolicy `yaml:"protection"`
+
+	// Settings is the GitHub repository settings `githelper github reconcile`
+	// keeps in place
+	Settings RepoSettingsConfig `yaml:"settings"`
+
+	// Templates says where `githelper repo create --type` looks for
+	// templates besides the builtin ones
+	Templates templates.Config `yaml:"templates"`
+}
+
+// RepoSettingsConfig holds settings shared by every repository and
+// per-repository overrides, keyed by the repository's githelper name
+type RepoSettingsConfig struct {
+	Defaults github.RepoSettings            `yaml:"defaults"`
+	Repos    map[string]github.RepoSettings `yaml:"repos"`
+}
+
+// RepoSettings returns the desired settings of the named repository: the
+// defaults with its overrides applied
+func (c *LocalConfig) RepoSettings(name string) github.RepoSettings {
+	return c.Settings.Defaults.Merge(c.Settings.Repos[name])
+}
+
+// ProtectionPolicy returns the configured branch protection policy, or the
+// default one if the config doesn't set it
+func (c *LocalConfig) ProtectionPolicy() remote.ProtectionPolicy {
+	if c.Protection == nil {
+		return remote.DefaultProtectionPolicy()
+	}
+	return *c.Protection
+}
+
+// PlatformHost describes the backend serving a host
+type PlatformHost struct {
+	Type   string `yaml:"type"`    // github, gitlab, gitea, forgejo, bitbucket, bitbucket-server
+	APIURL string `yaml:"api_url"` // optional, defaults to the platform's standard API path
+}
+
+// DefaultLocalConfigPath returns ~/.githelper/config.yaml
+func DefaultLocalConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", localConfigFile), nil
+}
+
+// LoadLocalConfig reads the local config file at path (or the default path if empty).
+// A missing file is not an error and yields an empty config.
+func LoadLocalConfig(path string) (*LocalConfig, error) {
+	if path == "" {
+		var err error
+		path, err = DefaultLocalConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &LocalConfig{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for host, p := range cfg.Platforms {
+		if strings.TrimSpace(p.Type) == "" {
+			return nil, fmt.Errorf("platform type missing for host %s in %s", host, path)
+		}
+	}
+
+	if err := cfg.Settings.Defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings.defaults in %s: %w", path, err)
+	}
+	for name, s := range cfg.Settings.Repos {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid settings for repository %s in %s: %w", name, path, err)
+		}
+	}
+
+	return cfg, nil
+}
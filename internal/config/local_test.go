@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLocalConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]PlatformHost
+		wantErr bool
+	}{
+		{
+			name: "platform mapping",
+			content: `platforms:
+  git.corp.example:2222:
+    type: gitea
+    api_url: https://git.corp.example/api/v1
+  gitlab.internal:
+    type: gitlab
+`,
+			want: map[string]PlatformHost{
+				"git.corp.example:2222": {Type: "gitea", APIURL: "https://git.corp.example/api/v1"},
+				"gitlab.internal":       {Type: "gitlab"},
+			},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    nil,
+		},
+		{
+			name: "missing type",
+			content: `platforms:
+  git.corp.example:
+    api_url: https://git.corp.example/api/v1
+`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "platforms: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			cfg, err := LoadLocalConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadLocalConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(cfg.Platforms) != len(tt.want) {
+				t.Fatalf("Platforms = %v, want %v", cfg.Platforms, tt.want)
+			}
+			for host, want := range tt.want {
+				if got := cfg.Platforms[host]; got != want {
+					t.Errorf("Platforms[%s] = %+v, want %+v", host, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadLocalConfig_Missing(t *testing.T) {
+	cfg, err := LoadLocalConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadLocalConfig() error = %v, want nil for missing file", err)
+	}
+	if len(cfg.Platforms) != 0 {
+		t.Errorf("Platforms = %v, want empty", cfg.Platforms)
+	}
+}
+
+func TestLoadLocalConfig_Settings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `settings:
+  defaults:
+    has_wiki: false
+    merge_methods: [squash]
+  repos:
+    site:
+      homepage: https://example.com
+      merge_methods: [merge, squash]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadLocalConfig(path)
+	if err != nil {
+		t.Fatalf("LoadLocalConfig() error = %v", err)
+	}
+
+	site := cfg.RepoSettings("site")
+	if site.HasWiki == nil || *site.HasWiki || site.Homepage == nil || *site.Homepage != "https://example.com" || len(site.MergeMethods) != 2 {
+		t.Errorf("RepoSettings(site) = %+v, want defaults with the site overrides", site)
+	}
+	other := cfg.RepoSettings("other")
+	if other.Homepage != nil || len(other.MergeMethods) != 1 {
+		t.Errorf("RepoSettings(other) = %+v, want just the defaults", other)
+	}
+
+	if err := os.WriteFile(path, []byte("settings:\n  defaults:\n    visibility: secret\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadLocalConfig(path); err == nil {
+		t.Error("LoadLocalConfig() error = nil, want invalid visibility rejected")
+	}
+}
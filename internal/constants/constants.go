@@ -20,4 +20,5 @@ const (
 	DefaultOperationTimeout = 10 * time.Second
 	QuickOperationTimeout   = 5 * time.Second
 	BranchOperationTimeout  = 2 * time.Second
+	StateLockTimeout        = 30 * time.Second
 )
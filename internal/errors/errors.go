@@ -9,6 +9,7 @@ type ErrorType string
 
 const (
 	ErrorTypeVault       ErrorType = "vault"
+	ErrorTypeSecrets     ErrorType = "secrets"
 	ErrorTypeGit         ErrorType = "git"
 	ErrorTypeConfig      ErrorType = "config"
 	ErrorTypeState       ErrorType = "state"
@@ -0,0 +1,247 @@
+package fleet
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/constants"
+	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+)
+
+// DefaultWorkers is the default number of repositories classified concurrently
+const DefaultWorkers = 8
+
+// Target is one repository to classify
+type Target struct {
+	Name    string
+	Path    string
+	Mirrors []string // Remote names; first two act as Core and GitHub
+}
+
+// RepoStatus is the classification result for one repository
+type RepoStatus struct {
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Scenarios []string `json:"scenarios,omitempty"` // Scenario IDs across every dimension
+	Severity  string   `json:"severity"`            // Worst severity among Scenarios
+	Error     string   `json:"error,omitempty"`     // Classification failure
+
+	State *scenarios.RepositoryState `json:"state,omitempty"`
+}
+
+// ScenarioGroup lists the repositories currently in one scenario
+type ScenarioGroup struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Severity string   `json:"severity"`
+	Repos    []string `json:"repos"`
+}
+
+// Report aggregates a fleet-wide classification run
+type Report struct {
+	Repositories []RepoStatus       `json:"repositories"`
+	Groups       []ScenarioGroup    `json:"groups"`
+	BySeverity   map[string]int     `json:"by_severity"` // Repositories per worst severity
+	Duration     scenarios.Duration `json:"duration_ms"`
+}
+
+// TargetsFromState builds classification targets from every repository in state
+func TargetsFromState(repos map[string]*state.Repository) []Target {
+	targets := make([]Target, 0, len(repos))
+	for name, repo := range repos {
+		var mirrors []string
+		for _, m := range repo.MirrorSet() {
+			mirrors = append(mirrors, m.Name)
+		}
+		targets = append(targets, Target{Name: name, Path: repo.Path, Mirrors: mirrors})
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	return targets
+}
+
+// Classify runs the classifier over every target with at most workers running
+// at once. Results are returned in target order. Cancelling ctx stops new
+// classifications from starting; unstarted targets are reported as errors.
+func Classify(ctx context.Context, targets []Target, workers int, options scenarios.DetectionOptions) *Report {
+	start := time.Now()
+
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	results := make([]RepoStatus, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = classifyOne(targets[i], options)
+			}
+		}()
+	}
+
+	for i := range targets {
+		if ctx.Err() != nil {
+			results[i] = RepoStatus{
+				Name:     targets[i].Name,
+				Path:     targets[i].Path,
+				Severity: scenarios.SeverityError,
+				Error:    ctx.Err().Error(),
+			}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := Aggregate(results)
+	report.Duration = scenarios.Duration{Duration: time.Since(start)}
+	return report
+}
+
+// classifyOne classifies a single repository
+func classifyOne(target Target, options scenarios.DetectionOptions) RepoStatus {
+	status := RepoStatus{Name: target.Name, Path: target.Path}
+
+	gitClient := git.NewClient(target.Path)
+	if !gitClient.IsRepository() {
+		status.Severity = scenarios.SeverityError
+		status.Error = "not a git repository"
+		return status
+	}
+
+	mirrors := target.Mirrors
+	if len(mirrors) == 0 {
+		mirrors = []string{constants.DefaultCoreRemote, constants.DefaultGitHubRemote}
+	}
+
+	repoState, err := scenarios.NewMirrorClassifier(gitClient, mirrors, options).Detect()
+	if err != nil {
+		status.Severity = scenarios.SeverityError
+		status.Error = err.Error()
+		return status
+	}
+	repoState.RepoPath = target.Path
+
+	status.State = repoState
+	status.Scenarios = ScenarioIDs(repoState)
+	status.Severity = WorstSeverity(status.Scenarios)
+	return status
+}
+
+// ScenarioIDs returns every scenario ID a repository state is in
+func ScenarioIDs(repoState *scenarios.RepositoryState) []string {
+	var ids []string
+	for _, id := range []string{
+		repoState.Existence.ID,
+		repoState.Sync.ID,
+		repoState.WorkingTree.ID,
+		repoState.Corruption.ID,
+	} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	// Tags only contribute their non-synced scenarios, once each
+	seen := make(map[string]bool)
+	for _, tag := range repoState.Tags {
+		if tag.ID != "T1" && !seen[tag.ID] {
+			seen[tag.ID] = true
+			ids = append(ids, tag.ID)
+		}
+	}
+
+	// Likewise only a default branch mismatch is listed
+	if ds := repoState.DefaultBranchState; ds != nil && ds.ID != "D1" {
+		ids = append(ids, ds.ID)
+	}
+
+	return ids
+}
+
+// severityRank orders severities from least to most serious
+var severityRank = map[string]int{
+	scenarios.SeverityInfo:     0,
+	scenarios.SeverityWarning:  1,
+	scenarios.SeverityError:    2,
+	scenarios.SeverityCritical: 3,
+}
+
+// WorstSeverity returns the most serious severity among scenario IDs.
+// Unknown IDs are ignored; an empty list is info.
+func WorstSeverity(ids []string) string {
+	worst := scenarios.SeverityInfo
+	for _, id := range ids {
+		def, ok := scenarios.LookupScenario(id)
+		if !ok {
+			continue
+		}
+		if severityRank[def.Severity] > severityRank[worst] {
+			worst = def.Severity
+		}
+	}
+	return worst
+}
+
+// Aggregate groups repository results by scenario ID.
+// Groups are ordered most severe first, then by scenario ID.
+func Aggregate(results []RepoStatus) *Report {
+	report := &Report{
+		Repositories: results,
+		BySeverity:   make(map[string]int),
+	}
+
+	groups := make(map[string]*ScenarioGroup)
+	for _, r := range results {
+		report.BySeverity[r.Severity]++
+
+		for _, id := range r.Scenarios {
+			g, ok := groups[id]
+			if !ok {
+				g = &ScenarioGroup{ID: id, Severity: scenarios.SeverityInfo}
+				if def, found := scenarios.LookupScenario(id); found {
+					g.Name = def.Name
+					g.Severity = def.Severity
+				}
+				groups[id] = g
+			}
+			g.Repos = append(g.Repos, r.Name)
+		}
+	}
+
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] > severityRank[b.Severity]
+		}
+		return a.ID < b.ID
+	})
+
+	return report
+}
+
+// Failing returns repositories in an error or critical scenario, or that failed to classify
+func (r *Report) Failing() []RepoStatus {
+	var failing []RepoStatus
+	for _, repo := range r.Repositories {
+		if severityRank[repo.Severity] >= severityRank[scenarios.SeverityError] {
+			failing = append(failing, repo)
+		}
+	}
+	return failing
+}
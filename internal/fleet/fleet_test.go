@@ -0,0 +1,138 @@
+package fleet
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/scenarios"
+)
+
+// runGit runs a git command in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// setupRepo creates a local clone with core and github bare remotes, both in sync
+func setupRepo(t *testing.T, tmp, name string) string {
+	t.Helper()
+
+	local := filepath.Join(tmp, name)
+	core := filepath.Join(tmp, name+"-core.git")
+	github := filepath.Join(tmp, name+"-github.git")
+
+	runGit(t, tmp, "init", "--bare", "-b", "main", core)
+	runGit(t, tmp, "init", "--bare", "-b", "main", github)
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "remote", "add", "origin", core)
+	runGit(t, local, "remote", "add", "github", github)
+	runGit(t, local, "commit", "--allow-empty", "-m", "first")
+	runGit(t, local, "push", "-q", "origin", "main")
+	runGit(t, local, "push", "-q", "github", "main")
+
+	return local
+}
+
+func TestClassify(t *testing.T) {
+	tmp := t.TempDir()
+
+	healthy := setupRepo(t, tmp, "healthy")
+
+	// lagging: GitHub missed the last push (S4, error severity)
+	lagging := setupRepo(t, tmp, "lagging")
+	runGit(t, lagging, "commit", "--allow-empty", "-m", "second")
+	runGit(t, lagging, "push", "-q", "origin", "main")
+
+	targets := []Target{
+		{Name: "healthy", Path: healthy, Mirrors: []string{"origin", "github"}},
+		{Name: "lagging", Path: lagging, Mirrors: []string{"origin", "github"}},
+		{Name: "missing", Path: filepath.Join(tmp, "does-not-exist")},
+	}
+
+	options := scenarios.DefaultDetectionOptions()
+	options.SkipCorruption = true
+
+	report := Classify(context.Background(), targets, 2, options)
+
+	if len(report.Repositories) != 3 {
+		t.Fatalf("got %d results, want 3", len(report.Repositories))
+	}
+
+	// Results keep target order
+	for i, want := range []string{"healthy", "lagging", "missing"} {
+		if report.Repositories[i].Name != want {
+			t.Errorf("Repositories[%d] = %s, want %s", i, report.Repositories[i].Name, want)
+		}
+	}
+
+	if got := report.Repositories[0].Severity; got != scenarios.SeverityInfo {
+		t.Errorf("healthy severity = %s, want info (%v)", got, report.Repositories[0].Scenarios)
+	}
+	if got := report.Repositories[1].Severity; got != scenarios.SeverityError {
+		t.Errorf("lagging severity = %s, want error (%v)", got, report.Repositories[1].Scenarios)
+	}
+	if report.Repositories[2].Error == "" {
+		t.Error("missing repository should report an error")
+	}
+
+	groups := map[string][]string{}
+	for _, g := range report.Groups {
+		groups[g.ID] = g.Repos
+	}
+	if len(groups["S4"]) != 1 || groups["S4"][0] != "lagging" {
+		t.Errorf("S4 group = %v, want [lagging]", groups["S4"])
+	}
+	if len(groups["E1"]) != 2 {
+		t.Errorf("E1 group = %v, want 2 repos", groups["E1"])
+	}
+
+	// Most severe group first
+	if report.Groups[0].Severity != scenarios.SeverityError {
+		t.Errorf("first group severity = %s, want error", report.Groups[0].Severity)
+	}
+
+	if failing := report.Failing(); len(failing) != 2 {
+		t.Errorf("Failing() = %d repos, want 2", len(failing))
+	}
+}
+
+func TestClassify_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := Classify(ctx, []Target{{Name: "a", Path: t.TempDir()}}, 1, scenarios.DefaultDetectionOptions())
+	if report.Repositories[0].Error == "" {
+		t.Error("expected cancelled target to report an error")
+	}
+}
+
+func TestWorstSeverity(t *testing.T) {
+	tests := []struct {
+		ids  []string
+		want string
+	}{
+		{nil, scenarios.SeverityInfo},
+		{[]string{"E1", "S1", "W1", "C1"}, scenarios.SeverityInfo},
+		{[]string{"E1", "S2", "W1"}, scenarios.SeverityWarning},
+		{[]string{"E1", "S4", "W2"}, scenarios.SeverityError},
+		{[]string{"S13", "S4"}, scenarios.SeverityCritical},
+		{[]string{"UNKNOWN"}, scenarios.SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		if got := WorstSeverity(tt.ids); got != tt.want {
+			t.Errorf("WorstSeverity(%v) = %s, want %s", tt.ids, got, tt.want)
+		}
+	}
+}
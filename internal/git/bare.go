@@ -0,0 +1,407 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/constants"
+)
+
+// bare.go contains operations on the bare (Core) repository itself rather
+// than on a clone: creating it and reading and repointing its HEAD, locally
+// or over SSH
+
+// BareLocation is where a bare repository lives. Host is empty for a
+// repository on the local filesystem.
+type BareLocation struct {
+	User string
+	Host string
+	Port string
+	Path string
+}
+
+// IsLocal reports whether the repository is on the local filesystem
+func (l BareLocation) IsLocal() bool {
+	return l.Host == ""
+}
+
+// String returns the location as host:path, or just the path if it's local
+func (l BareLocation) String() string {
+	if l.IsLocal() {
+		return l.Path
+	}
+	return l.Host + ":" + l.Path
+}
+
+// ParseBareURL parses a bare repository URL: a local path, file://path,
+// ssh://[user@]host[:port]/path, or scp-like [user@]host:path
+func ParseBareURL(rawURL string) (BareLocation, error) {
+	switch {
+	case rawURL == "":
+		return BareLocation{}, fmt.Errorf("empty repository URL")
+
+	case strings.HasPrefix(rawURL, "file://"):
+		return BareLocation{Path: strings.TrimPrefix(rawURL, "file://")}, nil
+
+	case strings.HasPrefix(rawURL, "ssh://"):
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return BareLocation{}, fmt.Errorf("invalid SSH URL %s: %w", rawURL, err)
+		}
+		if u.Hostname() == "" || u.Path == "" || u.Path == "/" {
+			return BareLocation{}, fmt.Errorf("SSH URL %s must include a host and path", rawURL)
+		}
+		loc := BareLocation{Host: u.Hostname(), Port: u.Port(), Path: u.Path}
+		if u.User != nil {
+			loc.User = u.User.Username()
+		}
+		// ssh://host/~/repo.git is relative to the home directory
+		loc.Path = strings.TrimPrefix(loc.Path, "/~/")
+		return loc, nil
+
+	case strings.Contains(rawURL, "://"):
+		return BareLocation{}, fmt.Errorf("unsupported URL scheme in %s (use a path, file://, or SSH)", rawURL)
+	}
+
+	// scp-like syntax: a colon before any slash
+	colon := strings.Index(rawURL, ":")
+	if colon > 0 && !strings.Contains(rawURL[:colon], "/") {
+		host, path := rawURL[:colon], rawURL[colon+1:]
+		if path == "" {
+			return BareLocation{}, fmt.Errorf("SSH URL %s must include a path", rawURL)
+		}
+		// Relative paths (and ~/) are resolved from the login directory
+		loc := BareLocation{Host: host, Path: strings.TrimPrefix(path, "~/")}
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			loc.User, loc.Host = host[:at], host[at+1:]
+		}
+		return loc, nil
+	}
+
+	return BareLocation{Path: rawURL}, nil
+}
+
+// GetRemoteHEAD asks the remote which branch its HEAD points to (its default
+// branch). Unlike GetDefaultBranch it never uses the cached origin/HEAD, which
+// is only written at clone time. It returns "" if HEAD is detached, unset, or
+// points at a branch that doesn't exist.
+func (c *Client) GetRemoteHEAD(remote string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
+	defer cancel()
+
+	output, err := c.runWithContext(ctx, "ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	// Output: "ref: refs/heads/main\tHEAD" followed by "<hash>\tHEAD"
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" {
+			return strings.TrimPrefix(fields[1], "refs/heads/"), nil
+		}
+	}
+	return "", nil
+}
+
+// GetBareHEAD reads HEAD straight from the bare repository behind remote,
+// locally or over SSH. Unlike GetRemoteHEAD it also sees a dangling HEAD (one
+// pointing at a branch that doesn't exist), which git servers don't advertise.
+func (c *Client) GetBareHEAD(remote string) (string, error) {
+	loc, err := c.bareLocation(remote)
+	if err != nil {
+		return "", err
+	}
+
+	sshCommand, _ := c.GetSSHCommand()
+	output, err := runOnBare(loc, sshCommand, "symbolic-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(output, "refs/heads/"), nil
+}
+
+// SetRemoteHEAD points the HEAD of the bare repository behind remote at
+// branch, which makes it the repository's default branch. The repository
+// is updated in place on disk or over SSH; git itself cannot change a
+// remote's HEAD.
+func (c *Client) SetRemoteHEAD(remote, branch string) error {
+	if err := validateBranchName(branch); err != nil {
+		return err
+	}
+
+	loc, err := c.bareLocation(remote)
+	if err != nil {
+		return err
+	}
+
+	sshCommand, _ := c.GetSSHCommand()
+	_, err = runOnBare(loc, sshCommand, "symbolic-ref", "HEAD", "refs/heads/"+branch)
+	return err
+}
+
+// bareLocation returns where the repository behind remote lives
+func (c *Client) bareLocation(remote string) (BareLocation, error) {
+	remoteURL, err := c.GetRemoteURL(remote)
+	if err != nil {
+		return BareLocation{}, fmt.Errorf("failed to get URL of %s: %w", remote, err)
+	}
+	return ParseBareURL(remoteURL)
+}
+
+// BareRepoOptions configures CreateBareRepo
+type BareRepoOptions struct {
+	// DefaultBranch is the branch HEAD points to, e.g. "main"
+	DefaultBranch string
+
+	// Shared is the core.sharedRepository setting: group, all, umask, or an
+	// octal mode such as 0660. Empty leaves permissions to the umask.
+	Shared string
+
+	// Group is the Unix group that should own the repository. Empty leaves it
+	// to the parent directory (or setgid bit).
+	Group string
+
+	// SSHCommand overrides the ssh invocation for remote repositories
+	SSHCommand string
+}
+
+// BareRepoStatus is the state of a bare repository as read back after
+// CreateBareRepo
+type BareRepoStatus struct {
+	Created bool   `json:"created"` // False if the repository already existed
+	Head    string `json:"head"`    // Branch HEAD points to
+	Shared  string `json:"shared,omitempty"`
+	Group   string `json:"group"`
+	Mode    string `json:"mode"` // Repository directory mode, e.g. drwxrwsr-x
+}
+
+// CreateBareRepo creates the bare repository at rawURL (a local path, file://
+// or SSH URL) with HEAD, sharing and group set from opts. It is idempotent: an
+// existing bare repository is left in place, its settings are reapplied and
+// its HEAD is only repointed if it names a branch that doesn't exist. The
+// result is read back and checked against opts.
+func CreateBareRepo(rawURL string, opts BareRepoOptions) (*BareRepoStatus, error) {
+	loc, err := ParseBareURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DefaultBranch == "" {
+		opts.DefaultBranch = constants.DefaultBranch
+	}
+	if err := validateBranchName(opts.DefaultBranch); err != nil {
+		return nil, err
+	}
+	if !validShared(opts.Shared) {
+		return nil, fmt.Errorf("invalid shared setting %q (use group, all, umask, or an octal mode)", opts.Shared)
+	}
+	if opts.Group != "" && !validGroupName(opts.Group) {
+		return nil, fmt.Errorf("invalid group name %q", opts.Group)
+	}
+
+	output, err := runOnHost(loc, opts.SSHCommand, "sh", "-c", bareRepoScript(loc.Path, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bare repository %s: %w", loc, err)
+	}
+
+	status := &BareRepoStatus{}
+	bare := false
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "created":
+			status.Created = value == "true"
+		case "bare":
+			bare = value == "true"
+		case "head":
+			status.Head = strings.TrimPrefix(value, "refs/heads/")
+		case "shared":
+			status.Shared = value
+		case "group":
+			status.Group = value
+		case "mode":
+			status.Mode = value
+		}
+	}
+
+	// Verify
+	switch {
+	case !bare:
+		return status, fmt.Errorf("%s is not a bare repository after creation", loc)
+	case status.Created && status.Head != opts.DefaultBranch:
+		return status, fmt.Errorf("HEAD of %s points to %q, want %s", loc, status.Head, opts.DefaultBranch)
+	case opts.Shared != "" && status.Shared != opts.Shared:
+		return status, fmt.Errorf("core.sharedRepository of %s is %q, want %s", loc, status.Shared, opts.Shared)
+	case opts.Group != "" && status.Group != opts.Group:
+		return status, fmt.Errorf("%s is owned by group %q, want %s", loc, status.Group, opts.Group)
+	}
+	return status, nil
+}
+
+// InitBareRepo creates a bare repository at path with the default options
+func InitBareRepo(path string) error {
+	_, err := CreateBareRepo(path, BareRepoOptions{})
+	return err
+}
+
+// bareRepoScript returns the POSIX shell script CreateBareRepo runs on the
+// repository's host. It prints key=value lines describing the result.
+func bareRepoScript(path string, opts BareRepoOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "set -e\nrepo=%s\ncreated=false\n", shellQuote(path))
+
+	initArgs := "--quiet --bare"
+	if opts.Shared != "" {
+		initArgs += " --shared=" + shellQuote(opts.Shared)
+	}
+	fmt.Fprintf(&b, `if [ -e "$repo" ]; then
+  if [ "$(git --git-dir "$repo" rev-parse --is-bare-repository 2>/dev/null)" != true ]; then
+    echo "$repo exists but is not a bare git repository" >&2
+    exit 3
+  fi
+else
+  mkdir -p "$repo"
+  git init %s "$repo"
+  created=true
+fi
+`, initArgs)
+
+	// Repoint HEAD on creation, or if it names a branch that doesn't exist
+	fmt.Fprintf(&b, `head=$(git --git-dir "$repo" symbolic-ref -q HEAD || true)
+if [ "$created" = true ] || ! git --git-dir "$repo" rev-parse -q --verify "$head^{commit}" >/dev/null 2>&1; then
+  git --git-dir "$repo" symbolic-ref HEAD %s
+fi
+`, shellQuote("refs/heads/"+opts.DefaultBranch))
+
+	if opts.Shared != "" {
+		fmt.Fprintf(&b, "git --git-dir \"$repo\" config core.sharedRepository %s\n", shellQuote(opts.Shared))
+	}
+	if opts.Group != "" {
+		fmt.Fprintf(&b, "chgrp -R %s \"$repo\"\n", shellQuote(opts.Group))
+	}
+	// git init --shared only fixes permissions of what it creates
+	switch opts.Shared {
+	case "group", "true", "1":
+		b.WriteString("chmod -R g+rwX \"$repo\"\nfind \"$repo\" -type d -exec chmod g+s {} +\n")
+	case "all", "world", "everybody", "2":
+		b.WriteString("chmod -R g+rwX,o+rX \"$repo\"\nfind \"$repo\" -type d -exec chmod g+s {} +\n")
+	}
+
+	b.WriteString(`echo "created=$created"
+echo "bare=$(git --git-dir "$repo" rev-parse --is-bare-repository)"
+echo "head=$(git --git-dir "$repo" symbolic-ref -q HEAD || true)"
+echo "shared=$(git --git-dir "$repo" config core.sharedRepository || true)"
+echo "group=$(ls -ld "$repo" | awk '{print $4}')"
+echo "mode=$(ls -ld "$repo" | awk '{print $1}')"
+`)
+	return b.String()
+}
+
+// validShared reports whether s is a core.sharedRepository value
+func validShared(s string) bool {
+	switch s {
+	case "", "group", "true", "1", "all", "world", "everybody", "2", "umask", "false", "0":
+		return true
+	}
+	if len(s) < 3 || len(s) > 4 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// validGroupName reports whether s is a plausible Unix group name
+func validGroupName(s string) bool {
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case (r >= '0' && r <= '9') || r == '-' || r == '.':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// runOnBare runs a git command against a bare repository, over SSH if it
+// isn't local, and returns its trimmed output
+func runOnBare(loc BareLocation, sshCommand string, args ...string) (string, error) {
+	argv := append([]string{"git", "--git-dir", loc.Path}, args...)
+	output, err := runOnHost(loc, sshCommand, argv...)
+	if err != nil {
+		return "", fmt.Errorf("git %s on %s failed: %w", strings.Join(args, " "), loc, err)
+	}
+	return output, nil
+}
+
+// runOnHost runs argv on the host holding loc: directly if it's local,
+// otherwise over SSH with every argument shell-quoted. sshCommand overrides
+// the ssh invocation (as core.sshCommand does); if empty, GIT_SSH_COMMAND or
+// plain ssh is used.
+func runOnHost(loc BareLocation, sshCommand string, argv ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if loc.IsLocal() {
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+	} else {
+		if sshCommand == "" {
+			sshCommand = os.Getenv("GIT_SSH_COMMAND")
+		}
+		if sshCommand == "" {
+			sshCommand = "ssh"
+		}
+		sshArgs := strings.Fields(sshCommand)
+		if loc.Port != "" {
+			sshArgs = append(sshArgs, "-p", loc.Port)
+		}
+		target := loc.Host
+		if loc.User != "" {
+			target = loc.User + "@" + loc.Host
+		}
+
+		quoted := make([]string, 0, len(argv))
+		for _, arg := range argv {
+			quoted = append(quoted, shellQuote(arg))
+		}
+		sshArgs = append(sshArgs, target, strings.Join(quoted, " "))
+		cmd = exec.CommandContext(ctx, sshArgs[0], sshArgs[1:]...)
+	}
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w\nstderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// validateBranchName rejects names git wouldn't accept as a branch, and so
+// keeps them safe to pass to a remote shell
+func validateBranchName(branch string) error {
+	if branch == "" || strings.HasPrefix(branch, "-") || strings.ContainsAny(branch, " \t\n~^:?*[\\'\"") ||
+		strings.Contains(branch, "..") || strings.HasSuffix(branch, ".lock") || strings.HasSuffix(branch, "/") {
+		return fmt.Errorf("invalid branch name %q", branch)
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
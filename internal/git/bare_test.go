@@ -0,0 +1,180 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseBareURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    BareLocation
+		wantErr bool
+	}{
+		{url: "/srv/git/repo.git", want: BareLocation{Path: "/srv/git/repo.git"}},
+		{url: "file:///srv/git/repo.git", want: BareLocation{Path: "/srv/git/repo.git"}},
+		{url: "git@core.example:repos/repo.git", want: BareLocation{User: "git", Host: "core.example", Path: "repos/repo.git"}},
+		{url: "core.example:~/repo.git", want: BareLocation{Host: "core.example", Path: "repo.git"}},
+		{url: "ssh://git@core.example:2222/srv/repo.git", want: BareLocation{User: "git", Host: "core.example", Port: "2222", Path: "/srv/repo.git"}},
+		{url: "ssh://core.example/~/repo.git", want: BareLocation{Host: "core.example", Path: "repo.git"}},
+		{url: "https://github.com/owner/repo.git", wantErr: true},
+		{url: "ssh://core.example", wantErr: true},
+		{url: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBareURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBareURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBareURL(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestSetRemoteHEAD(t *testing.T) {
+	tmp := t.TempDir()
+	bare := filepath.Join(tmp, "core.git")
+	local := filepath.Join(tmp, "local")
+
+	for _, args := range [][]string{
+		{"init", "--bare", "-b", "master", bare},
+		{"init", "-b", "main", local},
+		{"-C", local, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "first"},
+		{"-C", local, "remote", "add", "origin", bare},
+		{"-C", local, "push", "origin", "main"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	client := NewClient(local)
+
+	// HEAD still points at master, which was never pushed
+	head, err := client.GetRemoteHEAD("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteHEAD() error = %v", err)
+	}
+	if head != "" {
+		t.Errorf("GetRemoteHEAD() = %q for a dangling HEAD, want empty", head)
+	}
+	head, err = client.GetBareHEAD("origin")
+	if err != nil {
+		t.Fatalf("GetBareHEAD() error = %v", err)
+	}
+	if head != "master" {
+		t.Errorf("GetBareHEAD() = %q, want master", head)
+	}
+
+	if err := client.SetRemoteHEAD("origin", "main"); err != nil {
+		t.Fatalf("SetRemoteHEAD() error = %v", err)
+	}
+
+	head, err = client.GetRemoteHEAD("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteHEAD() error = %v", err)
+	}
+	if head != "main" {
+		t.Errorf("GetRemoteHEAD() = %q, want main", head)
+	}
+
+	if err := client.SetRemoteHEAD("origin", "main; rm -rf /"); err == nil {
+		t.Error("SetRemoteHEAD() accepted an invalid branch name")
+	}
+}
+
+func TestCreateBareRepo_Local(t *testing.T) {
+	group, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	if err != nil {
+		t.Skipf("cannot look up current group: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "srv", "git", "project.git")
+	opts := BareRepoOptions{DefaultBranch: "trunk", Shared: "group", Group: group.Name}
+
+	status, err := CreateBareRepo(path, opts)
+	if err != nil {
+		t.Fatalf("CreateBareRepo() error = %v", err)
+	}
+	if !status.Created || status.Head != "trunk" || status.Shared != "group" || status.Group != group.Name {
+		t.Errorf("CreateBareRepo() = %+v", status)
+	}
+	if !strings.Contains(status.Mode, "rws") {
+		t.Errorf("mode = %s, want group-writable with setgid", status.Mode)
+	}
+
+	// Running again is a no-op that still verifies
+	status, err = CreateBareRepo("file://"+path, opts)
+	if err != nil {
+		t.Fatalf("second CreateBareRepo() error = %v", err)
+	}
+	if status.Created || status.Head != "trunk" {
+		t.Errorf("second CreateBareRepo() = %+v, want existing repository", status)
+	}
+
+	// A directory that isn't a bare repository is never reused
+	notRepo := filepath.Join(t.TempDir(), "plain")
+	if err := os.MkdirAll(notRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateBareRepo(notRepo, opts); err == nil {
+		t.Error("CreateBareRepo() accepted a directory that is not a bare repository")
+	}
+
+	if _, err := CreateBareRepo(path, BareRepoOptions{Shared: "everyone; rm -rf /"}); err == nil {
+		t.Error("CreateBareRepo() accepted an invalid shared setting")
+	}
+}
+
+func TestCreateBareRepo_SSH(t *testing.T) {
+	tmp := t.TempDir()
+	logPath := filepath.Join(tmp, "ssh.log")
+
+	// ssh stand-in: records its options and target, then runs the command locally
+	shim := filepath.Join(tmp, "fake-ssh")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -p|-i|-o|-l) echo "opt $1 $2" >> '` + logPath + `'; shift 2 ;;
+    -*) shift ;;
+    *) break ;;
+  esac
+done
+echo "target $1" >> '` + logPath + `'
+shift
+exec sh -c "$*"
+`
+	if err := os.WriteFile(shim, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_SSH_COMMAND", shim)
+
+	path := filepath.Join(tmp, "remote", "repo's.git")
+	status, err := CreateBareRepo("ssh://git@core.example:2222"+path, BareRepoOptions{DefaultBranch: "main"})
+	if err != nil {
+		t.Fatalf("CreateBareRepo() error = %v", err)
+	}
+	if !status.Created || status.Head != "main" {
+		t.Errorf("CreateBareRepo() = %+v", status)
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("ssh shim was not run: %v", err)
+	}
+	if !strings.Contains(string(log), "opt -p 2222") || !strings.Contains(string(log), "target git@core.example") {
+		t.Errorf("ssh invocation log = %q", log)
+	}
+
+	out, err := exec.Command("git", "--git-dir", path, "symbolic-ref", "HEAD").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "refs/heads/main" {
+		t.Errorf("HEAD of created repository = %q, %v", out, err)
+	}
+}
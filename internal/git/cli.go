@@ -3,6 +3,7 @@ package git
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -18,6 +19,7 @@ import (
 // - cli_branch.go: Branch operations (GetCurrentBranch, ListBranches, etc.)
 // - cli_status.go: Status/detection operations (IsRepository, GetStagedFiles, etc.)
 // - cli_advanced.go: Advanced operations (CountCommitsBetween, ScanLargeBinaries, etc.)
+// - bare.go: Operations on the bare repository itself (CreateBareRepo, GetRemoteHEAD, GetBareHEAD, SetRemoteHEAD)
 type Client struct {
 	workdir string
 	mu      sync.Mutex // Serialize all git operations to prevent races
@@ -37,6 +39,11 @@ func (c *Client) run(args ...string) (string, error) {
 
 // runWithContext executes a git command with explicit context
 func (c *Client) runWithContext(ctx context.Context, args ...string) (string, error) {
+	return c.runWithEnv(ctx, nil, args...)
+}
+
+// runWithEnv executes a git command with extra environment variables
+func (c *Client) runWithEnv(ctx context.Context, env []string, args ...string) (string, error) {
 	// CRITICAL: Serialize all git operations to prevent races
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -51,6 +58,7 @@ func (c *Client) runWithContext(ctx context.Context, args ...string) (string, er
 		"GIT_TERMINAL_PROMPT=0", // Prevent credential hangs
 		"LC_ALL=C",              // Stable output parsing
 	)
+	cmd.Env = append(cmd.Env, env...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -63,3 +71,9 @@ func (c *Client) runWithContext(ctx context.Context, args ...string) (string, er
 
 	return strings.TrimSpace(stdout.String()), nil
 }
+
+// isExitCode reports whether err (possibly wrapped) is a git exit with the given status
+func isExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
@@ -52,6 +52,19 @@ func (c *Client) FetchRemote(remote string) error {
 	return err
 }
 
+// FetchRemoteRefs fetches a remote's branches and tags without touching local
+// tags: the remote's tags go to refs/remotes/<remote>/tags/. Two remotes with
+// the same tag name on different objects can then both be fetched.
+func (c *Client) FetchRemoteRefs(remote string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
+	defer cancel()
+
+	_, err := c.runWithContext(ctx, "fetch", "--no-tags", remote,
+		fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remote),
+		fmt.Sprintf("+refs/tags/*:refs/remotes/%s/tags/*", remote))
+	return err
+}
+
 // ResetToRef performs hard reset to ref (used in auto-fix)
 func (c *Client) ResetToRef(ref string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
@@ -11,13 +11,23 @@ import (
 )
 
 // cli_branch.go contains branch operations: GetCurrentBranch, GetBranchHash,
-// GetRemoteBranchHash, ListBranches, IsAncestor, GetDefaultBranch
+// GetRemoteBranchHash, ListBranches, ListTags, IsAncestor, GetDefaultBranch, SetHEADBranch
 
 // GetCurrentBranch returns the current branch name
 func (c *Client) GetCurrentBranch() (string, error) {
 	return c.run("rev-parse", "--abbrev-ref", "HEAD")
 }
 
+// SetHEADBranch points HEAD at a branch without touching the working tree.
+// Used to choose the branch name before the first commit of an empty clone.
+func (c *Client) SetHEADBranch(branch string) error {
+	if err := validateBranchName(branch); err != nil {
+		return err
+	}
+	_, err := c.run("symbolic-ref", "HEAD", "refs/heads/"+branch)
+	return err
+}
+
 // GetBranchHash returns commit hash for a local branch
 func (c *Client) GetBranchHash(branch string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.BranchOperationTimeout)
@@ -70,6 +80,29 @@ func (c *Client) ListBranches() (local, remote []string, err error) {
 	return local, remote, nil
 }
 
+// ListTags returns all local tags as a map of tag name to object hash.
+// Annotated tags map to the tag object, matching what ls-remote reports.
+func (c *Client) ListTags() (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultOperationTimeout)
+	defer cancel()
+
+	output, err := c.runWithContext(ctx, "for-each-ref", "--format=%(objectname) %(refname:lstrip=2)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		tags[fields[1]] = fields[0]
+	}
+
+	return tags, nil
+}
+
 // IsAncestor checks if commit1 is an ancestor of commit2 (for fast-forward validation)
 func (c *Client) IsAncestor(commit1, commit2 string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.QuickOperationTimeout)
@@ -116,7 +149,8 @@ func (c *Client) GetDefaultBranch(remote string) (string, error) {
 	output, err = c.runWithContext(ctx, "remote", "show", remote)
 	if err == nil {
 		for _, line := range strings.Split(output, "\n") {
-			if strings.Contains(line, "HEAD branch:") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "HEAD branch:") {
 				branch := strings.TrimSpace(strings.TrimPrefix(line, "HEAD branch:"))
 				return branch, nil
 			}
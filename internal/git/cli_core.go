@@ -3,9 +3,7 @@ package git
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 )
 
@@ -87,6 +85,14 @@ func (c *Client) ConfigGet(key string) (string, error) {
 	return c.run("config", "--get", key)
 }
 
+// ConfigUnset removes a git config value; a value that isn't set is not an error
+func (c *Client) ConfigUnset(key string) error {
+	if _, err := c.run("config", "--unset", key); err != nil && !isExitCode(err, 5) {
+		return err
+	}
+	return nil
+}
+
 // SetSSHCommand sets the SSH command for git operations
 func (c *Client) SetSSHCommand(keyPath string) error {
 	sshCmd := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", keyPath)
@@ -107,21 +113,3 @@ func (c *Client) GetRevList(ref1, ref2 string) ([]string, error) {
 
 	return strings.Split(output, "\n"), nil
 }
-
-// InitBareRepo creates a bare repository at the specified path
-func InitBareRepo(path string) error {
-	// Check if parent directory exists
-	parentDir := filepath.Dir(path)
-	if _, err := os.Stat(parentDir); os.IsNotExist(err) {
-		return fmt.Errorf("parent directory does not exist: %s", parentDir)
-	}
-
-	// Create the directory for the bare repo
-	if err := os.MkdirAll(path, 0755); err != nil {
-		return fmt.Errorf("failed to create bare repo directory: %w", err)
-	}
-
-	// Initialize as bare
-	client := NewClient(path)
-	return client.Init(true)
-}
@@ -1,12 +1,14 @@
 package git
 
 import (
-	"fmt"
+	"context"
 	"strings"
+
+	"github.com/lcgerke/githelper/internal/constants"
 )
 
 // cli_remote.go contains remote operations: AddRemote, RemoveRemote, SetURL, AddPushURL,
-// ConfigureDualPush, GetRemoteURL, GetPushURLs, ListRemotes
+// ConfigureDualPush, GetRemoteURL, GetPushURLs, ListRemotes, ListRemoteRefs
 
 // AddRemote adds a remote
 func (c *Client) AddRemote(name, url string) error {
@@ -33,23 +35,10 @@ func (c *Client) AddPushURL(remote, url string) error {
 }
 
 // ConfigureDualPush sets up dual-push for a remote
-// This configures the remote to push to multiple URLs
+// This configures the remote to fetch from the bare repo and push to both URLs.
+// It is the two-mirror case of ConfigureMirrorPush.
 func (c *Client) ConfigureDualPush(remote, bareURL, githubURL string) error {
-	// First, set the fetch URL (usually the bare repo)
-	if err := c.SetURL(remote, bareURL); err != nil {
-		return fmt.Errorf("failed to set fetch URL: %w", err)
-	}
-
-	// Add both push URLs
-	if err := c.AddPushURL(remote, bareURL); err != nil {
-		return fmt.Errorf("failed to add bare push URL: %w", err)
-	}
-
-	if err := c.AddPushURL(remote, githubURL); err != nil {
-		return fmt.Errorf("failed to add GitHub push URL: %w", err)
-	}
-
-	return nil
+	return c.ConfigureMirrorPush(remote, bareURL, []string{bareURL, githubURL})
 }
 
 // GetRemoteURL gets the URL for a remote
@@ -84,3 +73,27 @@ func (c *Client) ListRemotes() ([]string, error) {
 
 	return strings.Split(output, "\n"), nil
 }
+
+// ListRemoteRefs lists branch and tag refs on a remote via ls-remote.
+// Returns a map of full ref name (refs/heads/main, refs/tags/v1.0) to object hash.
+// Peeled tag entries (^{}) are skipped so annotated tags map to the tag object.
+func (c *Client) ListRemoteRefs(remote string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
+	defer cancel()
+
+	output, err := c.runWithContext(ctx, "ls-remote", "--heads", "--tags", remote)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.HasSuffix(fields[1], "^{}") {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+
+	return refs, nil
+}
@@ -7,70 +7,85 @@ import (
 // SetupDualPush configures a remote to push to multiple URLs
 // This is the core dual-push feature: one `git push` → pushes to both remotes
 func (c *Client) SetupDualPush(remoteName, fetchURL, bareURL, githubURL string) error {
-	// Check if remote exists
-	remotes, err := c.ListRemotes()
-	if err != nil {
-		return fmt.Errorf("failed to list remotes: %w", err)
+	return c.ConfigureMirrorPush(remoteName, fetchURL, []string{bareURL, githubURL})
+}
+
+// ConfigureMirrorPush configures a remote to fetch from fetchURL and push to every
+// URL in pushURLs, so one `git push` updates the whole mirror set.
+// Existing push URLs on the remote are replaced.
+func (c *Client) ConfigureMirrorPush(remoteName, fetchURL string, pushURLs []string) error {
+	if len(pushURLs) == 0 {
+		return fmt.Errorf("at least one push URL is required")
 	}
 
-	remoteExists := false
-	for _, r := range remotes {
-		if r == remoteName {
-			remoteExists = true
-			break
-		}
+	if err := c.EnsureRemote(remoteName, fetchURL); err != nil {
+		return err
 	}
 
-	// If remote doesn't exist, create it
-	if !remoteExists {
-		if err := c.AddRemote(remoteName, fetchURL); err != nil {
-			return fmt.Errorf("failed to add remote: %w", err)
-		}
-	} else {
-		// Update fetch URL
-		if err := c.SetURL(remoteName, fetchURL); err != nil {
-			return fmt.Errorf("failed to set fetch URL: %w", err)
+	// Clear existing push URLs so we control the full set.
+	// `git remote set-url --push` refuses to overwrite multiple values,
+	// so unset them directly (exit status 5 just means there were none).
+	if _, err := c.run("config", "--unset-all", "remote."+remoteName+".pushurl"); err != nil && !isExitCode(err, 5) {
+		return fmt.Errorf("failed to clear push URLs: %w", err)
+	}
+
+	for _, url := range pushURLs {
+		if err := c.AddPushURL(remoteName, url); err != nil {
+			return fmt.Errorf("failed to add push URL %s: %w", url, err)
 		}
 	}
 
-	// Clear existing push URLs by setting to the first URL
-	// This is necessary because we need to control all push URLs
-	_, err = c.run("remote", "set-url", "--push", remoteName, bareURL)
+	return nil
+}
+
+// EnsureRemote adds remoteName with fetchURL, or updates its fetch URL if it already exists
+func (c *Client) EnsureRemote(remoteName, fetchURL string) error {
+	remotes, err := c.ListRemotes()
 	if err != nil {
-		return fmt.Errorf("failed to set initial push URL: %w", err)
+		return fmt.Errorf("failed to list remotes: %w", err)
 	}
 
-	// Add GitHub as second push URL
-	if err := c.AddPushURL(remoteName, githubURL); err != nil {
-		return fmt.Errorf("failed to add GitHub push URL: %w", err)
+	for _, r := range remotes {
+		if r == remoteName {
+			if err := c.SetURL(remoteName, fetchURL); err != nil {
+				return fmt.Errorf("failed to set fetch URL: %w", err)
+			}
+			return nil
+		}
 	}
 
+	if err := c.AddRemote(remoteName, fetchURL); err != nil {
+		return fmt.Errorf("failed to add remote: %w", err)
+	}
 	return nil
 }
 
 // VerifyDualPush checks that dual-push is configured correctly
 func (c *Client) VerifyDualPush(remoteName, bareURL, githubURL string) (bool, error) {
+	return c.VerifyMirrorPush(remoteName, []string{bareURL, githubURL})
+}
+
+// VerifyMirrorPush checks that remoteName pushes to exactly the given URLs (order might vary)
+func (c *Client) VerifyMirrorPush(remoteName string, expected []string) (bool, error) {
 	pushURLs, err := c.GetPushURLs(remoteName)
 	if err != nil {
 		return false, err
 	}
 
-	if len(pushURLs) != 2 {
+	if len(pushURLs) != len(expected) {
 		return false, nil
 	}
 
-	// Check that both URLs are present (order might vary)
-	hasBare := false
-	hasGitHub := false
-
+	configured := make(map[string]bool, len(pushURLs))
 	for _, url := range pushURLs {
-		if url == bareURL {
-			hasBare = true
-		}
-		if url == githubURL {
-			hasGitHub = true
+		configured[url] = true
+	}
+
+	for _, url := range expected {
+		if !configured[url] {
+			return false, nil
 		}
 	}
 
-	return hasBare && hasGitHub, nil
+	return true, nil
 }
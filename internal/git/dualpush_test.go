@@ -0,0 +1,62 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestConfigureMirrorPush(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := exec.Command("git", "init", repoDir).Run(); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	client := NewClient(repoDir)
+	mirrors := []string{
+		"git@core.example:repo.git",
+		"git@github.com:owner/repo.git",
+		"git@backup.example:repo.git",
+	}
+
+	if err := client.ConfigureMirrorPush("origin", mirrors[0], mirrors); err != nil {
+		t.Fatalf("ConfigureMirrorPush() error = %v", err)
+	}
+
+	fetchURL, err := client.GetRemoteURL("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteURL() error = %v", err)
+	}
+	if fetchURL != mirrors[0] {
+		t.Errorf("fetch URL = %s, want %s", fetchURL, mirrors[0])
+	}
+
+	ok, err := client.VerifyMirrorPush("origin", mirrors)
+	if err != nil {
+		t.Fatalf("VerifyMirrorPush() error = %v", err)
+	}
+	if !ok {
+		pushURLs, _ := client.GetPushURLs("origin")
+		t.Errorf("VerifyMirrorPush() = false, push URLs = %v", pushURLs)
+	}
+
+	// Reconfiguring with fewer mirrors replaces the push URLs rather than appending
+	if err := client.ConfigureMirrorPush("origin", mirrors[0], mirrors[:2]); err != nil {
+		t.Fatalf("ConfigureMirrorPush() error = %v", err)
+	}
+
+	ok, err = client.VerifyDualPush("origin", mirrors[0], mirrors[1])
+	if err != nil {
+		t.Fatalf("VerifyDualPush() error = %v", err)
+	}
+	if !ok {
+		pushURLs, _ := client.GetPushURLs("origin")
+		t.Errorf("VerifyDualPush() = false after reconfigure, push URLs = %v", pushURLs)
+	}
+}
+
+func TestConfigureMirrorPush_NoURLs(t *testing.T) {
+	client := NewClient(t.TempDir())
+	if err := client.ConfigureMirrorPush("origin", "git@core.example:repo.git", nil); err == nil {
+		t.Error("ConfigureMirrorPush() should fail without push URLs")
+	}
+}
@@ -1,7 +1,10 @@
 package git
 
 import (
+	"context"
 	"fmt"
+
+	"github.com/lcgerke/githelper/internal/constants"
 )
 
 // ConfigureSSH sets up SSH for git operations
@@ -12,6 +15,19 @@ func (c *Client) ConfigureSSH(privateKeyPath string) error {
 	return c.ConfigSet("core.sshCommand", sshCmd)
 }
 
+// VerifyPushAccess checks that privateKeyPath can push to url, without
+// changing anything: a dry-run push still authenticates with the server
+// and asks for write access. It pushes HEAD to a scratch ref so that the
+// remote being ahead can't make the check fail.
+func (c *Client) VerifyPushAccess(url, privateKeyPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultFetchTimeout)
+	defer cancel()
+
+	sshCmd := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", privateKeyPath)
+	_, err := c.runWithEnv(ctx, []string{"GIT_SSH_COMMAND=" + sshCmd}, "push", "--dry-run", "--porcelain", url, "HEAD:refs/githelper/key-check")
+	return err
+}
+
 // GetSSHCommand returns the current SSH command configuration
 func (c *Client) GetSSHCommand() (string, error) {
 	return c.ConfigGet("core.sshCommand")
@@ -0,0 +1,213 @@
+package github
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// Token audit severities, in increasing order
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// Token audit finding codes
+const (
+	FindingInvalid      = "invalid"
+	FindingUnreachable  = "unreachable"
+	FindingExpired      = "expired"
+	FindingExpiresSoon  = "expires_soon"
+	FindingNoExpiry     = "no_expiry"
+	FindingMissingScope = "missing_scope"
+	FindingExcessScope  = "excess_scope"
+	FindingFineGrained  = "fine_grained"
+)
+
+// DefaultExpiryWarning is how far ahead an upcoming expiry is reported
+const DefaultExpiryWarning = 14 * 24 * time.Hour
+
+// DefaultRequiredScopes are the scopes githelper needs: repo covers creating
+// repositories and managing their deploy keys
+var DefaultRequiredScopes = []string{"repo"}
+
+// broadScopes grant far more than githelper ever uses
+var broadScopes = map[string]bool{
+	"admin:enterprise":      true,
+	"admin:gpg_key":         true,
+	"admin:org":             true,
+	"admin:org_hook":        true,
+	"admin:public_key":      true,
+	"admin:ssh_signing_key": true,
+	"delete_repo":           true,
+	"delete:packages":       true,
+	"site_admin":            true,
+	"write:org":             true,
+}
+
+// TokenInfo is what GitHub reports about the token a client uses
+type TokenInfo struct {
+	Login string `json:"login"`
+	// Scopes granted to a classic PAT, from X-OAuth-Scopes. Fine-grained
+	// tokens and GitHub App tokens don't report scopes.
+	Scopes      []string  `json:"scopes"`
+	ScopesKnown bool      `json:"scopes_known"`
+	Expires     time.Time `json:"expires,omitzero"` // Zero if the token doesn't expire
+}
+
+// Finding is one problem (or note) about a token
+type Finding struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// AuditOptions tunes AuditToken
+type AuditOptions struct {
+	Now            time.Time     // Defaults to time.Now()
+	ExpiryWarning  time.Duration // Defaults to DefaultExpiryWarning
+	RequiredScopes []string      // Defaults to DefaultRequiredScopes
+}
+
+// TokenInfo asks GitHub who the client's token belongs to, and reads its
+// scopes and expiry from the response headers
+func (c *Client) TokenInfo() (*TokenInfo, error) {
+	user, resp, err := c.client.Users.Get(c.ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TokenInfo{Login: user.GetLogin()}
+	if header, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		info.ScopesKnown = true
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+		sort.Strings(info.Scopes)
+	}
+
+	if expiry := resp.Header.Get("GitHub-Authentication-Token-Expiration"); expiry != "" {
+		t, err := parseTokenExpiry(expiry)
+		if err != nil {
+			return nil, err
+		}
+		info.Expires = t
+	}
+
+	return info, nil
+}
+
+// parseTokenExpiry parses GitHub's "2006-01-02 15:04:05 UTC" expiry header
+func parseTokenExpiry(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized token expiration %q", value)
+}
+
+// Audit fetches the client's token info and audits it. Failures to talk to
+// GitHub are reported as findings rather than errors, so that one bad token
+// doesn't stop an audit of several.
+func (c *Client) Audit(opts AuditOptions) (*TokenInfo, []Finding) {
+	info, err := c.TokenInfo()
+	if err != nil {
+		var ghErr *github.ErrorResponse
+		if stderrors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusUnauthorized {
+			return nil, []Finding{{
+				Severity: SeverityCritical,
+				Code:     FindingInvalid,
+				Message:  "GitHub rejected the token (revoked, expired, or mistyped)",
+			}}
+		}
+		return nil, []Finding{{
+			Severity: SeverityError,
+			Code:     FindingUnreachable,
+			Message:  fmt.Sprintf("could not inspect token: %v", err),
+		}}
+	}
+
+	return info, AuditToken(info, opts)
+}
+
+// AuditToken checks a token's expiry and scopes
+func AuditToken(info *TokenInfo, opts AuditOptions) []Finding {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.ExpiryWarning == 0 {
+		opts.ExpiryWarning = DefaultExpiryWarning
+	}
+	if opts.RequiredScopes == nil {
+		opts.RequiredScopes = DefaultRequiredScopes
+	}
+
+	var findings []Finding
+	add := func(severity, code, message string) {
+		findings = append(findings, Finding{Severity: severity, Code: code, Message: message})
+	}
+
+	switch {
+	case info.Expires.IsZero():
+		if info.ScopesKnown {
+			add(SeverityWarning, FindingNoExpiry, "token never expires; prefer one with an expiration date")
+		}
+	case !info.Expires.After(opts.Now):
+		add(SeverityCritical, FindingExpired, fmt.Sprintf("token expired on %s", info.Expires.Format("2006-01-02")))
+	case info.Expires.Sub(opts.Now) <= opts.ExpiryWarning:
+		days := int(info.Expires.Sub(opts.Now).Hours() / 24)
+		add(SeverityWarning, FindingExpiresSoon, fmt.Sprintf("token expires on %s (%d days)", info.Expires.Format("2006-01-02"), days))
+	}
+
+	if !info.ScopesKnown {
+		add(SeverityInfo, FindingFineGrained, "token does not report scopes (fine-grained or app token); check its repository permissions on GitHub")
+		return findings
+	}
+
+	granted := make(map[string]bool)
+	for _, s := range info.Scopes {
+		granted[s] = true
+	}
+	for _, s := range opts.RequiredScopes {
+		if !granted[s] {
+			add(SeverityError, FindingMissingScope, fmt.Sprintf("token lacks the %q scope needed to create and manage repositories", s))
+		}
+	}
+	for _, s := range info.Scopes {
+		if broadScopes[s] {
+			add(SeverityWarning, FindingExcessScope, fmt.Sprintf("token has the %q scope, which githelper never needs", s))
+		}
+	}
+
+	return findings
+}
+
+// WorstSeverity returns the most severe finding's severity, or "" if there are none
+func WorstSeverity(findings []Finding) string {
+	rank := map[string]int{SeverityInfo: 1, SeverityWarning: 2, SeverityError: 3, SeverityCritical: 4}
+	worst := ""
+	for _, f := range findings {
+		if rank[f.Severity] > rank[worst] {
+			worst = f.Severity
+		}
+	}
+	return worst
+}
+
+// RedactToken shows enough of a token to tell tokens apart
+func RedactToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + "…" + token[len(token)-4:]
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStubClient returns a client whose API is a stub serving GET /user with
+// the given headers for the token "good", and 401 for anything else
+func newStubClient(t *testing.T, token string, headers map[string]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"Bad credentials"}`)
+			return
+		}
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		fmt.Fprint(w, `{"login":"alice"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(context.Background(), token)
+	base, _ := url.Parse(srv.URL + "/")
+	c.client.BaseURL = base
+	return c
+}
+
+func codes(findings []Finding) string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.Severity+":"+f.Code)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestClient_TokenInfo(t *testing.T) {
+	c := newStubClient(t, "good", map[string]string{
+		"X-OAuth-Scopes":                         "repo, delete_repo,  read:org",
+		"GitHub-Authentication-Token-Expiration": "2026-03-01 12:00:00 UTC",
+	})
+
+	info, err := c.TokenInfo()
+	if err != nil {
+		t.Fatalf("TokenInfo() error = %v", err)
+	}
+	if info.Login != "alice" || !info.ScopesKnown {
+		t.Errorf("TokenInfo() = %+v", info)
+	}
+	if got := strings.Join(info.Scopes, ","); got != "delete_repo,read:org,repo" {
+		t.Errorf("Scopes = %s", got)
+	}
+	if want := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !info.Expires.Equal(want) {
+		t.Errorf("Expires = %v, want %v", info.Expires, want)
+	}
+}
+
+func TestClient_TokenInfo_FineGrained(t *testing.T) {
+	c := newStubClient(t, "good", nil)
+
+	info, err := c.TokenInfo()
+	if err != nil {
+		t.Fatalf("TokenInfo() error = %v", err)
+	}
+	if info.ScopesKnown || !info.Expires.IsZero() {
+		t.Errorf("TokenInfo() = %+v, want unknown scopes and no expiry", info)
+	}
+}
+
+func TestClient_Audit(t *testing.T) {
+	now := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		token   string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "healthy",
+			token:   "good",
+			headers: map[string]string{"X-OAuth-Scopes": "repo, workflow", "GitHub-Authentication-Token-Expiration": "2026-06-01 00:00:00 UTC"},
+			want:    "",
+		},
+		{
+			name:    "expiring, over-scoped",
+			token:   "good",
+			headers: map[string]string{"X-OAuth-Scopes": "repo, admin:org", "GitHub-Authentication-Token-Expiration": "2026-03-01 00:00:00 UTC"},
+			want:    "warning:expires_soon,warning:excess_scope",
+		},
+		{
+			name:    "no expiry, missing repo",
+			token:   "good",
+			headers: map[string]string{"X-OAuth-Scopes": "public_repo"},
+			want:    "warning:no_expiry,error:missing_scope",
+		},
+		{
+			name:    "expired",
+			token:   "good",
+			headers: map[string]string{"X-OAuth-Scopes": "repo", "GitHub-Authentication-Token-Expiration": "2026-02-19 00:00:00 UTC"},
+			want:    "critical:expired",
+		},
+		{
+			name:    "fine-grained",
+			token:   "good",
+			headers: map[string]string{"GitHub-Authentication-Token-Expiration": "2026-12-01 00:00:00 UTC"},
+			want:    "info:fine_grained",
+		},
+		{
+			name:  "rejected",
+			token: "revoked",
+			want:  "critical:invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newStubClient(t, tt.token, tt.headers)
+			_, findings := c.Audit(AuditOptions{Now: now})
+			if got := codes(findings); got != tt.want {
+				t.Errorf("Audit() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Audit_Unreachable(t *testing.T) {
+	c := newStubClient(t, "good", nil)
+	c.client.BaseURL, _ = url.Parse("http://127.0.0.1:1/")
+
+	if _, findings := c.Audit(AuditOptions{}); codes(findings) != "error:unreachable" {
+		t.Errorf("Audit() = %s, want error:unreachable", codes(findings))
+	}
+}
+
+func TestWorstSeverity(t *testing.T) {
+	findings := []Finding{{Severity: SeverityInfo}, {Severity: SeverityError}, {Severity: SeverityWarning}}
+	if got := WorstSeverity(findings); got != SeverityError {
+		t.Errorf("WorstSeverity() = %s, want error", got)
+	}
+	if got := WorstSeverity(nil); got != "" {
+		t.Errorf("WorstSeverity(nil) = %q", got)
+	}
+}
+
+func TestRedactToken(t *testing.T) {
+	if got := RedactToken("ghp_abcdefghijklmnop1234"); got != "ghp_…1234" {
+		t.Errorf("RedactToken() = %s", got)
+	}
+	if got := RedactToken("short"); got != "*****" {
+		t.Errorf("RedactToken(short) = %s", got)
+	}
+}
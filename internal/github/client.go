@@ -3,9 +3,11 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os/exec"
 
 	"github.com/google/go-github/v56/github"
+	"github.com/lcgerke/githelper/internal/remote"
 	"golang.org/x/oauth2"
 )
 
@@ -16,17 +18,27 @@ type Client struct {
 	token  string
 }
 
-// NewClient creates a new GitHub client with PAT authentication
+// NewClient creates a new GitHub client with PAT authentication. Requests go
+// through the shared transport, so rate limits are waited out and unchanged
+// reads are answered from the on-disk cache.
 func NewClient(ctx context.Context, token string) *Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
+	client := NewClientWithTokenSource(ctx, ts)
+	client.token = token
+	return client
+}
+
+// NewClientWithTokenSource creates a GitHub client authenticated by ts, such
+// as a GitHub App installation token source that refreshes itself
+func NewClientWithTokenSource(ctx context.Context, ts oauth2.TokenSource) *Client {
+	base := context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: remote.DefaultTransport()})
+	tc := oauth2.NewClient(base, ts)
 
 	return &Client{
 		client: github.NewClient(tc),
 		ctx:    ctx,
-		token:  token,
 	}
 }
 
@@ -103,6 +115,50 @@ func (c *Client) GetSSHURL(owner, repo string) (string, error) {
 	return *repository.SSHURL, nil
 }
 
+// AddDeployKey registers an SSH public key as a deploy key on a repository
+func (c *Client) AddDeployKey(owner, repo, title, publicKey string, readOnly bool) (*github.Key, error) {
+	key, _, err := c.client.Repositories.CreateKey(c.ctx, owner, repo, &github.Key{
+		Title:    github.String(title),
+		Key:      github.String(publicKey),
+		ReadOnly: github.Bool(readOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add deploy key to %s/%s: %w", owner, repo, err)
+	}
+
+	return key, nil
+}
+
+// ListDeployKeys returns all deploy keys of a repository
+func (c *Client) ListDeployKeys(owner, repo string) ([]*github.Key, error) {
+	var all []*github.Key
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		keys, resp, err := c.client.Repositories.ListKeys(c.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deploy keys of %s/%s: %w", owner, repo, err)
+		}
+		all = append(all, keys...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// DeleteDeployKey removes a deploy key. A key that no longer exists is not an error.
+func (c *Client) DeleteDeployKey(owner, repo string, id int64) error {
+	resp, err := c.client.Repositories.DeleteKey(c.ctx, owner, repo, id)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete deploy key %d from %s/%s: %w", id, owner, repo, err)
+	}
+
+	return nil
+}
+
 // CheckGHCLIAvailable checks if the gh CLI is installed and available
 func CheckGHCLIAvailable() bool {
 	_, err := exec.LookPath("gh")
@@ -2,8 +2,15 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os/exec"
 	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 func TestCreateRepositoryViaGH(t *testing.T) {
@@ -79,3 +86,60 @@ func TestCheckGHAuthenticated(t *testing.T) {
 	authenticated := CheckGHAuthenticated()
 	t.Logf("gh CLI authenticated: %v", authenticated)
 }
+
+func TestNewClient_RetriesRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// Secondary rate limit
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit"}`)
+			return
+		}
+		fmt.Fprint(w, `{"login":"alice"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(context.Background(), "good")
+	c.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	info, err := c.TokenInfo()
+	if err != nil {
+		t.Fatalf("TokenInfo() error = %v", err)
+	}
+	if info.Login != "alice" || calls != 2 {
+		t.Errorf("TokenInfo() = %+v after %d calls, want success on the retry", info, calls)
+	}
+}
+
+// rotatingSource hands out a new token on every call, like an app
+// installation token source after expiry
+type rotatingSource struct{ n int }
+
+func (s *rotatingSource) Token() (*oauth2.Token, error) {
+	s.n++
+	return &oauth2.Token{AccessToken: fmt.Sprintf("ghs_%d", s.n), Expiry: time.Now().Add(-time.Second)}, nil
+}
+
+func TestNewClientWithTokenSource(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"id":1,"full_name":"team/service"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithTokenSource(context.Background(), &rotatingSource{})
+	c.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetRepository("team", "service"); err != nil {
+			t.Fatalf("GetRepository() error = %v", err)
+		}
+	}
+	if len(seen) != 2 || seen[0] != "Bearer ghs_1" || seen[1] != "Bearer ghs_2" {
+		t.Errorf("Authorization headers = %v, want a fresh token from the source per request", seen)
+	}
+}
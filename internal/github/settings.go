@@ -0,0 +1,220 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// Merge methods a repository can allow
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// RepoSettings is the desired state of a repository's GitHub settings. Unset
+// (nil or empty) fields are not managed, so a settings block only changes the
+// settings it names. An empty topics list (topics: []) removes every topic.
+type RepoSettings struct {
+	Description  *string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Homepage     *string  `yaml:"homepage,omitempty" json:"homepage,omitempty"`
+	Topics       []string `yaml:"topics,omitempty" json:"topics,omitempty"`
+	Visibility   string   `yaml:"visibility,omitempty" json:"visibility,omitempty"` // public, private, internal
+	HasIssues    *bool    `yaml:"has_issues,omitempty" json:"has_issues,omitempty"`
+	HasWiki      *bool    `yaml:"has_wiki,omitempty" json:"has_wiki,omitempty"`
+	HasProjects  *bool    `yaml:"has_projects,omitempty" json:"has_projects,omitempty"`
+	MergeMethods []string `yaml:"merge_methods,omitempty" json:"merge_methods,omitempty"` // merge, squash, rebase
+}
+
+// Validate checks visibility and merge method names
+func (s RepoSettings) Validate() error {
+	switch s.Visibility {
+	case "", "public", "private", "internal":
+	default:
+		return fmt.Errorf("invalid visibility %q: must be public, private, or internal", s.Visibility)
+	}
+
+	if s.MergeMethods != nil && len(s.MergeMethods) == 0 {
+		return fmt.Errorf("merge_methods must allow at least one of merge, squash, rebase")
+	}
+	for _, m := range s.MergeMethods {
+		switch m {
+		case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+		default:
+			return fmt.Errorf("invalid merge method %q: must be merge, squash, or rebase", m)
+		}
+	}
+	return nil
+}
+
+// Merge returns s with every field override sets replaced
+func (s RepoSettings) Merge(override RepoSettings) RepoSettings {
+	if override.Description != nil {
+		s.Description = override.Description
+	}
+	if override.Homepage != nil {
+		s.Homepage = override.Homepage
+	}
+	if override.Topics != nil {
+		s.Topics = override.Topics
+	}
+	if override.Visibility != "" {
+		s.Visibility = override.Visibility
+	}
+	if override.HasIssues != nil {
+		s.HasIssues = override.HasIssues
+	}
+	if override.HasWiki != nil {
+		s.HasWiki = override.HasWiki
+	}
+	if override.HasProjects != nil {
+		s.HasProjects = override.HasProjects
+	}
+	if override.MergeMethods != nil {
+		s.MergeMethods = override.MergeMethods
+	}
+	return s
+}
+
+// IsEmpty reports whether the settings manage nothing
+func (s RepoSettings) IsEmpty() bool {
+	return s.Description == nil && s.Homepage == nil && s.Topics == nil && s.Visibility == "" &&
+		s.HasIssues == nil && s.HasWiki == nil && s.HasProjects == nil && s.MergeMethods == nil
+}
+
+// SettingChange is one setting that differs from the desired state
+type SettingChange struct {
+	Field   string      `json:"field"`
+	Current interface{} `json:"current"`
+	Desired interface{} `json:"desired"`
+}
+
+// String describes the change, e.g. `has_wiki: true -> false`
+func (c SettingChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, formatSetting(c.Current), formatSetting(c.Desired))
+}
+
+func formatSetting(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// PlanSettings lists the changes that bring repo to the desired settings
+func PlanSettings(repo *github.Repository, desired RepoSettings) []SettingChange {
+	var changes []SettingChange
+	addString := func(field, current string, want *string) {
+		if want != nil && *want != current {
+			changes = append(changes, SettingChange{Field: field, Current: current, Desired: *want})
+		}
+	}
+	addBool := func(field string, current bool, want *bool) {
+		if want != nil && *want != current {
+			changes = append(changes, SettingChange{Field: field, Current: current, Desired: *want})
+		}
+	}
+
+	addString("description", repo.GetDescription(), desired.Description)
+	addString("homepage", repo.GetHomepage(), desired.Homepage)
+
+	if desired.Topics != nil {
+		current, want := normalizeTopics(repo.Topics), normalizeTopics(desired.Topics)
+		if strings.Join(current, ",") != strings.Join(want, ",") {
+			changes = append(changes, SettingChange{Field: "topics", Current: current, Desired: want})
+		}
+	}
+
+	if desired.Visibility != "" {
+		addString("visibility", repoVisibility(repo), &desired.Visibility)
+	}
+
+	addBool("has_issues", repo.GetHasIssues(), desired.HasIssues)
+	addBool("has_wiki", repo.GetHasWiki(), desired.HasWiki)
+	addBool("has_projects", repo.GetHasProjects(), desired.HasProjects)
+
+	if desired.MergeMethods != nil {
+		allowed := make(map[string]bool)
+		for _, m := range desired.MergeMethods {
+			allowed[m] = true
+		}
+		addBool("allow_merge_commit", repo.GetAllowMergeCommit(), github.Bool(allowed[MergeMethodMerge]))
+		addBool("allow_squash_merge", repo.GetAllowSquashMerge(), github.Bool(allowed[MergeMethodSquash]))
+		addBool("allow_rebase_merge", repo.GetAllowRebaseMerge(), github.Bool(allowed[MergeMethodRebase]))
+	}
+
+	return changes
+}
+
+// ApplySettings makes the planned changes to owner/repo
+func (c *Client) ApplySettings(owner, repo string, changes []SettingChange) error {
+	edit := &github.Repository{}
+	editing := false
+	for _, change := range changes {
+		switch change.Field {
+		case "topics":
+			topics := change.Desired.([]string)
+			if _, _, err := c.client.Repositories.ReplaceAllTopics(c.ctx, owner, repo, topics); err != nil {
+				return fmt.Errorf("failed to set topics: %w", err)
+			}
+			continue
+		case "description":
+			edit.Description = github.String(change.Desired.(string))
+		case "homepage":
+			edit.Homepage = github.String(change.Desired.(string))
+		case "visibility":
+			edit.Visibility = github.String(change.Desired.(string))
+		case "has_issues":
+			edit.HasIssues = github.Bool(change.Desired.(bool))
+		case "has_wiki":
+			edit.HasWiki = github.Bool(change.Desired.(bool))
+		case "has_projects":
+			edit.HasProjects = github.Bool(change.Desired.(bool))
+		case "allow_merge_commit":
+			edit.AllowMergeCommit = github.Bool(change.Desired.(bool))
+		case "allow_squash_merge":
+			edit.AllowSquashMerge = github.Bool(change.Desired.(bool))
+		case "allow_rebase_merge":
+			edit.AllowRebaseMerge = github.Bool(change.Desired.(bool))
+		default:
+			return fmt.Errorf("unknown setting %q", change.Field)
+		}
+		editing = true
+	}
+
+	if editing {
+		if _, _, err := c.client.Repositories.Edit(c.ctx, owner, repo, edit); err != nil {
+			return fmt.Errorf("failed to update repository settings: %w", err)
+		}
+	}
+	return nil
+}
+
+// repoVisibility returns the repository's visibility, falling back to its
+// private flag for responses that don't include it
+func repoVisibility(repo *github.Repository) string {
+	if v := repo.GetVisibility(); v != "" {
+		return v
+	}
+	if repo.GetPrivate() {
+		return "private"
+	}
+	return "public"
+}
+
+// normalizeTopics lowercases and sorts topics the way GitHub stores them
+func normalizeTopics(topics []string) []string {
+	out := make([]string, 0, len(topics))
+	for _, t := range topics {
+		out = append(out, strings.ToLower(strings.TrimSpace(t)))
+	}
+	sort.Strings(out)
+	return out
+}
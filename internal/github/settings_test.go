@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+)
+
+func TestPlanSettings(t *testing.T) {
+	repo := &github.Repository{
+		Description:      github.String("old"),
+		Topics:           []string{"go", "CLI"},
+		Private:          github.Bool(true),
+		HasIssues:        github.Bool(true),
+		HasWiki:          github.Bool(true),
+		AllowMergeCommit: github.Bool(true),
+		AllowSquashMerge: github.Bool(true),
+	}
+
+	desired := RepoSettings{
+		Description:  github.String("new"),
+		Topics:       []string{"cli", "go"},
+		Visibility:   "private",
+		HasIssues:    github.Bool(true),
+		HasWiki:      github.Bool(false),
+		MergeMethods: []string{MergeMethodSquash},
+	}
+
+	var got []string
+	for _, c := range PlanSettings(repo, desired) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`description: "old" -> "new"`,
+		`has_wiki: true -> false`,
+		`allow_merge_commit: true -> false`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("PlanSettings() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := PlanSettings(repo, RepoSettings{}); len(changes) != 0 {
+		t.Errorf("PlanSettings(empty) = %v, want no changes", changes)
+	}
+}
+
+func TestRepoSettings_Merge(t *testing.T) {
+	defaults := RepoSettings{HasWiki: github.Bool(false), MergeMethods: []string{MergeMethodSquash}, Visibility: "private"}
+	override := RepoSettings{Visibility: "public", Topics: []string{}}
+
+	got := defaults.Merge(override)
+	if got.Visibility != "public" || got.HasWiki == nil || *got.HasWiki || len(got.MergeMethods) != 1 {
+		t.Errorf("Merge() = %+v, want defaults with the override's visibility", got)
+	}
+	if got.Topics == nil {
+		t.Errorf("Merge() dropped an explicitly empty topics list")
+	}
+}
+
+func TestRepoSettings_Validate(t *testing.T) {
+	tests := []struct {
+		settings RepoSettings
+		wantErr  bool
+	}{
+		{settings: RepoSettings{}},
+		{settings: RepoSettings{Visibility: "internal", MergeMethods: []string{"merge", "rebase"}}},
+		{settings: RepoSettings{Visibility: "secret"}, wantErr: true},
+		{settings: RepoSettings{MergeMethods: []string{"fast-forward"}}, wantErr: true},
+		{settings: RepoSettings{MergeMethods: []string{}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.settings.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.settings, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	var edits []map[string]interface{}
+	var topics []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			edits = append(edits, body)
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/o/r/topics":
+			var body struct {
+				Names []string `json:"names"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			topics = body.Names
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(context.Background(), "token")
+	base, _ := url.Parse(srv.URL + "/")
+	c.client.BaseURL = base
+
+	changes := []SettingChange{
+		{Field: "has_wiki", Current: true, Desired: false},
+		{Field: "topics", Current: []string{}, Desired: []string{"go"}},
+		{Field: "allow_rebase_merge", Current: true, Desired: false},
+	}
+	if err := c.ApplySettings("o", "r", changes); err != nil {
+		t.Fatalf("ApplySettings() error = %v", err)
+	}
+
+	if len(edits) != 1 || edits[0]["has_wiki"] != false || edits[0]["allow_rebase_merge"] != false || len(edits[0]) != 2 {
+		t.Errorf("edits = %v, want one PATCH with only the changed fields", edits)
+	}
+	if len(topics) != 1 || topics[0] != "go" {
+		t.Errorf("topics = %v, want [go]", topics)
+	}
+}
@@ -0,0 +1,113 @@
+package history
+
+import (
+	"time"
+
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+)
+
+// NewSnapshot condenses a classified repository state into a history entry
+func NewSnapshot(repoState *scenarios.RepositoryState) state.Snapshot {
+	snap := state.Snapshot{
+		Time:            repoState.DetectedAt,
+		Existence:       repoState.Existence.ID,
+		Sync:            repoState.Sync.ID,
+		WorkingTree:     repoState.WorkingTree.ID,
+		Corruption:      repoState.Corruption.ID,
+		Branch:          repoState.Sync.Branch,
+		LocalHash:       repoState.Sync.LocalHash,
+		CoreHash:        repoState.Sync.CoreHash,
+		GitHubHash:      repoState.Sync.GitHubHash,
+		CoreReachable:   repoState.Existence.CoreReachable,
+		GitHubReachable: repoState.Existence.GitHubReachable,
+	}
+	if snap.Time.IsZero() {
+		snap.Time = time.Now()
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range repoState.Tags {
+		if tag.ID != "T1" && !seen[tag.ID] {
+			seen[tag.ID] = true
+			snap.Tags = append(snap.Tags, tag.ID)
+		}
+	}
+
+	for _, w := range repoState.Warnings {
+		snap.Warnings = append(snap.Warnings, w.Code)
+	}
+
+	return snap
+}
+
+// Transition is a change of sync scenario between two consecutive snapshots
+type Transition struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// Trend summarizes a repository's history
+type Trend struct {
+	Snapshots   int                `json:"snapshots"`
+	Since       time.Time          `json:"since,omitempty"`
+	Current     string             `json:"current,omitempty"` // Latest sync scenario
+	Transitions []Transition       `json:"transitions"`
+	OutOfSync   scenarios.Duration `json:"out_of_sync_ms"`
+	Observed    scenarios.Duration `json:"observed_ms"`
+
+	LastCoreReachable   time.Time `json:"last_core_reachable,omitempty"`
+	LastGitHubReachable time.Time `json:"last_github_reachable,omitempty"`
+}
+
+// OutOfSyncRatio returns the fraction of observed time spent out of sync
+func (t Trend) OutOfSyncRatio() float64 {
+	if t.Observed.Duration <= 0 {
+		return 0
+	}
+	return float64(t.OutOfSync.Duration) / float64(t.Observed.Duration)
+}
+
+// Analyze computes transitions and out-of-sync time from a history.
+// Each snapshot's scenario is assumed to hold until the next snapshot, and the
+// latest one until now. Any sync scenario other than S1 counts as out of sync.
+func Analyze(history []state.Snapshot, now time.Time) Trend {
+	trend := Trend{Snapshots: len(history), Transitions: []Transition{}}
+	if len(history) == 0 {
+		return trend
+	}
+
+	trend.Since = history[0].Time
+	trend.Current = history[len(history)-1].Sync
+
+	for i, snap := range history {
+		end := now
+		if i+1 < len(history) {
+			end = history[i+1].Time
+		}
+		if span := end.Sub(snap.Time); span > 0 {
+			trend.Observed.Duration += span
+			if snap.Sync != "S1" {
+				trend.OutOfSync.Duration += span
+			}
+		}
+
+		if i > 0 && snap.Sync != history[i-1].Sync {
+			trend.Transitions = append(trend.Transitions, Transition{
+				Time: snap.Time,
+				From: history[i-1].Sync,
+				To:   snap.Sync,
+			})
+		}
+
+		if snap.CoreReachable {
+			trend.LastCoreReachable = snap.Time
+		}
+		if snap.GitHubReachable {
+			trend.LastGitHubReachable = snap.Time
+		}
+	}
+
+	return trend
+}
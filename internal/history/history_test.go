@@ -0,0 +1,91 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	detected := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repoState := &scenarios.RepositoryState{
+		DetectedAt: detected,
+		Existence:  scenarios.ExistenceState{ID: "E1", CoreReachable: true},
+		Sync:       scenarios.SyncState{ID: "S4", Branch: "main", LocalHash: "aaa", CoreHash: "aaa", GitHubHash: "bbb"},
+		Tags: []scenarios.TagState{
+			{ID: "T1", Tag: "v1"},
+			{ID: "T2", Tag: "v2"},
+			{ID: "T2", Tag: "v3"},
+		},
+		Warnings: []scenarios.Warning{{Code: scenarios.WarnStaleRemoteData}},
+	}
+
+	snap := NewSnapshot(repoState)
+
+	if !snap.Time.Equal(detected) || snap.Existence != "E1" || snap.Sync != "S4" {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if snap.GitHubHash != "bbb" || !snap.CoreReachable || snap.GitHubReachable {
+		t.Errorf("hashes/reachability not copied: %+v", snap)
+	}
+	if len(snap.Tags) != 1 || snap.Tags[0] != "T2" {
+		t.Errorf("Tags = %v, want [T2]", snap.Tags)
+	}
+	if len(snap.Warnings) != 1 || snap.Warnings[0] != scenarios.WarnStaleRemoteData {
+		t.Errorf("Warnings = %v", snap.Warnings)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	history := []state.Snapshot{
+		{Time: at(0), Sync: "S1", CoreReachable: true, GitHubReachable: true},
+		{Time: at(1), Sync: "S1", CoreReachable: true, GitHubReachable: true},
+		{Time: at(2), Sync: "S4", CoreReachable: true, GitHubReachable: false},
+		{Time: at(5), Sync: "S1", CoreReachable: true, GitHubReachable: true},
+		{Time: at(6), Sync: "S_UNAVAILABLE", CoreReachable: false, GitHubReachable: true},
+	}
+
+	trend := Analyze(history, at(8))
+
+	if trend.Snapshots != 5 || trend.Current != "S_UNAVAILABLE" || !trend.Since.Equal(at(0)) {
+		t.Errorf("unexpected summary: %+v", trend)
+	}
+
+	want := []Transition{
+		{Time: at(2), From: "S1", To: "S4"},
+		{Time: at(5), From: "S4", To: "S1"},
+		{Time: at(6), From: "S1", To: "S_UNAVAILABLE"},
+	}
+	if len(trend.Transitions) != len(want) {
+		t.Fatalf("Transitions = %+v, want %+v", trend.Transitions, want)
+	}
+	for i := range want {
+		if trend.Transitions[i] != want[i] {
+			t.Errorf("Transitions[%d] = %+v, want %+v", i, trend.Transitions[i], want[i])
+		}
+	}
+
+	// S4 for 3h plus S_UNAVAILABLE for 2h, out of 8h observed
+	if trend.OutOfSync.Duration != 5*time.Hour || trend.Observed.Duration != 8*time.Hour {
+		t.Errorf("OutOfSync = %s, Observed = %s", trend.OutOfSync.Duration, trend.Observed.Duration)
+	}
+	if ratio := trend.OutOfSyncRatio(); ratio != 5.0/8.0 {
+		t.Errorf("OutOfSyncRatio() = %f", ratio)
+	}
+
+	if !trend.LastCoreReachable.Equal(at(5)) || !trend.LastGitHubReachable.Equal(at(6)) {
+		t.Errorf("last reachable core=%s github=%s", trend.LastCoreReachable, trend.LastGitHubReachable)
+	}
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	trend := Analyze(nil, time.Now())
+	if trend.Snapshots != 0 || len(trend.Transitions) != 0 || trend.OutOfSyncRatio() != 0 {
+		t.Errorf("unexpected trend for empty history: %+v", trend)
+	}
+}
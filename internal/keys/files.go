@@ -0,0 +1,72 @@
+package keys
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lcgerke/githelper/internal/errors"
+)
+
+func pemEncode(block *pem.Block) []byte {
+	return pem.EncodeToMemory(block)
+}
+
+// writeKeyPair writes a private key to path and its public key to path.pub
+func writeKeyPair(path, privateKey, publicKey string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(errors.ErrorTypeFileSystem, "failed to create SSH directory", err)
+	}
+	if err := os.WriteFile(path, []byte(privateKey), 0600); err != nil {
+		return errors.Wrap(errors.ErrorTypeFileSystem, fmt.Sprintf("failed to write %s", path), err)
+	}
+	if err := os.WriteFile(path+".pub", []byte(publicKey+"\n"), 0644); err != nil {
+		return errors.Wrap(errors.ErrorTypeFileSystem, fmt.Sprintf("failed to write %s.pub", path), err)
+	}
+	return nil
+}
+
+// removeKeyPair removes path and path.pub; missing files are not an error
+func removeKeyPair(path string) error {
+	for _, p := range []string{path, path + ".pub"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(errors.ErrorTypeFileSystem, fmt.Sprintf("failed to remove %s", p), err)
+		}
+	}
+	return nil
+}
+
+// renameKeyPair moves path and path.pub, skipping a missing public key
+func renameKeyPair(from, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		return errors.Wrap(errors.ErrorTypeFileSystem, fmt.Sprintf("failed to move %s to %s", from, to), err)
+	}
+	if err := os.Rename(from+".pub", to+".pub"); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(errors.ErrorTypeFileSystem, fmt.Sprintf("failed to move %s.pub to %s.pub", from, to), err)
+	}
+	return nil
+}
+
+// swapKeyPair moves the key at path to backup, if there is one, and moves
+// staged into its place. It reports whether a key was backed up.
+func swapKeyPair(staged, path, backup string) (bool, error) {
+	hadKey := false
+	if _, err := os.Stat(path); err == nil {
+		if err := removeKeyPair(backup); err != nil {
+			return false, err
+		}
+		if err := renameKeyPair(path, backup); err != nil {
+			return false, err
+		}
+		hadKey = true
+	}
+
+	if err := renameKeyPair(staged, path); err != nil {
+		if hadKey {
+			_ = renameKeyPair(backup, path)
+		}
+		return false, err
+	}
+	return hadKey, nil
+}
@@ -0,0 +1,296 @@
+// Package keys rotates the per-repository SSH deploy keys githelper manages.
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/secrets"
+	"github.com/lcgerke/githelper/internal/vault"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeployKeys manages a repository's deploy keys
+type DeployKeys interface {
+	AddDeployKey(owner, repo, title, publicKey string, readOnly bool) (*github.Key, error)
+	ListDeployKeys(owner, repo string) ([]*github.Key, error)
+	DeleteDeployKey(owner, repo string, id int64) error
+}
+
+// Target is a repository whose deploy key is rotated
+type Target struct {
+	Name    string // githelper repository name
+	Path    string // local working copy
+	Owner   string // GitHub owner
+	Repo    string // GitHub repository
+	PushURL string // SSH URL used to verify push access
+}
+
+// Result describes a completed rotation
+type Result struct {
+	Repo          string  `json:"repo"`
+	SecretPath    string  `json:"secret_path"`
+	KeyPath       string  `json:"key_path"`
+	Fingerprint   string  `json:"fingerprint"`
+	DeployKeyID   int64   `json:"deploy_key_id"`
+	RemovedKeyIDs []int64 `json:"removed_key_ids,omitempty"`
+
+	// LeftoverKeyIDs are old deploy keys that could not be removed and still
+	// grant access; Warnings says why
+	LeftoverKeyIDs []int64  `json:"leftover_key_ids,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// Rotator replaces a repository's deploy key. Each step registers how to
+// undo itself; if a later step fails before the new key is in use, every
+// completed step is undone in reverse order, leaving the old key in place.
+type Rotator struct {
+	Secrets secrets.Provider
+	Layout  secrets.Layout
+	GitHub  DeployKeys
+	SSHDir  string // Where key files are written, e.g. ~/.ssh
+
+	// Verify checks that keyPath can push to the target; defaults to a dry-run push
+	Verify func(t Target, keyPath string) error
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// rollback is a stack of undo steps
+type rollback []func() error
+
+func (r *rollback) add(undo func() error) {
+	*r = append(*r, undo)
+}
+
+// run undoes completed steps, newest first, and reports any that failed
+func (r rollback) run(cause error) error {
+	var failed []string
+	for i := len(r) - 1; i >= 0; i-- {
+		if err := r[i](); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.WithHint(
+			errors.Wrap(errors.ErrorTypeValidation, fmt.Sprintf("rotation failed and rollback was incomplete (%s)", strings.Join(failed, "; ")), cause),
+			"Check the repository's deploy keys on GitHub and core.sshCommand by hand",
+		)
+	}
+	return cause
+}
+
+// Rotate generates a new ed25519 key for t, registers it as a deploy key,
+// stores it in the secrets backend, verifies it can push, points
+// core.sshCommand at it, and finally removes the old deploy key.
+func (r *Rotator) Rotate(t Target) (*Result, error) {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	verify := r.Verify
+	if verify == nil {
+		verify = func(t Target, keyPath string) error {
+			return git.NewClient(t.Path).VerifyPushAccess(t.PushURL, keyPath)
+		}
+	}
+
+	vars := secrets.Vars{Repo: t.Name, Org: t.Owner}
+	secretPath, err := r.Layout.RepoSSHKeyPath(vars)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeConfig, "cannot rotate without a repository-specific SSH key path", err)
+	}
+
+	// Work out what is being replaced before changing anything
+	old, err := r.Secrets.Get(secretPath)
+	if err != nil {
+		if !stderrors.Is(err, secrets.ErrNotFound) {
+			return nil, errors.Wrap(errors.ErrorTypeSecrets, "failed to read current SSH key", err)
+		}
+		old = nil
+	}
+
+	existing, err := r.GitHub.ListDeployKeys(t.Owner, t.Repo)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeGitHub, "failed to list deploy keys", err)
+	}
+	oldIDs := oldDeployKeys(old, existing)
+
+	// 1. Generate the new key pair
+	privatePEM, publicKey, fingerprint, err := generateKey(fmt.Sprintf("githelper-%s", t.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	var undo rollback
+	fail := func(err error) (*Result, error) {
+		return nil, undo.run(err)
+	}
+
+	// 2. Register it as a deploy key with write access
+	title := fmt.Sprintf("githelper %s %s", t.Name, now().UTC().Format("2006-01-02 15:04"))
+	key, err := r.GitHub.AddDeployKey(t.Owner, t.Repo, title, publicKey, false)
+	if err != nil {
+		return fail(errors.Wrap(errors.ErrorTypeGitHub, "failed to register deploy key", err))
+	}
+	newID := key.GetID()
+	undo.add(func() error {
+		return r.GitHub.DeleteDeployKey(t.Owner, t.Repo, newID)
+	})
+
+	// 3. Store it in the secrets backend
+	err = r.Secrets.Put(secretPath, map[string]interface{}{
+		"private_key":   privatePEM,
+		"public_key":    publicKey,
+		"deploy_key_id": strconv.FormatInt(newID, 10),
+		"rotated_at":    now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fail(errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("failed to store new key at %s", secretPath), err))
+	}
+	undo.add(func() error {
+		if old != nil {
+			return r.Secrets.Put(secretPath, old)
+		}
+		return r.Secrets.Delete(secretPath)
+	})
+
+	// 4. Write it beside the current key file and verify it can push
+	keyPath := vault.GetSSHKeyPath(t.Name, r.SSHDir)
+	stagedPath := keyPath + ".rotating"
+	if err := writeKeyPair(stagedPath, privatePEM, publicKey); err != nil {
+		return fail(err)
+	}
+	undo.add(func() error {
+		return removeKeyPair(stagedPath)
+	})
+
+	if err := verify(t, stagedPath); err != nil {
+		return fail(errors.WithHint(
+			errors.Wrap(errors.ErrorTypeGitHub, "new key cannot push to the repository", err),
+			"GitHub can take a few seconds to accept a new deploy key; try again shortly",
+		))
+	}
+
+	// 5. Swap the key files and point core.sshCommand at the key
+	backupPath := keyPath + ".old"
+	hadKey, err := swapKeyPair(stagedPath, keyPath, backupPath)
+	if err != nil {
+		return fail(err)
+	}
+	undo.add(func() error {
+		if hadKey {
+			return renameKeyPair(backupPath, keyPath)
+		}
+		return removeKeyPair(keyPath)
+	})
+
+	gitClient := git.NewClient(t.Path)
+	oldSSHCommand, getErr := gitClient.ConfigGet("core.sshCommand")
+	if err := gitClient.ConfigureSSH(keyPath); err != nil {
+		return fail(errors.Wrap(errors.ErrorTypeGit, "failed to update core.sshCommand", err))
+	}
+	undo.add(func() error {
+		if getErr != nil || oldSSHCommand == "" {
+			return gitClient.ConfigUnset("core.sshCommand")
+		}
+		return gitClient.ConfigSet("core.sshCommand", oldSSHCommand)
+	})
+
+	// Committed: the new key works and is in use, and the backup of the
+	// previous key file is no longer needed
+	_ = removeKeyPair(backupPath)
+
+	result := &Result{
+		Repo:        t.Name,
+		SecretPath:  secretPath,
+		KeyPath:     keyPath,
+		Fingerprint: fingerprint,
+		DeployKeyID: newID,
+	}
+
+	// 6. Remove the old deploy keys last, so that until now they still work.
+	// Rolling back here could leave no working key once one is gone, so keys
+	// that can't be removed are reported instead.
+	for _, id := range oldIDs {
+		if err := r.GitHub.DeleteDeployKey(t.Owner, t.Repo, id); err != nil {
+			result.LeftoverKeyIDs = append(result.LeftoverKeyIDs, id)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to remove old deploy key %d: %v", id, err))
+			continue
+		}
+		result.RemovedKeyIDs = append(result.RemovedKeyIDs, id)
+	}
+
+	return result, nil
+}
+
+// oldDeployKeys returns the IDs of deploy keys belonging to the current
+// secret: the recorded ID, or else any key with the same public key material
+func oldDeployKeys(old map[string]interface{}, existing []*github.Key) []int64 {
+	if old == nil {
+		return nil
+	}
+
+	if s, ok := old["deploy_key_id"].(string); ok {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			for _, k := range existing {
+				if k.GetID() == id {
+					return []int64{id}
+				}
+			}
+		}
+	}
+
+	pub, _ := old["public_key"].(string)
+	material := keyMaterial(pub)
+	if material == "" {
+		return nil
+	}
+
+	var ids []int64
+	for _, k := range existing {
+		if keyMaterial(k.GetKey()) == material {
+			ids = append(ids, k.GetID())
+		}
+	}
+	return ids
+}
+
+// keyMaterial strips the comment from an authorized_keys line
+func keyMaterial(authorizedKey string) string {
+	fields := strings.Fields(authorizedKey)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[0] + " " + fields[1]
+}
+
+// generateKey returns an OpenSSH private key, its authorized_keys line, and
+// its SHA256 fingerprint
+func generateKey(comment string) (string, string, string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to generate ed25519 key: %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " " + comment
+	return string(pemEncode(block)), authorized, ssh.FingerprintSHA256(sshPub), nil
+}
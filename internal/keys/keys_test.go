@@ -0,0 +1,306 @@
+package keys
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/secrets"
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeDeployKeys keeps deploy keys in memory; failDelete makes deleting the
+// given key ID fail
+type fakeDeployKeys struct {
+	keys       []*github.Key
+	nextID     int64
+	failDelete int64
+}
+
+func (f *fakeDeployKeys) AddDeployKey(owner, repo, title, publicKey string, readOnly bool) (*github.Key, error) {
+	f.nextID++
+	key := &github.Key{ID: github.Int64(f.nextID), Title: github.String(title), Key: github.String(publicKey), ReadOnly: github.Bool(readOnly)}
+	f.keys = append(f.keys, key)
+	return key, nil
+}
+
+func (f *fakeDeployKeys) ListDeployKeys(owner, repo string) ([]*github.Key, error) {
+	return append([]*github.Key(nil), f.keys...), nil
+}
+
+func (f *fakeDeployKeys) DeleteDeployKey(owner, repo string, id int64) error {
+	if id == f.failDelete {
+		return fmt.Errorf("deploy key %d is locked", id)
+	}
+	for i, k := range f.keys {
+		if k.GetID() == id {
+			f.keys = append(f.keys[:i], f.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeployKeys) ids() []int64 {
+	var ids []int64
+	for _, k := range f.keys {
+		ids = append(ids, k.GetID())
+	}
+	return ids
+}
+
+type fixture struct {
+	rotator *Rotator
+	github  *fakeDeployKeys
+	target  Target
+	keyPath string
+}
+
+// newFixture sets up a repository whose current key (deploy key 1) is
+// stored in secrets, on disk, and in core.sshCommand
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	dir := t.TempDir()
+
+	repoPath := filepath.Join(dir, "repo")
+	if out, err := exec.Command("git", "init", repoPath).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	provider, err := secrets.NewKeyringProvider(secrets.KeyringOptions{FileOnly: true, FallbackPath: filepath.Join(dir, "keyring.json")})
+	if err != nil {
+		t.Fatalf("NewKeyringProvider() error = %v", err)
+	}
+
+	f := &fixture{
+		github:  &fakeDeployKeys{},
+		target:  Target{Name: "myrepo", Path: repoPath, Owner: "alice", Repo: "myrepo", PushURL: "git@github.com:alice/myrepo.git"},
+		keyPath: filepath.Join(dir, "ssh", "github_myrepo"),
+	}
+	f.rotator = &Rotator{
+		Secrets: provider,
+		Layout:  secrets.DefaultLayout(),
+		GitHub:  f.github,
+		SSHDir:  filepath.Join(dir, "ssh"),
+		Verify:  func(Target, string) error { return nil },
+	}
+
+	private, public, _, err := generateKey("old")
+	if err != nil {
+		t.Fatalf("generateKey() error = %v", err)
+	}
+	f.github.AddDeployKey("alice", "myrepo", "old", public, false)
+	if err := provider.Put("githelper/github/myrepo/ssh", map[string]interface{}{"private_key": private, "public_key": public}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := writeKeyPair(f.keyPath, private, public); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.NewClient(repoPath).ConfigSet("core.sshCommand", "ssh -i old-key"); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *fixture) sshCommand(t *testing.T) string {
+	t.Helper()
+	cmd, _ := git.NewClient(f.target.Path).ConfigGet("core.sshCommand")
+	return cmd
+}
+
+func (f *fixture) secret(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := f.rotator.Secrets.Get("githelper/github/myrepo/ssh")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return data
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotate(t *testing.T) {
+	f := newFixture(t)
+
+	var verified string
+	f.rotator.Verify = func(_ Target, keyPath string) error {
+		verified = readFile(t, keyPath)
+		return nil
+	}
+
+	result, err := f.rotator.Rotate(f.target)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	if ids := f.github.ids(); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("deploy keys = %v, want only the new key 2", ids)
+	}
+	if result.DeployKeyID != 2 || len(result.RemovedKeyIDs) != 1 || result.RemovedKeyIDs[0] != 1 {
+		t.Errorf("Rotate() = %+v", result)
+	}
+
+	data := f.secret(t)
+	if data["deploy_key_id"] != "2" {
+		t.Errorf("stored deploy_key_id = %v, want 2", data["deploy_key_id"])
+	}
+	private := readFile(t, f.keyPath)
+	if data["private_key"] != private || verified != private {
+		t.Error("key file, stored secret, and verified key differ")
+	}
+	if _, err := ssh.ParsePrivateKey([]byte(private)); err != nil {
+		t.Errorf("new key does not parse: %v", err)
+	}
+	if pub := strings.TrimSpace(readFile(t, f.keyPath+".pub")); pub != data["public_key"] {
+		t.Errorf("public key file = %q, want %q", pub, data["public_key"])
+	}
+
+	if cmd := f.sshCommand(t); !strings.Contains(cmd, f.keyPath) {
+		t.Errorf("core.sshCommand = %q, want it to use %s", cmd, f.keyPath)
+	}
+
+	for _, leftover := range []string{f.keyPath + ".old", f.keyPath + ".rotating"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s was left behind", leftover)
+		}
+	}
+}
+
+func TestRotate_FirstKey(t *testing.T) {
+	f := newFixture(t)
+	f.github.keys = nil
+	if err := f.rotator.Secrets.Delete("githelper/github/myrepo/ssh"); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(f.keyPath)
+	os.Remove(f.keyPath + ".pub")
+
+	result, err := f.rotator.Rotate(f.target)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if len(result.RemovedKeyIDs) != 0 {
+		t.Errorf("RemovedKeyIDs = %v, want none", result.RemovedKeyIDs)
+	}
+	if _, err := os.Stat(f.keyPath); err != nil {
+		t.Errorf("key file missing: %v", err)
+	}
+}
+
+// assertRolledBack checks that everything still points at the old key
+func assertRolledBack(t *testing.T, f *fixture, oldPrivate string) {
+	t.Helper()
+
+	if ids := f.github.ids(); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("deploy keys = %v, want only the old key 1", ids)
+	}
+	if got := f.secret(t)["private_key"]; got != oldPrivate {
+		t.Error("stored secret was not restored")
+	}
+	if got := readFile(t, f.keyPath); got != oldPrivate {
+		t.Error("key file was not restored")
+	}
+	if cmd := f.sshCommand(t); cmd != "ssh -i old-key" {
+		t.Errorf("core.sshCommand = %q, want it restored", cmd)
+	}
+	for _, leftover := range []string{f.keyPath + ".old", f.keyPath + ".rotating"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s was left behind", leftover)
+		}
+	}
+}
+
+func TestRotate_VerifyFails(t *testing.T) {
+	f := newFixture(t)
+	oldPrivate := readFile(t, f.keyPath)
+
+	denied := stderrors.New("Permission denied (publickey)")
+	f.rotator.Verify = func(Target, string) error { return denied }
+
+	_, err := f.rotator.Rotate(f.target)
+	if !stderrors.Is(err, denied) {
+		t.Fatalf("Rotate() error = %v, want verification failure", err)
+	}
+
+	assertRolledBack(t, f, oldPrivate)
+}
+
+func TestRotate_RemoveOldKeyFails(t *testing.T) {
+	f := newFixture(t)
+	f.github.failDelete = 1
+
+	result, err := f.rotator.Rotate(f.target)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v, want the rotation kept", err)
+	}
+
+	if len(result.RemovedKeyIDs) != 0 || len(result.LeftoverKeyIDs) != 1 || result.LeftoverKeyIDs[0] != 1 {
+		t.Errorf("Rotate() = %+v, want old key 1 left over", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "failed to remove old deploy key 1") {
+		t.Errorf("Warnings = %v", result.Warnings)
+	}
+
+	// The new key stays in use
+	if ids := f.github.ids(); len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("deploy keys = %v, want old key 1 and new key 2", ids)
+	}
+	if f.secret(t)["deploy_key_id"] != "2" {
+		t.Error("stored secret was rolled back")
+	}
+	if cmd := f.sshCommand(t); !strings.Contains(cmd, f.keyPath) {
+		t.Errorf("core.sshCommand = %q, want it to use %s", cmd, f.keyPath)
+	}
+}
+
+func TestRotate_SharedLayoutPath(t *testing.T) {
+	f := newFixture(t)
+	f.rotator.Layout = secrets.Layout{SSHKey: secrets.DefaultSSHKeyPath}
+
+	if _, err := f.rotator.Rotate(f.target); err == nil {
+		t.Fatal("Rotate() succeeded with a layout that shares one key across repositories")
+	}
+	if ids := f.github.ids(); len(ids) != 1 {
+		t.Errorf("deploy keys = %v, want untouched", ids)
+	}
+}
+
+func TestOldDeployKeys(t *testing.T) {
+	existing := []*github.Key{
+		{ID: github.Int64(7), Key: github.String("ssh-ed25519 AAAA githelper-a")},
+		{ID: github.Int64(8), Key: github.String("ssh-ed25519 BBBB")},
+	}
+
+	tests := []struct {
+		name string
+		old  map[string]interface{}
+		want []int64
+	}{
+		{name: "no secret", old: nil, want: nil},
+		{name: "recorded id", old: map[string]interface{}{"deploy_key_id": "8", "public_key": "ssh-ed25519 AAAA"}, want: []int64{8}},
+		{name: "stale id falls back to key match", old: map[string]interface{}{"deploy_key_id": "3", "public_key": "ssh-ed25519 AAAA other-comment"}, want: []int64{7}},
+		{name: "unregistered key", old: map[string]interface{}{"public_key": "ssh-ed25519 CCCC"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oldDeployKeys(tt.old, existing)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("oldDeployKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// ProtectionRules represents branch protection settings
+// This is a local copy to avoid import cycles
+type ProtectionRules struct {
+	Enabled             bool
+	RequireReviews      bool
+	RequireStatusChecks bool
+	EnforceAdmins       bool
+	AllowForcePush      bool
+}
+
+// RepositoryPermissions represents the user's permissions on a repository
+type RepositoryPermissions struct {
+	Admin bool `json:"admin"`
+	Push  bool `json:"push"`
+	Pull  bool `json:"pull"`
+}
+
+// ResponseError is returned when the Bitbucket API answers with a non-2xx status
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *ResponseError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d", e.Method, e.Path, e.StatusCode)
+}
+
+// apiClient is the HTTP plumbing shared by the Cloud and Server clients
+type apiClient struct {
+	httpClient    *http.Client
+	baseURL       string // Cloud: https://api.bitbucket.org/2.0, Server: https://<host>[/<context>]
+	authorization string // Authorization header value
+	ctx           context.Context
+}
+
+// newAPIClient creates an apiClient with the default timeout
+func newAPIClient(baseURL, authorization string) *apiClient {
+	return &apiClient{
+		httpClient:    &http.Client{Timeout: defaultHTTPTimeout},
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		authorization: authorization,
+		ctx:           context.Background(),
+	}
+}
+
+// do performs an API request and decodes a JSON response into out (if non-nil).
+// path is relative to the base URL unless it is absolute (pagination links).
+func (a *apiClient) do(method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	target := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		target = a.baseURL + path
+	}
+
+	req, err := http.NewRequestWithContext(a.ctx, method, target, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", a.authorization)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return &ResponseError{StatusCode: resp.StatusCode, Method: method, Path: path, Message: errorMessage(data)}
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// errorMessage extracts the message from a Cloud ({"error": {"message"}}) or
+// Server ({"errors": [{"message"}]}) error body
+func errorMessage(data []byte) string {
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &apiErr); err != nil {
+		return ""
+	}
+	if apiErr.Error.Message != "" {
+		return apiErr.Error.Message
+	}
+	messages := make([]string, 0, len(apiErr.Errors))
+	for _, e := range apiErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// isNotFound reports whether err is a 404 from the API
+func isNotFound(err error) bool {
+	if respErr, ok := err.(*ResponseError); ok {
+		return respErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
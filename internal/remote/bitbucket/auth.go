@@ -0,0 +1,92 @@
+package bitbucket
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TokenSource represents where the credentials were found
+type TokenSource string
+
+const (
+	SourceEnvVar       TokenSource = "BITBUCKET_TOKEN"
+	SourceAppPassword  TokenSource = "BITBUCKET_USERNAME/BITBUCKET_APP_PASSWORD"
+	SourceServerEnvVar TokenSource = "BITBUCKET_SERVER_TOKEN"
+	SourceGitConfig    TokenSource = "git config bitbucket.token"
+)
+
+// TokenInfo contains the Authorization header value and its source
+type TokenInfo struct {
+	Authorization string
+	Source        TokenSource
+}
+
+// getCloudTokenInfo finds Bitbucket Cloud credentials
+// Priority: BITBUCKET_TOKEN env var > BITBUCKET_USERNAME + BITBUCKET_APP_PASSWORD > git config
+func getCloudTokenInfo() (*TokenInfo, error) {
+	// 1. Access token (repository, project or workspace)
+	if token := os.Getenv("BITBUCKET_TOKEN"); token != "" {
+		return &TokenInfo{Authorization: "Bearer " + token, Source: SourceEnvVar}, nil
+	}
+
+	// 2. Username and app password
+	user, password := os.Getenv("BITBUCKET_USERNAME"), os.Getenv("BITBUCKET_APP_PASSWORD")
+	if user != "" && password != "" {
+		basic := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+		return &TokenInfo{Authorization: "Basic " + basic, Source: SourceAppPassword}, nil
+	}
+
+	// 3. git config (bitbucket.token)
+	if token, err := readGitConfigToken(); err == nil && token != "" {
+		return &TokenInfo{Authorization: "Bearer " + token, Source: SourceGitConfig}, nil
+	}
+
+	return nil, fmt.Errorf("no Bitbucket credentials found\n\n" +
+		"Please authenticate using one of:\n" +
+		"  1. Set BITBUCKET_TOKEN to a repository or workspace access token\n" +
+		"  2. Set BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD\n" +
+		"  3. Run: git config --global bitbucket.token YOUR_TOKEN")
+}
+
+// getServerTokenInfo finds a Bitbucket Server/Data Center HTTP access token for host
+// Priority: BITBUCKET_SERVER_TOKEN env var > BITBUCKET_TOKEN env var > git config
+func getServerTokenInfo(host string) (*TokenInfo, error) {
+	// 1. Server-specific token, so Cloud and Server credentials can coexist
+	if token := os.Getenv("BITBUCKET_SERVER_TOKEN"); token != "" {
+		return &TokenInfo{Authorization: "Bearer " + token, Source: SourceServerEnvVar}, nil
+	}
+
+	// 2. Generic token
+	if token := os.Getenv("BITBUCKET_TOKEN"); token != "" {
+		return &TokenInfo{Authorization: "Bearer " + token, Source: SourceEnvVar}, nil
+	}
+
+	// 3. git config (bitbucket.token)
+	if token, err := readGitConfigToken(); err == nil && token != "" {
+		return &TokenInfo{Authorization: "Bearer " + token, Source: SourceGitConfig}, nil
+	}
+
+	return nil, fmt.Errorf("no Bitbucket Server token found for %s\n\n"+
+		"Create an HTTP access token at https://%s/plugins/servlet/access-tokens/manage, then either:\n"+
+		"  1. Set BITBUCKET_SERVER_TOKEN environment variable\n"+
+		"  2. Run: git config --global bitbucket.token YOUR_TOKEN", host, host)
+}
+
+// readGitConfigToken reads token from git config
+func readGitConfigToken() (string, error) {
+	cmd := exec.Command("git", "config", "--global", "bitbucket.token")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("git config bitbucket.token is empty")
+	}
+
+	return token, nil
+}
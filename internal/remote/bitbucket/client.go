@@ -0,0 +1,390 @@
+// Package bitbucket implements the remote.Platform interface for Bitbucket
+// Cloud (REST API 2.0, Client) and Bitbucket Server/Data Center (REST API
+// 1.0, ServerClient).
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultCloudAPIURL is the Bitbucket Cloud API base URL
+const DefaultCloudAPIURL = "https://api.bitbucket.org/2.0"
+
+// Client talks to the Bitbucket Cloud API and implements the Platform interface
+type Client struct {
+	api       *apiClient
+	workspace string
+	repo      string // repository slug
+}
+
+// NewClient creates a Bitbucket Cloud client from a remote URL
+// Supports: https://[user@]bitbucket.org/workspace/repo.git, git@bitbucket.org:workspace/repo.git
+func NewClient(remoteURL string) (*Client, error) {
+	return NewClientWithBaseURL(remoteURL, "")
+}
+
+// NewClientWithBaseURL creates a Bitbucket Cloud client using an explicit API
+// base URL. An empty baseURL falls back to DefaultCloudAPIURL.
+func NewClientWithBaseURL(remoteURL, baseURL string) (*Client, error) {
+	workspace, repo, err := parseCloudURL(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Bitbucket URL: %w", err)
+	}
+
+	info, err := getCloudTokenInfo()
+	if err != nil {
+		return nil, fmt.Errorf("Bitbucket authentication required: %w", err)
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultCloudAPIURL
+	}
+
+	return &Client{
+		api:       newAPIClient(baseURL, info.Authorization),
+		workspace: workspace,
+		repo:      repo,
+	}, nil
+}
+
+// NewClientWithTimeout creates a client with custom timeout
+// Returns client and a cancel function that must be called when done
+func NewClientWithTimeout(remoteURL string, timeout time.Duration) (*Client, context.CancelFunc, error) {
+	client, err := NewClient(remoteURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	client.api.ctx = ctx
+
+	return client, cancel, nil
+}
+
+// parseCloudURL extracts the workspace and repository slug from a remote URL
+func parseCloudURL(remoteURL string) (workspace, repo string, err error) {
+	var path string
+
+	switch {
+	case strings.Contains(remoteURL, "://"):
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", err
+		}
+		if u.Host == "" {
+			return "", "", fmt.Errorf("missing host in URL: %s", remoteURL)
+		}
+		path = u.Path
+
+	case strings.Contains(remoteURL, "@") && strings.Contains(remoteURL, ":"):
+		// scp-like syntax: git@bitbucket.org:workspace/repo.git
+		rest := remoteURL[strings.Index(remoteURL, "@")+1:]
+		path = rest[strings.Index(rest, ":")+1:]
+
+	default:
+		return "", "", fmt.Errorf("unrecognized remote URL format: %s", remoteURL)
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid Bitbucket path: %s (expected workspace/repo)", path)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// GetOwner returns the workspace
+func (c *Client) GetOwner() string {
+	return c.workspace
+}
+
+// GetRepo returns the repository slug
+func (c *Client) GetRepo() string {
+	return c.repo
+}
+
+// GetPlatform returns "bitbucket"
+func (c *Client) GetPlatform() string {
+	return "bitbucket"
+}
+
+// repoPath returns the API path of the repository
+func (c *Client) repoPath() string {
+	return "/repositories/" + url.PathEscape(c.workspace) + "/" + url.PathEscape(c.repo)
+}
+
+// Branch restriction kinds managed by SetBranchProtection
+const (
+	kindDelete                  = "delete"
+	kindForce                   = "force"
+	kindRequireApprovals        = "require_approvals_to_merge"
+	kindRequireDefaultApprovals = "require_default_reviewer_approvals_to_merge"
+	kindRequirePassingBuilds    = "require_passing_builds_to_merge"
+	kindEnforceMergeChecks      = "enforce_merge_checks"
+)
+
+// branchRestriction is the Bitbucket Cloud branch restriction resource.
+// Each restriction enforces a single rule (kind) on a branch pattern.
+type branchRestriction struct {
+	ID              int    `json:"id,omitempty"`
+	Kind            string `json:"kind"`
+	BranchMatchKind string `json:"branch_match_kind,omitempty"`
+	Pattern         string `json:"pattern"`
+	Value           *int   `json:"value,omitempty"`
+}
+
+// value returns the restriction's numeric value, 0 if it has none
+func (r branchRestriction) value() int {
+	if r.Value == nil {
+		return 0
+	}
+	return *r.Value
+}
+
+// SetDefaultBranch updates the repository's main branch
+func (c *Client) SetDefaultBranch(branch string) error {
+	body := map[string]interface{}{
+		"mainbranch": map[string]string{"name": branch},
+	}
+	if err := c.api.do(http.MethodPut, c.repoPath(), body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the repository's main branch
+func (c *Client) GetDefaultBranch() (string, error) {
+	var r struct {
+		MainBranch *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := c.api.do(http.MethodGet, c.repoPath(), nil, &r); err != nil {
+		return "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	if r.MainBranch == nil {
+		return "", fmt.Errorf("repository has no main branch")
+	}
+	return r.MainBranch.Name, nil
+}
+
+// getBranchRestrictions returns the restrictions whose pattern is exactly
+// branch, following pagination
+func (c *Client) getBranchRestrictions(branch string) ([]branchRestriction, error) {
+	var restrictions []branchRestriction
+	next := c.repoPath() + "/branch-restrictions?pattern=" + url.QueryEscape(branch)
+	for next != "" {
+		var page struct {
+			Values []branchRestriction `json:"values"`
+			Next   string              `json:"next"`
+		}
+		if err := c.api.do(http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Values {
+			// Branching-model restrictions (branch_match_kind=branching_model) aren't per-branch
+			if r.Pattern == branch && (r.BranchMatchKind == "" || r.BranchMatchKind == "glob") {
+				restrictions = append(restrictions, r)
+			}
+		}
+		next = page.Next
+	}
+	return restrictions, nil
+}
+
+// IsBranchProtected checks if a branch has any restrictions
+func (c *Client) IsBranchProtected(branch string) (bool, error) {
+	restrictions, err := c.getBranchRestrictions(branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check branch protection: %w", err)
+	}
+	return len(restrictions) > 0, nil
+}
+
+// GetBranchProtection maps the branch's restrictions onto protection rules:
+// approvals to merge are reviews, passing builds are status checks, enforced
+// merge checks (Premium) bind admins too, and a force restriction forbids
+// force pushes
+func (c *Client) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	restrictions, err := c.getBranchRestrictions(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+	if len(restrictions) == 0 {
+		return &ProtectionRules{Enabled: false}, nil
+	}
+
+	rules := &ProtectionRules{Enabled: true, AllowForcePush: true}
+	for _, r := range restrictions {
+		switch r.Kind {
+		case kindRequireApprovals, kindRequireDefaultApprovals:
+			rules.RequireReviews = rules.RequireReviews || r.value() > 0
+		case kindRequirePassingBuilds:
+			rules.RequireStatusChecks = r.value() > 0
+		case kindEnforceMergeChecks:
+			rules.EnforceAdmins = true
+		case kindForce:
+			rules.AllowForcePush = false
+		}
+	}
+	return rules, nil
+}
+
+// SetBranchProtection makes branch's restrictions match rules. Protecting a
+// branch always prevents its deletion; disabling removes every restriction on
+// it. Restriction kinds the rules don't cover (push and merge access) are left
+// alone, and existing approval and build counts are kept.
+func (c *Client) SetBranchProtection(branch string, rules ProtectionRules) error {
+	current, err := c.getBranchRestrictions(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch protection: %w", err)
+	}
+
+	path := c.repoPath() + "/branch-restrictions"
+	existing := make(map[string]branchRestriction)
+	for _, r := range current {
+		if !rules.Enabled {
+			if err := c.api.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, r.ID), nil, nil); err != nil {
+				return fmt.Errorf("failed to remove branch restriction %s: %w", r.Kind, err)
+			}
+			continue
+		}
+		existing[r.Kind] = r
+	}
+	if !rules.Enabled {
+		return nil
+	}
+
+	// Desired restriction kinds and values (0 means the kind takes no value)
+	want := map[string]int{kindDelete: 0}
+	if !rules.AllowForcePush {
+		want[kindForce] = 0
+	}
+	if rules.RequireReviews {
+		want[kindRequireApprovals] = keepCount(existing[kindRequireApprovals])
+	}
+	if rules.RequireStatusChecks {
+		want[kindRequirePassingBuilds] = keepCount(existing[kindRequirePassingBuilds])
+	}
+	if rules.EnforceAdmins {
+		want[kindEnforceMergeChecks] = 0
+	}
+
+	for _, kind := range []string{kindDelete, kindForce, kindRequireApprovals, kindRequireDefaultApprovals, kindRequirePassingBuilds, kindEnforceMergeChecks} {
+		r, have := existing[kind]
+		value, wanted := want[kind]
+		var err error
+
+		// Default reviewer approvals count as reviews; keep them while reviews are required
+		if kind == kindRequireDefaultApprovals {
+			wanted, value = rules.RequireReviews && have, r.value()
+		}
+
+		switch {
+		case wanted && !have:
+			body := branchRestriction{Kind: kind, BranchMatchKind: "glob", Pattern: branch}
+			if value > 0 {
+				body.Value = &value
+			}
+			err = c.api.do(http.MethodPost, path, body, nil)
+		case !wanted && have:
+			err = c.api.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, r.ID), nil, nil)
+		case wanted && value > 0 && r.value() != value:
+			r.Value = &value
+			err = c.api.do(http.MethodPut, fmt.Sprintf("%s/%d", path, r.ID), r, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update branch restriction %s: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// keepCount returns an existing restriction's count, or 1 if it has none
+func keepCount(r branchRestriction) int {
+	if r.value() > 0 {
+		return r.value()
+	}
+	return 1
+}
+
+// ErrPermissionsUnknown is returned by CheckPermissions, CanPush and CanAdmin
+// when the credentials can read the repository but aren't a user's (e.g. a
+// repository or workspace access token), so Bitbucket won't report what
+// they may do. It does not mean they lack permission.
+var ErrPermissionsUnknown = errors.New("permissions unknown: Bitbucket only reports them for user credentials")
+
+// CheckPermissions returns the authenticated user's permissions on the repository
+func (c *Client) CheckPermissions() (*RepositoryPermissions, error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf(`repository.full_name="%s/%s"`, c.workspace, c.repo))
+
+	var page struct {
+		Values []struct {
+			Permission string `json:"permission"` // admin, write, read
+		} `json:"values"`
+	}
+	if err := c.api.do(http.MethodGet, "/user/permissions/repositories?"+query.Encode(), nil, &page); err != nil {
+		var respErr *ResponseError
+		if errors.As(err, &respErr) && (respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden) {
+			// Access tokens are refused by user endpoints; if the repository
+			// itself is readable the credentials are fine, just not a user's
+			if c.TestConnection() == nil {
+				return nil, ErrPermissionsUnknown
+			}
+		}
+		return nil, fmt.Errorf("failed to get repository permissions: %w", err)
+	}
+
+	perms := &RepositoryPermissions{}
+	if len(page.Values) == 0 {
+		return perms, nil
+	}
+	switch page.Values[0].Permission {
+	case "admin":
+		perms.Admin = true
+		fallthrough
+	case "write":
+		perms.Push = true
+		fallthrough
+	case "read":
+		perms.Pull = true
+	}
+	return perms, nil
+}
+
+// CanPush checks if authenticated user can push to the repository
+func (c *Client) CanPush() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Push, nil
+}
+
+// CanAdmin checks if authenticated user has admin access
+func (c *Client) CanAdmin() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Admin, nil
+}
+
+// TestConnection tests the Bitbucket API connection by reading the
+// repository, which works for user credentials and access tokens alike
+func (c *Client) TestConnection() error {
+	if err := c.api.do(http.MethodGet, c.repoPath(), nil, nil); err != nil {
+		return fmt.Errorf("Bitbucket API connection test failed: %w", err)
+	}
+	return nil
+}
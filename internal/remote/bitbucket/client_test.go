@@ -0,0 +1,353 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestClient returns a Cloud client pointing at a fake API served by mux
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &Client{
+		api: &apiClient{
+			httpClient:    server.Client(),
+			baseURL:       server.URL + "/2.0",
+			authorization: "Bearer test_token",
+			ctx:           context.Background(),
+		},
+		workspace: "testws",
+		repo:      "testrepo",
+	}
+}
+
+func TestParseCloudURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		wantWorkspace string
+		wantRepo      string
+		wantErr       bool
+	}{
+		{name: "https with .git", url: "https://bitbucket.org/ws/repo.git", wantWorkspace: "ws", wantRepo: "repo"},
+		{name: "https with user", url: "https://someone@bitbucket.org/ws/repo.git", wantWorkspace: "ws", wantRepo: "repo"},
+		{name: "ssh scp-like", url: "git@bitbucket.org:ws/repo.git", wantWorkspace: "ws", wantRepo: "repo"},
+		{name: "ssh url", url: "ssh://git@bitbucket.org/ws/repo", wantWorkspace: "ws", wantRepo: "repo"},
+		{name: "missing workspace", url: "https://bitbucket.org/repo.git", wantErr: true},
+		{name: "nested path", url: "https://bitbucket.org/a/b/repo.git", wantErr: true},
+		{name: "not a url", url: "not-a-url", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace, repo, err := parseCloudURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCloudURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if workspace != tt.wantWorkspace || repo != tt.wantRepo {
+				t.Errorf("parseCloudURL() = (%v, %v), want (%v, %v)", workspace, repo, tt.wantWorkspace, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestNewClient_Credentials(t *testing.T) {
+	t.Setenv("BITBUCKET_TOKEN", "")
+	t.Setenv("BITBUCKET_USERNAME", "alice")
+	t.Setenv("BITBUCKET_APP_PASSWORD", "secret")
+
+	client, err := NewClient("git@bitbucket.org:ws/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if !strings.HasPrefix(client.api.authorization, "Basic ") {
+		t.Errorf("authorization = %q, want basic auth from app password", client.api.authorization)
+	}
+	if client.api.baseURL != DefaultCloudAPIURL {
+		t.Errorf("baseURL = %s, want %s", client.api.baseURL, DefaultCloudAPIURL)
+	}
+
+	t.Setenv("BITBUCKET_TOKEN", "access_token")
+	client, err = NewClient("git@bitbucket.org:ws/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.api.authorization != "Bearer access_token" {
+		t.Errorf("authorization = %q, want the access token", client.api.authorization)
+	}
+}
+
+func TestDefaultBranch_Mock(t *testing.T) {
+	mainBranch := "main"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/testws/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test_token" {
+			t.Errorf("Authorization = %q, want Bearer test_token", r.Header.Get("Authorization"))
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{"mainbranch": map[string]string{"name": mainBranch}})
+		case http.MethodPut:
+			var body struct {
+				MainBranch struct {
+					Name string `json:"name"`
+				} `json:"mainbranch"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			mainBranch = body.MainBranch.Name
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+		}
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.SetDefaultBranch("develop"); err != nil {
+		t.Fatalf("SetDefaultBranch() error = %v", err)
+	}
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "develop" {
+		t.Errorf("GetDefaultBranch() = %v, want develop", branch)
+	}
+}
+
+// fakeRestrictions serves the branch restrictions API from memory, one
+// restriction per page to exercise pagination
+type fakeRestrictions struct {
+	mu     sync.Mutex
+	nextID int
+	items  []branchRestriction
+}
+
+func (f *fakeRestrictions) register(mux *http.ServeMux) {
+	const base = "/2.0/repositories/testws/testrepo/branch-restrictions"
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			var matching []branchRestriction
+			for _, item := range f.items {
+				if item.Pattern == r.URL.Query().Get("pattern") {
+					matching = append(matching, item)
+				}
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			resp := map[string]interface{}{"values": []branchRestriction{}}
+			if page < len(matching) {
+				resp["values"] = matching[page : page+1]
+			}
+			if page+1 < len(matching) {
+				resp["next"] = fmt.Sprintf("http://%s%s?pattern=%s&page=%d", r.Host, base, r.URL.Query().Get("pattern"), page+1)
+			}
+			json.NewEncoder(w).Encode(resp)
+		case http.MethodPost:
+			var item branchRestriction
+			json.NewDecoder(r.Body).Decode(&item)
+			f.nextID++
+			item.ID = f.nextID
+			f.items = append(f.items, item)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(item)
+		}
+	})
+	mux.HandleFunc(base+"/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, base+"/"))
+		for i, item := range f.items {
+			if item.ID != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodDelete:
+				f.items = append(f.items[:i], f.items[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+			case http.MethodPut:
+				json.NewDecoder(r.Body).Decode(&f.items[i])
+				json.NewEncoder(w).Encode(f.items[i])
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
+func (f *fakeRestrictions) add(kind string, value int) {
+	f.nextID++
+	item := branchRestriction{ID: f.nextID, Kind: kind, BranchMatchKind: "glob", Pattern: "main"}
+	if value > 0 {
+		item.Value = &value
+	}
+	f.items = append(f.items, item)
+}
+
+func TestGetBranchProtection_Mock(t *testing.T) {
+	fake := &fakeRestrictions{}
+	fake.add(kindRequireApprovals, 2)
+	fake.add(kindForce, 0)
+	fake.add("push", 0)
+
+	mux := http.NewServeMux()
+	fake.register(mux)
+	client := newTestClient(t, mux)
+
+	rules, err := client.GetBranchProtection("main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	want := ProtectionRules{Enabled: true, RequireReviews: true}
+	if *rules != want {
+		t.Errorf("GetBranchProtection() = %+v, want %+v", *rules, want)
+	}
+
+	rules, err = client.GetBranchProtection("develop")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if rules.Enabled {
+		t.Errorf("GetBranchProtection(develop) = %+v, want unprotected", *rules)
+	}
+}
+
+func TestSetBranchProtection_Mock(t *testing.T) {
+	fake := &fakeRestrictions{}
+	fake.add(kindRequireApprovals, 2)
+	fake.add("push", 0)
+
+	mux := http.NewServeMux()
+	fake.register(mux)
+	client := newTestClient(t, mux)
+
+	want := ProtectionRules{Enabled: true, RequireReviews: true, RequireStatusChecks: true, EnforceAdmins: true}
+	if err := client.SetBranchProtection("main", want); err != nil {
+		t.Fatalf("SetBranchProtection() error = %v", err)
+	}
+
+	rules, err := client.GetBranchProtection("main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if *rules != want {
+		t.Errorf("after set, GetBranchProtection() = %+v, want %+v", *rules, want)
+	}
+
+	kinds := make(map[string]int)
+	for _, item := range fake.items {
+		kinds[item.Kind] = item.value()
+	}
+	if kinds[kindRequireApprovals] != 2 {
+		t.Errorf("approval count = %d, want the existing 2 kept", kinds[kindRequireApprovals])
+	}
+	for _, kind := range []string{"push", kindDelete, kindForce, kindRequirePassingBuilds, kindEnforceMergeChecks} {
+		if _, ok := kinds[kind]; !ok {
+			t.Errorf("restriction %s missing, have %v", kind, kinds)
+		}
+	}
+
+	// Disabling removes every restriction on the branch
+	if err := client.SetBranchProtection("main", ProtectionRules{}); err != nil {
+		t.Fatalf("SetBranchProtection(disabled) error = %v", err)
+	}
+	if len(fake.items) != 0 {
+		t.Errorf("restrictions left after disabling: %+v", fake.items)
+	}
+}
+
+func TestPermissions_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/user/permissions/repositories", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != `repository.full_name="testws/testrepo"` {
+			t.Errorf("q = %s", q)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"values": []map[string]string{{"permission": "write"}},
+		})
+	})
+
+	client := newTestClient(t, mux)
+
+	canPush, err := client.CanPush()
+	if err != nil || !canPush {
+		t.Errorf("CanPush() = %v, %v, want true", canPush, err)
+	}
+	canAdmin, err := client.CanAdmin()
+	if err != nil || canAdmin {
+		t.Errorf("CanAdmin() = %v, %v, want false", canAdmin, err)
+	}
+}
+
+func TestPermissions_AccessToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/user/permissions/repositories", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "Access token not supported"}})
+	})
+	mux.HandleFunc("/2.0/user", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("TestConnection() used a user endpoint")
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/2.0/repositories/testws/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"full_name": "testws/testrepo"})
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.TestConnection(); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+	if _, err := client.CanPush(); !errors.Is(err, ErrPermissionsUnknown) {
+		t.Errorf("CanPush() error = %v, want ErrPermissionsUnknown", err)
+	}
+	if _, err := client.CanAdmin(); !errors.Is(err, ErrPermissionsUnknown) {
+		t.Errorf("CanAdmin() error = %v, want ErrPermissionsUnknown", err)
+	}
+}
+
+func TestPermissions_BadCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.TestConnection(); err == nil {
+		t.Error("TestConnection() accepted bad credentials")
+	}
+	if _, err := client.CanPush(); err == nil || errors.Is(err, ErrPermissionsUnknown) {
+		t.Errorf("CanPush() error = %v, want the authentication failure", err)
+	}
+}
+
+func TestResponseError_Message(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/testws/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"type": "error", "error": {"message": "Repository testws/testrepo not found"}}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	_, err := client.GetDefaultBranch()
+	if err == nil || !strings.Contains(err.Error(), "Repository testws/testrepo not found") {
+		t.Errorf("GetDefaultBranch() error = %v, want the API message", err)
+	}
+}
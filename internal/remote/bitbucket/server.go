@@ -0,0 +1,427 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ServerClient talks to the Bitbucket Server/Data Center API and implements
+// the Platform interface
+type ServerClient struct {
+	api     *apiClient
+	project string // project key, or ~username for personal repositories
+	repo    string // repository slug
+}
+
+// NewServerClient creates a Bitbucket Server client from a remote URL
+// Supports: https://host[/context]/scm/PROJ/repo.git, ssh://git@host:7999/proj/repo.git,
+// git@host:proj/repo.git, and https://host/projects/PROJ/repos/repo/browse
+// The API base URL is derived from the remote (https://<host>[/context]).
+func NewServerClient(remoteURL string) (*ServerClient, error) {
+	return NewServerClientWithBaseURL(remoteURL, "")
+}
+
+// NewServerClientWithBaseURL creates a Bitbucket Server client using an
+// explicit base URL (the web root, without /rest). Servers reached over SSH
+// whose web UI isn't https://<host> need this.
+// An empty baseURL falls back to the base derived from the remote URL.
+func NewServerClientWithBaseURL(remoteURL, baseURL string) (*ServerClient, error) {
+	webBase, project, repo, err := parseServerURL(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Bitbucket Server URL: %w", err)
+	}
+
+	if baseURL == "" {
+		baseURL = webBase
+	}
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	info, err := getServerTokenInfo(host)
+	if err != nil {
+		return nil, fmt.Errorf("Bitbucket Server authentication required: %w", err)
+	}
+
+	return &ServerClient{
+		api:     newAPIClient(baseURL, info.Authorization),
+		project: project,
+		repo:    repo,
+	}, nil
+}
+
+// NewServerClientWithTimeout creates a client with custom timeout
+// Returns client and a cancel function that must be called when done
+func NewServerClientWithTimeout(remoteURL string, timeout time.Duration) (*ServerClient, context.CancelFunc, error) {
+	client, err := NewServerClient(remoteURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	client.api.ctx = ctx
+
+	return client, cancel, nil
+}
+
+// parseServerURL extracts the web base URL, project key and repository slug
+// from a remote URL. SSH remotes map to https://<host>.
+func parseServerURL(remoteURL string) (webBase, project, repo string, err error) {
+	var path string
+
+	switch {
+	case strings.Contains(remoteURL, "://"):
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", "", err
+		}
+		if u.Host == "" {
+			return "", "", "", fmt.Errorf("missing host in URL: %s", remoteURL)
+		}
+		path = u.Path
+
+		if u.Scheme == "ssh" || u.Scheme == "git+ssh" {
+			webBase = "https://" + u.Hostname()
+			break
+		}
+
+		// Clone URLs live under <context>/scm/, browse URLs under <context>/projects/
+		webBase = u.Scheme + "://" + u.Host
+		for _, marker := range []string{"/scm/", "/projects/", "/users/"} {
+			if i := strings.Index(path, marker); i >= 0 {
+				webBase += path[:i]
+				path = path[i:]
+				break
+			}
+		}
+
+	case strings.Contains(remoteURL, "@") && strings.Contains(remoteURL, ":"):
+		// scp-like syntax: git@bitbucket.corp.example:proj/repo.git
+		rest := remoteURL[strings.Index(remoteURL, "@")+1:]
+		colon := strings.Index(rest, ":")
+		webBase = "https://" + rest[:colon]
+		path = rest[colon+1:]
+
+	default:
+		return "", "", "", fmt.Errorf("unrecognized remote URL format: %s", remoteURL)
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 4 && parts[0] == "projects" && parts[2] == "repos":
+		project, repo = parts[1], parts[3]
+	case len(parts) >= 4 && parts[0] == "users" && parts[2] == "repos":
+		project, repo = "~"+parts[1], parts[3]
+	default:
+		if len(parts) > 0 && parts[0] == "scm" {
+			parts = parts[1:]
+		}
+		if len(parts) != 2 {
+			return "", "", "", fmt.Errorf("invalid Bitbucket Server path: %s (expected project/repo)", path)
+		}
+		project, repo = parts[0], strings.TrimSuffix(parts[1], ".git")
+	}
+
+	if project == "" || project == "~" || repo == "" {
+		return "", "", "", fmt.Errorf("invalid Bitbucket Server path: %s", path)
+	}
+
+	// Project keys are upper case; personal repositories use ~username
+	if !strings.HasPrefix(project, "~") {
+		project = strings.ToUpper(project)
+	}
+	return webBase, project, repo, nil
+}
+
+// GetOwner returns the project key
+func (c *ServerClient) GetOwner() string {
+	return c.project
+}
+
+// GetRepo returns the repository slug
+func (c *ServerClient) GetRepo() string {
+	return c.repo
+}
+
+// GetPlatform returns "bitbucket-server"
+func (c *ServerClient) GetPlatform() string {
+	return "bitbucket-server"
+}
+
+// repoPath returns the path of the repository below an API root
+func (c *ServerClient) repoPath() string {
+	return "/projects/" + url.PathEscape(c.project) + "/repos/" + url.PathEscape(c.repo)
+}
+
+// Branch permission types managed by SetBranchProtection
+const (
+	restrictionNoDeletes       = "no-deletes"
+	restrictionFastForwardOnly = "fast-forward-only"
+	restrictionPullRequestOnly = "pull-request-only"
+	restrictionReadOnly        = "read-only"
+)
+
+// restrictionMatcher selects the refs a branch permission applies to
+type restrictionMatcher struct {
+	ID   string `json:"id"`
+	Type struct {
+		ID string `json:"id"`
+	} `json:"type"`
+}
+
+// restriction is the Bitbucket Server branch permission resource. Users,
+// groups and access keys listed on it are exempt from it.
+type restriction struct {
+	ID         int                `json:"id,omitempty"`
+	Type       string             `json:"type"`
+	Matcher    restrictionMatcher `json:"matcher"`
+	Users      []interface{}      `json:"users"`
+	Groups     []interface{}      `json:"groups"`
+	AccessKeys []interface{}      `json:"accessKeys"`
+}
+
+// exempts reports whether anyone is exempt from the restriction
+func (r restriction) exempts() bool {
+	return len(r.Users) > 0 || len(r.Groups) > 0 || len(r.AccessKeys) > 0
+}
+
+// newRestriction returns a restriction of type on branch with no exemptions
+func newRestriction(restrictionType, branch string) restriction {
+	r := restriction{
+		Type:       restrictionType,
+		Users:      []interface{}{},
+		Groups:     []interface{}{},
+		AccessKeys: []interface{}{},
+	}
+	r.Matcher.ID = "refs/heads/" + branch
+	r.Matcher.Type.ID = "BRANCH"
+	return r
+}
+
+// SetDefaultBranch updates the repository's default branch
+func (c *ServerClient) SetDefaultBranch(branch string) error {
+	body := map[string]string{"id": "refs/heads/" + branch}
+	if err := c.api.do(http.MethodPut, "/rest/api/1.0"+c.repoPath()+"/branches/default", body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the current default branch
+func (c *ServerClient) GetDefaultBranch() (string, error) {
+	var ref struct {
+		DisplayID string `json:"displayId"`
+	}
+	if err := c.api.do(http.MethodGet, "/rest/api/1.0"+c.repoPath()+"/branches/default", nil, &ref); err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	return ref.DisplayID, nil
+}
+
+// getRestrictions returns the branch permissions matching exactly branch,
+// following pagination
+func (c *ServerClient) getRestrictions(branch string) ([]restriction, error) {
+	query := url.Values{}
+	query.Set("matcherType", "BRANCH")
+	query.Set("matcherId", "refs/heads/"+branch)
+
+	var restrictions []restriction
+	start := 0
+	for {
+		query.Set("start", fmt.Sprint(start))
+		var page struct {
+			Values        []restriction `json:"values"`
+			IsLastPage    bool          `json:"isLastPage"`
+			NextPageStart int           `json:"nextPageStart"`
+		}
+		path := "/rest/branch-permissions/2.0" + c.repoPath() + "/restrictions?" + query.Encode()
+		if err := c.api.do(http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Values {
+			if r.Matcher.Type.ID == "BRANCH" && r.Matcher.ID == "refs/heads/"+branch {
+				restrictions = append(restrictions, r)
+			}
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			return restrictions, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// IsBranchProtected checks if a branch has any branch permissions
+func (c *ServerClient) IsBranchProtected(branch string) (bool, error) {
+	restrictions, err := c.getRestrictions(branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check branch protection: %w", err)
+	}
+	return len(restrictions) > 0, nil
+}
+
+// GetBranchProtection maps the branch's permissions onto protection rules:
+// pull-request-only means changes need review, fast-forward-only (or
+// read-only) forbids force pushes, and admins are bound when nobody is
+// exempt. Required builds can't be read per branch, so status checks are
+// always reported as off.
+func (c *ServerClient) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	restrictions, err := c.getRestrictions(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+	if len(restrictions) == 0 {
+		return &ProtectionRules{Enabled: false}, nil
+	}
+
+	rules := &ProtectionRules{Enabled: true, AllowForcePush: true, EnforceAdmins: true}
+	for _, r := range restrictions {
+		switch r.Type {
+		case restrictionPullRequestOnly:
+			rules.RequireReviews = true
+		case restrictionFastForwardOnly, restrictionReadOnly:
+			rules.AllowForcePush = false
+		}
+		if r.exempts() {
+			rules.EnforceAdmins = false
+		}
+	}
+	return rules, nil
+}
+
+// SetBranchProtection makes branch's permissions match rules. Protecting a
+// branch always prevents its deletion; disabling removes every permission on
+// it. Enforcing for admins recreates managed permissions without exemptions.
+// Required status checks are not supported: Bitbucket Server's required
+// builds need build keys, which the rules don't carry.
+func (c *ServerClient) SetBranchProtection(branch string, rules ProtectionRules) error {
+	if rules.RequireStatusChecks {
+		return fmt.Errorf("requiring status checks is not supported on Bitbucket Server; configure required builds in the repository settings")
+	}
+
+	current, err := c.getRestrictions(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch protection: %w", err)
+	}
+
+	path := "/rest/branch-permissions/2.0" + c.repoPath() + "/restrictions"
+	existing := make(map[string]restriction)
+	for _, r := range current {
+		if !rules.Enabled {
+			if err := c.api.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, r.ID), nil, nil); err != nil {
+				return fmt.Errorf("failed to remove branch permission %s: %w", r.Type, err)
+			}
+			continue
+		}
+		existing[r.Type] = r
+	}
+	if !rules.Enabled {
+		return nil
+	}
+
+	want := map[string]bool{
+		restrictionNoDeletes:       true,
+		restrictionFastForwardOnly: !rules.AllowForcePush,
+		restrictionPullRequestOnly: rules.RequireReviews,
+	}
+	for _, restrictionType := range []string{restrictionNoDeletes, restrictionFastForwardOnly, restrictionPullRequestOnly} {
+		r, have := existing[restrictionType]
+
+		// Exemptions can't be edited away in place; replace the permission
+		if have && want[restrictionType] && rules.EnforceAdmins && r.exempts() {
+			if err := c.api.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, r.ID), nil, nil); err != nil {
+				return fmt.Errorf("failed to replace branch permission %s: %w", restrictionType, err)
+			}
+			have = false
+		}
+
+		var err error
+		switch {
+		case want[restrictionType] && !have:
+			err = c.api.do(http.MethodPost, path, newRestriction(restrictionType, branch), nil)
+		case !want[restrictionType] && have:
+			err = c.api.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, r.ID), nil, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update branch permission %s: %w", restrictionType, err)
+		}
+	}
+	return nil
+}
+
+// hasPermission reports whether the authenticated user holds permission
+// (REPO_READ, REPO_WRITE or REPO_ADMIN) on the repository
+func (c *ServerClient) hasPermission(permission string) (bool, error) {
+	query := url.Values{}
+	query.Set("projectkey", c.project)
+	query.Set("name", c.repo)
+	query.Set("permission", permission)
+
+	var page struct {
+		Values []struct {
+			Slug    string `json:"slug"`
+			Project struct {
+				Key string `json:"key"`
+			} `json:"project"`
+		} `json:"values"`
+	}
+	if err := c.api.do(http.MethodGet, "/rest/api/1.0/repos?"+query.Encode(), nil, &page); err != nil {
+		return false, fmt.Errorf("failed to get repository permissions: %w", err)
+	}
+	for _, r := range page.Values {
+		if strings.EqualFold(r.Slug, c.repo) && strings.EqualFold(r.Project.Key, c.project) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CheckPermissions returns the authenticated user's permissions on the repository
+func (c *ServerClient) CheckPermissions() (*RepositoryPermissions, error) {
+	perms := &RepositoryPermissions{}
+	var err error
+	if perms.Admin, err = c.hasPermission("REPO_ADMIN"); err != nil {
+		return nil, err
+	}
+	if perms.Admin {
+		perms.Push, perms.Pull = true, true
+		return perms, nil
+	}
+	if perms.Push, err = c.hasPermission("REPO_WRITE"); err != nil {
+		return nil, err
+	}
+	if perms.Push {
+		perms.Pull = true
+		return perms, nil
+	}
+	if perms.Pull, err = c.hasPermission("REPO_READ"); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// CanPush checks if authenticated user can push to the repository
+func (c *ServerClient) CanPush() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Push, nil
+}
+
+// CanAdmin checks if authenticated user has admin access
+func (c *ServerClient) CanAdmin() (bool, error) {
+	return c.hasPermission("REPO_ADMIN")
+}
+
+// TestConnection tests the Bitbucket Server API connection
+func (c *ServerClient) TestConnection() error {
+	if err := c.api.do(http.MethodGet, "/rest/api/1.0/application-properties", nil, nil); err != nil {
+		return fmt.Errorf("Bitbucket Server API connection test failed: %w", err)
+	}
+	return nil
+}
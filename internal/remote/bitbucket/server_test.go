@@ -0,0 +1,318 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestServerClient returns a Server client pointing at a fake API served by mux
+func newTestServerClient(t *testing.T, mux *http.ServeMux) *ServerClient {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &ServerClient{
+		api: &apiClient{
+			httpClient:    server.Client(),
+			baseURL:       server.URL,
+			authorization: "Bearer test_token",
+			ctx:           context.Background(),
+		},
+		project: "PROJ",
+		repo:    "testrepo",
+	}
+}
+
+func TestParseServerURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantBase    string
+		wantProject string
+		wantRepo    string
+		wantErr     bool
+	}{
+		{
+			name:     "https clone url",
+			url:      "https://bitbucket.corp.example/scm/proj/repo.git",
+			wantBase: "https://bitbucket.corp.example", wantProject: "PROJ", wantRepo: "repo",
+		},
+		{
+			name:     "https clone url with context path",
+			url:      "https://corp.example/bitbucket/scm/proj/repo.git",
+			wantBase: "https://corp.example/bitbucket", wantProject: "PROJ", wantRepo: "repo",
+		},
+		{
+			name:     "ssh url with port",
+			url:      "ssh://git@bitbucket.corp.example:7999/proj/repo.git",
+			wantBase: "https://bitbucket.corp.example", wantProject: "PROJ", wantRepo: "repo",
+		},
+		{
+			name:     "scp-like",
+			url:      "git@bitbucket.corp.example:proj/repo.git",
+			wantBase: "https://bitbucket.corp.example", wantProject: "PROJ", wantRepo: "repo",
+		},
+		{
+			name:     "personal repository",
+			url:      "https://bitbucket.corp.example/scm/~alice/repo.git",
+			wantBase: "https://bitbucket.corp.example", wantProject: "~alice", wantRepo: "repo",
+		},
+		{
+			name:     "browse url",
+			url:      "https://bitbucket.corp.example/projects/PROJ/repos/repo/browse",
+			wantBase: "https://bitbucket.corp.example", wantProject: "PROJ", wantRepo: "repo",
+		},
+		{
+			name:    "missing project",
+			url:     "https://bitbucket.corp.example/scm/repo.git",
+			wantErr: true,
+		},
+		{
+			name:    "not a url",
+			url:     "not-a-url",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, project, repo, err := parseServerURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServerURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if base != tt.wantBase || project != tt.wantProject || repo != tt.wantRepo {
+				t.Errorf("parseServerURL() = (%v, %v, %v), want (%v, %v, %v)",
+					base, project, repo, tt.wantBase, tt.wantProject, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestNewServerClientWithBaseURL(t *testing.T) {
+	t.Setenv("BITBUCKET_SERVER_TOKEN", "server_token")
+
+	client, err := NewServerClientWithBaseURL("ssh://git@stash.corp.example:7999/proj/repo.git", "https://stash.corp.example/bitbucket/")
+	if err != nil {
+		t.Fatalf("NewServerClientWithBaseURL() error = %v", err)
+	}
+	if client.api.baseURL != "https://stash.corp.example/bitbucket" {
+		t.Errorf("baseURL = %s", client.api.baseURL)
+	}
+	if client.api.authorization != "Bearer server_token" {
+		t.Errorf("authorization = %q, want the server token", client.api.authorization)
+	}
+	if client.GetOwner() != "PROJ" || client.GetRepo() != "repo" || client.GetPlatform() != "bitbucket-server" {
+		t.Errorf("client = %s/%s on %s", client.GetOwner(), client.GetRepo(), client.GetPlatform())
+	}
+}
+
+func TestServerDefaultBranch_Mock(t *testing.T) {
+	defaultBranch := "refs/heads/master"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/testrepo/branches/default", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]string{
+				"id":        defaultBranch,
+				"displayId": strings.TrimPrefix(defaultBranch, "refs/heads/"),
+			})
+		case http.MethodPut:
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			defaultBranch = body["id"]
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	client := newTestServerClient(t, mux)
+
+	if err := client.SetDefaultBranch("main"); err != nil {
+		t.Fatalf("SetDefaultBranch() error = %v", err)
+	}
+	if defaultBranch != "refs/heads/main" {
+		t.Errorf("PUT id = %s, want refs/heads/main", defaultBranch)
+	}
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("GetDefaultBranch() = %v, want main", branch)
+	}
+}
+
+// fakeServerRestrictions serves the branch permissions API from memory, one
+// restriction per page to exercise pagination
+type fakeServerRestrictions struct {
+	mu     sync.Mutex
+	nextID int
+	items  []restriction
+}
+
+func (f *fakeServerRestrictions) register(mux *http.ServeMux) {
+	const base = "/rest/branch-permissions/2.0/projects/PROJ/repos/testrepo/restrictions"
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			var matching []restriction
+			for _, item := range f.items {
+				if item.Matcher.ID == r.URL.Query().Get("matcherId") {
+					matching = append(matching, item)
+				}
+			}
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			resp := map[string]interface{}{"values": []restriction{}, "isLastPage": start+1 >= len(matching)}
+			if start < len(matching) {
+				resp["values"] = matching[start : start+1]
+				resp["nextPageStart"] = start + 1
+			}
+			json.NewEncoder(w).Encode(resp)
+		case http.MethodPost:
+			var item restriction
+			json.NewDecoder(r.Body).Decode(&item)
+			f.nextID++
+			item.ID = f.nextID
+			f.items = append(f.items, item)
+			json.NewEncoder(w).Encode(item)
+		}
+	})
+	mux.HandleFunc(base+"/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, base+"/"))
+		for i, item := range f.items {
+			if item.ID == id && r.Method == http.MethodDelete {
+				f.items = append(f.items[:i], f.items[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
+func (f *fakeServerRestrictions) add(restrictionType string, exemptUser bool) {
+	item := newRestriction(restrictionType, "main")
+	if exemptUser {
+		item.Users = []interface{}{map[string]string{"name": "ci"}}
+	}
+	f.nextID++
+	item.ID = f.nextID
+	f.items = append(f.items, item)
+}
+
+func TestServerGetBranchProtection_Mock(t *testing.T) {
+	fake := &fakeServerRestrictions{}
+	fake.add(restrictionPullRequestOnly, true)
+	fake.add(restrictionNoDeletes, false)
+
+	mux := http.NewServeMux()
+	fake.register(mux)
+	client := newTestServerClient(t, mux)
+
+	rules, err := client.GetBranchProtection("main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	want := ProtectionRules{Enabled: true, RequireReviews: true, AllowForcePush: true}
+	if *rules != want {
+		t.Errorf("GetBranchProtection() = %+v, want %+v", *rules, want)
+	}
+}
+
+func TestServerSetBranchProtection_Mock(t *testing.T) {
+	fake := &fakeServerRestrictions{}
+	fake.add(restrictionPullRequestOnly, true)
+	fake.add(restrictionReadOnly, false)
+
+	mux := http.NewServeMux()
+	fake.register(mux)
+	client := newTestServerClient(t, mux)
+
+	want := ProtectionRules{Enabled: true, RequireReviews: true, EnforceAdmins: true}
+	if err := client.SetBranchProtection("main", want); err != nil {
+		t.Fatalf("SetBranchProtection() error = %v", err)
+	}
+
+	rules, err := client.GetBranchProtection("main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if *rules != want {
+		t.Errorf("after set, GetBranchProtection() = %+v, want %+v", *rules, want)
+	}
+
+	types := make(map[string]bool)
+	for _, item := range fake.items {
+		types[item.Type] = true
+	}
+	for _, restrictionType := range []string{restrictionNoDeletes, restrictionFastForwardOnly, restrictionPullRequestOnly, restrictionReadOnly} {
+		if !types[restrictionType] {
+			t.Errorf("branch permission %s missing, have %v", restrictionType, types)
+		}
+	}
+
+	if err := client.SetBranchProtection("main", ProtectionRules{Enabled: true, RequireStatusChecks: true}); err == nil {
+		t.Error("SetBranchProtection() should reject required status checks")
+	}
+
+	// Disabling removes every permission on the branch
+	if err := client.SetBranchProtection("main", ProtectionRules{}); err != nil {
+		t.Fatalf("SetBranchProtection(disabled) error = %v", err)
+	}
+	if len(fake.items) != 0 {
+		t.Errorf("permissions left after disabling: %+v", fake.items)
+	}
+}
+
+func TestServerPermissions_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/repos", func(w http.ResponseWriter, r *http.Request) {
+		values := []map[string]interface{}{}
+		if r.URL.Query().Get("permission") != "REPO_ADMIN" {
+			values = append(values, map[string]interface{}{
+				"slug":    "testrepo",
+				"project": map[string]string{"key": "PROJ"},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"values": values, "isLastPage": true})
+	})
+
+	client := newTestServerClient(t, mux)
+
+	perms, err := client.CheckPermissions()
+	if err != nil {
+		t.Fatalf("CheckPermissions() error = %v", err)
+	}
+	if *perms != (RepositoryPermissions{Push: true, Pull: true}) {
+		t.Errorf("CheckPermissions() = %+v, want push and pull", *perms)
+	}
+}
+
+func TestServerResponseError_Message(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/testrepo/branches/default", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors": [{"message": "Authentication failed. Please check your credentials and try again."}]}`))
+	})
+
+	client := newTestServerClient(t, mux)
+
+	_, err := client.GetDefaultBranch()
+	if err == nil || !strings.Contains(err.Error(), "Authentication failed") {
+		t.Errorf("GetDefaultBranch() error = %v, want the API message", err)
+	}
+}
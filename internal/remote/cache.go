@@ -0,0 +1,332 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCachedBody bounds the size of a response body the cache will store
+const maxCachedBody = 4 << 20
+
+// Cache limits. Entries are keyed by credentials, so every new token (GitHub
+// App installation tokens last an hour) starts a fresh set; old entries are
+// pruned as new ones are written.
+const (
+	DefaultCacheMaxAge  = 7 * 24 * time.Hour
+	DefaultCacheMaxSize = 100 << 20
+
+	// cachePruneInterval is the least time between two scans of the directory
+	cachePruneInterval = 10 * time.Minute
+)
+
+// CacheTransport is an http.RoundTripper that caches GET responses on disk
+// and revalidates them with If-None-Match / If-Modified-Since. GitHub answers
+// an unchanged resource with 304 Not Modified, which doesn't count against
+// the rate limit, and the cached body is returned in its place. Entries are
+// keyed by the request's credentials as well as its URL, so tokens never see
+// each other's responses.
+type CacheTransport struct {
+	Next    http.RoundTripper // Defaults to http.DefaultTransport
+	Dir     string            // Where entries are stored
+	Metrics *MetricsCollector // Optional
+
+	// Entries not used for MaxAge are removed, then the least recently used
+	// ones until the directory is under MaxSize bytes
+	MaxAge  time.Duration // Defaults to DefaultCacheMaxAge
+	MaxSize int64         // Defaults to DefaultCacheMaxSize
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// cacheEntry is one stored response
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	StoredAt     time.Time   `json:"stored_at"`
+}
+
+// DefaultCacheDir returns ~/.githelper/cache/http, beside the config cache
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".githelper", "cache", "http"), nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	// Only plain GETs are cached; callers making their own conditional
+	// or partial requests are left alone
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry := t.load(key)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		t.recordHit()
+		t.touch(key)
+		cached := entry.response(req, resp.Header)
+		drain(resp)
+		return cached, nil
+	}
+	t.recordMiss()
+
+	switch {
+	case resp.StatusCode == http.StatusOK && cacheable(resp):
+		return t.store(key, req, resp)
+	case entry != nil && resp.StatusCode == http.StatusNotFound:
+		t.remove(key)
+	}
+	return resp, nil
+}
+
+// cacheKey identifies a request by everything that changes its response
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{
+		req.URL.String(),
+		req.Header.Get("Authorization"),
+		req.Header.Get("Accept"),
+		req.Header.Get("X-GitHub-Api-Version"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func cacheable(resp *http.Response) bool {
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	return !strings.Contains(resp.Header.Get("Cache-Control"), "no-store")
+}
+
+func (t *CacheTransport) path(key string) string {
+	return filepath.Join(t.Dir, key+".json")
+}
+
+// load returns the stored entry for key, or nil if there is none or it
+// can't be read
+func (t *CacheTransport) load(key string) *cacheEntry {
+	data, err := os.ReadFile(t.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// store saves a 200 response and returns an equivalent one with a fresh body.
+// Failing to write the cache never fails the request.
+func (t *CacheTransport) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if len(body) > maxCachedBody {
+		// Too big to cache; hand back the response with what was already read
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := cacheEntry{
+		URL:          req.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		StoredAt:     time.Now(),
+	}
+	if err := t.write(key, &entry); err != nil {
+		defaultLogger.Debugf("HTTP cache write failed for %s: %v", req.URL.Path, err)
+	}
+	t.prune(time.Now())
+	return resp, nil
+}
+
+func (t *CacheTransport) write(key string, entry *cacheEntry) error {
+	if err := os.MkdirAll(t.Dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(t.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.path(key))
+}
+
+func (t *CacheTransport) remove(key string) {
+	_ = os.Remove(t.path(key))
+}
+
+// touch marks an entry as used, so pruning keeps it
+func (t *CacheTransport) touch(key string) {
+	now := time.Now()
+	_ = os.Chtimes(t.path(key), now, now)
+}
+
+// prune removes entries unused for MaxAge, then the least recently used
+// ones until the cache is under MaxSize. It scans the directory at most once
+// per cachePruneInterval.
+func (t *CacheTransport) prune(now time.Time) {
+	t.mu.Lock()
+	if !t.lastPrune.IsZero() && now.Sub(t.lastPrune) < cachePruneInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastPrune = now
+	t.mu.Unlock()
+
+	maxAge := t.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+	maxSize := t.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultCacheMaxSize
+	}
+
+	dirEntries, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cacheFile
+	var total int64
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(t.Dir, de.Name())
+		if now.Sub(info.ModTime()) > maxAge {
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if total <= maxSize {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= maxSize {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
+
+// response rebuilds the stored 200 response. Rate limit headers are taken
+// from the 304 that revalidated it, since they describe the current state.
+func (e *cacheEntry) response(req *http.Request, fresh http.Header) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	for name, values := range fresh {
+		if strings.HasPrefix(strings.ToLower(name), "x-ratelimit-") {
+			header[name] = values
+		}
+	}
+	header.Set("X-Githelper-Cache", "hit")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func (t *CacheTransport) recordHit() {
+	if t.Metrics != nil {
+		t.Metrics.RecordCacheHit()
+	}
+}
+
+func (t *CacheTransport) recordMiss() {
+	if t.Metrics != nil {
+		t.Metrics.RecordCacheMiss()
+	}
+}
@@ -0,0 +1,211 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// etagServer serves a versioned resource with an ETag and answers matching
+// If-None-Match requests with 304
+type etagServer struct {
+	mu       sync.Mutex
+	version  int
+	calls    int
+	notMod   int
+	sawAuths []string
+}
+
+func newETagServer(t *testing.T) (*etagServer, *httptest.Server) {
+	s := &etagServer{version: 1}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *etagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.sawAuths = append(s.sawAuths, r.Header.Get("Authorization"))
+
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(5000-s.calls))
+	if r.Method == http.MethodGet && r.Header.Get("If-None-Match") == etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprintf(w, `{"version":%d}`, s.version)
+}
+
+func cachedGet(t *testing.T, rt http.RoundTripper, url, auth string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestCacheTransport_RevalidatesWithETag(t *testing.T) {
+	server, srv := newETagServer(t)
+	metrics := NewMetricsCollector()
+	rt := &CacheTransport{Next: &RetryTransport{Metrics: metrics}, Dir: t.TempDir(), Metrics: metrics}
+
+	_, first := cachedGet(t, rt, srv.URL+"/repos/o/r", "Bearer a")
+	resp, second := cachedGet(t, rt, srv.URL+"/repos/o/r", "Bearer a")
+
+	if first != `{"version":1}` || second != first {
+		t.Errorf("bodies = %q, %q; want the cached body returned on 304", first, second)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Githelper-Cache") != "hit" {
+		t.Errorf("status = %d, cache header = %q; want a 200 served from cache", resp.StatusCode, resp.Header.Get("X-Githelper-Cache"))
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "4998" {
+		t.Errorf("X-RateLimit-Remaining = %q, want the 304's value", resp.Header.Get("X-RateLimit-Remaining"))
+	}
+	if server.notMod != 1 {
+		t.Errorf("304s = %d, want 1", server.notMod)
+	}
+	if metrics.CacheHits != 1 || metrics.CacheMisses != 1 {
+		t.Errorf("hits = %d, misses = %d; want 1 and 1", metrics.CacheHits, metrics.CacheMisses)
+	}
+	if metrics.SuccessfulCalls != 2 || metrics.FailedCalls != 0 {
+		t.Errorf("successful = %d, failed = %d; want the 304 counted as a success", metrics.SuccessfulCalls, metrics.FailedCalls)
+	}
+	if !strings.Contains(metrics.Report(), "Cache hits: 1, misses: 1") {
+		t.Errorf("Report() = %q, want cache counts", metrics.Report())
+	}
+}
+
+func TestCacheTransport_ChangedResource(t *testing.T) {
+	server, srv := newETagServer(t)
+	rt := &CacheTransport{Dir: t.TempDir()}
+
+	cachedGet(t, rt, srv.URL, "")
+	server.mu.Lock()
+	server.version = 2
+	server.mu.Unlock()
+
+	if _, body := cachedGet(t, rt, srv.URL, ""); body != `{"version":2}` {
+		t.Errorf("body = %q, want the new version", body)
+	}
+	if _, body := cachedGet(t, rt, srv.URL, ""); body != `{"version":2}` {
+		t.Errorf("body = %q, want the new version from cache", body)
+	}
+	if server.notMod != 1 {
+		t.Errorf("304s = %d, want only the last request revalidated", server.notMod)
+	}
+}
+
+func TestCacheTransport_KeyedByCredentials(t *testing.T) {
+	server, srv := newETagServer(t)
+	metrics := NewMetricsCollector()
+	rt := &CacheTransport{Dir: t.TempDir(), Metrics: metrics}
+
+	cachedGet(t, rt, srv.URL, "Bearer a")
+	cachedGet(t, rt, srv.URL, "Bearer b")
+
+	if server.notMod != 0 || metrics.CacheHits != 0 {
+		t.Errorf("304s = %d, hits = %d; want another token's entry ignored", server.notMod, metrics.CacheHits)
+	}
+}
+
+func TestCacheTransport_PrunesOldEntries(t *testing.T) {
+	_, srv := newETagServer(t)
+	dir := t.TempDir()
+	now := time.Now()
+
+	// Entries left behind by expired tokens: one past MaxAge, and two recent
+	// ones that together push the cache over MaxSize
+	for name, age := range map[string]time.Duration{"expired": 30 * 24 * time.Hour, "older": 2 * time.Hour, "newer": time.Hour} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, make([]byte, 600), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rt := &CacheTransport{Dir: dir, MaxAge: 7 * 24 * time.Hour, MaxSize: 1000}
+	cachedGet(t, rt, srv.URL, "Bearer fresh")
+
+	for name, want := range map[string]bool{"expired": false, "older": false, "newer": true} {
+		_, err := os.Stat(filepath.Join(dir, name+".json"))
+		if got := err == nil; got != want {
+			t.Errorf("%s kept = %v, want %v", name, got, want)
+		}
+	}
+	if rt.load(cacheKey(newRequest(t, srv.URL, "Bearer fresh"))) == nil {
+		t.Error("new entry was pruned")
+	}
+}
+
+func newRequest(t *testing.T, url, auth string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", auth)
+	return req
+}
+
+func TestCacheTransport_SkipsNonGET(t *testing.T) {
+	server, srv := newETagServer(t)
+	dir := t.TempDir()
+	metrics := NewMetricsCollector()
+	rt := &CacheTransport{Dir: dir, Metrics: metrics}
+
+	for i := 0; i < 2; i++ {
+		resp, err := (&http.Client{Transport: rt}).Post(srv.URL, "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 || server.notMod != 0 || metrics.CacheMisses != 0 {
+		t.Errorf("entries = %d, 304s = %d, misses = %d; want POSTs passed straight through", len(entries), server.notMod, metrics.CacheMisses)
+	}
+}
+
+func TestCacheTransport_NotCacheable(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("unexpected conditional request")
+		}
+		w.Header().Set("ETag", `"x"`)
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, "secret")
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	rt := &CacheTransport{Dir: dir}
+	cachedGet(t, rt, srv.URL, "")
+	cachedGet(t, rt, srv.URL, "")
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 || calls != 2 {
+		t.Errorf("entries = %d, calls = %d; want no-store responses left uncached", len(entries), calls)
+	}
+}
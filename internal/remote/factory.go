@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lcgerke/githelper/internal/remote/bitbucket"
+	"github.com/lcgerke/githelper/internal/remote/gitea"
 	"github.com/lcgerke/githelper/internal/remote/github"
+	"github.com/lcgerke/githelper/internal/remote/gitlab"
 )
 
 // githubClientWrapper wraps github.Client to adapt ProtectionRules types
@@ -29,46 +32,205 @@ func (w *githubClientWrapper) GetBranchProtection(branch string) (*ProtectionRul
 	}, nil
 }
 
+// SetBranchProtection wraps the github client method to convert types
+func (w *githubClientWrapper) SetBranchProtection(branch string, rules ProtectionRules) error {
+	return w.Client.SetBranchProtection(branch, github.ProtectionRules(rules))
+}
+
+// gitlabClientWrapper wraps gitlab.Client to adapt ProtectionRules types
+type gitlabClientWrapper struct {
+	*gitlab.Client
+}
+
+// GetBranchProtection wraps the gitlab client method to convert types
+func (w *gitlabClientWrapper) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	glRules, err := w.Client.GetBranchProtection(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtectionRules{
+		Enabled:             glRules.Enabled,
+		RequireReviews:      glRules.RequireReviews,
+		RequireStatusChecks: glRules.RequireStatusChecks,
+		EnforceAdmins:       glRules.EnforceAdmins,
+		AllowForcePush:      glRules.AllowForcePush,
+	}, nil
+}
+
+// SetBranchProtection wraps the gitlab client method to convert types
+func (w *gitlabClientWrapper) SetBranchProtection(branch string, rules ProtectionRules) error {
+	return w.Client.SetBranchProtection(branch, gitlab.ProtectionRules(rules))
+}
+
+// giteaClientWrapper wraps gitea.Client to adapt ProtectionRules types
+type giteaClientWrapper struct {
+	*gitea.Client
+}
+
+// GetBranchProtection wraps the gitea client method to convert types
+func (w *giteaClientWrapper) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	gtRules, err := w.Client.GetBranchProtection(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtectionRules{
+		Enabled:             gtRules.Enabled,
+		RequireReviews:      gtRules.RequireReviews,
+		RequireStatusChecks: gtRules.RequireStatusChecks,
+		EnforceAdmins:       gtRules.EnforceAdmins,
+		AllowForcePush:      gtRules.AllowForcePush,
+	}, nil
+}
+
+// SetBranchProtection wraps the gitea client method to convert types
+func (w *giteaClientWrapper) SetBranchProtection(branch string, rules ProtectionRules) error {
+	return w.Client.SetBranchProtection(branch, gitea.ProtectionRules(rules))
+}
+
+// bitbucketClientWrapper wraps bitbucket.Client to adapt ProtectionRules types
+type bitbucketClientWrapper struct {
+	*bitbucket.Client
+}
+
+// GetBranchProtection wraps the bitbucket client method to convert types
+func (w *bitbucketClientWrapper) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	bbRules, err := w.Client.GetBranchProtection(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtectionRules{
+		Enabled:             bbRules.Enabled,
+		RequireReviews:      bbRules.RequireReviews,
+		RequireStatusChecks: bbRules.RequireStatusChecks,
+		EnforceAdmins:       bbRules.EnforceAdmins,
+		AllowForcePush:      bbRules.AllowForcePush,
+	}, nil
+}
+
+// SetBranchProtection wraps the bitbucket client method to convert types
+func (w *bitbucketClientWrapper) SetBranchProtection(branch string, rules ProtectionRules) error {
+	return w.Client.SetBranchProtection(branch, bitbucket.ProtectionRules(rules))
+}
+
+// bitbucketServerClientWrapper wraps bitbucket.ServerClient to adapt ProtectionRules types
+type bitbucketServerClientWrapper struct {
+	*bitbucket.ServerClient
+}
+
+// GetBranchProtection wraps the bitbucket server client method to convert types
+func (w *bitbucketServerClientWrapper) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	bbRules, err := w.ServerClient.GetBranchProtection(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtectionRules{
+		Enabled:             bbRules.Enabled,
+		RequireReviews:      bbRules.RequireReviews,
+		RequireStatusChecks: bbRules.RequireStatusChecks,
+		EnforceAdmins:       bbRules.EnforceAdmins,
+		AllowForcePush:      bbRules.AllowForcePush,
+	}, nil
+}
+
+// SetBranchProtection wraps the bitbucket server client method to convert types
+func (w *bitbucketServerClientWrapper) SetBranchProtection(branch string, rules ProtectionRules) error {
+	return w.ServerClient.SetBranchProtection(branch, bitbucket.ProtectionRules(rules))
+}
+
 // NewClient creates appropriate platform client based on remote URL
-// Automatically detects the platform (GitHub, GitLab, Bitbucket) and returns
-// the corresponding client implementation.
+// Automatically detects the platform (GitHub, GitLab, Gitea, Bitbucket Cloud
+// and Server) and returns the corresponding client implementation. Hosts
+// registered with RegisterHost take precedence over well-known hostnames.
 func NewClient(remoteURL string) (Platform, error) {
 	platform := detectPlatform(remoteURL)
+	hostCfg, _ := lookupHost(remoteURL)
 
 	switch platform {
 	case "github":
-		ghClient, err := github.NewClient(remoteURL)
+		ghClient, err := github.NewClientWithBaseURL(remoteURL, githubAPIURL(remoteURL, hostCfg), DefaultTransport())
 		if err != nil {
 			return nil, err
 		}
 		return &githubClientWrapper{Client: ghClient}, nil
 	case "gitlab":
-		return nil, fmt.Errorf("GitLab support not yet implemented")
+		glClient, err := gitlab.NewClientWithBaseURL(remoteURL, hostCfg.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		return &gitlabClientWrapper{Client: glClient}, nil
+	case "gitea":
+		gtClient, err := gitea.NewClientWithBaseURL(remoteURL, hostCfg.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		return &giteaClientWrapper{Client: gtClient}, nil
 	case "bitbucket":
-		return nil, fmt.Errorf("Bitbucket support not yet implemented")
+		bbClient, err := bitbucket.NewClientWithBaseURL(remoteURL, hostCfg.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		return &bitbucketClientWrapper{Client: bbClient}, nil
+	case "bitbucket-server":
+		bbsClient, err := bitbucket.NewServerClientWithBaseURL(remoteURL, hostCfg.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		return &bitbucketServerClientWrapper{ServerClient: bbsClient}, nil
 	default:
 		return nil, fmt.Errorf("unsupported platform: %s", platform)
 	}
 }
 
+// githubAPIURL returns the API base URL for a GitHub remote: empty for
+// github.com, otherwise the host mapping's api_url or the GitHub Enterprise
+// Server default https://<host>/api/v3/
+func githubAPIURL(remoteURL string, hostCfg HostConfig) string {
+	if hostCfg.APIURL != "" {
+		return hostCfg.APIURL
+	}
+	host, _ := splitRemoteHost(remoteURL)
+	if host == "" || host == "github.com" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/api/v3/", host)
+}
+
 // detectPlatform identifies the platform from remote URL
-// Supports GitHub, GitLab, and Bitbucket URLs
+// Registered hosts are checked first, then well-known GitHub, GitLab,
+// Gitea/Codeberg and Bitbucket hostnames, then Bitbucket Server's /scm/
+// clone path and SSH port 7999
 func detectPlatform(remoteURL string) string {
+	if cfg, ok := lookupHost(remoteURL); ok {
+		return cfg.Platform
+	}
+
 	switch {
 	case strings.Contains(remoteURL, "github.com"):
 		return "github"
 	case strings.Contains(remoteURL, "gitlab.com"):
 		return "gitlab"
+	case strings.Contains(remoteURL, "gitea.com"), strings.Contains(remoteURL, "codeberg.org"):
+		return "gitea"
 	case strings.Contains(remoteURL, "bitbucket.org"):
 		return "bitbucket"
+	case strings.Contains(remoteURL, "/scm/"), strings.Contains(remoteURL, ":7999/"):
+		return "bitbucket-server"
 	default:
 		return "unknown"
 	}
 }
 
 // IsPlatformSupported checks if a remote URL points to a supported platform
-// Currently only GitHub is fully supported
+// Currently GitHub, GitLab, Gitea/Forgejo and Bitbucket Cloud/Server are supported
 func IsPlatformSupported(remoteURL string) bool {
-	platform := detectPlatform(remoteURL)
-	return platform == "github" // Expand as more platforms are added
+	switch detectPlatform(remoteURL) {
+	case "github", "gitlab", "gitea", "bitbucket", "bitbucket-server":
+		return true
+	default:
+		return false
+	}
 }
@@ -42,6 +42,16 @@ func TestDetectPlatform(t *testing.T) {
 			url:  "git@bitbucket.org:owner/repo.git",
 			want: "bitbucket",
 		},
+		{
+			name: "bitbucket server clone url",
+			url:  "https://git.corp.example/scm/proj/repo.git",
+			want: "bitbucket-server",
+		},
+		{
+			name: "bitbucket server ssh url",
+			url:  "ssh://git@git.corp.example:7999/proj/repo.git",
+			want: "bitbucket-server",
+		},
 		{
 			name: "unknown url",
 			url:  "https://example.com/owner/repo.git",
@@ -71,14 +81,19 @@ func TestIsPlatformSupported(t *testing.T) {
 			want: true,
 		},
 		{
-			name: "gitlab not yet supported",
+			name: "gitlab supported",
 			url:  "https://gitlab.com/owner/repo.git",
-			want: false,
+			want: true,
 		},
 		{
-			name: "bitbucket not yet supported",
+			name: "bitbucket supported",
 			url:  "https://bitbucket.org/owner/repo.git",
-			want: false,
+			want: true,
+		},
+		{
+			name: "bitbucket server supported",
+			url:  "ssh://git@bitbucket.corp.example:7999/proj/repo.git",
+			want: true,
 		},
 		{
 			name: "unknown not supported",
@@ -110,28 +125,54 @@ func TestNewClient(t *testing.T) {
 
 	os.Setenv("GITHUB_TOKEN", "test_token")
 
+	originalGitLabToken := os.Getenv("GITLAB_TOKEN")
+	defer func() {
+		if originalGitLabToken != "" {
+			os.Setenv("GITLAB_TOKEN", originalGitLabToken)
+		} else {
+			os.Unsetenv("GITLAB_TOKEN")
+		}
+	}()
+
+	os.Setenv("GITLAB_TOKEN", "test_token")
+	t.Setenv("BITBUCKET_TOKEN", "test_token")
+
 	tests := []struct {
-		name       string
-		remoteURL  string
-		wantErr    bool
-		wantErrMsg string
+		name         string
+		remoteURL    string
+		wantErr      bool
+		wantErrMsg   string
+		wantPlatform string
 	}{
 		{
-			name:      "github url creates client",
-			remoteURL: "https://github.com/owner/repo.git",
-			wantErr:   false,
+			name:         "github url creates client",
+			remoteURL:    "https://github.com/owner/repo.git",
+			wantErr:      false,
+			wantPlatform: "github",
 		},
 		{
-			name:       "gitlab url returns error",
-			remoteURL:  "https://gitlab.com/owner/repo.git",
-			wantErr:    true,
-			wantErrMsg: "GitLab support not yet implemented",
+			name:         "gitlab url creates client",
+			remoteURL:    "https://gitlab.com/owner/repo.git",
+			wantErr:      false,
+			wantPlatform: "gitlab",
 		},
 		{
-			name:       "bitbucket url returns error",
-			remoteURL:  "https://bitbucket.org/owner/repo.git",
-			wantErr:    true,
-			wantErrMsg: "Bitbucket support not yet implemented",
+			name:         "gitlab subgroup url creates client",
+			remoteURL:    "git@gitlab.com:group/subgroup/repo.git",
+			wantErr:      false,
+			wantPlatform: "gitlab",
+		},
+		{
+			name:         "bitbucket url creates client",
+			remoteURL:    "https://bitbucket.org/owner/repo.git",
+			wantErr:      false,
+			wantPlatform: "bitbucket",
+		},
+		{
+			name:         "bitbucket server url creates client",
+			remoteURL:    "https://bitbucket.corp.example/scm/proj/repo.git",
+			wantErr:      false,
+			wantPlatform: "bitbucket-server",
 		},
 		{
 			name:       "unknown platform returns error",
@@ -167,8 +208,8 @@ func TestNewClient(t *testing.T) {
 
 			// Verify the platform interface is implemented
 			if !tt.wantErr && client != nil {
-				if client.GetPlatform() != "github" {
-					t.Errorf("NewClient() platform = %v, want github", client.GetPlatform())
+				if client.GetPlatform() != tt.wantPlatform {
+					t.Errorf("NewClient() platform = %v, want %v", client.GetPlatform(), tt.wantPlatform)
 				}
 			}
 		})
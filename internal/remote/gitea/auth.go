@@ -0,0 +1,119 @@
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenSource represents where the token was found
+type TokenSource string
+
+const (
+	SourceEnvVar        TokenSource = "GITEA_TOKEN"
+	SourceForgejoEnvVar TokenSource = "FORGEJO_TOKEN"
+	SourceTeaConfig     TokenSource = "~/.config/tea/config.yml"
+	SourceGitConfig     TokenSource = "git config gitea.token"
+)
+
+// TokenInfo contains token and its source
+type TokenInfo struct {
+	Token  string
+	Source TokenSource
+}
+
+// getGiteaToken attempts to find a Gitea/Forgejo token for host from multiple sources
+// Priority: GITEA_TOKEN env var > FORGEJO_TOKEN env var > tea CLI config > git config
+func getGiteaToken(host string) (string, error) {
+	info, err := getGiteaTokenInfo(host)
+	if err != nil {
+		return "", err
+	}
+	return info.Token, nil
+}
+
+// getGiteaTokenInfo returns token with source information (useful for diagnostics)
+func getGiteaTokenInfo(host string) (*TokenInfo, error) {
+	// 1. Try GITEA_TOKEN environment variable
+	if token := os.Getenv("GITEA_TOKEN"); token != "" {
+		return &TokenInfo{Token: token, Source: SourceEnvVar}, nil
+	}
+
+	// 2. Try FORGEJO_TOKEN (Forgejo instances)
+	if token := os.Getenv("FORGEJO_TOKEN"); token != "" {
+		return &TokenInfo{Token: token, Source: SourceForgejoEnvVar}, nil
+	}
+
+	// 3. Try tea CLI config (~/.config/tea/config.yml)
+	if token, err := readTeaConfigToken(host); err == nil && token != "" {
+		return &TokenInfo{Token: token, Source: SourceTeaConfig}, nil
+	}
+
+	// 4. Try git config (gitea.token)
+	if token, err := readGitConfigToken(); err == nil && token != "" {
+		return &TokenInfo{Token: token, Source: SourceGitConfig}, nil
+	}
+
+	return nil, fmt.Errorf("no Gitea token found for %s\n\n"+
+		"Please authenticate using one of:\n"+
+		"  1. Set GITEA_TOKEN environment variable\n"+
+		"  2. Run: tea login add --url https://%s\n"+
+		"  3. Run: git config --global gitea.token YOUR_TOKEN", host, host)
+}
+
+// readTeaConfigToken reads the token for host from tea CLI config
+func readTeaConfigToken(host string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configPath := filepath.Join(home, ".config", "tea", "config.yml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	var config struct {
+		Logins []struct {
+			URL     string `yaml:"url"`
+			Token   string `yaml:"token"`
+			SSHHost string `yaml:"ssh_host"`
+		} `yaml:"logins"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+
+	for _, login := range config.Logins {
+		if login.SSHHost == host {
+			return login.Token, nil
+		}
+		if u, err := url.Parse(login.URL); err == nil && (u.Host == host || u.Hostname() == host) {
+			return login.Token, nil
+		}
+	}
+
+	return "", fmt.Errorf("no token for %s in tea config", host)
+}
+
+// readGitConfigToken reads token from git config
+func readGitConfigToken() (string, error) {
+	cmd := exec.Command("git", "config", "--global", "gitea.token")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("git config gitea.token is empty")
+	}
+
+	return token, nil
+}
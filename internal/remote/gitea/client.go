@@ -0,0 +1,396 @@
+// Package gitea implements the remote.Platform interface on top of the
+// Gitea REST API (v1). Forgejo exposes the same API and is handled here too.
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// Client talks to the Gitea API and implements the Platform interface
+type Client struct {
+	httpClient *http.Client
+	baseURL    string // e.g. https://gitea.example.com/api/v1
+	token      string
+	owner      string
+	repo       string
+	ctx        context.Context
+}
+
+// ProtectionRules represents branch protection settings
+// This is a local copy to avoid import cycles
+type ProtectionRules struct {
+	Enabled             bool
+	RequireReviews      bool
+	RequireStatusChecks bool
+	EnforceAdmins       bool
+	AllowForcePush      bool
+}
+
+// RepositoryPermissions represents the user's permissions on a repository
+type RepositoryPermissions struct {
+	Admin bool `json:"admin"`
+	Push  bool `json:"push"`
+	Pull  bool `json:"pull"`
+}
+
+// ResponseError is returned when the Gitea API answers with a non-2xx status
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *ResponseError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d", e.Method, e.Path, e.StatusCode)
+}
+
+// NewClient creates a Gitea client from a remote URL
+// Supports: https://gitea.example.com/owner/repo.git, git@gitea.example.com:owner/repo.git,
+// ssh://git@gitea.example.com:2222/owner/repo.git
+// The API base URL is derived from the remote host (https://<host>/api/v1).
+func NewClient(remoteURL string) (*Client, error) {
+	return NewClientWithBaseURL(remoteURL, "")
+}
+
+// NewClientWithBaseURL creates a Gitea client using an explicit API base URL.
+// Self-hosted instances reached over SSH on a non-standard port usually need this,
+// since the web host cannot be derived from the SSH URL.
+// An empty baseURL falls back to https://<host>/api/v1.
+func NewClientWithBaseURL(remoteURL, baseURL string) (*Client, error) {
+	host, owner, repo, err := parseGiteaURL(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gitea URL: %w", err)
+	}
+
+	token, err := getGiteaToken(host)
+	if err != nil {
+		return nil, fmt.Errorf("Gitea authentication required: %w", err)
+	}
+
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/api/v1", host)
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: defaultHTTPTimeout},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		owner:      owner,
+		repo:       repo,
+		ctx:        context.Background(),
+	}, nil
+}
+
+// NewClientWithTimeout creates a client with custom timeout
+// Returns client and a cancel function that must be called when done
+func NewClientWithTimeout(remoteURL string, timeout time.Duration) (*Client, context.CancelFunc, error) {
+	client, err := NewClient(remoteURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	client.ctx = ctx
+
+	return client, cancel, nil
+}
+
+// parseGiteaURL extracts host, owner and repo from a remote URL.
+// SSH ports are dropped from the host, HTTPS ports are kept.
+func parseGiteaURL(remoteURL string) (host, owner, repo string, err error) {
+	var path string
+
+	switch {
+	case strings.Contains(remoteURL, "://"):
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", "", err
+		}
+		if u.Host == "" {
+			return "", "", "", fmt.Errorf("missing host in URL: %s", remoteURL)
+		}
+		host = u.Host
+		if u.Scheme == "ssh" || u.Scheme == "git+ssh" {
+			host = u.Hostname()
+		}
+		path = u.Path
+
+	case strings.Contains(remoteURL, "@") && strings.Contains(remoteURL, ":"):
+		// scp-like syntax: git@gitea.example.com:owner/repo.git
+		at := strings.Index(remoteURL, "@")
+		rest := remoteURL[at+1:]
+		colon := strings.Index(rest, ":")
+		host = rest[:colon]
+		path = rest[colon+1:]
+
+	default:
+		return "", "", "", fmt.Errorf("unrecognized remote URL format: %s", remoteURL)
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", "", fmt.Errorf("invalid Gitea path: %s", path)
+	}
+
+	// Gitea repositories are always owner/repo; anything before that is a sub-path install
+	return host, parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// GetOwner returns the repository owner
+func (c *Client) GetOwner() string {
+	return c.owner
+}
+
+// GetRepo returns the repository name
+func (c *Client) GetRepo() string {
+	return c.repo
+}
+
+// GetPlatform returns "gitea"
+func (c *Client) GetPlatform() string {
+	return "gitea"
+}
+
+// repoPath returns the API path of the repository
+func (c *Client) repoPath() string {
+	return "/repos/" + url.PathEscape(c.owner) + "/" + url.PathEscape(c.repo)
+}
+
+// repository is the subset of the Gitea repository resource githelper uses
+type repository struct {
+	DefaultBranch string                 `json:"default_branch"`
+	Permissions   *RepositoryPermissions `json:"permissions"`
+}
+
+// branchProtection is the Gitea branch protection resource
+type branchProtection struct {
+	RuleName                string `json:"rule_name"`
+	EnablePush              bool   `json:"enable_push"`
+	EnableStatusCheck       bool   `json:"enable_status_check"`
+	RequiredApprovals       int    `json:"required_approvals"`
+	EnableForcePush         bool   `json:"enable_force_push"`
+	BlockAdminMergeOverride bool   `json:"block_admin_merge_override"`
+}
+
+// do performs an API request and decodes a JSON response into out (if non-nil)
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = json.Unmarshal(data, &apiErr)
+		return &ResponseError{StatusCode: resp.StatusCode, Method: method, Path: path, Message: apiErr.Message}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isNotFound reports whether err is a 404 from the API
+func isNotFound(err error) bool {
+	if respErr, ok := err.(*ResponseError); ok {
+		return respErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// getRepository retrieves the repository resource
+func (c *Client) getRepository() (*repository, error) {
+	var r repository
+	if err := c.do(http.MethodGet, c.repoPath(), nil, &r); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	return &r, nil
+}
+
+// SetDefaultBranch updates the repository's default branch
+func (c *Client) SetDefaultBranch(branch string) error {
+	body := map[string]string{"default_branch": branch}
+	if err := c.do(http.MethodPatch, c.repoPath(), body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the current default branch
+func (c *Client) GetDefaultBranch() (string, error) {
+	r, err := c.getRepository()
+	if err != nil {
+		return "", err
+	}
+	return r.DefaultBranch, nil
+}
+
+// getBranchProtection returns the protection rule for branch, or nil if there is none
+func (c *Client) getBranchProtection(branch string) (*branchProtection, error) {
+	var bp branchProtection
+	err := c.do(http.MethodGet, c.repoPath()+"/branch_protections/"+url.PathEscape(branch), nil, &bp)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &bp, nil
+}
+
+// IsBranchProtected checks if a branch has protection rules
+func (c *Client) IsBranchProtected(branch string) (bool, error) {
+	bp, err := c.getBranchProtection(branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check branch protection: %w", err)
+	}
+	return bp != nil, nil
+}
+
+// GetBranchProtection returns detailed protection rules
+func (c *Client) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	bp, err := c.getBranchProtection(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+	if bp == nil {
+		return &ProtectionRules{Enabled: false}, nil
+	}
+
+	return &ProtectionRules{
+		Enabled:             true,
+		RequireReviews:      bp.RequiredApprovals > 0,
+		RequireStatusChecks: bp.EnableStatusCheck,
+		EnforceAdmins:       bp.BlockAdminMergeOverride,
+		AllowForcePush:      bp.EnableForcePush,
+	}, nil
+}
+
+// SetBranchProtection makes branch's protection rule match rules, creating or
+// editing the rule as needed. Disabling deletes the rule. An existing rule's
+// approval count is kept when reviews stay required.
+func (c *Client) SetBranchProtection(branch string, rules ProtectionRules) error {
+	current, err := c.getBranchProtection(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch protection: %w", err)
+	}
+
+	path := c.repoPath() + "/branch_protections"
+	if !rules.Enabled {
+		if current == nil {
+			return nil
+		}
+		if err := c.do(http.MethodDelete, path+"/"+url.PathEscape(current.RuleName), nil, nil); err != nil {
+			return fmt.Errorf("failed to remove branch protection: %w", err)
+		}
+		return nil
+	}
+
+	approvals := 0
+	if rules.RequireReviews {
+		approvals = 1
+		if current != nil && current.RequiredApprovals > 0 {
+			approvals = current.RequiredApprovals
+		}
+	}
+	body := map[string]interface{}{
+		"required_approvals":         approvals,
+		"enable_status_check":        rules.RequireStatusChecks,
+		"block_admin_merge_override": rules.EnforceAdmins,
+		"enable_force_push":          rules.AllowForcePush,
+	}
+
+	if current == nil {
+		body["rule_name"] = branch
+		err = c.do(http.MethodPost, path, body, nil)
+	} else {
+		err = c.do(http.MethodPatch, path+"/"+url.PathEscape(current.RuleName), body, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update branch protection: %w", err)
+	}
+	return nil
+}
+
+// CheckPermissions returns the authenticated user's permissions on the repository
+func (c *Client) CheckPermissions() (*RepositoryPermissions, error) {
+	r, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	if r.Permissions == nil {
+		return &RepositoryPermissions{}, nil
+	}
+	return r.Permissions, nil
+}
+
+// CanPush checks if authenticated user can push to the repository
+func (c *Client) CanPush() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Push, nil
+}
+
+// CanAdmin checks if authenticated user has admin access
+func (c *Client) CanAdmin() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Admin, nil
+}
+
+// TestConnection tests the Gitea API connection
+func (c *Client) TestConnection() error {
+	if err := c.do(http.MethodGet, "/user", nil, nil); err != nil {
+		return fmt.Errorf("Gitea API connection test failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestParseGiteaURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantHost  string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{
+			name:      "https with .git",
+			url:       "https://gitea.com/owner/repo.git",
+			wantHost:  "gitea.com",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "ssh scp-like",
+			url:       "git@codeberg.org:owner/repo.git",
+			wantHost:  "codeberg.org",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "ssh url with port",
+			url:       "ssh://git@git.corp.example:2222/owner/repo.git",
+			wantHost:  "git.corp.example",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "sub-path install",
+			url:       "https://corp.example/gitea/owner/repo.git",
+			wantHost:  "corp.example",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:    "missing owner",
+			url:     "https://gitea.com/repo.git",
+			wantErr: true,
+		},
+		{
+			name:    "not a url",
+			url:     "not-a-url",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, owner, repo, err := parseGiteaURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGiteaURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if host != tt.wantHost || owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("parseGiteaURL() = (%v, %v, %v), want (%v, %v, %v)",
+					host, owner, repo, tt.wantHost, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestNewClientWithBaseURL(t *testing.T) {
+	os.Setenv("GITEA_TOKEN", "test_token")
+	defer os.Unsetenv("GITEA_TOKEN")
+
+	client, err := NewClientWithBaseURL("ssh://git@git.corp.example:2222/owner/repo.git", "https://git.corp.example/api/v1")
+	if err != nil {
+		t.Fatalf("NewClientWithBaseURL() error = %v", err)
+	}
+
+	if client.GetPlatform() != "gitea" {
+		t.Errorf("GetPlatform() = %v, want gitea", client.GetPlatform())
+	}
+	if client.baseURL != "https://git.corp.example/api/v1" {
+		t.Errorf("baseURL = %v, want https://git.corp.example/api/v1", client.baseURL)
+	}
+
+	client, err = NewClient("https://gitea.com/owner/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.baseURL != "https://gitea.com/api/v1" {
+		t.Errorf("baseURL = %v, want https://gitea.com/api/v1", client.baseURL)
+	}
+}
+
+func TestSetBranchProtection(t *testing.T) {
+	os.Setenv("GITEA_TOKEN", "test_token")
+	defer os.Unsetenv("GITEA_TOKEN")
+
+	var existing *branchProtection
+	var method, path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if existing == nil {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(existing)
+			return
+		}
+		method, path = r.Method, r.URL.Path
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithBaseURL("https://gitea.com/owner/repo.git", srv.URL)
+	if err != nil {
+		t.Fatalf("NewClientWithBaseURL() error = %v", err)
+	}
+
+	// No rule yet: one is created
+	if err := client.SetBranchProtection("main", ProtectionRules{Enabled: true, RequireReviews: true}); err != nil {
+		t.Fatalf("SetBranchProtection() error = %v", err)
+	}
+	if method != http.MethodPost || path != "/repos/owner/repo/branch_protections" {
+		t.Errorf("request = %s %s, want POST to branch_protections", method, path)
+	}
+	if body["rule_name"] != "main" || body["required_approvals"] != float64(1) || body["enable_force_push"] != false {
+		t.Errorf("body = %v", body)
+	}
+
+	// Existing rule: edited in place, keeping its approval count
+	existing = &branchProtection{RuleName: "main", RequiredApprovals: 2, EnableForcePush: true}
+	if err := client.SetBranchProtection("main", ProtectionRules{Enabled: true, RequireReviews: true}); err != nil {
+		t.Fatalf("SetBranchProtection() error = %v", err)
+	}
+	if method != http.MethodPatch || path != "/repos/owner/repo/branch_protections/main" {
+		t.Errorf("request = %s %s, want PATCH of the rule", method, path)
+	}
+	if body["required_approvals"] != float64(2) || body["enable_force_push"] != false {
+		t.Errorf("body = %v", body)
+	}
+
+	// Disabled: the rule is deleted
+	if err := client.SetBranchProtection("main", ProtectionRules{}); err != nil {
+		t.Fatalf("SetBranchProtection() error = %v", err)
+	}
+	if method != http.MethodDelete || path != "/repos/owner/repo/branch_protections/main" {
+		t.Errorf("request = %s %s, want DELETE of the rule", method, path)
+	}
+}
+
+func TestGetGiteaTokenInfo_ForgejoToken(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "")
+	t.Setenv("FORGEJO_TOKEN", "forgejo_token")
+
+	info, err := getGiteaTokenInfo("codeberg.org")
+	if err != nil {
+		t.Fatalf("getGiteaTokenInfo() error = %v", err)
+	}
+	if info.Token != "forgejo_token" || info.Source != SourceForgejoEnvVar {
+		t.Errorf("getGiteaTokenInfo() = %+v, want FORGEJO_TOKEN", info)
+	}
+}
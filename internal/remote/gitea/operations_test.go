@@ -0,0 +1,157 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client pointing at a fake Gitea API served by mux
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &Client{
+		httpClient: server.Client(),
+		baseURL:    server.URL + "/api/v1",
+		token:      "test_token",
+		owner:      "testowner",
+		repo:       "testrepo",
+		ctx:        context.Background(),
+	}
+}
+
+func TestGetDefaultBranch_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testowner/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token test_token" {
+			t.Errorf("Authorization = %q, want token test_token", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode(map[string]string{"default_branch": "main"})
+	})
+
+	client := newTestClient(t, mux)
+
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("GetDefaultBranch() = %v, want main", branch)
+	}
+}
+
+func TestSetDefaultBranch_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testowner/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %s, want PATCH", r.Method)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if body["default_branch"] != "develop" {
+			t.Errorf("default_branch = %v, want develop", body["default_branch"])
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"default_branch": "develop"})
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.SetDefaultBranch("develop"); err != nil {
+		t.Fatalf("SetDefaultBranch() error = %v", err)
+	}
+}
+
+func TestGetBranchProtection_Mock(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   map[string]interface{}
+		want   ProtectionRules
+	}{
+		{
+			name:   "not protected",
+			status: http.StatusNotFound,
+			body:   map[string]interface{}{"message": "not found"},
+			want:   ProtectionRules{},
+		},
+		{
+			name:   "reviews and status checks",
+			status: http.StatusOK,
+			body: map[string]interface{}{
+				"rule_name":           "main",
+				"required_approvals":  1,
+				"enable_status_check": true,
+			},
+			want: ProtectionRules{Enabled: true, RequireReviews: true, RequireStatusChecks: true},
+		},
+		{
+			name:   "admins blocked, force push allowed",
+			status: http.StatusOK,
+			body: map[string]interface{}{
+				"rule_name":                  "main",
+				"enable_force_push":          true,
+				"block_admin_merge_override": true,
+			},
+			want: ProtectionRules{Enabled: true, EnforceAdmins: true, AllowForcePush: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/repos/testowner/testrepo/branch_protections/main", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(tt.body)
+			})
+
+			client := newTestClient(t, mux)
+
+			rules, err := client.GetBranchProtection("main")
+			if err != nil {
+				t.Fatalf("GetBranchProtection() error = %v", err)
+			}
+			if *rules != tt.want {
+				t.Errorf("GetBranchProtection() = %+v, want %+v", *rules, tt.want)
+			}
+
+			protected, err := client.IsBranchProtected("main")
+			if err != nil {
+				t.Fatalf("IsBranchProtected() error = %v", err)
+			}
+			if protected != tt.want.Enabled {
+				t.Errorf("IsBranchProtected() = %v, want %v", protected, tt.want.Enabled)
+			}
+		})
+	}
+}
+
+func TestPermissions_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/testowner/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"default_branch": "main",
+			"permissions":    map[string]bool{"admin": false, "push": true, "pull": true},
+		})
+	})
+
+	client := newTestClient(t, mux)
+
+	canPush, err := client.CanPush()
+	if err != nil || !canPush {
+		t.Errorf("CanPush() = %v, %v, want true, nil", canPush, err)
+	}
+
+	canAdmin, err := client.CanAdmin()
+	if err != nil || canAdmin {
+		t.Errorf("CanAdmin() = %v, %v, want false, nil", canAdmin, err)
+	}
+}
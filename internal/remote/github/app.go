@@ -0,0 +1,229 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultAPIURL is the GitHub REST API used to exchange app tokens
+const DefaultAPIURL = "https://api.github.com/"
+
+const (
+	// appJWTLifetime is how long an app JWT is valid; GitHub allows at most 10 minutes
+	appJWTLifetime = 9 * time.Minute
+	// appClockSkew backdates the JWT so a slightly fast GitHub clock accepts it
+	appClockSkew = 60 * time.Second
+	// appTokenRefreshMargin renews the installation token this long before it expires
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppConfig identifies a GitHub App installation to authenticate as
+type AppConfig struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte // PEM-encoded RSA key
+	APIURL         string // Defaults to DefaultAPIURL
+}
+
+// AppConfigFromEnv reads GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, and the
+// app's private key from GITHUB_APP_PRIVATE_KEY_FILE (or GITHUB_APP_PRIVATE_KEY,
+// the PEM itself). GITHUB_API_URL overrides the API endpoint. It returns nil
+// if no app is configured.
+func AppConfigFromEnv() (*AppConfig, error) {
+	appID := os.Getenv("GITHUB_APP_ID")
+	if appID == "" {
+		return nil, nil
+	}
+
+	cfg := &AppConfig{APIURL: os.Getenv("GITHUB_API_URL")}
+
+	var err error
+	if cfg.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_ID %q: must be a number", appID)
+	}
+
+	installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID")
+	if installationID == "" {
+		return nil, fmt.Errorf("GITHUB_APP_ID is set but GITHUB_APP_INSTALLATION_ID is not")
+	}
+	if cfg.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_INSTALLATION_ID %q: must be a number", installationID)
+	}
+
+	if path := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"); path != "" {
+		if cfg.PrivateKey, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+	} else if key := os.Getenv("GITHUB_APP_PRIVATE_KEY"); key != "" {
+		cfg.PrivateKey = []byte(key)
+	} else {
+		return nil, fmt.Errorf("GITHUB_APP_ID is set but neither GITHUB_APP_PRIVATE_KEY_FILE nor GITHUB_APP_PRIVATE_KEY is")
+	}
+
+	return cfg, nil
+}
+
+// appTokenSource mints installation tokens: it signs a JWT with the app's
+// private key and exchanges it for a token scoped to one installation
+type appTokenSource struct {
+	cfg        AppConfig
+	key        *rsa.PrivateKey
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewAppTokenSource returns a token source for a GitHub App installation.
+// Tokens are cached and replaced shortly before they expire, so a
+// long-running command never sends an expired token.
+func NewAppTokenSource(cfg AppConfig) (oauth2.TokenSource, error) {
+	src, err := newAppTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSourceWithExpiry(nil, src, appTokenRefreshMargin), nil
+}
+
+func newAppTokenSource(cfg AppConfig) (*appTokenSource, error) {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	if !strings.HasSuffix(cfg.APIURL, "/") {
+		cfg.APIURL += "/"
+	}
+
+	key, err := parseAppPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appTokenSource{
+		cfg:        cfg,
+		key:        key,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}, nil
+}
+
+// parseAppPrivateKey accepts the PKCS#1 key GitHub generates, or PKCS#8
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM-encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
+
+// Token exchanges a fresh app JWT for an installation token
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.signJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.cfg.APIURL, s.cfg.InstallationID)
+	ctx, cancel := context.WithTimeout(context.Background(), s.httpClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub App token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("GitHub App token exchange failed for installation %d: %s %s",
+			s.cfg.InstallationID, resp.Status, body.Message)
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid GitHub App token response: %w", err)
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("GitHub App token exchange returned no token")
+	}
+
+	return &oauth2.Token{AccessToken: result.Token, TokenType: "Bearer", Expiry: result.ExpiresAt}, nil
+}
+
+// signJWT builds the RS256 JWT that authenticates as the app itself
+func (s *appTokenSource) signJWT() (string, error) {
+	now := s.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.cfg.AppID, 10),
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// appTokenInfo returns the GitHub App candidate for the token chain, or nil
+// if no app is configured
+func appTokenInfo() (*TokenInfo, error) {
+	cfg, err := AppConfigFromEnv()
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+
+	ts, err := NewAppTokenSource(*cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenInfo{
+		Source:      SourceGitHubApp,
+		TokenSource: ts,
+		App:         cfg,
+	}, nil
+}
@@ -0,0 +1,315 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAppAPI verifies app JWTs and issues installation tokens for installation 42
+type fakeAppAPI struct {
+	t   *testing.T
+	key *rsa.PublicKey
+
+	mu        sync.Mutex
+	issued    int
+	tokenTTL  time.Duration
+	lastToken string
+}
+
+func newFakeAppAPI(t *testing.T, key *rsa.PrivateKey) (*fakeAppAPI, *httptest.Server) {
+	f := &fakeAppAPI{t: t, key: &key.PublicKey, tokenTTL: time.Hour}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeAppAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+		if err := f.verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"message":%q}`, err.Error())
+			return
+		}
+		f.issued++
+		f.lastToken = fmt.Sprintf("ghs_installation_%d", f.issued)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      f.lastToken,
+			"expires_at": time.Now().Add(f.tokenTTL).UTC().Format(time.RFC3339),
+		})
+
+	case r.URL.Path == "/repos/owner/repo":
+		if r.Header.Get("Authorization") != "Bearer "+f.lastToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"default_branch":"main"}`)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeAppAPI) verifyJWT(jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(f.key, crypto.SHA256, digest[:], sig); err != nil {
+		return fmt.Errorf("bad signature")
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if claims.Iss != "7" || claims.Iat > now || claims.Exp <= now || claims.Exp-claims.Iat > 600 {
+		return fmt.Errorf("bad claims %+v", claims)
+	}
+	return nil
+}
+
+func (f *fakeAppAPI) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issued
+}
+
+func testAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// clearTokenEnv isolates the token chain from the machine's own credentials
+func clearTokenEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN", "GITHUB_APP_ID", "GITHUB_APP_INSTALLATION_ID",
+		"GITHUB_APP_PRIVATE_KEY", "GITHUB_APP_PRIVATE_KEY_FILE", "GITHUB_API_URL"} {
+		t.Setenv(name, "")
+	}
+}
+
+// setAppEnv configures app 7, installation 42, against the fake API
+func setAppEnv(t *testing.T, keyPEM []byte, apiURL string) {
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_APP_ID", "7")
+	t.Setenv("GITHUB_APP_INSTALLATION_ID", "42")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY_FILE", keyFile)
+	t.Setenv("GITHUB_API_URL", apiURL)
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, keyPEM := testAppKey(t)
+	fake, srv := newFakeAppAPI(t, key)
+
+	ts, err := NewAppTokenSource(AppConfig{AppID: 7, InstallationID: 42, PrivateKey: keyPEM, APIURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewAppTokenSource() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token.AccessToken != "ghs_installation_1" {
+			t.Errorf("Token() = %s, want the cached installation token", token.AccessToken)
+		}
+	}
+	if n := fake.count(); n != 1 {
+		t.Errorf("issued %d installation tokens, want 1", n)
+	}
+}
+
+func TestAppTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	key, keyPEM := testAppKey(t)
+	fake, srv := newFakeAppAPI(t, key)
+	fake.tokenTTL = 2 * time.Minute // Inside the refresh margin
+
+	ts, err := NewAppTokenSource(AppConfig{AppID: 7, InstallationID: 42, PrivateKey: keyPEM, APIURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewAppTokenSource() error = %v", err)
+	}
+
+	first, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	second, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if first.AccessToken == second.AccessToken {
+		t.Error("token close to expiry was reused instead of refreshed")
+	}
+}
+
+func TestAppTokenSource_Rejected(t *testing.T) {
+	_, keyPEM := testAppKey(t)
+	other, _ := testAppKey(t)
+	_, srv := newFakeAppAPI(t, other)
+
+	ts, err := NewAppTokenSource(AppConfig{AppID: 7, InstallationID: 42, PrivateKey: keyPEM, APIURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewAppTokenSource() error = %v", err)
+	}
+	if _, err := ts.Token(); err == nil || !strings.Contains(err.Error(), "bad signature") {
+		t.Errorf("Token() error = %v, want rejection", err)
+	}
+}
+
+func TestNewAppTokenSource_BadKey(t *testing.T) {
+	if _, err := NewAppTokenSource(AppConfig{AppID: 7, InstallationID: 42, PrivateKey: []byte("not a key")}); err == nil {
+		t.Error("NewAppTokenSource() accepted a key that isn't PEM")
+	}
+}
+
+func TestAppConfigFromEnv(t *testing.T) {
+	_, keyPEM := testAppKey(t)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantNil bool
+		wantErr string
+	}{
+		{name: "not configured", env: map[string]string{}, wantNil: true},
+		{name: "inline key", env: map[string]string{"GITHUB_APP_ID": "7", "GITHUB_APP_INSTALLATION_ID": "42", "GITHUB_APP_PRIVATE_KEY": string(keyPEM)}},
+		{name: "missing installation", env: map[string]string{"GITHUB_APP_ID": "7", "GITHUB_APP_PRIVATE_KEY": string(keyPEM)}, wantErr: "GITHUB_APP_INSTALLATION_ID"},
+		{name: "missing key", env: map[string]string{"GITHUB_APP_ID": "7", "GITHUB_APP_INSTALLATION_ID": "42"}, wantErr: "GITHUB_APP_PRIVATE_KEY"},
+		{name: "bad app id", env: map[string]string{"GITHUB_APP_ID": "my-app", "GITHUB_APP_INSTALLATION_ID": "42"}, wantErr: "must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearTokenEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := AppConfigFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AppConfigFromEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AppConfigFromEnv() error = %v", err)
+			}
+			if (cfg == nil) != tt.wantNil {
+				t.Errorf("AppConfigFromEnv() = %+v, wantNil %v", cfg, tt.wantNil)
+			}
+			if cfg != nil && (cfg.AppID != 7 || cfg.InstallationID != 42) {
+				t.Errorf("AppConfigFromEnv() = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestTokenChain_GitHubApp(t *testing.T) {
+	key, keyPEM := testAppKey(t)
+	_, srv := newFakeAppAPI(t, key)
+
+	clearTokenEnv(t)
+	setAppEnv(t, keyPEM, srv.URL)
+
+	info, err := getGitHubTokenInfo()
+	if err != nil {
+		t.Fatalf("getGitHubTokenInfo() error = %v", err)
+	}
+	if info.Source != SourceGitHubApp || info.TokenSource == nil {
+		t.Errorf("getGitHubTokenInfo() = %+v, want the GitHub App", info)
+	}
+
+	// An explicit token in the environment still takes priority
+	t.Setenv("GITHUB_TOKEN", "ghp_explicit")
+	if info, _ := getGitHubTokenInfo(); info.Source != SourceEnvVar {
+		t.Errorf("source = %s, want %s", info.Source, SourceEnvVar)
+	}
+	if candidates := TokenCandidates(); len(candidates) != 2 || candidates[1].Source != SourceGitHubApp {
+		t.Errorf("TokenCandidates() = %+v, want env token then app", candidates)
+	}
+}
+
+func TestTokenChain_BrokenAppDoesNotFallBack(t *testing.T) {
+	clearTokenEnv(t)
+	t.Setenv("GITHUB_APP_ID", "7")
+
+	if _, err := getGitHubTokenInfo(); err == nil || !strings.Contains(err.Error(), "GITHUB_APP_INSTALLATION_ID") {
+		t.Errorf("getGitHubTokenInfo() error = %v, want the app configuration error", err)
+	}
+}
+
+func TestNewClient_GitHubApp(t *testing.T) {
+	key, keyPEM := testAppKey(t)
+	fake, srv := newFakeAppAPI(t, key)
+
+	clearTokenEnv(t)
+	setAppEnv(t, keyPEM, srv.URL)
+
+	client, err := NewClient("git@github.com:owner/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("GetDefaultBranch() = %s", branch)
+	}
+
+	if got := client.AuthSource(); got != "GitHub App 7 (installation 42)" {
+		t.Errorf("AuthSource() = %q", got)
+	}
+	expiry, err := client.AuthExpiry()
+	if err != nil || time.Until(expiry) < 50*time.Minute {
+		t.Errorf("AuthExpiry() = %v, %v", expiry, err)
+	}
+	if n := fake.count(); n != 1 {
+		t.Errorf("issued %d installation tokens, want 1", n)
+	}
+}
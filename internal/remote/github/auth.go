@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,53 +16,93 @@ type TokenSource string
 
 const (
 	SourceEnvVar    TokenSource = "GITHUB_TOKEN"
+	SourceGHToken   TokenSource = "GH_TOKEN"
 	SourceGhConfig  TokenSource = "~/.config/gh/hosts.yml"
 	SourceGitConfig TokenSource = "git config github.token"
+	SourceGitHubApp TokenSource = "GitHub App"
 )
 
 // TokenInfo contains token and its source
 type TokenInfo struct {
 	Token  string
 	Source TokenSource
+
+	// GitHub App credentials have no fixed token; TokenSource issues
+	// short-lived installation tokens instead, and Token is empty
+	TokenSource oauth2.TokenSource
+	App         *AppConfig
+}
+
+// oauth2Source returns a token source for the credential
+func (i *TokenInfo) oauth2Source() oauth2.TokenSource {
+	if i.TokenSource != nil {
+		return i.TokenSource
+	}
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: i.Token})
+}
+
+// getGitHubTokenInfo finds GitHub credentials from multiple sources
+// Priority: GITHUB_TOKEN env var > GH_TOKEN env var > GitHub App > gh CLI config > git config
+func getGitHubTokenInfo() (*TokenInfo, error) {
+	// An app that is configured but unusable is an error rather than a reason
+	// to fall back to a stored PAT
+	if _, err := appTokenInfo(); err != nil {
+		return nil, err
+	}
+
+	if candidates := TokenCandidates(); len(candidates) > 0 {
+		return &candidates[0], nil
+	}
+
+	return nil, fmt.Errorf("no GitHub token found\n\n" +
+		"Please authenticate using one of:\n" +
+		"  1. Set GITHUB_TOKEN environment variable\n" +
+		"  2. Set GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, and GITHUB_APP_PRIVATE_KEY_FILE\n" +
+		"  3. Run: gh auth login\n" +
+		"  4. Run: git config --global github.token YOUR_TOKEN")
 }
 
-// getGitHubToken attempts to find GitHub token from multiple sources
-// Priority: GITHUB_TOKEN env var > GH_TOKEN env var > gh CLI config > git config
-func getGitHubToken() (string, error) {
+// ResolveTokenSource returns the credential the resolution chain picks, as a
+// token source for API clients built outside this package
+func ResolveTokenSource() (oauth2.TokenSource, TokenSource, error) {
 	info, err := getGitHubTokenInfo()
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
-	return info.Token, nil
+	return info.oauth2Source(), info.Source, nil
 }
 
-// getGitHubTokenInfo returns token with source information (useful for diagnostics)
-func getGitHubTokenInfo() (*TokenInfo, error) {
+// TokenCandidates returns every token the resolution chain can see, in
+// priority order; the first one is the token that is used
+func TokenCandidates() []TokenInfo {
+	var candidates []TokenInfo
+
 	// 1. Try GITHUB_TOKEN environment variable
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return &TokenInfo{Token: token, Source: SourceEnvVar}, nil
+		candidates = append(candidates, TokenInfo{Token: token, Source: SourceEnvVar})
 	}
 
 	// 2. Try GH_TOKEN (alternative env var)
 	if token := os.Getenv("GH_TOKEN"); token != "" {
-		return &TokenInfo{Token: token, Source: SourceEnvVar}, nil
+		candidates = append(candidates, TokenInfo{Token: token, Source: SourceGHToken})
 	}
 
-	// 3. Try gh CLI config (~/.config/gh/hosts.yml)
+	// 3. Try a GitHub App installation
+	if app, err := appTokenInfo(); err == nil && app != nil {
+		candidates = append(candidates, *app)
+	}
+
+	// 4. Try gh CLI config (~/.config/gh/hosts.yml)
 	if token, err := readGhConfigToken(); err == nil && token != "" {
-		return &TokenInfo{Token: token, Source: SourceGhConfig}, nil
+		candidates = append(candidates, TokenInfo{Token: token, Source: SourceGhConfig})
 	}
 
-	// 4. Try git config (github.token)
+	// 5. Try git config (github.token)
 	if token, err := readGitConfigToken(); err == nil && token != "" {
-		return &TokenInfo{Token: token, Source: SourceGitConfig}, nil
+		candidates = append(candidates, TokenInfo{Token: token, Source: SourceGitConfig})
 	}
 
-	return nil, fmt.Errorf("no GitHub token found\n\n" +
-		"Please authenticate using one of:\n" +
-		"  1. Set GITHUB_TOKEN environment variable\n" +
-		"  2. Run: gh auth login\n" +
-		"  3. Run: git config --global github.token YOUR_TOKEN")
+	return candidates
 }
 
 // readGhConfigToken reads token from gh CLI config
@@ -18,6 +18,7 @@ type Client struct {
 	owner  string
 	repo   string
 	ctx    context.Context
+	auth   *TokenInfo
 }
 
 // ProtectionRules represents branch protection settings
@@ -33,25 +34,57 @@ type ProtectionRules struct {
 // NewClient creates a GitHub client from a remote URL
 // Supports: https://github.com/owner/repo.git, git@github.com:owner/repo.git
 func NewClient(remoteURL string) (*Client, error) {
-	owner, repo, err := parseGitHubURL(remoteURL)
+	return NewClientWithTransport(remoteURL, nil)
+}
+
+// NewClientWithTransport creates a GitHub client whose API requests go
+// through transport (nil means http.DefaultTransport)
+func NewClientWithTransport(remoteURL string, transport http.RoundTripper) (*Client, error) {
+	return NewClientWithBaseURL(remoteURL, "", transport)
+}
+
+// NewClientWithBaseURL creates a client for a GitHub Enterprise Server
+// repository whose API is served at apiURL (e.g. https://ghe.corp.example/api/v3/).
+// The remote may then be on any host. An empty apiURL means github.com.
+func NewClientWithBaseURL(remoteURL, apiURL string, transport http.RoundTripper) (*Client, error) {
+	var owner, repo string
+	var err error
+	if apiURL == "" {
+		owner, repo, err = parseGitHubURL(remoteURL)
+	} else {
+		owner, repo, err = parseEnterpriseURL(remoteURL)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid GitHub URL: %w", err)
 	}
 
-	token, err := getGitHubToken()
+	auth, err := getGitHubTokenInfo()
 	if err != nil {
 		return nil, fmt.Errorf("GitHub authentication required: %w", err)
 	}
 
 	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
+	base := ctx
+	if transport != nil {
+		base = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+	}
+	tc := oauth2.NewClient(base, auth.oauth2Source())
+
+	client := github.NewClient(tc)
+	if apiURL != "" {
+		// Uploads go to <host>/api/uploads/, which go-github derives from the base URL
+		client, err = client.WithEnterpriseURLs(apiURL, apiURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub API URL %s: %w", apiURL, err)
+		}
+	}
 
 	return &Client{
-		client: github.NewClient(tc),
+		client: client,
 		owner:  owner,
 		repo:   repo,
 		ctx:    ctx,
+		auth:   auth,
 	}, nil
 }
 
@@ -104,6 +137,34 @@ func parseGitHubURL(remoteURL string) (owner, repo string, err error) {
 	return parts[0], parts[1], nil
 }
 
+// parseEnterpriseURL extracts owner and repo from a remote URL on any host:
+// https://host/owner/repo.git, ssh://git@host[:port]/owner/repo.git or
+// git@host:owner/repo.git
+func parseEnterpriseURL(remoteURL string) (owner, repo string, err error) {
+	var path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", err
+		}
+		path = u.Path
+	} else {
+		at := strings.Index(remoteURL, "@")
+		colon := strings.Index(remoteURL, ":")
+		if colon <= at+1 {
+			return "", "", fmt.Errorf("unrecognized remote URL: %s", remoteURL)
+		}
+		path = remoteURL[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid GitHub path: %s", path)
+	}
+	return parts[0], parts[1], nil
+}
+
 // GetOwner returns the repository owner
 func (c *Client) GetOwner() string {
 	return c.owner
@@ -114,6 +175,30 @@ func (c *Client) GetRepo() string {
 	return c.repo
 }
 
+// AuthSource describes where the client's credentials came from
+func (c *Client) AuthSource() string {
+	if c.auth == nil {
+		return ""
+	}
+	if app := c.auth.App; app != nil {
+		return fmt.Sprintf("%s %d (installation %d)", SourceGitHubApp, app.AppID, app.InstallationID)
+	}
+	return string(c.auth.Source)
+}
+
+// AuthExpiry returns when the client's current token expires, fetching one
+// if needed. It is the zero time for tokens without a known expiry.
+func (c *Client) AuthExpiry() (time.Time, error) {
+	if c.auth == nil || c.auth.TokenSource == nil {
+		return time.Time{}, nil
+	}
+	token, err := c.auth.TokenSource.Token()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return token.Expiry, nil
+}
+
 // GetPlatform returns "github"
 func (c *Client) GetPlatform() string {
 	return "github"
@@ -177,6 +262,107 @@ func (c *Client) GetBranchProtection(branch string) (*ProtectionRules, error) {
 	}, nil
 }
 
+// SetBranchProtection makes branch's protection match rules. Settings rules
+// doesn't cover (required status check names, review counts, push
+// restrictions, ...) are carried over from the current protection, since
+// GitHub replaces the whole protection on update. Disabling removes it.
+func (c *Client) SetBranchProtection(branch string, rules ProtectionRules) error {
+	if !rules.Enabled {
+		resp, err := c.client.Repositories.RemoveBranchProtection(c.ctx, c.owner, c.repo, branch)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to remove branch protection: %w", err)
+		}
+		return nil
+	}
+
+	current, resp, err := c.client.Repositories.GetBranchProtection(c.ctx, c.owner, c.repo, branch)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("failed to get branch protection: %w", err)
+		}
+		current = &github.Protection{}
+	}
+
+	req := &github.ProtectionRequest{
+		EnforceAdmins:    rules.EnforceAdmins,
+		AllowForcePushes: github.Bool(rules.AllowForcePush),
+	}
+
+	var statusChecks *statusChecksRequest
+	if rules.RequireStatusChecks {
+		// Checks configured through the legacy contexts field are carried over as checks
+		statusChecks = &statusChecksRequest{Strict: true, Checks: []*github.RequiredStatusCheck{}}
+		if checks := current.GetRequiredStatusChecks(); checks != nil {
+			statusChecks.Strict = checks.Strict
+			if len(checks.Checks) > 0 {
+				statusChecks.Checks = checks.Checks
+			} else {
+				for _, context := range checks.Contexts {
+					statusChecks.Checks = append(statusChecks.Checks, &github.RequiredStatusCheck{Context: context})
+				}
+			}
+		}
+	}
+
+	if rules.RequireReviews {
+		req.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{RequiredApprovingReviewCount: 1}
+		if reviews := current.GetRequiredPullRequestReviews(); reviews != nil {
+			req.RequiredPullRequestReviews.DismissStaleReviews = reviews.DismissStaleReviews
+			req.RequiredPullRequestReviews.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+			req.RequiredPullRequestReviews.RequireLastPushApproval = github.Bool(reviews.RequireLastPushApproval)
+			if reviews.RequiredApprovingReviewCount > 0 {
+				req.RequiredPullRequestReviews.RequiredApprovingReviewCount = reviews.RequiredApprovingReviewCount
+			}
+		}
+	}
+
+	if restrictions := current.GetRestrictions(); restrictions != nil {
+		req.Restrictions = &github.BranchRestrictionsRequest{Users: []string{}, Teams: []string{}, Apps: []string{}}
+		for _, u := range restrictions.Users {
+			req.Restrictions.Users = append(req.Restrictions.Users, u.GetLogin())
+		}
+		for _, t := range restrictions.Teams {
+			req.Restrictions.Teams = append(req.Restrictions.Teams, t.GetSlug())
+		}
+		for _, a := range restrictions.Apps {
+			req.Restrictions.Apps = append(req.Restrictions.Apps, a.GetSlug())
+		}
+	}
+	if v := current.GetRequireLinearHistory(); v != nil {
+		req.RequireLinearHistory = github.Bool(v.Enabled)
+	}
+	if v := current.GetAllowDeletions(); v != nil {
+		req.AllowDeletions = github.Bool(v.Enabled)
+	}
+	if v := current.GetRequiredConversationResolution(); v != nil {
+		req.RequiredConversationResolution = github.Bool(v.Enabled)
+	}
+
+	// Sent by hand rather than through UpdateBranchProtection: go-github drops
+	// an empty checks list, and GitHub rejects status checks with no list at all
+	u := fmt.Sprintf("repos/%v/%v/branches/%v/protection", c.owner, c.repo, url.PathEscape(branch))
+	httpReq, err := c.client.NewRequest(http.MethodPut, u, &protectionRequest{ProtectionRequest: req, RequiredStatusChecks: statusChecks})
+	if err != nil {
+		return fmt.Errorf("failed to update branch protection: %w", err)
+	}
+	if _, err := c.client.Do(c.ctx, httpReq, nil); err != nil {
+		return fmt.Errorf("failed to update branch protection: %w", err)
+	}
+	return nil
+}
+
+// protectionRequest is github.ProtectionRequest with a status checks field
+// that always sends its checks list
+type protectionRequest struct {
+	*github.ProtectionRequest
+	RequiredStatusChecks *statusChecksRequest `json:"required_status_checks"`
+}
+
+type statusChecksRequest struct {
+	Strict bool                          `json:"strict"`
+	Checks []*github.RequiredStatusCheck `json:"checks"`
+}
+
 // CanPush checks if authenticated user can push to repository
 func (c *Client) CanPush() (bool, error) {
 	perms, err := c.CheckPermissions()
@@ -330,3 +330,29 @@ func setupTestContext(t *testing.T) context.Context {
 	t.Cleanup(cancel)
 	return ctx
 }
+
+func TestParseEnterpriseURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{url: "https://ghe.corp.example/team/service.git", wantOwner: "team", wantRepo: "service"},
+		{url: "ssh://git@ghe.corp.example:2222/team/service.git", wantOwner: "team", wantRepo: "service"},
+		{url: "git@ghe.corp.example:team/service.git", wantOwner: "team", wantRepo: "service"},
+		{url: "https://ghe.corp.example/team.git", wantErr: true},
+		{url: "not-a-url", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		owner, repo, err := parseEnterpriseURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEnterpriseURL(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if owner != tt.wantOwner || repo != tt.wantRepo {
+			t.Errorf("parseEnterpriseURL(%s) = (%s, %s), want (%s, %s)", tt.url, owner, repo, tt.wantOwner, tt.wantRepo)
+		}
+	}
+}
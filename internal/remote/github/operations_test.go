@@ -278,6 +278,71 @@ func TestGetBranchProtection_Mock(t *testing.T) {
 	}
 }
 
+func TestSetBranchProtection_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// main is unprotected; legacy has a check configured through contexts
+	sent := make(map[string]map[string]json.RawMessage)
+	handle := func(branch string, current *github.Protection) {
+		mux.HandleFunc("/repos/testowner/testrepo/branches/"+branch+"/protection", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				if current == nil {
+					w.WriteHeader(http.StatusNotFound)
+					json.NewEncoder(w).Encode(map[string]string{"message": "Branch not protected"})
+					return
+				}
+				json.NewEncoder(w).Encode(current)
+			case http.MethodPut:
+				var body map[string]json.RawMessage
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				sent[branch] = body
+				json.NewEncoder(w).Encode(&github.Protection{})
+			}
+		})
+	}
+	handle("main", nil)
+	handle("legacy", &github.Protection{
+		RequiredStatusChecks: &github.RequiredStatusChecks{Strict: false, Contexts: []string{"ci/build"}},
+	})
+
+	client := &Client{
+		client: github.NewClient(nil),
+		owner:  "testowner",
+		repo:   "testrepo",
+		ctx:    context.Background(),
+	}
+	baseURL, _ := url.Parse(server.URL + "/")
+	client.client.BaseURL = baseURL
+
+	rules := ProtectionRules{Enabled: true, RequireStatusChecks: true}
+	for _, branch := range []string{"main", "legacy"} {
+		if err := client.SetBranchProtection(branch, rules); err != nil {
+			t.Fatalf("SetBranchProtection(%s) error = %v", branch, err)
+		}
+	}
+
+	var checks github.RequiredStatusChecks
+	if raw := sent["main"]["required_status_checks"]; json.Unmarshal(raw, &checks) != nil || checks.Checks == nil {
+		t.Errorf("main required_status_checks = %s, want an empty checks list", raw)
+	} else if !checks.Strict || len(checks.Checks) != 0 {
+		t.Errorf("main required_status_checks = %+v, want strict with no checks", checks)
+	}
+
+	checks = github.RequiredStatusChecks{}
+	raw := sent["legacy"]["required_status_checks"]
+	if err := json.Unmarshal(raw, &checks); err != nil {
+		t.Fatalf("legacy required_status_checks = %s: %v", raw, err)
+	}
+	if checks.Strict || len(checks.Checks) != 1 || checks.Checks[0].Context != "ci/build" || len(checks.Contexts) != 0 {
+		t.Errorf("legacy required_status_checks = %s, want ci/build carried over as a check", raw)
+	}
+}
+
 func TestCanPush_Mock(t *testing.T) {
 	// Create mock server
 	mux := http.NewServeMux()
@@ -0,0 +1,111 @@
+package gitlab
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenSource represents where the token was found
+type TokenSource string
+
+const (
+	SourceEnvVar     TokenSource = "GITLAB_TOKEN"
+	SourceGLToken    TokenSource = "GL_TOKEN"
+	SourceGlabConfig TokenSource = "~/.config/glab-cli/config.yml"
+	SourceGitConfig  TokenSource = "git config gitlab.token"
+)
+
+// TokenInfo contains token and its source
+type TokenInfo struct {
+	Token  string
+	Source TokenSource
+}
+
+// getGitLabToken attempts to find a GitLab token for host from multiple sources
+// Priority: GITLAB_TOKEN env var > GL_TOKEN env var > glab CLI config > git config
+func getGitLabToken(host string) (string, error) {
+	info, err := getGitLabTokenInfo(host)
+	if err != nil {
+		return "", err
+	}
+	return info.Token, nil
+}
+
+// getGitLabTokenInfo returns token with source information (useful for diagnostics)
+func getGitLabTokenInfo(host string) (*TokenInfo, error) {
+	// 1. Try GITLAB_TOKEN environment variable
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		return &TokenInfo{Token: token, Source: SourceEnvVar}, nil
+	}
+
+	// 2. Try GL_TOKEN (alternative env var)
+	if token := os.Getenv("GL_TOKEN"); token != "" {
+		return &TokenInfo{Token: token, Source: SourceGLToken}, nil
+	}
+
+	// 3. Try glab CLI config (~/.config/glab-cli/config.yml)
+	if token, err := readGlabConfigToken(host); err == nil && token != "" {
+		return &TokenInfo{Token: token, Source: SourceGlabConfig}, nil
+	}
+
+	// 4. Try git config (gitlab.token)
+	if token, err := readGitConfigToken(); err == nil && token != "" {
+		return &TokenInfo{Token: token, Source: SourceGitConfig}, nil
+	}
+
+	return nil, fmt.Errorf("no GitLab token found for %s\n\n"+
+		"Please authenticate using one of:\n"+
+		"  1. Set GITLAB_TOKEN environment variable\n"+
+		"  2. Run: glab auth login --hostname %s\n"+
+		"  3. Run: git config --global gitlab.token YOUR_TOKEN", host, host)
+}
+
+// readGlabConfigToken reads the token for host from glab CLI config
+func readGlabConfigToken(host string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configPath := filepath.Join(home, ".config", "glab-cli", "config.yml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	var config struct {
+		Hosts map[string]map[string]string `yaml:"hosts"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+
+	if hostConfig, ok := config.Hosts[host]; ok {
+		if token, ok := hostConfig["token"]; ok {
+			return token, nil
+		}
+	}
+
+	return "", fmt.Errorf("no token for %s in glab config", host)
+}
+
+// readGitConfigToken reads token from git config
+func readGitConfigToken() (string, error) {
+	cmd := exec.Command("git", "config", "--global", "gitlab.token")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("git config gitlab.token is empty")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,541 @@
+// Package gitlab implements the remote.Platform interface on top of the
+// GitLab REST API (v4). It works against gitlab.com and self-hosted instances.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLab access levels (see https://docs.gitlab.com/ee/api/members.html)
+const (
+	AccessNone       = 0
+	AccessGuest      = 10
+	AccessReporter   = 20
+	AccessDeveloper  = 30
+	AccessMaintainer = 40
+	AccessOwner      = 50
+	AccessAdmin      = 60
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// Client talks to the GitLab API and implements the Platform interface
+type Client struct {
+	httpClient *http.Client
+	baseURL    string // e.g. https://gitlab.com/api/v4
+	token      string
+	owner      string // namespace path, may contain subgroups (group/subgroup)
+	repo       string
+	ctx        context.Context
+}
+
+// ProtectionRules represents branch protection settings
+// This is a local copy to avoid import cycles
+type ProtectionRules struct {
+	Enabled             bool
+	RequireReviews      bool
+	RequireStatusChecks bool
+	EnforceAdmins       bool
+	AllowForcePush      bool
+}
+
+// RepositoryPermissions represents the user's permissions on a project
+type RepositoryPermissions struct {
+	AccessLevel int
+	Push        bool
+	Admin       bool
+}
+
+// ResponseError is returned when the GitLab API answers with a non-2xx status
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *ResponseError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d", e.Method, e.Path, e.StatusCode)
+}
+
+// NewClient creates a GitLab client from a remote URL
+// Supports: https://gitlab.com/group/repo.git, git@gitlab.com:group/sub/repo.git,
+// ssh://git@gitlab.example.com:2222/group/repo.git
+// The API base URL is derived from the remote host (https://<host>/api/v4).
+func NewClient(remoteURL string) (*Client, error) {
+	return NewClientWithBaseURL(remoteURL, "")
+}
+
+// NewClientWithBaseURL creates a GitLab client using an explicit API base URL.
+// An empty baseURL falls back to https://<host>/api/v4.
+func NewClientWithBaseURL(remoteURL, baseURL string) (*Client, error) {
+	host, owner, repo, err := parseGitLabURL(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab URL: %w", err)
+	}
+
+	token, err := getGitLabToken(host)
+	if err != nil {
+		return nil, fmt.Errorf("GitLab authentication required: %w", err)
+	}
+
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/api/v4", host)
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: defaultHTTPTimeout},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		owner:      owner,
+		repo:       repo,
+		ctx:        context.Background(),
+	}, nil
+}
+
+// NewClientWithTimeout creates a client with custom timeout
+// Returns client and a cancel function that must be called when done
+func NewClientWithTimeout(remoteURL string, timeout time.Duration) (*Client, context.CancelFunc, error) {
+	client, err := NewClient(remoteURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	client.ctx = ctx
+
+	return client, cancel, nil
+}
+
+// parseGitLabURL extracts host, namespace and project name from a remote URL.
+// The host returned is suitable for building the API URL: SSH ports are dropped,
+// HTTPS ports are kept.
+func parseGitLabURL(remoteURL string) (host, owner, repo string, err error) {
+	var path string
+
+	switch {
+	case strings.Contains(remoteURL, "://"):
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", "", err
+		}
+		if u.Host == "" {
+			return "", "", "", fmt.Errorf("missing host in URL: %s", remoteURL)
+		}
+		host = u.Host
+		if u.Scheme == "ssh" || u.Scheme == "git+ssh" {
+			host = u.Hostname()
+		}
+		path = u.Path
+
+	case strings.Contains(remoteURL, "@") && strings.Contains(remoteURL, ":"):
+		// scp-like syntax: git@gitlab.com:group/repo.git
+		at := strings.Index(remoteURL, "@")
+		rest := remoteURL[at+1:]
+		colon := strings.Index(rest, ":")
+		host = rest[:colon]
+		path = rest[colon+1:]
+
+	default:
+		return "", "", "", fmt.Errorf("unrecognized remote URL format: %s", remoteURL)
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+
+	idx := strings.LastIndex(path, "/")
+	if idx <= 0 || idx == len(path)-1 {
+		return "", "", "", fmt.Errorf("invalid GitLab project path: %s", path)
+	}
+
+	return host, path[:idx], path[idx+1:], nil
+}
+
+// GetOwner returns the project namespace (group or user, including subgroups)
+func (c *Client) GetOwner() string {
+	return c.owner
+}
+
+// GetRepo returns the project name
+func (c *Client) GetRepo() string {
+	return c.repo
+}
+
+// GetPlatform returns "gitlab"
+func (c *Client) GetPlatform() string {
+	return "gitlab"
+}
+
+// projectPath returns the URL-encoded project ID used by the API
+func (c *Client) projectPath() string {
+	return "/projects/" + url.PathEscape(c.owner+"/"+c.repo)
+}
+
+// project is the subset of the GitLab project resource githelper uses
+type project struct {
+	DefaultBranch                    string `json:"default_branch"`
+	OnlyAllowMergeIfPipelineSucceeds bool   `json:"only_allow_merge_if_pipeline_succeeds"`
+	Permissions                      struct {
+		ProjectAccess *struct {
+			AccessLevel int `json:"access_level"`
+		} `json:"project_access"`
+		GroupAccess *struct {
+			AccessLevel int `json:"access_level"`
+		} `json:"group_access"`
+	} `json:"permissions"`
+}
+
+// protectedBranch is the GitLab protected branch resource
+type protectedBranch struct {
+	Name                      string        `json:"name"`
+	PushAccessLevels          []accessLevel `json:"push_access_levels"`
+	MergeAccessLevels         []accessLevel `json:"merge_access_levels"`
+	AllowForcePush            bool          `json:"allow_force_push"`
+	CodeOwnerApprovalRequired bool          `json:"code_owner_approval_required"`
+}
+
+// accessLevel grants access to a role, or (on paid tiers) to one user,
+// group or deploy key
+type accessLevel struct {
+	AccessLevel int `json:"access_level"`
+	UserID      int `json:"user_id,omitempty"`
+	GroupID     int `json:"group_id,omitempty"`
+	DeployKeyID int `json:"deploy_key_id,omitempty"`
+}
+
+// isRole reports whether l grants access to a role rather than to one user,
+// group or deploy key
+func (l accessLevel) isRole() bool {
+	return l.UserID == 0 && l.GroupID == 0 && l.DeployKeyID == 0
+}
+
+// grant returns the allowed_to_* entry that recreates l
+func (l accessLevel) grant() map[string]int {
+	switch {
+	case l.UserID != 0:
+		return map[string]int{"user_id": l.UserID}
+	case l.GroupID != 0:
+		return map[string]int{"group_id": l.GroupID}
+	case l.DeployKeyID != 0:
+		return map[string]int{"deploy_key_id": l.DeployKeyID}
+	}
+	return map[string]int{"access_level": l.AccessLevel}
+}
+
+// do performs an API request and decodes a JSON response into out (if non-nil)
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = json.Unmarshal(data, &apiErr)
+
+		msg := apiErr.Error
+		if apiErr.Message != nil {
+			msg = fmt.Sprint(apiErr.Message)
+		}
+		return &ResponseError{StatusCode: resp.StatusCode, Method: method, Path: path, Message: msg}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isNotFound reports whether err is a 404 from the API
+func isNotFound(err error) bool {
+	if respErr, ok := err.(*ResponseError); ok {
+		return respErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// getProject retrieves the project resource
+func (c *Client) getProject() (*project, error) {
+	var p project
+	if err := c.do(http.MethodGet, c.projectPath(), nil, &p); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return &p, nil
+}
+
+// SetDefaultBranch updates the project's default branch
+func (c *Client) SetDefaultBranch(branch string) error {
+	body := map[string]string{"default_branch": branch}
+	if err := c.do(http.MethodPut, c.projectPath(), body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the current default branch
+func (c *Client) GetDefaultBranch() (string, error) {
+	p, err := c.getProject()
+	if err != nil {
+		return "", err
+	}
+	return p.DefaultBranch, nil
+}
+
+// getProtectedBranch returns the protected branch resource, or nil if the branch is unprotected
+func (c *Client) getProtectedBranch(branch string) (*protectedBranch, error) {
+	var pb protectedBranch
+	err := c.do(http.MethodGet, c.projectPath()+"/protected_branches/"+url.PathEscape(branch), nil, &pb)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pb, nil
+}
+
+// IsBranchProtected checks if a branch has protection rules
+func (c *Client) IsBranchProtected(branch string) (bool, error) {
+	pb, err := c.getProtectedBranch(branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check branch protection: %w", err)
+	}
+	return pb != nil, nil
+}
+
+// GetBranchProtection returns detailed protection rules mapped onto ProtectionRules.
+//
+// GitLab has no direct equivalent of every GitHub setting, so the mapping is:
+//   - RequireReviews: nobody may push directly (changes land via merge requests)
+//     or code owner approval is required
+//   - RequireStatusChecks: the project only allows merges when the pipeline succeeds
+//   - EnforceAdmins: direct pushes are blocked for every role, maintainers included
+//   - AllowForcePush: the protected branch allows force pushes
+func (c *Client) GetBranchProtection(branch string) (*ProtectionRules, error) {
+	pb, err := c.getProtectedBranch(branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+	if pb == nil {
+		return &ProtectionRules{Enabled: false}, nil
+	}
+
+	p, err := c.getProject()
+	if err != nil {
+		return nil, err
+	}
+
+	noDirectPush := noAccess(pb.PushAccessLevels)
+
+	return &ProtectionRules{
+		Enabled:             true,
+		RequireReviews:      noDirectPush || pb.CodeOwnerApprovalRequired,
+		RequireStatusChecks: p.OnlyAllowMergeIfPipelineSucceeds,
+		EnforceAdmins:       noDirectPush,
+		AllowForcePush:      pb.AllowForcePush,
+	}, nil
+}
+
+// noAccess reports whether the access level list grants nobody access
+func noAccess(levels []accessLevel) bool {
+	for _, l := range levels {
+		if l.AccessLevel != AccessNone {
+			return false
+		}
+	}
+	return true
+}
+
+// SetBranchProtection makes branch's protection match rules, using the
+// mapping described on GetBranchProtection in reverse: RequireReviews or
+// EnforceAdmins blocks direct pushes for every role. GitLab can't change a
+// protected branch's access levels in place, so when those change the branch
+// is unprotected and protected again, keeping its merge access levels; if
+// protecting it again fails, the previous protection is restored. Disabling
+// unprotects the branch.
+func (c *Client) SetBranchProtection(branch string, rules ProtectionRules) error {
+	current, err := c.getProtectedBranch(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch protection: %w", err)
+	}
+
+	path := c.projectPath() + "/protected_branches"
+	branchPath := path + "/" + url.PathEscape(branch)
+	if !rules.Enabled {
+		if current == nil {
+			return nil
+		}
+		if err := c.do(http.MethodDelete, branchPath, nil, nil); err != nil {
+			return fmt.Errorf("failed to remove branch protection: %w", err)
+		}
+		return nil
+	}
+
+	p, err := c.getProject()
+	if err != nil {
+		return err
+	}
+	if p.OnlyAllowMergeIfPipelineSucceeds != rules.RequireStatusChecks {
+		body := map[string]bool{"only_allow_merge_if_pipeline_succeeds": rules.RequireStatusChecks}
+		if err := c.do(http.MethodPut, c.projectPath(), body, nil); err != nil {
+			return fmt.Errorf("failed to update pipeline requirement: %w", err)
+		}
+	}
+
+	noDirectPush := rules.RequireReviews || rules.EnforceAdmins
+	codeOwners := rules.RequireReviews && current != nil && current.CodeOwnerApprovalRequired
+
+	if current != nil && noAccess(current.PushAccessLevels) == noDirectPush {
+		body := map[string]bool{
+			"allow_force_push":             rules.AllowForcePush,
+			"code_owner_approval_required": codeOwners,
+		}
+		if err := c.do(http.MethodPatch, branchPath, body, nil); err != nil {
+			return fmt.Errorf("failed to update branch protection: %w", err)
+		}
+		return nil
+	}
+
+	push := []accessLevel{{AccessLevel: AccessMaintainer}}
+	if noDirectPush {
+		push = []accessLevel{{AccessLevel: AccessNone}}
+	}
+	merge := []accessLevel{{AccessLevel: AccessMaintainer}}
+	if current != nil {
+		if len(current.MergeAccessLevels) > 0 {
+			merge = current.MergeAccessLevels
+		}
+		if err := c.do(http.MethodDelete, branchPath, nil, nil); err != nil {
+			return fmt.Errorf("failed to update branch protection: %w", err)
+		}
+	}
+
+	body := protectionBody(branch, push, merge, rules.AllowForcePush, codeOwners)
+	if err := c.do(http.MethodPost, path, body, nil); err != nil {
+		if current == nil {
+			return fmt.Errorf("failed to protect branch: %w", err)
+		}
+		// Don't leave the branch unprotected
+		previous := protectionBody(branch, current.PushAccessLevels, current.MergeAccessLevels,
+			current.AllowForcePush, current.CodeOwnerApprovalRequired)
+		if restoreErr := c.do(http.MethodPost, path, previous, nil); restoreErr != nil {
+			return fmt.Errorf("failed to protect branch: %w; restoring its previous protection also failed, so it is unprotected: %v", err, restoreErr)
+		}
+		return fmt.Errorf("failed to protect branch (previous protection restored): %w", err)
+	}
+	return nil
+}
+
+// protectionBody is the request that protects branch with the given access
+// levels. A single role uses the *_access_level fields every tier accepts;
+// anything else (several levels, users, groups, deploy keys) is sent as the
+// allowed_to_* lists of the paid tiers that have such levels.
+func protectionBody(branch string, push, merge []accessLevel, allowForcePush, codeOwners bool) map[string]interface{} {
+	body := map[string]interface{}{
+		"name":                         branch,
+		"allow_force_push":             allowForcePush,
+		"code_owner_approval_required": codeOwners,
+	}
+	for kind, levels := range map[string][]accessLevel{"push": push, "merge": merge} {
+		switch {
+		case len(levels) == 0:
+			// GitLab's default applies
+		case len(levels) == 1 && levels[0].isRole():
+			body[kind+"_access_level"] = levels[0].AccessLevel
+		default:
+			var allowed []map[string]int
+			for _, l := range levels {
+				allowed = append(allowed, l.grant())
+			}
+			body["allowed_to_"+kind] = allowed
+		}
+	}
+	return body
+}
+
+// CheckPermissions returns the authenticated user's effective access to the project
+func (c *Client) CheckPermissions() (*RepositoryPermissions, error) {
+	p, err := c.getProject()
+	if err != nil {
+		return nil, err
+	}
+
+	level := AccessNone
+	if p.Permissions.ProjectAccess != nil && p.Permissions.ProjectAccess.AccessLevel > level {
+		level = p.Permissions.ProjectAccess.AccessLevel
+	}
+	if p.Permissions.GroupAccess != nil && p.Permissions.GroupAccess.AccessLevel > level {
+		level = p.Permissions.GroupAccess.AccessLevel
+	}
+
+	return &RepositoryPermissions{
+		AccessLevel: level,
+		Push:        level >= AccessDeveloper,
+		Admin:       level >= AccessMaintainer,
+	}, nil
+}
+
+// CanPush checks if authenticated user can push to the project (Developer or above)
+func (c *Client) CanPush() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Push, nil
+}
+
+// CanAdmin checks if authenticated user can administer the project (Maintainer or above)
+func (c *Client) CanAdmin() (bool, error) {
+	perms, err := c.CheckPermissions()
+	if err != nil {
+		return false, err
+	}
+	return perms.Admin, nil
+}
+
+// TestConnection tests the GitLab API connection
+func (c *Client) TestConnection() error {
+	if err := c.do(http.MethodGet, "/user", nil, nil); err != nil {
+		return fmt.Errorf("GitLab API connection test failed: %w", err)
+	}
+	return nil
+}
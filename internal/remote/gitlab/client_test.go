@@ -0,0 +1,144 @@
+package gitlab
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseGitLabURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantHost  string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{
+			name:      "https with .git",
+			url:       "https://gitlab.com/owner/repo.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "https without .git",
+			url:       "https://gitlab.com/owner/repo",
+			wantHost:  "gitlab.com",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "ssh scp-like",
+			url:       "git@gitlab.com:owner/repo.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "subgroups",
+			url:       "git@gitlab.com:group/sub/deeper/repo.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "group/sub/deeper",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "ssh url with port",
+			url:       "ssh://git@gitlab.example.com:2222/group/repo.git",
+			wantHost:  "gitlab.example.com",
+			wantOwner: "group",
+			wantRepo:  "repo",
+		},
+		{
+			name:      "self-hosted https with port",
+			url:       "https://gitlab.example.com:8443/group/repo.git",
+			wantHost:  "gitlab.example.com:8443",
+			wantOwner: "group",
+			wantRepo:  "repo",
+		},
+		{
+			name:    "missing namespace",
+			url:     "https://gitlab.com/repo.git",
+			wantErr: true,
+		},
+		{
+			name:    "not a url",
+			url:     "not-a-url",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, owner, repo, err := parseGitLabURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGitLabURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if host != tt.wantHost {
+				t.Errorf("parseGitLabURL() host = %v, want %v", host, tt.wantHost)
+			}
+			if owner != tt.wantOwner {
+				t.Errorf("parseGitLabURL() owner = %v, want %v", owner, tt.wantOwner)
+			}
+			if repo != tt.wantRepo {
+				t.Errorf("parseGitLabURL() repo = %v, want %v", repo, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	os.Setenv("GITLAB_TOKEN", "test_token")
+	defer os.Unsetenv("GITLAB_TOKEN")
+
+	client, err := NewClient("git@gitlab.example.com:group/sub/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if client.GetPlatform() != "gitlab" {
+		t.Errorf("GetPlatform() = %v, want gitlab", client.GetPlatform())
+	}
+	if client.GetOwner() != "group/sub" {
+		t.Errorf("GetOwner() = %v, want group/sub", client.GetOwner())
+	}
+	if client.GetRepo() != "repo" {
+		t.Errorf("GetRepo() = %v, want repo", client.GetRepo())
+	}
+	if client.baseURL != "https://gitlab.example.com/api/v4" {
+		t.Errorf("baseURL = %v, want https://gitlab.example.com/api/v4", client.baseURL)
+	}
+	if got := client.projectPath(); got != "/projects/group%2Fsub%2Frepo" {
+		t.Errorf("projectPath() = %v, want /projects/group%%2Fsub%%2Frepo", got)
+	}
+}
+
+func TestNewClientWithBaseURL(t *testing.T) {
+	os.Setenv("GITLAB_TOKEN", "test_token")
+	defer os.Unsetenv("GITLAB_TOKEN")
+
+	client, err := NewClientWithBaseURL("https://git.internal/owner/repo.git", "https://git.internal/gitlab/api/v4/")
+	if err != nil {
+		t.Fatalf("NewClientWithBaseURL() error = %v", err)
+	}
+
+	if client.baseURL != "https://git.internal/gitlab/api/v4" {
+		t.Errorf("baseURL = %v, want https://git.internal/gitlab/api/v4", client.baseURL)
+	}
+}
+
+func TestGetGitLabTokenInfo_GLToken(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("GL_TOKEN", "gl_token")
+
+	info, err := getGitLabTokenInfo("gitlab.com")
+	if err != nil {
+		t.Fatalf("getGitLabTokenInfo() error = %v", err)
+	}
+	if info.Token != "gl_token" || info.Source != SourceGLToken {
+		t.Errorf("getGitLabTokenInfo() = %+v, want GL_TOKEN", info)
+	}
+}
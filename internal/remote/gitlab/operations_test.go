@@ -0,0 +1,372 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client pointing at a fake GitLab API served by mux
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &Client{
+		httpClient: server.Client(),
+		baseURL:    server.URL + "/api/v4",
+		token:      "test_token",
+		owner:      "group/sub",
+		repo:       "repo",
+		ctx:        context.Background(),
+	}
+}
+
+// projectHandler serves the project resource for group/sub/repo
+func projectHandler(t *testing.T, body map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Frepo" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "test_token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want test_token", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func TestGetDefaultBranch_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", projectHandler(t, map[string]interface{}{
+		"default_branch": "main",
+	}))
+
+	client := newTestClient(t, mux)
+
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+
+	if branch != "main" {
+		t.Errorf("GetDefaultBranch() = %v, want main", branch)
+	}
+}
+
+func TestSetDefaultBranch_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if body["default_branch"] != "develop" {
+			t.Errorf("default_branch = %v, want develop", body["default_branch"])
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"default_branch": "develop"})
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.SetDefaultBranch("develop"); err != nil {
+		t.Fatalf("SetDefaultBranch() error = %v", err)
+	}
+}
+
+func TestIsBranchProtected_Mock(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   bool
+	}{
+		{name: "protected", status: http.StatusOK, want: true},
+		{name: "not protected", status: http.StatusNotFound, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Frepo/protected_branches/main" {
+					t.Errorf("unexpected path %s", r.URL.EscapedPath())
+				}
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					json.NewEncoder(w).Encode(map[string]string{"name": "main"})
+				} else {
+					json.NewEncoder(w).Encode(map[string]string{"message": "404 Not found"})
+				}
+			})
+
+			client := newTestClient(t, mux)
+
+			got, err := client.IsBranchProtected("main")
+			if err != nil {
+				t.Fatalf("IsBranchProtected() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBranchProtected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchProtection_Mock(t *testing.T) {
+	tests := []struct {
+		name      string
+		protected map[string]interface{}
+		pipeline  bool
+		want      ProtectionRules
+	}{
+		{
+			name: "maintainers can push",
+			protected: map[string]interface{}{
+				"name":               "main",
+				"push_access_levels": []map[string]int{{"access_level": AccessMaintainer}},
+				"allow_force_push":   false,
+			},
+			want: ProtectionRules{Enabled: true},
+		},
+		{
+			name: "merge requests only with pipelines",
+			protected: map[string]interface{}{
+				"name":               "main",
+				"push_access_levels": []map[string]int{{"access_level": AccessNone}},
+				"allow_force_push":   false,
+			},
+			pipeline: true,
+			want: ProtectionRules{
+				Enabled:             true,
+				RequireReviews:      true,
+				RequireStatusChecks: true,
+				EnforceAdmins:       true,
+			},
+		},
+		{
+			name: "force push allowed with code owners",
+			protected: map[string]interface{}{
+				"name":                         "main",
+				"push_access_levels":           []map[string]int{{"access_level": AccessDeveloper}},
+				"allow_force_push":             true,
+				"code_owner_approval_required": true,
+			},
+			want: ProtectionRules{
+				Enabled:        true,
+				RequireReviews: true,
+				AllowForcePush: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.EscapedPath() {
+				case "/api/v4/projects/group%2Fsub%2Frepo/protected_branches/main":
+					json.NewEncoder(w).Encode(tt.protected)
+				case "/api/v4/projects/group%2Fsub%2Frepo":
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"default_branch":                        "main",
+						"only_allow_merge_if_pipeline_succeeds": tt.pipeline,
+					})
+				default:
+					t.Errorf("unexpected path %s", r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			client := newTestClient(t, mux)
+
+			rules, err := client.GetBranchProtection("main")
+			if err != nil {
+				t.Fatalf("GetBranchProtection() error = %v", err)
+			}
+			if *rules != tt.want {
+				t.Errorf("GetBranchProtection() = %+v, want %+v", *rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchProtection_NotProtected(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	client := newTestClient(t, mux)
+
+	rules, err := client.GetBranchProtection("main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if rules.Enabled {
+		t.Error("GetBranchProtection() Enabled = true, want false")
+	}
+}
+
+func TestPermissions_Mock(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions map[string]interface{}
+		wantPush    bool
+		wantAdmin   bool
+	}{
+		{
+			name: "reporter",
+			permissions: map[string]interface{}{
+				"project_access": map[string]int{"access_level": AccessReporter},
+			},
+		},
+		{
+			name: "developer",
+			permissions: map[string]interface{}{
+				"project_access": map[string]int{"access_level": AccessDeveloper},
+			},
+			wantPush: true,
+		},
+		{
+			name: "maintainer via group",
+			permissions: map[string]interface{}{
+				"project_access": map[string]int{"access_level": AccessReporter},
+				"group_access":   map[string]int{"access_level": AccessMaintainer},
+			},
+			wantPush:  true,
+			wantAdmin: true,
+		},
+		{
+			name:        "no access",
+			permissions: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/", projectHandler(t, map[string]interface{}{
+				"default_branch": "main",
+				"permissions":    tt.permissions,
+			}))
+
+			client := newTestClient(t, mux)
+
+			canPush, err := client.CanPush()
+			if err != nil {
+				t.Fatalf("CanPush() error = %v", err)
+			}
+			if canPush != tt.wantPush {
+				t.Errorf("CanPush() = %v, want %v", canPush, tt.wantPush)
+			}
+
+			canAdmin, err := client.CanAdmin()
+			if err != nil {
+				t.Fatalf("CanAdmin() error = %v", err)
+			}
+			if canAdmin != tt.wantAdmin {
+				t.Errorf("CanAdmin() = %v, want %v", canAdmin, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestAPIError_Mock(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "401 Unauthorized"})
+	})
+
+	client := newTestClient(t, mux)
+
+	_, err := client.GetDefaultBranch()
+	if err == nil {
+		t.Fatal("GetDefaultBranch() should fail on 401")
+	}
+}
+
+func TestSetBranchProtection_Recreate(t *testing.T) {
+	current := map[string]interface{}{
+		"name":               "main",
+		"push_access_levels": []map[string]int{{"access_level": AccessMaintainer}},
+		"merge_access_levels": []map[string]int{
+			{"access_level": AccessDeveloper},
+			{"access_level": AccessMaintainer, "user_id": 7},
+		},
+	}
+	wantMerge := `[{"access_level":30},{"user_id":7}]`
+
+	tests := []struct {
+		name      string
+		failFirst bool
+		wantErr   string
+		wantPush  []int // push_access_level of each POST
+	}{
+		{name: "recreated", wantPush: []int{AccessNone}},
+		{name: "restored after a failure", failFirst: true, wantErr: "previous protection restored", wantPush: []int{AccessNone, AccessMaintainer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts []map[string]json.RawMessage
+			deleted := false
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.EscapedPath() {
+				case "GET /api/v4/projects/group%2Fsub%2Frepo/protected_branches/main":
+					json.NewEncoder(w).Encode(current)
+				case "GET /api/v4/projects/group%2Fsub%2Frepo":
+					json.NewEncoder(w).Encode(map[string]interface{}{"default_branch": "main"})
+				case "DELETE /api/v4/projects/group%2Fsub%2Frepo/protected_branches/main":
+					deleted = true
+					w.WriteHeader(http.StatusNoContent)
+				case "POST /api/v4/projects/group%2Fsub%2Frepo/protected_branches":
+					var body map[string]json.RawMessage
+					json.NewDecoder(r.Body).Decode(&body)
+					posts = append(posts, body)
+					if tt.failFirst && len(posts) == 1 {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					json.NewEncoder(w).Encode(current)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			client := newTestClient(t, mux)
+
+			err := client.SetBranchProtection("main", ProtectionRules{Enabled: true, EnforceAdmins: true})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("SetBranchProtection() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("SetBranchProtection() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if !deleted || len(posts) != len(tt.wantPush) {
+				t.Fatalf("deleted = %v, POSTs = %d; want a delete and %d POSTs", deleted, len(posts), len(tt.wantPush))
+			}
+			for i, body := range posts {
+				if got := string(body["push_access_level"]); got != strconv.Itoa(tt.wantPush[i]) {
+					t.Errorf("POST %d push_access_level = %s, want %d", i, got, tt.wantPush[i])
+				}
+				if got := string(body["allowed_to_merge"]); got != wantMerge {
+					t.Errorf("POST %d allowed_to_merge = %s, want every merge level kept: %s", i, got, wantMerge)
+				}
+			}
+		})
+	}
+}
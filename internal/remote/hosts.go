@@ -0,0 +1,101 @@
+package remote
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// HostConfig maps a self-hosted Git server onto a platform backend
+type HostConfig struct {
+	Platform string // "github", "gitlab", "gitea", "bitbucket", "bitbucket-server"
+	APIURL   string // optional API base URL, e.g. https://git.corp.example/api/v1
+}
+
+var (
+	hostsMu sync.RWMutex
+	hosts   = map[string]HostConfig{}
+)
+
+// RegisterHost maps host (optionally host:port) to a platform backend.
+// Host lookups try host:port first, then the bare hostname.
+func RegisterHost(host string, cfg HostConfig) error {
+	platform := normalizePlatform(cfg.Platform)
+	if !isKnownPlatform(platform) {
+		return fmt.Errorf("unknown platform %q for host %s", cfg.Platform, host)
+	}
+	cfg.Platform = platform
+
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	hosts[strings.ToLower(host)] = cfg
+	return nil
+}
+
+// ResetHosts removes all registered host mappings
+func ResetHosts() {
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	hosts = map[string]HostConfig{}
+}
+
+// lookupHost returns the registered configuration for the host in remoteURL
+func lookupHost(remoteURL string) (HostConfig, bool) {
+	host, port := splitRemoteHost(remoteURL)
+	if host == "" {
+		return HostConfig{}, false
+	}
+
+	hostsMu.RLock()
+	defer hostsMu.RUnlock()
+
+	if port != "" {
+		if cfg, ok := hosts[host+":"+port]; ok {
+			return cfg, true
+		}
+	}
+	cfg, ok := hosts[host]
+	return cfg, ok
+}
+
+// splitRemoteHost extracts the lowercased hostname and port (if any) from a remote URL
+func splitRemoteHost(remoteURL string) (host, port string) {
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", ""
+		}
+		return strings.ToLower(u.Hostname()), u.Port()
+	}
+
+	// scp-like syntax: git@host:owner/repo.git
+	at := strings.Index(remoteURL, "@")
+	colon := strings.Index(remoteURL, ":")
+	if colon <= at+1 {
+		return "", ""
+	}
+	return strings.ToLower(remoteURL[at+1 : colon]), ""
+}
+
+// normalizePlatform maps platform aliases onto backend names
+func normalizePlatform(platform string) string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	switch platform {
+	case "forgejo":
+		return "gitea"
+	case "bitbucket-datacenter", "bitbucket-dc":
+		return "bitbucket-server"
+	}
+	return platform
+}
+
+// isKnownPlatform reports whether platform names a backend
+func isKnownPlatform(platform string) bool {
+	switch platform {
+	case "github", "gitlab", "gitea", "bitbucket", "bitbucket-server":
+		return true
+	default:
+		return false
+	}
+}
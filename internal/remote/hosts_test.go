@@ -0,0 +1,160 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRegisterHost_DetectPlatform(t *testing.T) {
+	defer ResetHosts()
+
+	if err := RegisterHost("git.corp.example:2222", HostConfig{Platform: "Forgejo"}); err != nil {
+		t.Fatalf("RegisterHost() error = %v", err)
+	}
+	if err := RegisterHost("gitlab.internal", HostConfig{Platform: "gitlab"}); err != nil {
+		t.Fatalf("RegisterHost() error = %v", err)
+	}
+	if err := RegisterHost("stash.corp.example", HostConfig{Platform: "bitbucket-datacenter"}); err != nil {
+		t.Fatalf("RegisterHost() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "ssh url with registered port",
+			url:  "ssh://git@git.corp.example:2222/owner/repo.git",
+			want: "gitea",
+		},
+		{
+			name: "same host without port is not matched",
+			url:  "https://git.corp.example/owner/repo.git",
+			want: "unknown",
+		},
+		{
+			name: "bare hostname matches any port",
+			url:  "ssh://git@gitlab.internal:2222/group/repo.git",
+			want: "gitlab",
+		},
+		{
+			name: "scp-like url",
+			url:  "git@GitLab.internal:group/repo.git",
+			want: "gitlab",
+		},
+		{
+			name: "well-known hosts still detected",
+			url:  "https://github.com/owner/repo.git",
+			want: "github",
+		},
+		{
+			name: "bitbucket data center alias",
+			url:  "git@stash.corp.example:proj/repo.git",
+			want: "bitbucket-server",
+		},
+		{
+			name: "codeberg detected as gitea",
+			url:  "https://codeberg.org/owner/repo.git",
+			want: "gitea",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectPlatform(tt.url); got != tt.want {
+				t.Errorf("detectPlatform(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterHost_UnknownPlatform(t *testing.T) {
+	defer ResetHosts()
+
+	if err := RegisterHost("git.corp.example", HostConfig{Platform: "sourcehut"}); err == nil {
+		t.Error("RegisterHost() should reject unknown platforms")
+	}
+}
+
+func TestNewClient_RegisteredGiteaHost(t *testing.T) {
+	defer ResetHosts()
+
+	os.Setenv("GITEA_TOKEN", "test_token")
+	defer os.Unsetenv("GITEA_TOKEN")
+
+	if err := RegisterHost("git.corp.example:2222", HostConfig{
+		Platform: "gitea",
+		APIURL:   "https://git.corp.example/api/v1",
+	}); err != nil {
+		t.Fatalf("RegisterHost() error = %v", err)
+	}
+
+	client, err := NewClient("ssh://git@git.corp.example:2222/owner/repo.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if client.GetPlatform() != "gitea" {
+		t.Errorf("GetPlatform() = %v, want gitea", client.GetPlatform())
+	}
+	if !IsPlatformSupported("ssh://git@git.corp.example:2222/owner/repo.git") {
+		t.Error("IsPlatformSupported() = false for registered gitea host")
+	}
+}
+
+func TestNewClient_RegisteredGitHubEnterpriseHost(t *testing.T) {
+	defer ResetHosts()
+
+	t.Setenv("GITHUB_TOKEN", "test_token")
+	t.Setenv("GITHELPER_HTTP_CACHE", "off")
+
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"default_branch": "trunk"}`))
+	}))
+	defer server.Close()
+
+	if err := RegisterHost("ghe.corp.example", HostConfig{Platform: "github", APIURL: server.URL + "/api/v3/"}); err != nil {
+		t.Fatalf("RegisterHost() error = %v", err)
+	}
+
+	client, err := NewClient("git@ghe.corp.example:team/service.git")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.GetPlatform() != "github" || client.GetOwner() != "team" || client.GetRepo() != "service" {
+		t.Errorf("client = %s/%s on %s", client.GetOwner(), client.GetRepo(), client.GetPlatform())
+	}
+
+	branch, err := client.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch() error = %v", err)
+	}
+	if branch != "trunk" || gotPath != "/api/v3/repos/team/service" {
+		t.Errorf("GetDefaultBranch() = %s via %s, want trunk via the mapped API URL", branch, gotPath)
+	}
+}
+
+func TestGitHubAPIURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		hostCfg HostConfig
+		want    string
+	}{
+		{url: "git@github.com:owner/repo.git", want: ""},
+		{url: "git@ghe.corp.example:owner/repo.git", hostCfg: HostConfig{Platform: "github"}, want: "https://ghe.corp.example/api/v3/"},
+		{url: "ssh://git@ghe.corp.example:2222/owner/repo.git", hostCfg: HostConfig{Platform: "github"}, want: "https://ghe.corp.example/api/v3/"},
+		{url: "https://ghe.corp.example/owner/repo", hostCfg: HostConfig{Platform: "github", APIURL: "https://api.ghe.corp.example/"}, want: "https://api.ghe.corp.example/"},
+	}
+
+	for _, tt := range tests {
+		if got := githubAPIURL(tt.url, tt.hostCfg); got != tt.want {
+			t.Errorf("githubAPIURL(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
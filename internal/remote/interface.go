@@ -10,9 +10,10 @@ type Platform interface {
 	SetDefaultBranch(branch string) error
 	GetDefaultBranch() (string, error)
 
-	// Protection checks
+	// Branch protection
 	IsBranchProtected(branch string) (bool, error)
 	GetBranchProtection(branch string) (*ProtectionRules, error)
+	SetBranchProtection(branch string, rules ProtectionRules) error
 
 	// Permission checks
 	CanPush() (bool, error)
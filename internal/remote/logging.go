@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -116,12 +117,18 @@ func LogRetry(operation string, attempt int, maxAttempts int, err error) {
 	defaultLogger.Infof("Retry %d/%d for %s: %v", attempt, maxAttempts, operation, err)
 }
 
-// MetricsCollector collects metrics about API usage
+// MetricsCollector collects metrics about API usage. It is safe for
+// concurrent use.
 type MetricsCollector struct {
+	mu sync.Mutex
+
 	TotalCalls      int
 	SuccessfulCalls int
 	FailedCalls     int
 	RateLimitHits   int
+	Retries         int
+	CacheHits       int
+	CacheMisses     int
 	TotalDuration   time.Duration
 }
 
@@ -132,10 +139,14 @@ func NewMetricsCollector() *MetricsCollector {
 
 // RecordCall records an API call
 func (m *MetricsCollector) RecordCall(statusCode int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.TotalCalls++
 	m.TotalDuration += duration
 
-	if statusCode >= 200 && statusCode < 300 {
+	// 304 answers a conditional read: the cached copy is still current
+	if statusCode >= 200 && statusCode < 300 || statusCode == 304 {
 		m.SuccessfulCalls++
 	} else {
 		m.FailedCalls++
@@ -146,8 +157,40 @@ func (m *MetricsCollector) RecordCall(statusCode int, duration time.Duration) {
 	}
 }
 
+// RecordRateLimit records a rate-limited call that was not a 429, such as
+// GitHub's 403 with X-RateLimit-Remaining: 0
+func (m *MetricsCollector) RecordRateLimit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.RateLimitHits++
+}
+
+// RecordRetry records a call being retried
+func (m *MetricsCollector) RecordRetry() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Retries++
+}
+
+// RecordCacheHit records a GET answered from the HTTP cache after a 304
+func (m *MetricsCollector) RecordCacheHit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.CacheHits++
+}
+
+// RecordCacheMiss records a GET whose response had to be fetched in full
+func (m *MetricsCollector) RecordCacheMiss() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.CacheMisses++
+}
+
 // Report returns a metrics report
 func (m *MetricsCollector) Report() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.TotalCalls == 0 {
 		return "No API calls made"
 	}
@@ -161,12 +204,17 @@ func (m *MetricsCollector) Report() string {
 			"  Successful: %d (%.1f%%)\n"+
 			"  Failed: %d\n"+
 			"  Rate limit hits: %d\n"+
+			"  Retries: %d\n"+
+			"  Cache hits: %d, misses: %d\n"+
 			"  Avg duration: %v",
 		m.TotalCalls,
 		m.SuccessfulCalls,
 		successRate,
 		m.FailedCalls,
 		m.RateLimitHits,
+		m.Retries,
+		m.CacheHits,
+		m.CacheMisses,
 		avgDuration,
 	)
 }
@@ -0,0 +1,90 @@
+package remote
+
+import "fmt"
+
+// ProtectionPolicy is the branch protection a repository's default branch
+// must have. Each field is a requirement; a false field means the policy
+// doesn't care, so applying a policy only ever tightens protection.
+type ProtectionPolicy struct {
+	RequireReviews      bool `yaml:"require_reviews" json:"require_reviews"`
+	RequireStatusChecks bool `yaml:"require_status_checks" json:"require_status_checks"`
+	EnforceAdmins       bool `yaml:"enforce_admins" json:"enforce_admins"`
+	ForbidForcePush     bool `yaml:"forbid_force_push" json:"forbid_force_push"`
+}
+
+// DefaultProtectionPolicy requires reviews and forbids force pushes
+func DefaultProtectionPolicy() ProtectionPolicy {
+	return ProtectionPolicy{
+		RequireReviews:  true,
+		ForbidForcePush: true,
+	}
+}
+
+// ProtectionViolation is one way a branch's protection falls short of a policy
+type ProtectionViolation struct {
+	Rule   string `json:"rule"`
+	Want   bool   `json:"want"`
+	Actual bool   `json:"actual"`
+}
+
+// String describes the violation, e.g. "require reviews: is false, policy wants true"
+func (v ProtectionViolation) String() string {
+	return fmt.Sprintf("%s: is %v, policy wants %v", v.Rule, v.Actual, v.Want)
+}
+
+// Check compares actual protection rules against the policy. A nil rules
+// value is treated as an unprotected branch.
+func (p ProtectionPolicy) Check(actual *ProtectionRules) []ProtectionViolation {
+	if p.IsEmpty() {
+		return nil
+	}
+	rules := ProtectionRules{}
+	if actual != nil {
+		rules = *actual
+	}
+
+	var violations []ProtectionViolation
+	if !rules.Enabled {
+		violations = append(violations, ProtectionViolation{Rule: "protected", Want: true, Actual: false})
+	}
+	if p.RequireReviews && !rules.RequireReviews {
+		violations = append(violations, ProtectionViolation{Rule: "require reviews", Want: true, Actual: false})
+	}
+	if p.RequireStatusChecks && !rules.RequireStatusChecks {
+		violations = append(violations, ProtectionViolation{Rule: "require status checks", Want: true, Actual: false})
+	}
+	if p.EnforceAdmins && !rules.EnforceAdmins {
+		violations = append(violations, ProtectionViolation{Rule: "enforce admins", Want: true, Actual: false})
+	}
+	// An unprotected branch allows force pushes whatever the stored flag says
+	if p.ForbidForcePush && (rules.AllowForcePush || !rules.Enabled) {
+		violations = append(violations, ProtectionViolation{Rule: "allow force push", Want: false, Actual: true})
+	}
+	return violations
+}
+
+// Apply returns actual tightened just enough to satisfy the policy; settings
+// the policy doesn't mention are left as they are
+func (p ProtectionPolicy) Apply(actual *ProtectionRules) ProtectionRules {
+	rules := ProtectionRules{}
+	if actual != nil {
+		rules = *actual
+	}
+	if p.IsEmpty() {
+		return rules
+	}
+
+	rules.Enabled = true
+	rules.RequireReviews = rules.RequireReviews || p.RequireReviews
+	rules.RequireStatusChecks = rules.RequireStatusChecks || p.RequireStatusChecks
+	rules.EnforceAdmins = rules.EnforceAdmins || p.EnforceAdmins
+	if p.ForbidForcePush {
+		rules.AllowForcePush = false
+	}
+	return rules
+}
+
+// IsEmpty reports whether the policy requires nothing
+func (p ProtectionPolicy) IsEmpty() bool {
+	return p == ProtectionPolicy{}
+}
@@ -0,0 +1,75 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtectionPolicy_Check(t *testing.T) {
+	policy := DefaultProtectionPolicy()
+
+	tests := []struct {
+		name   string
+		actual *ProtectionRules
+		want   []string
+	}{
+		{
+			name:   "unprotected",
+			actual: &ProtectionRules{Enabled: false},
+			want:   []string{"protected", "require reviews", "allow force push"},
+		},
+		{
+			name:   "nil rules",
+			actual: nil,
+			want:   []string{"protected", "require reviews", "allow force push"},
+		},
+		{
+			name:   "force push allowed",
+			actual: &ProtectionRules{Enabled: true, RequireReviews: true, AllowForcePush: true},
+			want:   []string{"allow force push"},
+		},
+		{
+			name:   "compliant",
+			actual: &ProtectionRules{Enabled: true, RequireReviews: true},
+		},
+		{
+			name:   "stricter than policy",
+			actual: &ProtectionRules{Enabled: true, RequireReviews: true, RequireStatusChecks: true, EnforceAdmins: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range policy.Check(tt.actual) {
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if v := (ProtectionPolicy{}).Check(nil); v != nil {
+		t.Errorf("empty policy Check() = %v, want nil", v)
+	}
+}
+
+func TestProtectionPolicy_Apply(t *testing.T) {
+	policy := ProtectionPolicy{RequireReviews: true, ForbidForcePush: true}
+
+	got := policy.Apply(&ProtectionRules{Enabled: true, RequireStatusChecks: true, AllowForcePush: true})
+	want := ProtectionRules{Enabled: true, RequireReviews: true, RequireStatusChecks: true}
+	if got != want {
+		t.Errorf("Apply() = %+v, want %+v (existing settings kept)", got, want)
+	}
+	if v := policy.Check(&got); len(v) != 0 {
+		t.Errorf("Check(Apply()) = %v, want no violations", v)
+	}
+
+	got = policy.Apply(nil)
+	want = ProtectionRules{Enabled: true, RequireReviews: true}
+	if got != want {
+		t.Errorf("Apply(nil) = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retry defaults
+const (
+	DefaultMaxAttempts = 4
+	DefaultBaseDelay   = 1 * time.Second
+	// DefaultMaxDelay caps a single wait. A server asking for longer (e.g. a
+	// primary rate limit that resets in 40 minutes) gets its error returned
+	// instead of stalling the command.
+	DefaultMaxDelay = 60 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper that retries API requests GitHub
+// answers with a retryable error (see IsRetryable), including primary and
+// secondary rate limits. It waits as long as Retry-After or X-RateLimit-Reset
+// ask, or backs off exponentially, and stops as soon as the request's
+// context is cancelled. Requests that change something are retried only
+// after a rate limit: a 5xx can arrive after the change was already made.
+type RetryTransport struct {
+	Base        http.RoundTripper // Defaults to http.DefaultTransport
+	MaxAttempts int               // Defaults to DefaultMaxAttempts
+	BaseDelay   time.Duration     // Defaults to DefaultBaseDelay
+	MaxDelay    time.Duration     // Defaults to DefaultMaxDelay
+	Metrics     *MetricsCollector // Optional
+
+	// now and sleep are replaced in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+var (
+	defaultTransport     http.RoundTripper
+	defaultTransportOnce sync.Once
+
+	// DefaultMetrics collects calls made through DefaultTransport
+	DefaultMetrics = NewMetricsCollector()
+)
+
+// DefaultTransport returns the transport shared by every GitHub client: an
+// on-disk cache for GET requests (see CacheTransport) over RetryTransport.
+// Setting GITHELPER_HTTP_CACHE=off disables the cache.
+func DefaultTransport() http.RoundTripper {
+	defaultTransportOnce.Do(func() {
+		retry := &RetryTransport{Metrics: DefaultMetrics}
+		defaultTransport = retry
+
+		if os.Getenv("GITHELPER_HTTP_CACHE") == "off" {
+			return
+		}
+		if dir, err := DefaultCacheDir(); err == nil {
+			defaultTransport = &CacheTransport{Next: retry, Dir: dir, Metrics: DefaultMetrics}
+		}
+	})
+	return defaultTransport
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	maxAttempts := t.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	now := t.now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	// A body can only be resent if it can be rewound
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		maxAttempts = 1
+	}
+
+	operation := fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		start := now()
+		resp, err := base.RoundTrip(attemptReq)
+		duration := now().Sub(start)
+		if err != nil {
+			t.record(0, duration, false)
+			return nil, err
+		}
+
+		LogAPICall(req.Method, req.URL.Path, resp.StatusCode, duration)
+		apiErr := classifyResponse(resp)
+		t.record(resp.StatusCode, duration, apiErr != nil && apiErr.Type == ErrorTypeRateLimit)
+
+		if apiErr == nil || !IsRetryable(apiErr) || attempt >= maxAttempts {
+			return resp, nil
+		}
+		if !isSafeMethod(req.Method) && apiErr.Type != ErrorTypeRateLimit {
+			return resp, nil
+		}
+
+		delay := t.retryDelay(resp, attempt, now())
+		if delay > t.maxDelay() {
+			defaultLogger.Infof("Not retrying %s: server asked to wait %v", operation, delay)
+			return resp, nil
+		}
+
+		LogRetry(operation, attempt, maxAttempts, apiErr)
+		drain(resp)
+		if t.Metrics != nil {
+			t.Metrics.RecordRetry()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) maxDelay() time.Duration {
+	if t.MaxDelay > 0 {
+		return t.MaxDelay
+	}
+	return DefaultMaxDelay
+}
+
+func (t *RetryTransport) record(statusCode int, duration time.Duration, rateLimited bool) {
+	if t.Metrics == nil {
+		return
+	}
+	t.Metrics.RecordCall(statusCode, duration)
+	if rateLimited && statusCode != http.StatusTooManyRequests {
+		t.Metrics.RecordRateLimit()
+	}
+}
+
+// retryDelay is how long to wait before the next attempt: what the server
+// asked for, or exponential backoff
+func (t *RetryTransport) retryDelay(resp *http.Response, attempt int, now time.Time) time.Duration {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return nonNegative(at.Sub(now))
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// Reset is whole seconds; wait one more to be past it
+			return nonNegative(time.Unix(reset, 0).Sub(now) + time.Second)
+		}
+	}
+
+	base := t.BaseDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	delay := base << (attempt - 1)
+	if delay > t.maxDelay() || delay <= 0 {
+		delay = t.maxDelay()
+	}
+	return delay
+}
+
+// classifyResponse returns the API error a response represents, or nil for
+// success. GitHub reports rate limits as 403 as well as 429, so those are
+// told apart from permission errors by their headers.
+func classifyResponse(resp *http.Response) *APIError {
+	status := resp.StatusCode
+	if status < 400 {
+		return nil
+	}
+
+	if status == http.StatusForbidden || status == http.StatusTooManyRequests {
+		if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			apiErr := ClassifyGitHubError(http.StatusTooManyRequests, fmt.Errorf("%s", resp.Status))
+			apiErr.StatusCode = status
+			return apiErr
+		}
+	}
+
+	return ClassifyGitHubError(status, fmt.Errorf("%s", resp.Status))
+}
+
+// isSafeMethod reports whether repeating a request cannot change its effect.
+// PUT and DELETE are left out: repeating one that already succeeded can
+// still fail (a second DELETE gets 404) or undo a concurrent change.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// drain discards the rest of a response so its connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// sleepContext waits for d, returning early with the context's error if it
+// is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
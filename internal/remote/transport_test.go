@@ -0,0 +1,277 @@
+package remote
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedStep is one canned response
+type scriptedStep struct {
+	status  int
+	headers map[string]string
+}
+
+// scriptedServer answers requests with its steps in order, repeating the
+// last one, and records the request bodies it saw
+type scriptedServer struct {
+	mu     sync.Mutex
+	steps  []scriptedStep
+	calls  int
+	bodies []string
+}
+
+func newScriptedServer(t *testing.T, steps ...scriptedStep) (*scriptedServer, *httptest.Server) {
+	s := &scriptedServer{steps: steps}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(body))
+
+	step := s.steps[len(s.steps)-1]
+	if s.calls < len(s.steps) {
+		step = s.steps[s.calls]
+	}
+	s.calls++
+
+	for k, v := range step.headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(step.status)
+	fmt.Fprintf(w, `{"call":%d}`, s.calls)
+}
+
+func (s *scriptedServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// fakeClock is a fixed clock whose sleeps are recorded instead of waited
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) install(t *RetryTransport) *RetryTransport {
+	t.now = func() time.Time { return c.now }
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		c.sleeps = append(c.sleeps, d)
+		return ctx.Err()
+	}
+	return t
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string) *http.Response {
+	t.Helper()
+	resp, err := (&http.Client{Transport: rt}).Get(url)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestRetryTransport_RetryAfter(t *testing.T) {
+	server, srv := newScriptedServer(t,
+		scriptedStep{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7"}},
+		scriptedStep{status: http.StatusOK},
+	)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	metrics := NewMetricsCollector()
+	rt := clock.install(&RetryTransport{Metrics: metrics})
+
+	if resp := get(t, rt, srv.URL); resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if server.count() != 2 {
+		t.Errorf("calls = %d, want 2", server.count())
+	}
+	if fmt.Sprint(clock.sleeps) != "[7s]" {
+		t.Errorf("sleeps = %v, want [7s]", clock.sleeps)
+	}
+	if metrics.TotalCalls != 2 || metrics.RateLimitHits != 1 || metrics.Retries != 1 || metrics.SuccessfulCalls != 1 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
+
+func TestRetryTransport_PrimaryRateLimitReset(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	reset := strconv.FormatInt(clock.now.Add(20*time.Second).Unix(), 10)
+	server, srv := newScriptedServer(t,
+		scriptedStep{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}},
+		scriptedStep{status: http.StatusOK},
+	)
+	metrics := NewMetricsCollector()
+	rt := clock.install(&RetryTransport{Metrics: metrics})
+
+	if resp := get(t, rt, srv.URL); resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if server.count() != 2 || fmt.Sprint(clock.sleeps) != "[21s]" {
+		t.Errorf("calls = %d, sleeps = %v; want 2 calls after waiting until the reset", server.count(), clock.sleeps)
+	}
+	if metrics.RateLimitHits != 1 {
+		t.Errorf("RateLimitHits = %d, want the 403 counted", metrics.RateLimitHits)
+	}
+}
+
+func TestRetryTransport_NotRetryable(t *testing.T) {
+	for _, status := range []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnauthorized, http.StatusUnprocessableEntity} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			server, srv := newScriptedServer(t, scriptedStep{status: status}, scriptedStep{status: http.StatusOK})
+			clock := &fakeClock{now: time.Unix(1700000000, 0)}
+			rt := clock.install(&RetryTransport{})
+
+			if resp := get(t, rt, srv.URL); resp.StatusCode != status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, status)
+			}
+			if server.count() != 1 || len(clock.sleeps) != 0 {
+				t.Errorf("calls = %d, sleeps = %v; want no retry", server.count(), clock.sleeps)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ExponentialBackoff(t *testing.T) {
+	server, srv := newScriptedServer(t, scriptedStep{status: http.StatusBadGateway})
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	rt := clock.install(&RetryTransport{MaxAttempts: 4, BaseDelay: time.Second})
+
+	if resp := get(t, rt, srv.URL); resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want the last 502", resp.StatusCode)
+	}
+	if server.count() != 4 {
+		t.Errorf("calls = %d, want 4", server.count())
+	}
+	if fmt.Sprint(clock.sleeps) != "[1s 2s 4s]" {
+		t.Errorf("sleeps = %v, want [1s 2s 4s]", clock.sleeps)
+	}
+}
+
+func TestRetryTransport_WaitTooLong(t *testing.T) {
+	server, srv := newScriptedServer(t,
+		scriptedStep{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "3600"}},
+		scriptedStep{status: http.StatusOK},
+	)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	rt := clock.install(&RetryTransport{})
+
+	if resp := get(t, rt, srv.URL); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 returned rather than waiting an hour", resp.StatusCode)
+	}
+	if server.count() != 1 {
+		t.Errorf("calls = %d, want 1", server.count())
+	}
+}
+
+func TestRetryTransport_ContextCancelled(t *testing.T) {
+	_, srv := newScriptedServer(t, scriptedStep{status: http.StatusServiceUnavailable, headers: map[string]string{"Retry-After": "30"}})
+	rt := &RetryTransport{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	start := time.Now()
+	_, err := (&http.Client{Transport: rt}).Do(req)
+	if !stderrors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation took %v", elapsed)
+	}
+}
+
+func TestRetryTransport_ResendsBody(t *testing.T) {
+	server, srv := newScriptedServer(t,
+		scriptedStep{status: http.StatusForbidden, headers: map[string]string{"Retry-After": "1"}},
+		scriptedStep{status: http.StatusCreated},
+	)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	rt := clock.install(&RetryTransport{})
+
+	resp, err := (&http.Client{Transport: rt}).Post(srv.URL, "application/json", strings.NewReader(`{"name":"repo"}`))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want 201", resp.StatusCode)
+	}
+	if len(server.bodies) != 2 || server.bodies[1] != `{"name":"repo"}` {
+		t.Errorf("bodies = %q, want the body sent twice", server.bodies)
+	}
+}
+
+func TestRetryTransport_NoRetryOfChangesOnServerError(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		server, srv := newScriptedServer(t,
+			scriptedStep{status: http.StatusBadGateway},
+			scriptedStep{status: http.StatusOK},
+		)
+		clock := &fakeClock{now: time.Unix(1700000000, 0)}
+		rt := clock.install(&RetryTransport{})
+
+		req, _ := http.NewRequest(method, srv.URL, strings.NewReader(`{"title":"deploy"}`))
+		resp, err := (&http.Client{Transport: rt}).Do(req)
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadGateway || server.count() != 1 {
+			t.Errorf("%s: status = %d after %d calls, want the 502 without a retry", method, resp.StatusCode, server.count())
+		}
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		status    int
+		headers   map[string]string
+		wantType  ErrorType
+		retryable bool
+	}{
+		{status: 200},
+		{status: 403, wantType: ErrorTypePermission},
+		{status: 403, headers: map[string]string{"Retry-After": "60"}, wantType: ErrorTypeRateLimit, retryable: true},
+		{status: 403, headers: map[string]string{"X-RateLimit-Remaining": "0"}, wantType: ErrorTypeRateLimit, retryable: true},
+		{status: 429, wantType: ErrorTypeRateLimit, retryable: true},
+		{status: 503, wantType: ErrorTypeNetwork, retryable: true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Status: http.StatusText(tt.status), Header: http.Header{}}
+		for k, v := range tt.headers {
+			resp.Header.Set(k, v)
+		}
+
+		apiErr := classifyResponse(resp)
+		if tt.wantType == "" {
+			if apiErr != nil {
+				t.Errorf("classifyResponse(%d) = %v, want nil", tt.status, apiErr)
+			}
+			continue
+		}
+		if apiErr == nil || apiErr.Type != tt.wantType || IsRetryable(apiErr) != tt.retryable || apiErr.StatusCode != tt.status {
+			t.Errorf("classifyResponse(%d, %v) = %+v, want %s retryable=%v", tt.status, tt.headers, apiErr, tt.wantType, tt.retryable)
+		}
+	}
+}
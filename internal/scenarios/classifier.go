@@ -2,6 +2,8 @@ package scenarios
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/lcgerke/githelper/internal/constants"
@@ -14,12 +16,25 @@ import (
 
 // NewClassifier creates a new scenario classifier
 func NewClassifier(gitClient *git.Client, coreRemote, githubRemote string, options DetectionOptions) *Classifier {
-	return &Classifier{
-		gitClient:    gitClient,
-		coreRemote:   coreRemote,
-		githubRemote: githubRemote,
-		options:      options,
+	return NewMirrorClassifier(gitClient, []string{coreRemote, githubRemote}, options)
+}
+
+// NewMirrorClassifier creates a classifier for an arbitrary mirror set.
+// The first two mirrors act as Core and GitHub for the E/S scenario IDs;
+// every mirror is reported individually in SyncState.Mirrors.
+func NewMirrorClassifier(gitClient *git.Client, mirrors []string, options DetectionOptions) *Classifier {
+	c := &Classifier{
+		gitClient: gitClient,
+		mirrors:   mirrors,
+		options:   options,
+	}
+	if len(mirrors) > 0 {
+		c.coreRemote = mirrors[0]
 	}
+	if len(mirrors) > 1 {
+		c.githubRemote = mirrors[1]
+	}
+	return c
 }
 
 // Detect performs full state detection and returns classified repository state
@@ -37,21 +52,18 @@ func (c *Classifier) Detect() (*RepositoryState, error) {
 
 	// Pre-flight fetch (unless disabled)
 	if !c.options.SkipFetch {
-		// Fetch both remotes concurrently (but serialized through mutex)
-		if err := gc.FetchRemote(c.coreRemote); err != nil {
-			state.Warnings = append(state.Warnings, Warning{
-				Code:    WarnStaleRemoteData,
-				Message: fmt.Sprintf("Failed to fetch from %s: %v", c.coreRemote, err),
-				Hint:    "Using stale remote data, run with --no-fetch to suppress",
-			})
-		}
-
-		if err := gc.FetchRemote(c.githubRemote); err != nil {
-			state.Warnings = append(state.Warnings, Warning{
-				Code:    WarnStaleRemoteData,
-				Message: fmt.Sprintf("Failed to fetch from %s: %v", c.githubRemote, err),
-				Hint:    "Using stale remote data",
-			})
+		// Fetch every mirror (serialized through the git client mutex)
+		for _, mirror := range c.mirrors {
+			if mirror == "" {
+				continue
+			}
+			if err := gc.FetchRemote(mirror); err != nil {
+				state.Warnings = append(state.Warnings, Warning{
+					Code:    WarnStaleRemoteData,
+					Message: fmt.Sprintf("Failed to fetch from %s: %v", mirror, err),
+					Hint:    "Using stale remote data, run with --no-fetch to suppress",
+				})
+			}
 		}
 	}
 
@@ -126,6 +138,26 @@ func (c *Classifier) Detect() (*RepositoryState, error) {
 		if defaultBranch == "" {
 			defaultBranch = constants.DefaultBranch // fallback
 		}
+		// Compare the live default branch on Core and GitHub (D1-D4) - unless
+		// skipped. The cached remote HEAD above is only written at clone time,
+		// so prefer the authoritative branch when it exists locally.
+		if state.Existence.ID == "E1" && !c.options.SkipDefaultBranch {
+			ds, err := c.detectDefaultBranch(gc)
+			if err != nil {
+				state.Warnings = append(state.Warnings, Warning{
+					Code:    WarnNetworkUnreachable,
+					Message: fmt.Sprintf("Failed to compare default branches: %v", err),
+					Hint:    "Check remote access: git ls-remote --symref <remote> HEAD",
+				})
+			} else {
+				state.DefaultBranchState = &ds
+				if ds.Branch != "" && ds.Branch != defaultBranch {
+					if _, err := gc.GetBranchHash(ds.Branch); err == nil {
+						defaultBranch = ds.Branch
+					}
+				}
+			}
+		}
 		state.DefaultBranch = defaultBranch
 
 		// Detect sync based on which remotes exist
@@ -147,6 +179,19 @@ func (c *Classifier) Detect() (*RepositoryState, error) {
 				state.Branches = branches
 			}
 
+			// Detect tag sync between Core and GitHub (T1-T4) - unless skipped
+			if !c.options.SkipTags {
+				tags, err := c.detectTagSync(gc)
+				if err != nil {
+					state.Warnings = append(state.Warnings, Warning{
+						Code:    WarnNetworkUnreachable,
+						Message: fmt.Sprintf("Failed to compare tags: %v", err),
+						Hint:    "Check remote access: git ls-remote --tags <remote>",
+					})
+				}
+				state.Tags = tags
+			}
+
 		case "E2": // Local + Core exist, GitHub missing
 			state.Sync = c.detectTwoWaySync(gc, defaultBranch, c.coreRemote, "", "GitHub")
 
@@ -160,6 +205,9 @@ func (c *Classifier) Detect() (*RepositoryState, error) {
 				Description: "N/A (not all locations exist)",
 			}
 		}
+
+		// Per-mirror ahead/behind for the whole mirror set
+		state.Sync.Mirrors = c.detectMirrorSync(gc, defaultBranch)
 	} else {
 		// No local repository - can't detect sync
 		state.Sync = SyncState{
@@ -582,6 +630,55 @@ func (c *Classifier) detectTwoWaySync(
 	return state
 }
 
+// detectMirrorSync compares the local default branch against every mirror
+func (c *Classifier) detectMirrorSync(gc *git.Client, branch string) []MirrorState {
+	localHash, err := gc.GetBranchHash(branch)
+	if err != nil {
+		return nil
+	}
+
+	remotes, err := gc.ListRemotes()
+	if err != nil {
+		remotes = []string{}
+	}
+	configured := make(map[string]bool, len(remotes))
+	for _, r := range remotes {
+		configured[r] = true
+	}
+
+	var mirrors []MirrorState
+	for _, name := range c.mirrors {
+		if name == "" {
+			continue
+		}
+
+		ms := MirrorState{Remote: name, Configured: configured[name]}
+		if !ms.Configured {
+			ms.Error = "remote not configured"
+			mirrors = append(mirrors, ms)
+			continue
+		}
+
+		remoteHash, err := gc.GetRemoteBranchHash(name, branch)
+		if err != nil {
+			ms.Error = fmt.Sprintf("branch %s not found on %s", branch, name)
+			mirrors = append(mirrors, ms)
+			continue
+		}
+		ms.Hash = remoteHash
+
+		if remoteHash != localHash {
+			ms.LocalAhead, _ = gc.CountCommitsBetween(localHash, remoteHash)
+			ms.LocalBehind, _ = gc.CountCommitsBetween(remoteHash, localHash)
+			ms.Diverged = ms.LocalAhead > 0 && ms.LocalBehind > 0
+		}
+
+		mirrors = append(mirrors, ms)
+	}
+
+	return mirrors
+}
+
 // detectBranchTopology analyzes per-branch sync state (B1-B7 for each branch)
 func (c *Classifier) detectBranchTopology(gc *git.Client) ([]BranchState, error) {
 	localBranches, _, err := gc.ListBranches()
@@ -657,3 +754,141 @@ func (c *Classifier) detectBranchTopology(gc *git.Client) ([]BranchState, error)
 
 	return branchStates, nil
 }
+
+// detectTagSync compares every tag on Core and GitHub (T1-T4).
+// Remote tags are read with ls-remote because fetched tags share one local
+// namespace and cannot tell which remote a tag came from.
+func (c *Classifier) detectTagSync(gc *git.Client) ([]TagState, error) {
+	coreRefs, err := gc.ListRemoteRefs(c.coreRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags on %s: %w", c.coreRemote, err)
+	}
+	githubRefs, err := gc.ListRemoteRefs(c.githubRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags on %s: %w", c.githubRemote, err)
+	}
+	localTags, _ := gc.ListTags()
+
+	coreTags := filterTagRefs(coreRefs)
+	githubTags := filterTagRefs(githubRefs)
+
+	// Union of tags on both remotes, sorted for stable output
+	names := make([]string, 0, len(coreTags)+len(githubTags))
+	for tag := range coreTags {
+		names = append(names, tag)
+	}
+	for tag := range githubTags {
+		if _, ok := coreTags[tag]; !ok {
+			names = append(names, tag)
+		}
+	}
+	sort.Strings(names)
+
+	var tagStates []TagState
+	for _, tag := range names {
+		ts := TagState{
+			Tag:        tag,
+			LocalHash:  localTags[tag],
+			CoreHash:   coreTags[tag],
+			GitHubHash: githubTags[tag],
+		}
+
+		// Classify
+		if ts.CoreHash == ts.GitHubHash {
+			ts.ID = "T1"
+			ts.Description = "Tag in sync"
+		} else if ts.GitHubHash == "" {
+			ts.ID = "T2"
+			ts.Description = "Tag missing on GitHub"
+		} else if ts.CoreHash == "" {
+			ts.ID = "T3"
+			ts.Description = "Tag missing on Core"
+		} else {
+			ts.ID = "T4"
+			ts.Description = "Tag points to different objects on Core and GitHub"
+			ts.Conflict = true
+		}
+
+		tagStates = append(tagStates, ts)
+	}
+
+	return tagStates, nil
+}
+
+// filterTagRefs extracts tags from ls-remote refs, keyed by short tag name
+func filterTagRefs(refs map[string]string) map[string]string {
+	tags := make(map[string]string)
+	for ref, hash := range refs {
+		if strings.HasPrefix(ref, "refs/tags/") {
+			tags[strings.TrimPrefix(ref, "refs/tags/")] = hash
+		}
+	}
+	return tags
+}
+
+// detectDefaultBranch compares the HEAD of Core and GitHub, and the local
+// init.defaultBranch, against the authoritative default branch (D1-D4)
+func (c *Classifier) detectDefaultBranch(gc *git.Client) (DefaultBranchState, error) {
+	ds := DefaultBranchState{}
+
+	coreHead, err := gc.GetRemoteHEAD(c.coreRemote)
+	if err != nil {
+		return ds, fmt.Errorf("failed to read HEAD of %s: %w", c.coreRemote, err)
+	}
+	githubHead, err := gc.GetRemoteHEAD(c.githubRemote)
+	if err != nil {
+		return ds, fmt.Errorf("failed to read HEAD of %s: %w", c.githubRemote, err)
+	}
+	coreRefs, err := gc.ListRemoteRefs(c.coreRemote)
+	if err != nil {
+		return ds, fmt.Errorf("failed to list branches on %s: %w", c.coreRemote, err)
+	}
+	githubRefs, err := gc.ListRemoteRefs(c.githubRemote)
+	if err != nil {
+		return ds, fmt.Errorf("failed to list branches on %s: %w", c.githubRemote, err)
+	}
+
+	// Servers don't advertise a dangling HEAD; Core is a bare repository we
+	// can read directly
+	if coreHead == "" && len(coreRefs) > 0 {
+		coreHead, _ = gc.GetBareHEAD(c.coreRemote)
+	}
+
+	ds.Core = coreHead
+	ds.GitHub = githubHead
+	ds.Local, _ = gc.ConfigGet("init.defaultBranch")
+	if ds.Local == "" {
+		ds.Local = constants.MasterBranch // git's built-in default
+		ds.LocalImplicit = true
+	}
+
+	// Core is authoritative unless its HEAD is dangling (common for bare
+	// repositories created before init.defaultBranch was set)
+	_, coreHeadExists := coreRefs["refs/heads/"+coreHead]
+	switch {
+	case coreHead != "" && (coreHeadExists || githubHead == ""):
+		ds.Branch, ds.Authority = coreHead, "core"
+	case githubHead != "":
+		ds.Branch, ds.Authority = githubHead, "github"
+	}
+	_, ds.CoreHasBranch = coreRefs["refs/heads/"+ds.Branch]
+	_, ds.GitHubHasBranch = githubRefs["refs/heads/"+ds.Branch]
+
+	// Classify; an empty remote has no HEAD to disagree with
+	switch {
+	case coreHead != "" && githubHead != "" && coreHead != githubHead && ds.Authority == "core":
+		ds.ID = "D2"
+		ds.Description = fmt.Sprintf("GitHub's default branch is %s, Core's is %s", githubHead, coreHead)
+	case coreHead != "" && githubHead != "" && coreHead != githubHead:
+		ds.ID = "D3"
+		ds.Description = fmt.Sprintf("Core's HEAD points to missing branch %s, GitHub's default is %s", coreHead, githubHead)
+	case ds.Branch != "" && ds.Local != ds.Branch:
+		ds.ID = "D4"
+		ds.Description = fmt.Sprintf("init.defaultBranch is %s, remotes use %s", ds.Local, ds.Branch)
+	default:
+		ds.ID = "D1"
+		ds.Description = "Default branch consistent"
+	}
+
+	return ds, nil
+}
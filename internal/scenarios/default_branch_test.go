@@ -0,0 +1,157 @@
+package scenarios
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+// setupDefaultBranchRepos creates Core and GitHub bare repositories with main
+// and dev pushed to both, and returns the local clone
+func setupDefaultBranchRepos(t *testing.T, coreHEAD, githubHEAD string) string {
+	t.Helper()
+	tmp := t.TempDir()
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", "-b", coreHEAD, "core.git")
+	runGit(t, tmp, "init", "--bare", "-b", githubHEAD, "github.git")
+
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "config", "init.defaultBranch", "main")
+	runGit(t, local, "remote", "add", "origin", filepath.Join(tmp, "core.git"))
+	runGit(t, local, "remote", "add", "github", filepath.Join(tmp, "github.git"))
+	runGit(t, local, "commit", "--allow-empty", "-m", "first")
+	runGit(t, local, "branch", "dev")
+	for _, remote := range []string{"origin", "github"} {
+		runGit(t, local, "push", remote, "main", "dev")
+	}
+	return local
+}
+
+func detectDefaultBranchState(t *testing.T, local string) *RepositoryState {
+	t.Helper()
+	options := DefaultDetectionOptions()
+	options.SkipFetch = true
+	options.SkipCorruption = true
+	options.SkipBranches = true
+	options.SkipTags = true
+
+	state, err := NewClassifier(git.NewClient(local), "origin", "github", options).Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if state.DefaultBranchState == nil {
+		t.Fatalf("DefaultBranchState not detected, warnings: %+v", state.Warnings)
+	}
+	return state
+}
+
+func TestDetect_DefaultBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		coreHEAD   string
+		githubHEAD string
+		localInit  string
+		wantID     string
+		wantBranch string
+		wantAuth   string
+	}{
+		{name: "consistent", coreHEAD: "main", githubHEAD: "main", localInit: "main", wantID: "D1", wantBranch: "main", wantAuth: "core"},
+		{name: "github differs", coreHEAD: "main", githubHEAD: "dev", localInit: "main", wantID: "D2", wantBranch: "main", wantAuth: "core"},
+		{name: "core dangling", coreHEAD: "master", githubHEAD: "main", localInit: "main", wantID: "D3", wantBranch: "main", wantAuth: "github"},
+		{name: "init differs", coreHEAD: "main", githubHEAD: "main", localInit: "trunk", wantID: "D4", wantBranch: "main", wantAuth: "core"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := setupDefaultBranchRepos(t, tt.coreHEAD, tt.githubHEAD)
+			runGit(t, local, "config", "init.defaultBranch", tt.localInit)
+
+			ds := detectDefaultBranchState(t, local).DefaultBranchState
+			if ds.ID != tt.wantID || ds.Branch != tt.wantBranch || ds.Authority != tt.wantAuth {
+				t.Errorf("DefaultBranchState = %+v, want %s on %s from %s", ds, tt.wantID, tt.wantBranch, tt.wantAuth)
+			}
+			if ds.Core != tt.coreHEAD {
+				t.Errorf("Core = %q, want %q", ds.Core, tt.coreHEAD)
+			}
+		})
+	}
+}
+
+func TestSuggestFixes_DefaultBranch(t *testing.T) {
+	state := &RepositoryState{
+		CoreRemote:   "origin",
+		GitHubRemote: "github",
+		Existence: ExistenceState{
+			ID:        "E1",
+			CoreURL:   "ssh://git@core.example:2222/srv/repo.git",
+			GitHubURL: "git@github.com:owner/repo.git",
+		},
+		DefaultBranchState: &DefaultBranchState{
+			ID: "D2", Core: "main", GitHub: "master", Branch: "main", Authority: "core",
+			CoreHasBranch: true, GitHubHasBranch: true,
+		},
+	}
+
+	// D2: GitHub follows Core through the API
+	fixes := suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, "origin", "github")
+	if len(fixes) != 1 || !fixes[0].AutoFixable {
+		t.Fatalf("D2 fixes = %+v, want one auto-fixable fix", fixes)
+	}
+	op, ok := fixes[0].Operation.(*SetPlatformDefaultBranchOperation)
+	if !ok || op.Remote != "github" || op.Branch != "main" || op.Previous != "master" {
+		t.Errorf("D2 operation = %#v", fixes[0].Operation)
+	}
+
+	// D2 without the branch on GitHub: push first, manually
+	state.DefaultBranchState.GitHubHasBranch = false
+	fixes = suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, "origin", "github")
+	if fixes[0].AutoFixable || !strings.HasPrefix(fixes[0].Command, "git push github refs/heads/main && gh repo edit") {
+		t.Errorf("D2 fix without branch = %+v", fixes[0])
+	}
+
+	// D3: Core's HEAD is repointed over SSH
+	state.DefaultBranchState = &DefaultBranchState{
+		ID: "D3", Core: "master", GitHub: "main", Branch: "main", Authority: "github",
+		CoreHasBranch: true, GitHubHasBranch: true,
+	}
+	fixes = suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, "origin", "github")
+	if len(fixes) != 1 || !fixes[0].AutoFixable {
+		t.Fatalf("D3 fixes = %+v, want one auto-fixable fix", fixes)
+	}
+	if _, ok := fixes[0].Operation.(*SetRemoteHEADOperation); !ok {
+		t.Errorf("D3 operation = %#v, want SetRemoteHEADOperation", fixes[0].Operation)
+	}
+	want := "ssh -p 2222 git@core.example git --git-dir /srv/repo.git symbolic-ref HEAD refs/heads/main"
+	if fixes[0].Command != want {
+		t.Errorf("D3 command = %q, want %q", fixes[0].Command, want)
+	}
+
+	// D4: only a manual suggestion
+	state.DefaultBranchState = &DefaultBranchState{ID: "D4", Local: "master", Branch: "main"}
+	fixes = suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, "origin", "github")
+	if len(fixes) != 1 || fixes[0].AutoFixable || fixes[0].Command != "git config --global init.defaultBranch main" {
+		t.Errorf("D4 fixes = %+v", fixes)
+	}
+}
+
+func TestSetRemoteHEADOperation_FixesDanglingCore(t *testing.T) {
+	local := setupDefaultBranchRepos(t, "master", "main")
+	state := detectDefaultBranchState(t, local)
+
+	fixes := suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, "origin", "github")
+	if len(fixes) != 1 || fixes[0].ScenarioID != "D3" {
+		t.Fatalf("fixes = %+v, want one D3 fix", fixes)
+	}
+
+	gc := git.NewClient(local)
+	if err := NewAutoFixExecutor(gc).Execute(fixes[0], state); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if ds := detectDefaultBranchState(t, local).DefaultBranchState; ds.ID != "D1" {
+		t.Errorf("after fix DefaultBranchState = %+v, want D1", ds)
+	}
+}
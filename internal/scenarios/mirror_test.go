@@ -0,0 +1,97 @@
+package scenarios
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+// runGit runs a git command in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func TestDetect_MirrorSet(t *testing.T) {
+	tmp := t.TempDir()
+	local := filepath.Join(tmp, "local")
+
+	for _, name := range []string{"core", "github", "backup"} {
+		runGit(t, tmp, "init", "--bare", "-b", "main", name+".git")
+	}
+
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "remote", "add", "origin", filepath.Join(tmp, "core.git"))
+	runGit(t, local, "remote", "add", "github", filepath.Join(tmp, "github.git"))
+	runGit(t, local, "remote", "add", "backup", filepath.Join(tmp, "backup.git"))
+
+	runGit(t, local, "commit", "--allow-empty", "-m", "first")
+	for _, remote := range []string{"origin", "github", "backup"} {
+		runGit(t, local, "push", remote, "main")
+	}
+
+	// Two more commits reach core and github but not the backup
+	runGit(t, local, "commit", "--allow-empty", "-m", "second")
+	runGit(t, local, "commit", "--allow-empty", "-m", "third")
+	runGit(t, local, "push", "origin", "main")
+	runGit(t, local, "push", "github", "main")
+
+	options := DefaultDetectionOptions()
+	options.SkipCorruption = true
+	options.SkipBranches = true
+
+	classifier := NewMirrorClassifier(git.NewClient(local), []string{"origin", "github", "backup", "offsite"}, options)
+	state, err := classifier.Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	// Core/GitHub projection is unchanged by the extra mirrors
+	if state.Existence.ID != "E1" {
+		t.Errorf("Existence.ID = %s, want E1", state.Existence.ID)
+	}
+	if state.Sync.ID != "S1" {
+		t.Errorf("Sync.ID = %s, want S1", state.Sync.ID)
+	}
+
+	if len(state.Sync.Mirrors) != 4 {
+		t.Fatalf("len(Sync.Mirrors) = %d, want 4: %+v", len(state.Sync.Mirrors), state.Sync.Mirrors)
+	}
+
+	byName := map[string]MirrorState{}
+	for _, m := range state.Sync.Mirrors {
+		byName[m.Remote] = m
+	}
+
+	if !byName["origin"].InSync() || !byName["github"].InSync() {
+		t.Errorf("origin/github should be in sync: %+v %+v", byName["origin"], byName["github"])
+	}
+	if backup := byName["backup"]; backup.LocalAhead != 2 || backup.LocalBehind != 0 {
+		t.Errorf("backup ahead/behind = %d/%d, want 2/0", backup.LocalAhead, backup.LocalBehind)
+	}
+	if offsite := byName["offsite"]; offsite.Configured || offsite.Error == "" {
+		t.Errorf("offsite should be reported as not configured: %+v", offsite)
+	}
+
+	fixes := SuggestFixes(state)
+	found := false
+	for _, fix := range fixes {
+		if fix.ScenarioID == "S_MIRROR_BEHIND" && fix.Command == "git push backup main" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected S_MIRROR_BEHIND fix for backup, got %+v", fixes)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/remote"
 )
 
 // ============================================================================
@@ -212,6 +213,120 @@ func (op *PullOperation) Rollback(gitClient interface{}) error {
 	return fmt.Errorf("pull rollback requires manual intervention (use: git reset --hard ORIG_HEAD)")
 }
 
+// SetRemoteHEADOperation - repoint a bare remote's HEAD (its default branch)
+type SetRemoteHEADOperation struct {
+	Remote   string
+	Branch   string
+	Previous string // HEAD before the change, restored on rollback
+}
+
+func (op *SetRemoteHEADOperation) Validate(state *RepositoryState, gitClient interface{}) error {
+	gc, ok := gitClient.(*git.Client)
+	if !ok {
+		return fmt.Errorf("invalid git client type")
+	}
+
+	// Never point HEAD at a branch the remote doesn't have
+	refs, err := gc.ListRemoteRefs(op.Remote)
+	if err != nil {
+		return fmt.Errorf("remote %s is not reachable: %w", op.Remote, err)
+	}
+	if _, ok := refs["refs/heads/"+op.Branch]; !ok {
+		return fmt.Errorf("branch %s does not exist on %s", op.Branch, op.Remote)
+	}
+	return nil
+}
+
+func (op *SetRemoteHEADOperation) Execute(gitClient interface{}) error {
+	gc, ok := gitClient.(*git.Client)
+	if !ok {
+		return fmt.Errorf("invalid git client type")
+	}
+
+	return gc.SetRemoteHEAD(op.Remote, op.Branch)
+}
+
+func (op *SetRemoteHEADOperation) Describe() string {
+	return fmt.Sprintf("Point HEAD of %s at %s", op.Remote, op.Branch)
+}
+
+func (op *SetRemoteHEADOperation) Rollback(gitClient interface{}) error {
+	gc, ok := gitClient.(*git.Client)
+	if !ok {
+		return fmt.Errorf("invalid git client type")
+	}
+	if op.Previous == "" {
+		return nil
+	}
+
+	return gc.SetRemoteHEAD(op.Remote, op.Previous)
+}
+
+// SetPlatformDefaultBranchOperation - change the default branch of a hosted
+// remote (GitHub, GitLab, Gitea) through its API
+type SetPlatformDefaultBranchOperation struct {
+	Remote   string
+	Branch   string
+	Previous string // Default branch before the change, restored on rollback
+
+	// newPlatform creates the API client for a remote URL (overridable in tests)
+	newPlatform func(remoteURL string) (remote.Platform, error)
+}
+
+func (op *SetPlatformDefaultBranchOperation) Validate(state *RepositoryState, gitClient interface{}) error {
+	gc, ok := gitClient.(*git.Client)
+	if !ok {
+		return fmt.Errorf("invalid git client type")
+	}
+
+	// The platform rejects a default branch it doesn't have
+	refs, err := gc.ListRemoteRefs(op.Remote)
+	if err != nil {
+		return fmt.Errorf("remote %s is not reachable: %w", op.Remote, err)
+	}
+	if _, ok := refs["refs/heads/"+op.Branch]; !ok {
+		return fmt.Errorf("branch %s does not exist on %s", op.Branch, op.Remote)
+	}
+	return nil
+}
+
+func (op *SetPlatformDefaultBranchOperation) Execute(gitClient interface{}) error {
+	return op.setDefaultBranch(gitClient, op.Branch)
+}
+
+func (op *SetPlatformDefaultBranchOperation) Describe() string {
+	return fmt.Sprintf("Set default branch of %s to %s", op.Remote, op.Branch)
+}
+
+func (op *SetPlatformDefaultBranchOperation) Rollback(gitClient interface{}) error {
+	if op.Previous == "" {
+		return nil
+	}
+	return op.setDefaultBranch(gitClient, op.Previous)
+}
+
+func (op *SetPlatformDefaultBranchOperation) setDefaultBranch(gitClient interface{}, branch string) error {
+	gc, ok := gitClient.(*git.Client)
+	if !ok {
+		return fmt.Errorf("invalid git client type")
+	}
+
+	remoteURL, err := gc.GetRemoteURL(op.Remote)
+	if err != nil {
+		return fmt.Errorf("failed to get URL of %s: %w", op.Remote, err)
+	}
+
+	newPlatform := op.newPlatform
+	if newPlatform == nil {
+		newPlatform = remote.NewClient
+	}
+	platform, err := newPlatform(remoteURL)
+	if err != nil {
+		return err
+	}
+	return platform.SetDefaultBranch(branch)
+}
+
 // CompositeOperation - sequence of operations (executed in order)
 type CompositeOperation struct {
 	Operations  []Operation
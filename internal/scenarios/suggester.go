@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/lcgerke/githelper/internal/constants"
+	"github.com/lcgerke/githelper/internal/git"
 )
 
 // ============================================================================
@@ -18,8 +19,11 @@ func SuggestFixes(state *RepositoryState) []Fix {
 	// Suggest fixes based on each dimension
 	fixes = append(fixes, suggestExistenceFixes(state.Existence)...)
 	fixes = append(fixes, suggestSyncFixes(state.Sync, state.CoreRemote, state.GitHubRemote)...)
+	fixes = append(fixes, suggestMirrorFixes(state.Sync, state.CoreRemote, state.GitHubRemote)...)
 	fixes = append(fixes, suggestWorkingTreeFixes(state.WorkingTree)...)
 	fixes = append(fixes, suggestCorruptionFixes(state.Corruption)...)
+	fixes = append(fixes, suggestTagFixes(state.Tags, state.CoreRemote, state.GitHubRemote)...)
+	fixes = append(fixes, suggestDefaultBranchFixes(state.DefaultBranchState, state.Existence, state.CoreRemote, state.GitHubRemote)...)
 
 	// Sort by priority (1=critical, 5=low)
 	return fixes
@@ -113,6 +117,47 @@ func suggestExistenceFixes(existence ExistenceState) []Fix {
 	}
 }
 
+// suggestMirrorFixes suggests fixes for mirrors beyond Core and GitHub.
+// Core and GitHub are already covered by the S1-S13 projection.
+func suggestMirrorFixes(sync SyncState, coreRemote, githubRemote string) []Fix {
+	var fixes []Fix
+
+	for _, mirror := range sync.Mirrors {
+		if mirror.Remote == coreRemote || mirror.Remote == githubRemote || !mirror.Configured {
+			continue
+		}
+
+		switch {
+		case mirror.LocalBehind > 0:
+			fixes = append(fixes, Fix{
+				ScenarioID:  "S_MIRROR_DIVERGED",
+				Description: fmt.Sprintf("Mirror %s has %d commits not in local", mirror.Remote, mirror.LocalBehind),
+				Command:     fmt.Sprintf("git log %s..%s/%s", sync.Branch, mirror.Remote, sync.Branch),
+				Operation:   nil,
+				AutoFixable: false,
+				Priority:    2,
+				Reason:      "Unknown commits on a mirror must be reviewed before overwriting",
+			})
+
+		case mirror.LocalAhead > 0:
+			fixes = append(fixes, Fix{
+				ScenarioID:  "S_MIRROR_BEHIND",
+				Description: fmt.Sprintf("Mirror %s is %d commits behind", mirror.Remote, mirror.LocalAhead),
+				Command:     fmt.Sprintf("git push %s %s", mirror.Remote, sync.Branch),
+				Operation: &PushOperation{
+					Remote:  mirror.Remote,
+					Refspec: sync.Branch,
+				},
+				AutoFixable: true,
+				Priority:    3,
+				Reason:      "Bring the mirror up to date with local",
+			})
+		}
+	}
+
+	return fixes
+}
+
 // suggestSyncFixes suggests fixes for sync scenarios (S1-S13)
 func suggestSyncFixes(sync SyncState, coreRemote, githubRemote string) []Fix {
 	switch sync.ID {
@@ -585,6 +630,163 @@ func suggestBranchFixes(branch BranchState, coreRemote, githubRemote string) []F
 	}
 }
 
+// suggestTagFixes suggests fixes for tag scenarios (T1-T4).
+// Missing tags are pushed without --force; conflicting tags are only ever flagged.
+func suggestTagFixes(tags []TagState, coreRemote, githubRemote string) []Fix {
+	var fixes []Fix
+
+	for _, tag := range tags {
+		refspec := "refs/tags/" + tag.Tag
+
+		switch tag.ID {
+		case "T2": // Tag missing on GitHub
+			fixes = append(fixes, missingTagFix("T2", tag, refspec, coreRemote, tag.CoreHash, githubRemote))
+
+		case "T3": // Tag missing on Core
+			fixes = append(fixes, missingTagFix("T3", tag, refspec, githubRemote, tag.GitHubHash, coreRemote))
+
+		case "T4": // Tag conflict
+			fixes = append(fixes, Fix{
+				ScenarioID:  "T4",
+				Description: fmt.Sprintf("Tag %s points to different objects on %s and %s", tag.Tag, coreRemote, githubRemote),
+				Command:     fmt.Sprintf("git ls-remote --tags %s %s && git ls-remote --tags %s %s", coreRemote, refspec, githubRemote, refspec),
+				Operation:   nil,
+				AutoFixable: false,
+				Priority:    1,
+				Reason:      "Conflicting tags may indicate tampering - decide which object is authoritative, tags are never force-overwritten",
+			})
+		}
+	}
+
+	return fixes
+}
+
+// missingTagFix builds the fix for a tag that exists on only one remote.
+// It is auto-fixable only when the local tag matches the source remote, so the
+// push can never publish a different object than the one already released.
+func missingTagFix(scenarioID string, tag TagState, refspec, sourceRemote, sourceHash, targetRemote string) Fix {
+	fix := Fix{
+		ScenarioID:  scenarioID,
+		Description: fmt.Sprintf("Tag %s exists on %s but not on %s", tag.Tag, sourceRemote, targetRemote),
+		Priority:    3,
+		Reason:      fmt.Sprintf("Publish the tag to %s", targetRemote),
+	}
+
+	if tag.LocalHash == sourceHash {
+		fix.Command = fmt.Sprintf("git push %s %s", targetRemote, refspec)
+		fix.Operation = &PushOperation{
+			Remote:  targetRemote,
+			Refspec: refspec,
+		}
+		fix.AutoFixable = true
+		return fix
+	}
+
+	// Local tag is missing or differs from the source; fetch the source's tag first
+	fix.Command = fmt.Sprintf("git fetch %s %s:%s && git push %s %s", sourceRemote, refspec, refspec, targetRemote, refspec)
+	if tag.LocalHash != "" {
+		fix.Reason = fmt.Sprintf("Local tag differs from %s - resolve locally before publishing to %s", sourceRemote, targetRemote)
+	}
+	return fix
+}
+
+// suggestDefaultBranchFixes suggests fixes for default branch scenarios (D1-D4).
+// The non-authoritative side follows the authoritative one: GitHub is updated
+// through its API, Core by repointing its bare repository's HEAD.
+func suggestDefaultBranchFixes(ds *DefaultBranchState, existence ExistenceState, coreRemote, githubRemote string) []Fix {
+	if ds == nil {
+		return nil
+	}
+
+	switch ds.ID {
+	case "D2": // GitHub differs, Core authoritative
+		fix := Fix{
+			ScenarioID:  "D2",
+			Description: fmt.Sprintf("GitHub's default branch is %s but Core's is %s", ds.GitHub, ds.Branch),
+			Command:     fmt.Sprintf("gh repo edit %s --default-branch %s", githubRepoArg(existence.GitHubURL), ds.Branch),
+			Priority:    2,
+			Reason:      "Clones from GitHub and pull requests target the wrong branch",
+		}
+		if ds.GitHubHasBranch {
+			fix.Operation = &SetPlatformDefaultBranchOperation{
+				Remote:   githubRemote,
+				Branch:   ds.Branch,
+				Previous: ds.GitHub,
+			}
+			fix.AutoFixable = true
+		} else {
+			fix.Command = fmt.Sprintf("git push %s refs/heads/%s && %s", githubRemote, ds.Branch, fix.Command)
+			fix.Reason = fmt.Sprintf("GitHub has no %s branch - push it before making it the default", ds.Branch)
+		}
+		return []Fix{fix}
+
+	case "D3": // Core's HEAD dangling, GitHub authoritative
+		fix := Fix{
+			ScenarioID:  "D3",
+			Description: fmt.Sprintf("Core's HEAD points to missing branch %s, GitHub's default is %s", ds.Core, ds.Branch),
+			Command:     bareHEADCommand(existence.CoreURL, ds.Branch),
+			Priority:    2,
+			Reason:      "Clones from Core check out nothing and tools read the wrong default branch",
+		}
+		if ds.CoreHasBranch {
+			fix.Operation = &SetRemoteHEADOperation{
+				Remote:   coreRemote,
+				Branch:   ds.Branch,
+				Previous: ds.Core,
+			}
+			fix.AutoFixable = true
+		} else {
+			fix.Command = fmt.Sprintf("git push %s refs/heads/%s && %s", coreRemote, ds.Branch, fix.Command)
+			fix.Reason = fmt.Sprintf("Core has no %s branch - push it before pointing HEAD at it", ds.Branch)
+		}
+		return []Fix{fix}
+
+	case "D4": // init.defaultBranch differs
+		return []Fix{{
+			ScenarioID:  "D4",
+			Description: fmt.Sprintf("init.defaultBranch is %s but the remotes use %s", ds.Local, ds.Branch),
+			Command:     fmt.Sprintf("git config --global init.defaultBranch %s", ds.Branch),
+			Operation:   nil,
+			AutoFixable: false, // Global config is the user's choice
+			Priority:    5,
+			Reason:      "New repositories would start on a different branch than the remotes use",
+		}}
+
+	default:
+		return nil
+	}
+}
+
+// githubRepoArg returns the repository argument for gh commands
+func githubRepoArg(githubURL string) string {
+	if githubURL == "" {
+		return "<owner>/<repo>"
+	}
+	return githubURL
+}
+
+// bareHEADCommand returns the command that repoints a bare repository's HEAD,
+// run over SSH when the repository isn't local
+func bareHEADCommand(coreURL, branch string) string {
+	symref := fmt.Sprintf("symbolic-ref HEAD refs/heads/%s", branch)
+	loc, err := git.ParseBareURL(coreURL)
+	if err != nil {
+		return fmt.Sprintf("git --git-dir <path> %s", symref)
+	}
+	if loc.IsLocal() {
+		return fmt.Sprintf("git --git-dir %s %s", loc.Path, symref)
+	}
+
+	target := loc.Host
+	if loc.User != "" {
+		target = loc.User + "@" + loc.Host
+	}
+	if loc.Port != "" {
+		target = "-p " + loc.Port + " " + target
+	}
+	return fmt.Sprintf("ssh %s git --git-dir %s %s", target, loc.Path, symref)
+}
+
 // PrioritizeFixes sorts fixes by priority (1=critical, 5=low)
 func PrioritizeFixes(fixes []Fix) []Fix {
 	sort.Slice(fixes, func(i, j int) bool {
@@ -255,3 +255,41 @@ func TestSuggestFixes_S1_Partial(t *testing.T) {
 		t.Errorf("Expected 0 fixes for S1, got %d", len(fixes))
 	}
 }
+
+// Test fixes for mirrors beyond Core and GitHub
+func TestSuggestMirrorFixes(t *testing.T) {
+	sync := SyncState{
+		ID:     "S1",
+		Branch: "main",
+		Mirrors: []MirrorState{
+			{Remote: "origin", Configured: true, LocalAhead: 2}, // covered by S1-S13
+			{Remote: "github", Configured: true},
+			{Remote: "backup", Configured: true, LocalAhead: 2},
+			{Remote: "offsite", Configured: true, LocalAhead: 1, LocalBehind: 1, Diverged: true},
+			{Remote: "missing", Configured: false, Error: "remote not configured"},
+		},
+	}
+
+	fixes := suggestMirrorFixes(sync, "origin", "github")
+
+	if len(fixes) != 2 {
+		t.Fatalf("Expected 2 fixes, got %d: %+v", len(fixes), fixes)
+	}
+
+	if fixes[0].ScenarioID != "S_MIRROR_BEHIND" {
+		t.Errorf("Expected ScenarioID S_MIRROR_BEHIND, got %s", fixes[0].ScenarioID)
+	}
+	if fixes[0].Command != "git push backup main" {
+		t.Errorf("Expected command 'git push backup main', got %s", fixes[0].Command)
+	}
+	if !fixes[0].AutoFixable {
+		t.Error("Expected behind mirror to be auto-fixable")
+	}
+
+	if fixes[1].ScenarioID != "S_MIRROR_DIVERGED" {
+		t.Errorf("Expected ScenarioID S_MIRROR_DIVERGED, got %s", fixes[1].ScenarioID)
+	}
+	if fixes[1].AutoFixable {
+		t.Error("Expected diverged mirror not to be auto-fixable")
+	}
+}
@@ -0,0 +1,269 @@
+package scenarios
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+// ============================================================================
+// Multi-ref sync planning (Core → GitHub)
+// ============================================================================
+
+// RefSyncStatus classifies a single ref between Core and GitHub
+type RefSyncStatus string
+
+const (
+	RefSynced        RefSyncStatus = "synced"         // Same object on both sides
+	RefFastForward   RefSyncStatus = "fast_forward"   // Core ahead, GitHub can be fast-forwarded
+	RefMissingGitHub RefSyncStatus = "missing_github" // Only on Core, can be created on GitHub
+	RefGitHubAhead   RefSyncStatus = "github_ahead"   // GitHub has commits Core doesn't
+	RefDiverged      RefSyncStatus = "diverged"       // Both sides have unique commits (or a tag moved)
+	RefMissingCore   RefSyncStatus = "missing_core"   // Only on GitHub
+)
+
+// Ref kinds
+const (
+	RefKindBranch = "branch"
+	RefKindTag    = "tag"
+)
+
+// Ref actions
+const (
+	RefActionNone   = "none"   // Nothing to do
+	RefActionPush   = "push"   // Safe: push Core's object to GitHub
+	RefActionManual = "manual" // Unsafe: needs a human decision
+)
+
+// Ref results after applying a plan
+const (
+	RefResultPushed  = "pushed"
+	RefResultFailed  = "failed"
+	RefResultSkipped = "skipped"
+)
+
+// RefPlan is the planned (and, once applied, actual) outcome for one ref
+type RefPlan struct {
+	Ref        string        `json:"ref"`         // Full ref name, e.g. refs/heads/main
+	Name       string        `json:"name"`        // Short name, e.g. main
+	Kind       string        `json:"kind"`        // "branch" or "tag"
+	ScenarioID string        `json:"scenario_id"` // B1-B7 equivalent (Core as source, GitHub as target)
+	Status     RefSyncStatus `json:"status"`
+	Action     string        `json:"action"`
+
+	CoreHash   string `json:"core_hash,omitempty"`
+	GitHubHash string `json:"github_hash,omitempty"`
+
+	CoreAhead   int `json:"core_ahead,omitempty"`
+	GitHubAhead int `json:"github_ahead,omitempty"`
+
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SyncPlan is the per-ref plan for syncing GitHub with Core
+type SyncPlan struct {
+	CoreRemote   string    `json:"core_remote"`
+	GitHubRemote string    `json:"github_remote"`
+	Refs         []RefPlan `json:"refs"`
+}
+
+// SyncPlanSummary counts refs per status and result
+type SyncPlanSummary struct {
+	Total    int                   `json:"total"`
+	ByStatus map[RefSyncStatus]int `json:"by_status"`
+	Pushed   int                   `json:"pushed"`
+	Failed   int                   `json:"failed"`
+	Manual   int                   `json:"manual"`
+}
+
+// Summary returns per-status counts for the plan
+func (p *SyncPlan) Summary() SyncPlanSummary {
+	summary := SyncPlanSummary{
+		Total:    len(p.Refs),
+		ByStatus: make(map[RefSyncStatus]int),
+	}
+	for _, ref := range p.Refs {
+		summary.ByStatus[ref.Status]++
+		switch {
+		case ref.Result == RefResultPushed:
+			summary.Pushed++
+		case ref.Result == RefResultFailed:
+			summary.Failed++
+		}
+		if ref.Action == RefActionManual {
+			summary.Manual++
+		}
+	}
+	return summary
+}
+
+// Pending returns the refs that applying the plan would push
+func (p *SyncPlan) Pending() []RefPlan {
+	var pending []RefPlan
+	for _, ref := range p.Refs {
+		if ref.Action == RefActionPush && ref.Result == "" {
+			pending = append(pending, ref)
+		}
+	}
+	return pending
+}
+
+// syncPlanGit is the subset of git.Client used for sync planning
+type syncPlanGit interface {
+	ListRemoteRefs(remote string) (map[string]string, error)
+	FetchRemoteRefs(remote string) error
+	CountCommitsBetween(ref1, ref2 string) (int, error)
+	Push(remote, refspec string) error
+}
+
+var _ syncPlanGit = (*git.Client)(nil)
+
+// BuildSyncPlan compares every branch and tag on coreRemote with githubRemote.
+// If branches is non-empty only those branches are planned and tags are skipped.
+// Both remotes are fetched first so commit counts can be computed locally.
+// Tags are fetched into per-remote namespaces, so a tag that differs between
+// the remotes is planned as diverged instead of failing the fetch.
+func BuildSyncPlan(gc syncPlanGit, coreRemote, githubRemote string, branches []string) (*SyncPlan, error) {
+	if err := gc.FetchRemoteRefs(coreRemote); err != nil {
+		return nil, fmt.Errorf("failed to fetch from %s: %w", coreRemote, err)
+	}
+	if err := gc.FetchRemoteRefs(githubRemote); err != nil {
+		return nil, fmt.Errorf("failed to fetch from %s: %w", githubRemote, err)
+	}
+
+	coreRefs, err := gc.ListRemoteRefs(coreRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs on %s: %w", coreRemote, err)
+	}
+	githubRefs, err := gc.ListRemoteRefs(githubRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs on %s: %w", githubRemote, err)
+	}
+
+	wanted := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		wanted["refs/heads/"+b] = true
+	}
+
+	// Union of refs on both sides
+	names := make(map[string]bool)
+	for ref := range coreRefs {
+		names[ref] = true
+	}
+	for ref := range githubRefs {
+		names[ref] = true
+	}
+
+	plan := &SyncPlan{CoreRemote: coreRemote, GitHubRemote: githubRemote}
+	for ref := range names {
+		if len(wanted) > 0 && !wanted[ref] {
+			continue
+		}
+		plan.Refs = append(plan.Refs, classifyRef(gc, ref, coreRefs[ref], githubRefs[ref]))
+	}
+
+	// Requested branches that exist nowhere are reported rather than silently dropped
+	for ref := range wanted {
+		if !names[ref] {
+			plan.Refs = append(plan.Refs, RefPlan{
+				Ref:    ref,
+				Name:   strings.TrimPrefix(ref, "refs/heads/"),
+				Kind:   RefKindBranch,
+				Status: RefMissingCore,
+				Action: RefActionNone,
+				Error:  "branch not found on either remote",
+			})
+		}
+	}
+
+	// Branches first, then tags, alphabetical within each
+	sort.Slice(plan.Refs, func(i, j int) bool {
+		if plan.Refs[i].Kind != plan.Refs[j].Kind {
+			return plan.Refs[i].Kind == RefKindBranch
+		}
+		return plan.Refs[i].Name < plan.Refs[j].Name
+	})
+
+	return plan, nil
+}
+
+// classifyRef determines the sync status of one ref.
+// Scenario IDs follow detectBranchTopology with Core as "local" and GitHub as "remote".
+func classifyRef(gc syncPlanGit, ref, coreHash, githubHash string) RefPlan {
+	rp := RefPlan{
+		Ref:        ref,
+		CoreHash:   coreHash,
+		GitHubHash: githubHash,
+	}
+
+	if strings.HasPrefix(ref, "refs/tags/") {
+		rp.Kind = RefKindTag
+		rp.Name = strings.TrimPrefix(ref, "refs/tags/")
+	} else {
+		rp.Kind = RefKindBranch
+		rp.Name = strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	switch {
+	case coreHash == githubHash:
+		rp.ScenarioID, rp.Status, rp.Action = "B1", RefSynced, RefActionNone
+
+	case githubHash == "":
+		rp.ScenarioID, rp.Status, rp.Action = "B6", RefMissingGitHub, RefActionPush
+
+	case coreHash == "":
+		// Branches created directly on GitHub (e.g. bot branches) are reported, not treated as conflicts
+		rp.ScenarioID, rp.Status, rp.Action = "B7", RefMissingCore, RefActionNone
+
+	case rp.Kind == RefKindTag:
+		// Tags never move; a tag pointing at different objects is never force-updated
+		rp.ScenarioID, rp.Status, rp.Action = "B4", RefDiverged, RefActionManual
+
+	default:
+		rp.CoreAhead, _ = gc.CountCommitsBetween(coreHash, githubHash)
+		rp.GitHubAhead, _ = gc.CountCommitsBetween(githubHash, coreHash)
+
+		switch {
+		case rp.CoreAhead > 0 && rp.GitHubAhead > 0:
+			rp.ScenarioID, rp.Status, rp.Action = "B4", RefDiverged, RefActionManual
+		case rp.GitHubAhead > 0:
+			rp.ScenarioID, rp.Status, rp.Action = "B3", RefGitHubAhead, RefActionManual
+		default:
+			rp.ScenarioID, rp.Status, rp.Action = "B2", RefFastForward, RefActionPush
+		}
+	}
+
+	return rp
+}
+
+// ApplySyncPlan pushes every safe ref in the plan to GitHub and records per-ref results.
+// Pushes are never forced, so a ref that moved since planning fails instead of being overwritten.
+// Returns an error if any push failed; the plan still holds the per-ref outcome.
+func ApplySyncPlan(gc syncPlanGit, plan *SyncPlan) error {
+	failed := 0
+
+	for i := range plan.Refs {
+		ref := &plan.Refs[i]
+		if ref.Action != RefActionPush {
+			ref.Result = RefResultSkipped
+			continue
+		}
+
+		refspec := fmt.Sprintf("%s:%s", ref.CoreHash, ref.Ref)
+		if err := gc.Push(plan.GitHubRemote, refspec); err != nil {
+			ref.Result = RefResultFailed
+			ref.Error = err.Error()
+			failed++
+			continue
+		}
+		ref.Result = RefResultPushed
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d ref(s) failed to sync", failed)
+	}
+	return nil
+}
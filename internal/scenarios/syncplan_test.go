@@ -0,0 +1,206 @@
+package scenarios
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+// setupSyncPlanRepos creates a local clone with "origin" (core) and "github" bare remotes
+// covering every ref status the planner distinguishes
+func setupSyncPlanRepos(t *testing.T) string {
+	t.Helper()
+
+	tmp := t.TempDir()
+	local := filepath.Join(tmp, "local")
+	other := filepath.Join(tmp, "other")
+
+	runGit(t, tmp, "init", "--bare", "-b", "main", "core.git")
+	runGit(t, tmp, "init", "--bare", "-b", "main", "github.git")
+
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "remote", "add", "origin", filepath.Join(tmp, "core.git"))
+	runGit(t, local, "remote", "add", "github", filepath.Join(tmp, "github.git"))
+
+	// main: synced
+	runGit(t, local, "commit", "--allow-empty", "-m", "base")
+	runGit(t, local, "push", "origin", "main")
+	runGit(t, local, "push", "github", "main")
+
+	// ff: core ahead of github
+	runGit(t, local, "branch", "ff")
+	runGit(t, local, "push", "github", "ff")
+	runGit(t, local, "checkout", "-q", "ff")
+	runGit(t, local, "commit", "--allow-empty", "-m", "ff work")
+	runGit(t, local, "push", "origin", "ff")
+
+	// feature: only on core
+	runGit(t, local, "checkout", "-q", "-b", "feature", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "feature work")
+	runGit(t, local, "push", "origin", "feature")
+
+	// diverged: different commits on each side
+	runGit(t, local, "checkout", "-q", "-b", "diverged", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "core side")
+	runGit(t, local, "push", "origin", "diverged")
+	runGit(t, local, "reset", "-q", "--hard", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "github side")
+	runGit(t, local, "push", "github", "diverged")
+
+	// hotfix: only on github
+	runGit(t, local, "checkout", "-q", "-b", "hotfix", "main")
+	runGit(t, local, "commit", "--allow-empty", "-m", "hotfix")
+	runGit(t, local, "push", "github", "hotfix")
+
+	// Tags: v1 on core only, v0 on both
+	runGit(t, local, "checkout", "-q", "main")
+	runGit(t, local, "tag", "-a", "v0", "-m", "v0")
+	runGit(t, local, "push", "origin", "v0")
+	runGit(t, local, "push", "github", "v0")
+	runGit(t, local, "tag", "v1", "feature")
+	runGit(t, local, "push", "origin", "v1")
+
+	// Work from a fresh clone so local refs don't mask what's on the remotes
+	runGit(t, tmp, "clone", "-q", filepath.Join(tmp, "core.git"), other)
+	runGit(t, other, "remote", "add", "github", filepath.Join(tmp, "github.git"))
+
+	return other
+}
+
+func TestBuildSyncPlan(t *testing.T) {
+	repo := setupSyncPlanRepos(t)
+	gc := git.NewClient(repo)
+
+	plan, err := BuildSyncPlan(gc, "origin", "github", nil)
+	if err != nil {
+		t.Fatalf("BuildSyncPlan() error = %v", err)
+	}
+
+	want := map[string]struct {
+		status     RefSyncStatus
+		action     string
+		scenarioID string
+	}{
+		"refs/heads/main":     {RefSynced, RefActionNone, "B1"},
+		"refs/heads/ff":       {RefFastForward, RefActionPush, "B2"},
+		"refs/heads/feature":  {RefMissingGitHub, RefActionPush, "B6"},
+		"refs/heads/diverged": {RefDiverged, RefActionManual, "B4"},
+		"refs/heads/hotfix":   {RefMissingCore, RefActionNone, "B7"},
+		"refs/tags/v0":        {RefSynced, RefActionNone, "B1"},
+		"refs/tags/v1":        {RefMissingGitHub, RefActionPush, "B6"},
+	}
+
+	if len(plan.Refs) != len(want) {
+		t.Fatalf("plan has %d refs, want %d: %+v", len(plan.Refs), len(want), plan.Refs)
+	}
+
+	for _, ref := range plan.Refs {
+		w, ok := want[ref.Ref]
+		if !ok {
+			t.Errorf("unexpected ref %s", ref.Ref)
+			continue
+		}
+		if ref.Status != w.status || ref.Action != w.action || ref.ScenarioID != w.scenarioID {
+			t.Errorf("%s = (%s, %s, %s), want (%s, %s, %s)", ref.Ref,
+				ref.Status, ref.Action, ref.ScenarioID, w.status, w.action, w.scenarioID)
+		}
+	}
+
+	// Branches sort before tags
+	if plan.Refs[len(plan.Refs)-1].Kind != RefKindTag {
+		t.Errorf("expected tags last, got %+v", plan.Refs[len(plan.Refs)-1])
+	}
+
+	if got := len(plan.Pending()); got != 3 {
+		t.Errorf("Pending() = %d refs, want 3", got)
+	}
+
+	if err := ApplySyncPlan(gc, plan); err != nil {
+		t.Fatalf("ApplySyncPlan() error = %v", err)
+	}
+
+	summary := plan.Summary()
+	if summary.Pushed != 3 || summary.Failed != 0 || summary.Manual != 1 {
+		t.Errorf("Summary() = %+v, want 3 pushed, 0 failed, 1 manual", summary)
+	}
+
+	// Re-planning shows the safe refs now synced and the unsafe ones untouched
+	replan, err := BuildSyncPlan(gc, "origin", "github", nil)
+	if err != nil {
+		t.Fatalf("BuildSyncPlan() after apply error = %v", err)
+	}
+	for _, ref := range replan.Refs {
+		switch ref.Ref {
+		case "refs/heads/diverged":
+			if ref.Status != RefDiverged {
+				t.Errorf("diverged branch status = %s after apply", ref.Status)
+			}
+		case "refs/heads/hotfix":
+			if ref.Status != RefMissingCore {
+				t.Errorf("hotfix branch status = %s after apply", ref.Status)
+			}
+		default:
+			if ref.Status != RefSynced {
+				t.Errorf("%s status = %s after apply, want synced", ref.Ref, ref.Status)
+			}
+		}
+	}
+}
+
+func TestBuildSyncPlan_BranchFilter(t *testing.T) {
+	repo := setupSyncPlanRepos(t)
+
+	plan, err := BuildSyncPlan(git.NewClient(repo), "origin", "github", []string{"ff", "nope"})
+	if err != nil {
+		t.Fatalf("BuildSyncPlan() error = %v", err)
+	}
+
+	if len(plan.Refs) != 2 {
+		t.Fatalf("plan has %d refs, want 2: %+v", len(plan.Refs), plan.Refs)
+	}
+	if plan.Refs[0].Name != "ff" || plan.Refs[0].Status != RefFastForward {
+		t.Errorf("first ref = %+v, want ff fast_forward", plan.Refs[0])
+	}
+	if plan.Refs[1].Name != "nope" || plan.Refs[1].Error == "" {
+		t.Errorf("second ref = %+v, want nope with error", plan.Refs[1])
+	}
+}
+
+func TestBuildSyncPlan_ConflictingTag(t *testing.T) {
+	repo := setupSyncPlanRepos(t)
+	tmp := filepath.Dir(repo)
+
+	// v2 names a different commit on each remote
+	runGit(t, repo, "tag", "v2", "origin/main")
+	runGit(t, repo, "push", "-q", "origin", "v2")
+	runGit(t, repo, "tag", "-f", "v2", "origin/feature")
+	runGit(t, repo, "push", "-q", filepath.Join(tmp, "github.git"), "v2")
+
+	gc := git.NewClient(repo)
+	plan, err := BuildSyncPlan(gc, "origin", "github", nil)
+	if err != nil {
+		t.Fatalf("BuildSyncPlan() error = %v", err)
+	}
+
+	var found bool
+	for _, ref := range plan.Refs {
+		if ref.Ref != "refs/tags/v2" {
+			continue
+		}
+		found = true
+		if ref.Status != RefDiverged || ref.Action != RefActionManual {
+			t.Errorf("v2 = %+v, want diverged needing manual resolution", ref)
+		}
+	}
+	if !found {
+		t.Fatalf("v2 missing from plan: %+v", plan.Refs)
+	}
+
+	// The local tag is left alone
+	got, _ := gc.GetCommit("v2^{commit}")
+	want, _ := gc.GetCommit("origin/feature")
+	if got != want {
+		t.Errorf("local v2 = %s, want %s (unchanged)", got, want)
+	}
+}
@@ -377,6 +377,39 @@ func GetScenarioTable() ScenarioTable {
 			},
 			RelatedIDs: []string{"C7"},
 		},
+		"S_MIRROR_BEHIND": {
+			ID:          "S_MIRROR_BEHIND",
+			Name:        "Mirror Behind Local",
+			Description: "An additional mirror is missing local commits",
+			Category:    CategorySync,
+			Severity:    SeverityWarning,
+			AutoFixable: true,
+			TypicalCauses: []string{
+				"Mirror was unreachable during the last push",
+				"Mirror added after commits were pushed",
+			},
+			ManualSteps: []string{
+				"Push to the mirror: git push <mirror> <branch>",
+			},
+			RelatedIDs: []string{"S4", "S5"},
+		},
+		"S_MIRROR_DIVERGED": {
+			ID:          "S_MIRROR_DIVERGED",
+			Name:        "Mirror Has Unknown Commits",
+			Description: "An additional mirror has commits that local does not",
+			Category:    CategorySync,
+			Severity:    SeverityError,
+			AutoFixable: false,
+			TypicalCauses: []string{
+				"Someone pushed directly to the mirror",
+				"Mirror was restored from an older or foreign backup",
+			},
+			ManualSteps: []string{
+				"Inspect: git log <branch>..<mirror>/<branch>",
+				"Merge the commits, or overwrite the mirror if they are unwanted",
+			},
+			RelatedIDs: []string{"S10", "S13"},
+		},
 
 		// ========== WORKING TREE SCENARIOS (W1-W5) ==========
 		"W1": {
@@ -703,6 +736,143 @@ func GetScenarioTable() ScenarioTable {
 			},
 			RelatedIDs: []string{"B5", "B6"},
 		},
+
+		// ========== TAG SCENARIOS (T1-T4) ==========
+		"T1": {
+			ID:          "T1",
+			Name:        "Tag in Sync",
+			Description: "Tag points to the same object on Core and GitHub",
+			Category:    CategoryTag,
+			Severity:    SeverityInfo,
+			AutoFixable: false,
+			TypicalCauses: []string{
+				"Normal state",
+			},
+			ManualSteps: []string{
+				"No action needed",
+			},
+			RelatedIDs: []string{},
+		},
+		"T2": {
+			ID:          "T2",
+			Name:        "Tag Missing on GitHub",
+			Description: "Tag exists on Core but not on GitHub",
+			Category:    CategoryTag,
+			Severity:    SeverityWarning,
+			AutoFixable: true,
+			TypicalCauses: []string{
+				"Tag pushed to Core only (git push does not push tags by default)",
+				"GitHub unreachable when the tag was pushed",
+			},
+			ManualSteps: []string{
+				"Push tag: git push github refs/tags/<tag>",
+			},
+			RelatedIDs: []string{"T3", "T4"},
+		},
+		"T3": {
+			ID:          "T3",
+			Name:        "Tag Missing on Core",
+			Description: "Tag exists on GitHub but not on Core",
+			Category:    CategoryTag,
+			Severity:    SeverityWarning,
+			AutoFixable: true,
+			TypicalCauses: []string{
+				"Release created through the GitHub UI",
+				"Tag pushed directly to GitHub",
+			},
+			ManualSteps: []string{
+				"Fetch tag: git fetch github refs/tags/<tag>:refs/tags/<tag>",
+				"Push tag: git push origin refs/tags/<tag>",
+			},
+			RelatedIDs: []string{"T2", "T4"},
+		},
+		"T4": {
+			ID:          "T4",
+			Name:        "Tag Conflict",
+			Description: "Tag points to different objects on Core and GitHub",
+			Category:    CategoryTag,
+			Severity:    SeverityCritical,
+			AutoFixable: false,
+			TypicalCauses: []string{
+				"Tag deleted and recreated on one remote",
+				"Tag force-pushed to one remote",
+				"Tampering with a release tag",
+			},
+			ManualSteps: []string{
+				"Compare: git ls-remote --tags origin <tag> && git ls-remote --tags github <tag>",
+				"Decide which object is authoritative - tags are never force-overwritten automatically",
+				"Then replace the wrong tag: git push --force <remote> refs/tags/<tag>",
+			},
+			RelatedIDs: []string{"T2", "T3"},
+		},
+
+		// ========== DEFAULT BRANCH SCENARIOS (D1-D4) ==========
+		"D1": {
+			ID:          "D1",
+			Name:        "Default Branch Consistent",
+			Description: "Core, GitHub, and init.defaultBranch agree on the default branch",
+			Category:    CategoryDefaultBranch,
+			Severity:    SeverityInfo,
+			AutoFixable: false,
+			TypicalCauses: []string{
+				"Normal state",
+			},
+			ManualSteps: []string{
+				"No action needed",
+			},
+			RelatedIDs: []string{},
+		},
+		"D2": {
+			ID:          "D2",
+			Name:        "GitHub Default Branch Differs",
+			Description: "GitHub's default branch differs from Core's HEAD, which is authoritative",
+			Category:    CategoryDefaultBranch,
+			Severity:    SeverityWarning,
+			AutoFixable: true,
+			TypicalCauses: []string{
+				"Default branch renamed on Core only",
+				"GitHub repository created with a different default branch",
+				"Default branch changed through the GitHub UI",
+			},
+			ManualSteps: []string{
+				"Push the branch if GitHub lacks it: git push github refs/heads/<branch>",
+				"Set GitHub's default: gh repo edit <owner>/<repo> --default-branch <branch>",
+			},
+			RelatedIDs: []string{"D3"},
+		},
+		"D3": {
+			ID:          "D3",
+			Name:        "Core HEAD Dangling",
+			Description: "Core's HEAD points to a branch that doesn't exist; GitHub's default branch is authoritative",
+			Category:    CategoryDefaultBranch,
+			Severity:    SeverityWarning,
+			AutoFixable: true,
+			TypicalCauses: []string{
+				"Bare repository created with git's built-in default (master) but pushed as main",
+				"Default branch renamed and the old branch deleted on Core",
+			},
+			ManualSteps: []string{
+				"Push the branch if Core lacks it: git push origin refs/heads/<branch>",
+				"Repoint HEAD on the Core server: git --git-dir <path> symbolic-ref HEAD refs/heads/<branch>",
+			},
+			RelatedIDs: []string{"D2"},
+		},
+		"D4": {
+			ID:          "D4",
+			Name:        "init.defaultBranch Differs",
+			Description: "New repositories would start on a different branch than the remotes use",
+			Category:    CategoryDefaultBranch,
+			Severity:    SeverityInfo,
+			AutoFixable: false,
+			TypicalCauses: []string{
+				"init.defaultBranch not set (git defaults to master)",
+				"Remotes migrated to a new default branch name",
+			},
+			ManualSteps: []string{
+				"Set it globally: git config --global init.defaultBranch <branch>",
+			},
+			RelatedIDs: []string{"D1"},
+		},
 	}
 }
 
@@ -0,0 +1,133 @@
+package scenarios
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+func TestDetect_TagSync(t *testing.T) {
+	tmp := t.TempDir()
+	local := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", "-b", "main", "core.git")
+	runGit(t, tmp, "init", "--bare", "-b", "main", "github.git")
+
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "remote", "add", "origin", filepath.Join(tmp, "core.git"))
+	runGit(t, local, "remote", "add", "github", filepath.Join(tmp, "github.git"))
+
+	runGit(t, local, "commit", "--allow-empty", "-m", "first")
+	runGit(t, local, "commit", "--allow-empty", "-m", "second")
+	runGit(t, local, "push", "origin", "main")
+	runGit(t, local, "push", "github", "main")
+
+	// v1.0: on both remotes
+	runGit(t, local, "tag", "-a", "v1.0", "-m", "v1.0")
+	runGit(t, local, "push", "origin", "v1.0")
+	runGit(t, local, "push", "github", "v1.0")
+
+	// v1.1: Core only
+	runGit(t, local, "tag", "v1.1")
+	runGit(t, local, "push", "origin", "v1.1")
+
+	// v1.2: GitHub only
+	runGit(t, local, "tag", "v1.2", "HEAD~1")
+	runGit(t, local, "push", "github", "v1.2")
+
+	// v2.0: different commits on each remote
+	runGit(t, local, "tag", "v2.0", "HEAD~1")
+	runGit(t, local, "push", "origin", "v2.0")
+	runGit(t, local, "tag", "-f", "v2.0", "HEAD")
+	runGit(t, local, "push", "github", "v2.0")
+
+	options := DefaultDetectionOptions()
+	options.SkipFetch = true
+	options.SkipCorruption = true
+	options.SkipBranches = true
+
+	state, err := NewClassifier(git.NewClient(local), "origin", "github", options).Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	want := map[string]string{
+		"v1.0": "T1",
+		"v1.1": "T2",
+		"v1.2": "T3",
+		"v2.0": "T4",
+	}
+
+	if len(state.Tags) != len(want) {
+		t.Fatalf("len(Tags) = %d, want %d: %+v", len(state.Tags), len(want), state.Tags)
+	}
+
+	for _, tag := range state.Tags {
+		if tag.ID != want[tag.Tag] {
+			t.Errorf("tag %s ID = %s, want %s", tag.Tag, tag.ID, want[tag.Tag])
+		}
+		if tag.Conflict != (tag.ID == "T4") {
+			t.Errorf("tag %s Conflict = %v", tag.Tag, tag.Conflict)
+		}
+	}
+
+	if state.Tags[0].Tag != "v1.0" {
+		t.Errorf("tags not sorted: first = %s", state.Tags[0].Tag)
+	}
+
+	// SkipTags leaves the tag dimension empty
+	options.SkipTags = true
+	state, err = NewClassifier(git.NewClient(local), "origin", "github", options).Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(state.Tags) != 0 {
+		t.Errorf("expected no tags with SkipTags, got %+v", state.Tags)
+	}
+}
+
+func TestSuggestTagFixes(t *testing.T) {
+	tags := []TagState{
+		{ID: "T1", Tag: "v1.0", LocalHash: "aaa", CoreHash: "aaa", GitHubHash: "aaa"},
+		{ID: "T2", Tag: "v1.1", LocalHash: "bbb", CoreHash: "bbb"},
+		{ID: "T3", Tag: "v1.2", GitHubHash: "ccc"},
+		{ID: "T4", Tag: "v2.0", LocalHash: "ddd", CoreHash: "ddd", GitHubHash: "eee", Conflict: true},
+	}
+
+	fixes := suggestTagFixes(tags, "origin", "github")
+	if len(fixes) != 3 {
+		t.Fatalf("Expected 3 fixes, got %d: %+v", len(fixes), fixes)
+	}
+
+	// T2: local matches Core, push is auto-fixable and never forced
+	t2 := fixes[0]
+	if t2.ScenarioID != "T2" || !t2.AutoFixable {
+		t.Errorf("T2 fix = %+v, want auto-fixable", t2)
+	}
+	push, ok := t2.Operation.(*PushOperation)
+	if !ok || push.Remote != "github" || push.Refspec != "refs/tags/v1.1" {
+		t.Errorf("T2 operation = %#v, want push refs/tags/v1.1 to github", t2.Operation)
+	}
+
+	// T3: no local tag, so the fix fetches first and is manual
+	t3 := fixes[1]
+	if t3.ScenarioID != "T3" || t3.AutoFixable || t3.Operation != nil {
+		t.Errorf("T3 fix = %+v, want manual", t3)
+	}
+	if !strings.Contains(t3.Command, "git fetch github") || !strings.Contains(t3.Command, "git push origin refs/tags/v1.2") {
+		t.Errorf("T3 command = %s", t3.Command)
+	}
+
+	// T4: flagged only
+	t4 := fixes[2]
+	if t4.ScenarioID != "T4" || t4.AutoFixable || t4.Operation != nil || t4.Priority != 1 {
+		t.Errorf("T4 fix = %+v, want priority 1 manual", t4)
+	}
+	for _, fix := range fixes {
+		if strings.Contains(fix.Command, "--force") {
+			t.Errorf("fix %s suggests a force push: %s", fix.ScenarioID, fix.Command)
+		}
+	}
+}
@@ -18,12 +18,16 @@ type RepositoryState struct {
 	CoreRemote   string `json:"core_remote"`   // e.g., "origin"
 	GitHubRemote string `json:"github_remote"` // e.g., "github"
 
-	// Classified states (5 dimensions)
+	// Classified states (6 dimensions)
 	Existence   ExistenceState   `json:"existence"`
 	Sync        SyncState        `json:"sync"`
 	WorkingTree WorkingTreeState `json:"working_tree"`
 	Corruption  CorruptionState  `json:"corruption"`
 	Branches    []BranchState    `json:"branches"`
+	Tags        []TagState       `json:"tags,omitempty"`
+
+	// Default branch agreement between Core, GitHub, and init.defaultBranch
+	DefaultBranchState *DefaultBranchState `json:"default_branch_state,omitempty"`
 
 	// Warnings and metadata
 	Warnings      []Warning `json:"warnings,omitempty"`
@@ -82,6 +86,28 @@ type SyncState struct {
 	CoreBehindGitHub   int `json:"core_behind_github"`
 
 	Diverged bool `json:"diverged"` // True if manual merge needed
+
+	// Mirrors reports the default branch against every remote in the mirror set.
+	// The Core/GitHub fields above are the two-remote projection of this list.
+	Mirrors []MirrorState `json:"mirrors,omitempty"`
+}
+
+// MirrorState describes the default branch on a single mirror relative to local
+type MirrorState struct {
+	Remote     string `json:"remote"`     // Remote name, e.g. "origin", "github", "backup"
+	Configured bool   `json:"configured"` // Remote exists in git config
+	Hash       string `json:"hash,omitempty"`
+
+	LocalAhead  int  `json:"local_ahead"`
+	LocalBehind int  `json:"local_behind"`
+	Diverged    bool `json:"diverged"`
+
+	Error string `json:"error,omitempty"` // Why the branch could not be compared
+}
+
+// InSync reports whether the mirror has exactly the local commit
+func (m MirrorState) InSync() bool {
+	return m.Configured && m.Error == "" && m.LocalAhead == 0 && m.LocalBehind == 0
 }
 
 // BranchState describes sync status of a single branch
@@ -102,6 +128,41 @@ type BranchState struct {
 	Diverged bool `json:"diverged"`
 }
 
+// TagState describes a single tag across Core and GitHub
+type TagState struct {
+	ID          string `json:"id"`          // T1-T4
+	Description string `json:"description"` // Human-readable
+	Tag         string `json:"tag"`         // Tag name
+
+	// Object hashes; annotated tags report the tag object, not the commit
+	LocalHash  string `json:"local_hash,omitempty"`
+	CoreHash   string `json:"core_hash,omitempty"`
+	GitHubHash string `json:"github_hash,omitempty"`
+
+	Conflict bool `json:"conflict"` // True if Core and GitHub disagree on the object
+}
+
+// DefaultBranchState compares the default branch (HEAD) of Core and GitHub,
+// and the local init.defaultBranch new repositories start on
+type DefaultBranchState struct {
+	ID          string `json:"id"`          // D1-D4
+	Description string `json:"description"` // Human-readable
+
+	Core   string `json:"core,omitempty"`   // Branch Core's HEAD points to
+	GitHub string `json:"github,omitempty"` // GitHub's default branch
+	Local  string `json:"local"`            // init.defaultBranch, or git's built-in default
+
+	LocalImplicit bool `json:"local_implicit,omitempty"` // init.defaultBranch is not set
+
+	// Branch is the authoritative default branch and Authority the side it
+	// came from ("core" or "github"). Core wins unless its HEAD is dangling.
+	Branch    string `json:"branch,omitempty"`
+	Authority string `json:"authority,omitempty"`
+
+	CoreHasBranch   bool `json:"core_has_branch"`   // Branch exists on Core
+	GitHubHasBranch bool `json:"github_has_branch"` // Branch exists on GitHub
+}
+
 // WorkingTreeState describes local modifications
 type WorkingTreeState struct {
 	ID          string `json:"id"`          // W1-W5
@@ -182,6 +243,12 @@ type DetectionOptions struct {
 	// MaxBranches limits how many branches to analyze (0 = unlimited)
 	MaxBranches int
 
+	// SkipTags disables tag comparison between Core and GitHub
+	SkipTags bool
+
+	// SkipDefaultBranch disables default branch comparison between Core and GitHub
+	SkipDefaultBranch bool
+
 	// BinarySizeThresholdMB sets large binary detection threshold
 	BinarySizeThresholdMB float64
 
@@ -199,6 +266,8 @@ func DefaultDetectionOptions() DetectionOptions {
 		SkipCorruption:        false,
 		SkipBranches:          false,
 		MaxBranches:           100, // Prevent extreme ref counts from hanging
+		SkipTags:              false,
+		SkipDefaultBranch:     false,
 		BinarySizeThresholdMB: 50.0, // 50MB threshold
 		FetchTimeout:          constants.DefaultFetchTimeout,
 		RemoteCheckTimeout:    constants.QuickOperationTimeout,
@@ -210,6 +279,7 @@ type Classifier struct {
 	gitClient     interface{} // git.Client (interface to avoid import cycle)
 	coreRemote    string
 	githubRemote  string
+	mirrors       []string // Full mirror set; mirrors[0]/mirrors[1] project onto core/github
 	options       DetectionOptions
 }
 
@@ -230,7 +300,7 @@ type ScenarioDefinition struct {
 	ID          string
 	Name        string
 	Description string
-	Category    string // "existence", "sync", "working_tree", "corruption", "branch"
+	Category    string // "existence", "sync", "working_tree", "corruption", "branch", "tag", "default_branch"
 	Severity    string // "info", "warning", "error", "critical"
 	AutoFixable bool
 	TypicalCauses []string
@@ -248,6 +318,8 @@ const (
 	CategoryWorkingTree = "working_tree"
 	CategoryCorruption  = "corruption"
 	CategoryBranch      = "branch"
+	CategoryTag         = "tag"
+	CategoryDefaultBranch = "default_branch"
 )
 
 // Constants for scenario severity
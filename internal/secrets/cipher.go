@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Encryption schemes for the file backend
+const (
+	EncryptionAge = "age"
+	EncryptionGPG = "gpg"
+)
+
+// cipher encrypts and decrypts the whole secrets file
+type cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// ageCipher encrypts with age to one or more recipients
+type ageCipher struct {
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// newAgeCipher loads identities from identityFile. Recipients default to the
+// public keys of those identities, so a single key file is enough.
+func newAgeCipher(identityFile string, recipients []string) (*ageCipher, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open age identity file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity file %s: %w", identityFile, err)
+	}
+
+	c := &ageCipher{identities: identities}
+
+	if len(recipients) > 0 {
+		c.recipients, err = age.ParseRecipients(strings.NewReader(strings.Join(recipients, "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age recipients: %w", err)
+		}
+	} else {
+		for _, id := range identities {
+			if x, ok := id.(*age.X25519Identity); ok {
+				c.recipients = append(c.recipients, x.Recipient())
+			}
+		}
+		if len(c.recipients) == 0 {
+			return nil, fmt.Errorf("no age recipients configured and none derivable from %s", identityFile)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *ageCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, c.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age encryption failed: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("age encryption failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("age encryption failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *ageCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), c.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("age decryption failed: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("age decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// gpgCipher shells out to gpg, using the caller's keyring and agent
+type gpgCipher struct {
+	recipients []string
+}
+
+func (c *gpgCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	args := []string{"--batch", "--yes", "--quiet", "--encrypt"}
+	if len(c.recipients) == 0 {
+		args = append(args, "--default-recipient-self")
+	}
+	for _, r := range c.recipients {
+		args = append(args, "--recipient", r)
+	}
+	return runGPG("encrypt", plaintext, args...)
+}
+
+func (c *gpgCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	return runGPG("decrypt", ciphertext, "--batch", "--quiet", "--decrypt")
+}
+
+func runGPG(op string, input []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("gpg", args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gpg %s failed: %w: %s", op, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
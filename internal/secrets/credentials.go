@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/lcgerke/githelper/internal/errors"
+	"github.com/lcgerke/githelper/internal/vault"
+)
+
+// GetConfig reads githelper configuration from the provider
+func GetConfig(p Provider, layout Layout) (*vault.Config, error) {
+	path, err := layout.ConfigPath(Vars{})
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeSecrets, "invalid secrets layout", err)
+	}
+
+	data, err := p.Get(path)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("failed to read config from %s", p.Name()), err)
+	}
+
+	cfg := &vault.Config{}
+
+	cfg.GitHubUsername = stringField(data, "github_username")
+	cfg.BareRepoPattern = stringField(data, "bare_repo_pattern")
+	cfg.DefaultVisibility = stringField(data, "default_visibility")
+	cfg.AutoCreateGitHub = boolField(data, "auto_create_github")
+	cfg.TestBeforePush = boolField(data, "test_before_push")
+	cfg.SyncOnSetup = boolField(data, "sync_on_setup")
+	cfg.RetryOnPartialFailure = boolField(data, "retry_on_partial_failure")
+
+	return cfg, nil
+}
+
+// GetSSHKey retrieves an SSH key.
+// Tries the repo-specific path first, falls back to the default.
+func GetSSHKey(p Provider, layout Layout, vars Vars) (*vault.SSHKey, error) {
+	data, path, err := getFirst(p, layout.SSHKeyPaths, vars)
+	if err != nil {
+		wrapped := errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("no SSH key found in %s", p.Name()), err)
+		if path == "" {
+			return nil, wrapped
+		}
+		return nil, errors.WithHint(wrapped, fmt.Sprintf("Add an SSH key at %s with fields 'private_key' and 'public_key'", path))
+	}
+
+	return parseSSHKey(data)
+}
+
+// GetPAT retrieves a GitHub Personal Access Token.
+// Tries the repo-specific path first, falls back to the default.
+func GetPAT(p Provider, layout Layout, vars Vars) (string, error) {
+	data, path, err := getFirst(p, layout.PATPaths, vars)
+	if err != nil {
+		wrapped := errors.Wrap(errors.ErrorTypeSecrets, fmt.Sprintf("no GitHub PAT found in %s", p.Name()), err)
+		if path == "" {
+			return "", wrapped
+		}
+		return "", errors.WithHint(wrapped, fmt.Sprintf("Add a GitHub Personal Access Token at %s with field 'token'", path))
+	}
+
+	if token := stringField(data, "token"); token != "" {
+		return token, nil
+	}
+
+	return "", errors.WithHint(
+		errors.New(errors.ErrorTypeSecrets, fmt.Sprintf("PAT secret at %s missing required 'token' field", path)),
+		"Ensure the secret has a 'token' field with your GitHub Personal Access Token",
+	)
+}
+
+// getFirst returns the first candidate path that holds a secret. On failure
+// the path reported is the last (default) one, or empty if the layout
+// couldn't be resolved.
+func getFirst(p Provider, paths func(Vars) ([]string, error), vars Vars) (map[string]interface{}, string, error) {
+	candidates, err := paths(vars)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var last error
+	for _, path := range candidates {
+		data, err := p.Get(path)
+		if err == nil {
+			return data, path, nil
+		}
+		last = err
+	}
+	return nil, candidates[len(candidates)-1], last
+}
+
+// ResolveSSHKeyPath returns the path the SSH key for vars is read from
+func ResolveSSHKeyPath(p Provider, layout Layout, vars Vars) (string, error) {
+	_, path, err := getFirst(p, layout.SSHKeyPaths, vars)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ResolvePATPath returns the path the PAT for vars is read from
+func ResolvePATPath(p Provider, layout Layout, vars Vars) (string, error) {
+	_, path, err := getFirst(p, layout.PATPaths, vars)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// DownloadSSHKey retrieves an SSH key and writes it to destDir.
+// Returns the path to the private key file.
+func DownloadSSHKey(p Provider, layout Layout, vars Vars, destDir string) (string, error) {
+	sshKey, err := GetSSHKey(p, layout, vars)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure destination directory exists
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create SSH directory: %w", err)
+	}
+
+	privateKeyPath := vault.GetSSHKeyPath(vars.Repo, destDir)
+	if err := os.WriteFile(privateKeyPath, []byte(sshKey.PrivateKey), 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	// Write public key if available
+	if sshKey.PublicKey != "" {
+		if err := os.WriteFile(privateKeyPath+".pub", []byte(sshKey.PublicKey), 0644); err != nil {
+			// Not fatal, just warn
+			fmt.Fprintf(os.Stderr, "Warning: failed to write public key: %v\n", err)
+		}
+	}
+
+	return privateKeyPath, nil
+}
+
+func parseSSHKey(data map[string]interface{}) (*vault.SSHKey, error) {
+	privateKey := stringField(data, "private_key")
+	if privateKey == "" {
+		return nil, errors.WithHint(
+			errors.New(errors.ErrorTypeSecrets, "SSH key secret missing required 'private_key' field"),
+			"Ensure the secret has a 'private_key' field with your SSH private key",
+		)
+	}
+
+	return &vault.SSHKey{
+		PrivateKey: privateKey,
+		PublicKey:  stringField(data, "public_key"),
+	}, nil
+}
+
+func stringField(data map[string]interface{}, key string) string {
+	v, _ := data[key].(string)
+	return v
+}
+
+// boolField accepts native booleans and, for backends that only store
+// strings (such as environment variables), "true"/"false" style values
+func boolField(data map[string]interface{}, key string) bool {
+	switch v := data[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvPrefix prefixes every secret environment variable
+const DefaultEnvPrefix = "GITHELPER_SECRET_"
+
+// EnvOptions configures the environment variable backend
+type EnvOptions struct {
+	Prefix string `yaml:"prefix"` // Defaults to DefaultEnvPrefix
+}
+
+// EnvProvider reads secrets from environment variables named
+// <prefix><PATH>__<FIELD>, with the path and field upper-cased and every
+// character other than a letter or digit replaced by "_". For example the
+// token field of githelper/github/default_pat is read from
+// GITHELPER_SECRET_GITHELPER_GITHUB_DEFAULT_PAT__TOKEN.
+// Field names are lower-cased when read back. The backend is read-only.
+type EnvProvider struct {
+	prefix  string
+	environ func() []string
+}
+
+// NewEnvProvider creates an environment variable provider
+func NewEnvProvider(options EnvOptions) *EnvProvider {
+	prefix := options.Prefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return &EnvProvider{prefix: prefix, environ: os.Environ}
+}
+
+// EnvVar returns the variable holding field of the secret at path
+func (p *EnvProvider) EnvVar(path, field string) string {
+	return p.prefix + envName(path) + "__" + envName(field)
+}
+
+func (p *EnvProvider) Name() string {
+	return "environment"
+}
+
+func (p *EnvProvider) Get(path string) (map[string]interface{}, error) {
+	prefix := p.prefix + envName(path) + "__"
+
+	data := make(map[string]interface{})
+	for _, kv := range p.environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		field := strings.ToLower(strings.TrimPrefix(name, prefix))
+		if field != "" {
+			data[field] = value
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, notFound(path)
+	}
+	return data, nil
+}
+
+func (p *EnvProvider) Put(path string, data map[string]interface{}) error {
+	var vars []string
+	for field := range data {
+		vars = append(vars, p.EnvVar(path, field))
+	}
+	sort.Strings(vars)
+	return fmt.Errorf("%w: set %s in the environment instead", ErrReadOnly, strings.Join(vars, ", "))
+}
+
+func (p *EnvProvider) Delete(path string) error {
+	return fmt.Errorf("%w: unset %s* in the environment instead", ErrReadOnly, p.prefix+envName(path)+"__")
+}
+
+func (p *EnvProvider) IsReachable() bool {
+	return true
+}
+
+// envName upper-cases s and replaces anything but letters and digits with "_"
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
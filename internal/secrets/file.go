@@ -0,0 +1,212 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default file backend locations inside ~/.githelper
+const (
+	defaultAgeSecretsFile = "secrets.age"
+	defaultGPGSecretsFile = "secrets.gpg"
+	defaultAgeIdentity    = "age-identity.txt"
+)
+
+// FileOptions configures the encrypted file backend
+type FileOptions struct {
+	Path       string   `yaml:"path"`       // Defaults to ~/.githelper/secrets.age (or secrets.gpg)
+	Encryption string   `yaml:"encryption"` // age (default) or gpg
+	Recipients []string `yaml:"recipients"` // age public keys or gpg key IDs; age defaults to the identity's key, gpg to the default key
+	Identity   string   `yaml:"identity"`   // age identity file, defaults to ~/.githelper/age-identity.txt
+}
+
+// FileProvider keeps every secret in one YAML document encrypted with age or
+// gpg. The whole file is decrypted on each read and re-encrypted on each write.
+type FileProvider struct {
+	path   string
+	cipher cipher
+	mu     sync.Mutex
+}
+
+// NewFileProvider creates an encrypted file provider. The file itself is
+// created on the first Put.
+func NewFileProvider(options FileOptions) (*FileProvider, error) {
+	encryption := options.Encryption
+	if encryption == "" {
+		encryption = EncryptionAge
+	}
+
+	var c cipher
+	defaultFile := defaultAgeSecretsFile
+	switch encryption {
+	case EncryptionAge:
+		identity := options.Identity
+		if identity == "" {
+			var err error
+			if identity, err = defaultPath(defaultAgeIdentity); err != nil {
+				return nil, err
+			}
+		}
+		identity, err := expandHome(identity)
+		if err != nil {
+			return nil, err
+		}
+		ac, err := newAgeCipher(identity, options.Recipients)
+		if err != nil {
+			return nil, err
+		}
+		c = ac
+	case EncryptionGPG:
+		c = &gpgCipher{recipients: options.Recipients}
+		defaultFile = defaultGPGSecretsFile
+	default:
+		return nil, fmt.Errorf("unknown secrets file encryption %q (want %s or %s)", encryption, EncryptionAge, EncryptionGPG)
+	}
+
+	path := options.Path
+	if path == "" {
+		var err error
+		if path, err = defaultPath(defaultFile); err != nil {
+			return nil, err
+		}
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileProvider{path: path, cipher: c}, nil
+}
+
+func (p *FileProvider) Name() string {
+	return fmt.Sprintf("secrets file %s", p.path)
+}
+
+func (p *FileProvider) Get(path string) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := all[path]
+	if !ok || len(data) == 0 {
+		return nil, notFound(path)
+	}
+	return data, nil
+}
+
+func (p *FileProvider) Put(path string, data map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	all[path] = data
+	return p.save(all)
+}
+
+func (p *FileProvider) Delete(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := all[path]; !ok {
+		return nil
+	}
+	delete(all, path)
+	return p.save(all)
+}
+
+// IsReachable reports whether the file can be decrypted. A file that doesn't
+// exist yet is reachable; it simply holds no secrets.
+func (p *FileProvider) IsReachable() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.load()
+	return err == nil
+}
+
+// load decrypts and parses the file
+func (p *FileProvider) load() (map[string]map[string]interface{}, error) {
+	all := make(map[string]map[string]interface{})
+
+	ciphertext, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	plaintext, err := p.cipher.Decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", p.path, err)
+	}
+
+	if err := yaml.Unmarshal(plaintext, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if all == nil {
+		all = make(map[string]map[string]interface{})
+	}
+	return all, nil
+}
+
+// save encrypts and atomically replaces the file
+func (p *FileProvider) save(all map[string]map[string]interface{}) error {
+	plaintext, err := yaml.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	ciphertext, err := p.cipher.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	return writeFileAtomic(p.path, ciphertext, 0600)
+}
+
+// writeFileAtomic writes data to a temp file beside path and renames it into place
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
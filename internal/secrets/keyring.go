@@ -0,0 +1,292 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultKeyringService = "githelper"
+	defaultKeyringFile    = "keyring.json"
+)
+
+// KeyringOptions configures the OS keyring backend
+type KeyringOptions struct {
+	Service      string `yaml:"service"`       // Keyring service name, defaults to "githelper"
+	FallbackPath string `yaml:"fallback_path"` // Used when no OS keyring is available, defaults to ~/.githelper/keyring.json
+	FileOnly     bool   `yaml:"file_only"`     // Always use the fallback file
+}
+
+// keyring stores one string value per path
+type keyring interface {
+	name() string
+	get(path string) (value string, found bool, err error)
+	set(path, value string) error
+	remove(path string) error
+}
+
+// KeyringProvider stores each secret as a JSON value in the OS keyring:
+// the macOS login keychain via security(1), or the Secret Service (GNOME
+// Keyring, KWallet) via secret-tool(1). Where neither is available it falls
+// back to a JSON file readable only by the owner, much like ~/.ssh keys.
+type KeyringProvider struct {
+	keyring keyring
+}
+
+// NewKeyringProvider creates a keyring provider, choosing the OS keyring if present
+func NewKeyringProvider(options KeyringOptions) (*KeyringProvider, error) {
+	service := options.Service
+	if service == "" {
+		service = defaultKeyringService
+	}
+
+	if !options.FileOnly {
+		if kr := detectOSKeyring(service); kr != nil {
+			return &KeyringProvider{keyring: kr}, nil
+		}
+	}
+
+	path := options.FallbackPath
+	if path == "" {
+		var err error
+		if path, err = defaultPath(defaultKeyringFile); err != nil {
+			return nil, err
+		}
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyringProvider{keyring: &fileKeyring{path: path}}, nil
+}
+
+// detectOSKeyring returns the platform keyring, or nil if none is usable
+func detectOSKeyring(service string) keyring {
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("security"); err == nil {
+			return &macKeyring{service: service}
+		}
+		return nil
+	}
+
+	// secret-tool needs a session bus to reach the keyring daemon
+	if _, err := exec.LookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return &secretToolKeyring{service: service}
+	}
+	return nil
+}
+
+func (p *KeyringProvider) Name() string {
+	return p.keyring.name()
+}
+
+func (p *KeyringProvider) Get(path string) (map[string]interface{}, error) {
+	value, found, err := p.keyring.get(path)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, notFound(path)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return nil, fmt.Errorf("failed to decode keyring entry %s: %w", path, err)
+	}
+	if len(data) == 0 {
+		return nil, notFound(path)
+	}
+	return data, nil
+}
+
+func (p *KeyringProvider) Put(path string, data map[string]interface{}) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode keyring entry %s: %w", path, err)
+	}
+	return p.keyring.set(path, string(value))
+}
+
+func (p *KeyringProvider) Delete(path string) error {
+	return p.keyring.remove(path)
+}
+
+func (p *KeyringProvider) IsReachable() bool {
+	_, _, err := p.keyring.get("githelper/probe")
+	return err == nil
+}
+
+// macKeyring uses the macOS keychain through security(1)
+type macKeyring struct {
+	service string
+}
+
+func (k *macKeyring) name() string {
+	return "macOS keychain"
+}
+
+func (k *macKeyring) get(path string) (string, bool, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", k.service, "-a", path, "-w").Output()
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) && exitErr.ExitCode() == 44 { // errSecItemNotFound
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("keychain lookup failed: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), true, nil
+}
+
+func (k *macKeyring) set(path, value string) error {
+	// Commands are read from stdin and the value hex-encoded, so the secret
+	// never appears in the process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", k.service, path, hex.EncodeToString([]byte(value))))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keychain store failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (k *macKeyring) remove(path string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", k.service, "-a", path).Run()
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) && exitErr.ExitCode() == 44 { // errSecItemNotFound
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("keychain delete failed: %w", err)
+	}
+	return nil
+}
+
+// secretToolKeyring uses the freedesktop Secret Service through secret-tool(1)
+type secretToolKeyring struct {
+	service string
+}
+
+func (k *secretToolKeyring) name() string {
+	return "Secret Service keyring"
+}
+
+func (k *secretToolKeyring) get(path string) (string, bool, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", k.service, "path", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// secret-tool exits 1 without output when nothing matches
+		var exitErr *exec.ExitError
+		if stderrors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("keyring lookup failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSuffix(string(out), "\n"), true, nil
+}
+
+func (k *secretToolKeyring) set(path, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", "githelper "+path, "service", k.service, "path", path)
+	cmd.Stdin = strings.NewReader(value)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring store failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (k *secretToolKeyring) remove(path string) error {
+	// clear succeeds whether or not anything matched
+	cmd := exec.Command("secret-tool", "clear", "service", k.service, "path", path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring delete failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// fileKeyring is the fallback: a JSON object of path to value, mode 0600
+type fileKeyring struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (k *fileKeyring) name() string {
+	return fmt.Sprintf("keyring file %s", k.path)
+}
+
+func (k *fileKeyring) get(path string) (string, bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entries, err := k.load()
+	if err != nil {
+		return "", false, err
+	}
+	value, found := entries[path]
+	return value, found, nil
+}
+
+func (k *fileKeyring) set(path, value string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+	entries[path] = value
+	return k.save(entries)
+}
+
+// save writes entries; the caller holds mu
+func (k *fileKeyring) save(entries map[string]string) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keyring file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return fmt.Errorf("failed to create keyring directory: %w", err)
+	}
+	return writeFileAtomic(k.path, data, 0600)
+}
+
+func (k *fileKeyring) remove(path string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[path]; !ok {
+		return nil
+	}
+	delete(entries, path)
+	return k.save(entries)
+}
+
+func (k *fileKeyring) load() (map[string]string, error) {
+	entries := make(map[string]string)
+
+	data, err := os.ReadFile(k.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring file %s: %w", k.path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,180 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+)
+
+// Default path templates
+const (
+	DefaultConfigPath    = "githelper/config"
+	DefaultSSHKeyPath    = "githelper/github/default_ssh"
+	DefaultPATPath       = "githelper/github/default_pat"
+	DefaultRepoSSHKeyTpl = "githelper/github/{repo}/ssh"
+	DefaultRepoPATTpl    = "githelper/github/{repo}/pat"
+)
+
+// Layout maps each kind of credential to a path template. Templates may use
+// {repo} (the githelper repository name), {org} (the repository's GitHub
+// owner), and {user} (the local login name). The repository-specific SSH key
+// and PAT are tried first, then the default ones.
+type Layout struct {
+	Config        string `yaml:"config"`
+	SSHKey        string `yaml:"ssh_key"`
+	DefaultSSHKey string `yaml:"default_ssh_key"`
+	PAT           string `yaml:"pat"`
+	DefaultPAT    string `yaml:"default_pat"`
+}
+
+// Vars fills in the placeholders of a Layout
+type Vars struct {
+	Repo string
+	Org  string
+	User string // Defaults to the local login name
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// DefaultLayout returns the layout githelper has always used
+func DefaultLayout() Layout {
+	return Layout{
+		Config:        DefaultConfigPath,
+		SSHKey:        DefaultRepoSSHKeyTpl,
+		DefaultSSHKey: DefaultSSHKeyPath,
+		PAT:           DefaultRepoPATTpl,
+		DefaultPAT:    DefaultPATPath,
+	}
+}
+
+// WithDefaults fills empty templates from DefaultLayout
+func (l Layout) WithDefaults() Layout {
+	d := DefaultLayout()
+	for _, f := range []struct{ field, def *string }{
+		{&l.Config, &d.Config},
+		{&l.SSHKey, &d.SSHKey},
+		{&l.DefaultSSHKey, &d.DefaultSSHKey},
+		{&l.PAT, &d.PAT},
+		{&l.DefaultPAT, &d.DefaultPAT},
+	} {
+		if strings.TrimSpace(*f.field) == "" {
+			*f.field = *f.def
+		}
+	}
+	return l
+}
+
+// Validate rejects unknown placeholders, and repository placeholders in the
+// config path (it is read before any repository is known)
+func (l Layout) Validate() error {
+	l = l.WithDefaults()
+	for name, tpl := range map[string]string{
+		"config":          l.Config,
+		"ssh_key":         l.SSHKey,
+		"default_ssh_key": l.DefaultSSHKey,
+		"pat":             l.PAT,
+		"default_pat":     l.DefaultPAT,
+	} {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(tpl, -1) {
+			switch m[1] {
+			case "repo", "org":
+				if name == "config" {
+					return fmt.Errorf("secrets layout %s: {%s} is not available for the config path", name, m[1])
+				}
+			case "user":
+			default:
+				return fmt.Errorf("secrets layout %s: unknown placeholder {%s} (want {repo}, {org}, or {user})", name, m[1])
+			}
+		}
+	}
+	return nil
+}
+
+// ConfigPath resolves the config path
+func (l Layout) ConfigPath(v Vars) (string, error) {
+	return expand(l.WithDefaults().Config, v)
+}
+
+// SSHKeyPaths returns the SSH key paths to try, most specific first. The
+// repository-specific path is left out when its placeholders can't be filled.
+func (l Layout) SSHKeyPaths(v Vars) ([]string, error) {
+	l = l.WithDefaults()
+	return candidates(l.SSHKey, l.DefaultSSHKey, v)
+}
+
+// PATPaths returns the PAT paths to try, most specific first
+func (l Layout) PATPaths(v Vars) ([]string, error) {
+	l = l.WithDefaults()
+	return candidates(l.PAT, l.DefaultPAT, v)
+}
+
+// RepoSSHKeyPath resolves the repository-specific SSH key path, failing if
+// the layout would place it at the shared default path
+func (l Layout) RepoSSHKeyPath(v Vars) (string, error) {
+	l = l.WithDefaults()
+	path, err := expand(l.SSHKey, v)
+	if err != nil {
+		return "", err
+	}
+	if def, err := expand(l.DefaultSSHKey, v); err == nil && def == path {
+		return "", fmt.Errorf("secrets layout ssh_key %q is shared with default_ssh_key", l.SSHKey)
+	}
+	return path, nil
+}
+
+func candidates(specific, fallback string, v Vars) ([]string, error) {
+	var paths []string
+	if v.Repo != "" {
+		if p, err := expand(specific, v); err == nil {
+			paths = append(paths, p)
+		}
+	}
+
+	p, err := expand(fallback, v)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 || paths[0] != p {
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// expand substitutes placeholders, failing if any needed value is empty
+func expand(tpl string, v Vars) (string, error) {
+	if v.User == "" && strings.Contains(tpl, "{user}") {
+		v.User = localUser()
+	}
+
+	var missing []string
+	path := placeholderPattern.ReplaceAllStringFunc(tpl, func(m string) string {
+		var value string
+		switch m {
+		case "{repo}":
+			value = v.Repo
+		case "{org}":
+			value = v.Org
+		case "{user}":
+			value = v.User
+		}
+		if value == "" {
+			missing = append(missing, m)
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("secret path %q needs %s", tpl, strings.Join(missing, ", "))
+	}
+	return path, nil
+}
+
+// localUser returns the login name of the current user
+func localUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLayout_Paths(t *testing.T) {
+	custom := Layout{
+		SSHKey:        "kv/{org}/{repo}/deploy-key",
+		DefaultSSHKey: "kv/{org}/deploy-key",
+		PAT:           "kv/users/{user}/{repo}/pat",
+		DefaultPAT:    "kv/users/{user}/pat",
+	}
+
+	tests := []struct {
+		name    string
+		layout  Layout
+		vars    Vars
+		wantSSH []string
+		wantPAT []string
+		wantErr bool
+	}{
+		{
+			name:    "default layout",
+			layout:  Layout{},
+			vars:    Vars{Repo: "api"},
+			wantSSH: []string{"githelper/github/api/ssh", DefaultSSHKeyPath},
+			wantPAT: []string{"githelper/github/api/pat", DefaultPATPath},
+		},
+		{
+			name:    "default layout without repo",
+			layout:  Layout{},
+			vars:    Vars{},
+			wantSSH: []string{DefaultSSHKeyPath},
+			wantPAT: []string{DefaultPATPath},
+		},
+		{
+			name:    "custom layout",
+			layout:  custom,
+			vars:    Vars{Repo: "api", Org: "acme", User: "bob"},
+			wantSSH: []string{"kv/acme/api/deploy-key", "kv/acme/deploy-key"},
+			wantPAT: []string{"kv/users/bob/api/pat", "kv/users/bob/pat"},
+		},
+		{
+			name:    "missing org",
+			layout:  custom,
+			vars:    Vars{Repo: "api", User: "bob"},
+			wantErr: true,
+		},
+		{
+			name:    "same specific and default path",
+			layout:  Layout{PAT: "shared/pat", DefaultPAT: "shared/pat", SSHKey: "shared/ssh", DefaultSSHKey: "shared/ssh"},
+			vars:    Vars{Repo: "api"},
+			wantSSH: []string{"shared/ssh"},
+			wantPAT: []string{"shared/pat"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ssh, err := tt.layout.SSHKeyPaths(tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SSHKeyPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(ssh, tt.wantSSH) {
+				t.Errorf("SSHKeyPaths() = %v, want %v", ssh, tt.wantSSH)
+			}
+
+			pat, err := tt.layout.PATPaths(tt.vars)
+			if err != nil {
+				t.Fatalf("PATPaths() error = %v", err)
+			}
+			if !reflect.DeepEqual(pat, tt.wantPAT) {
+				t.Errorf("PATPaths() = %v, want %v", pat, tt.wantPAT)
+			}
+		})
+	}
+}
+
+func TestLayout_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		wantErr bool
+	}{
+		{name: "empty", layout: Layout{}},
+		{name: "all placeholders", layout: Layout{Config: "{user}/config", PAT: "{org}/{repo}/{user}/pat"}},
+		{name: "repo in config", layout: Layout{Config: "githelper/{repo}/config"}, wantErr: true},
+		{name: "unknown placeholder", layout: Layout{DefaultPAT: "githelper/{team}/pat"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.layout.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
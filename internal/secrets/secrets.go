@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lcgerke/githelper/internal/vault"
+)
+
+// Backends
+const (
+	BackendVault   = "vault"
+	BackendFile    = "file"
+	BackendEnv     = "env"
+	BackendKeyring = "keyring"
+)
+
+var (
+	// ErrNotFound is returned (wrapped) by Get when no secret exists at a path
+	ErrNotFound = errors.New("secret not found")
+
+	// ErrReadOnly is returned (wrapped) by Put on backends that cannot store secrets
+	ErrReadOnly = errors.New("secrets backend is read-only")
+)
+
+// Provider stores secrets as small key/value documents addressed by
+// slash-separated paths such as "githelper/github/default_pat"
+type Provider interface {
+	// Name identifies the backend in messages
+	Name() string
+	// Get returns the document at path, or an error wrapping ErrNotFound
+	Get(path string) (map[string]interface{}, error)
+	// Put replaces the document at path
+	Put(path string, data map[string]interface{}) error
+	// Delete removes the document at path; a missing path is not an error
+	Delete(path string) error
+	// IsReachable reports whether the backend can currently be read
+	IsReachable() bool
+}
+
+// Options selects and configures a backend. It is the "secrets" section of
+// ~/.githelper/config.yaml; an empty Backend means Vault. Layout applies to
+// every backend.
+type Options struct {
+	Backend string         `yaml:"backend"`
+	Layout  Layout         `yaml:"layout"`
+	Vault   vault.Options  `yaml:"vault"`
+	File    FileOptions    `yaml:"file"`
+	Env     EnvOptions     `yaml:"env"`
+	Keyring KeyringOptions `yaml:"keyring"`
+}
+
+// New creates the provider described by options
+func New(ctx context.Context, options Options) (Provider, error) {
+	if err := options.Layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch options.Backend {
+	case "", BackendVault:
+		return NewVaultProvider(ctx, options.Vault)
+	case BackendFile:
+		return NewFileProvider(options.File)
+	case BackendEnv:
+		return NewEnvProvider(options.Env), nil
+	case BackendKeyring:
+		return NewKeyringProvider(options.Keyring)
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q (want %s, %s, %s, or %s)",
+			options.Backend, BackendVault, BackendFile, BackendEnv, BackendKeyring)
+	}
+}
+
+// defaultPath returns name inside ~/.githelper
+func defaultPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", name), nil
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) (string, error) {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// notFound wraps ErrNotFound with the path
+func notFound(path string) error {
+	return fmt.Errorf("%w at %s", ErrNotFound, path)
+}
@@ -0,0 +1,402 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/lcgerke/githelper/internal/vault"
+)
+
+// seedFunc stores data at path, for backends whose Put is read-only
+type seedFunc func(t *testing.T, path string, data map[string]interface{})
+
+// testProvider runs the behaviour every backend must share. If seed is nil
+// the provider's own Put is used.
+func testProvider(t *testing.T, p Provider, seed seedFunc) {
+	t.Helper()
+
+	put := func(t *testing.T, path string, data map[string]interface{}) {
+		t.Helper()
+		if seed != nil {
+			seed(t, path, data)
+			return
+		}
+		if err := p.Put(path, data); err != nil {
+			t.Fatalf("Put(%s) error = %v", path, err)
+		}
+	}
+
+	t.Run("reachable", func(t *testing.T) {
+		if !p.IsReachable() {
+			t.Error("IsReachable() = false, want true")
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := p.Get("githelper/missing")
+		if !stderrors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		put(t, "githelper/test/roundtrip", map[string]interface{}{"token": "abc123", "user": "alice"})
+
+		data, err := p.Get("githelper/test/roundtrip")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if data["token"] != "abc123" || data["user"] != "alice" {
+			t.Errorf("Get() = %v", data)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		put(t, "githelper/test/overwrite", map[string]interface{}{"token": "old"})
+		put(t, "githelper/test/overwrite", map[string]interface{}{"token": "new"})
+
+		data, err := p.Get("githelper/test/overwrite")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if data["token"] != "new" {
+			t.Errorf("token = %v, want new", data["token"])
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if seed != nil {
+			t.Skip("backend is read-only")
+		}
+		put(t, "githelper/test/delete", map[string]interface{}{"token": "gone"})
+
+		if err := p.Delete("githelper/test/delete"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := p.Get("githelper/test/delete"); !stderrors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+		}
+		if err := p.Delete("githelper/test/delete"); err != nil {
+			t.Errorf("Delete() of missing path error = %v", err)
+		}
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		if seed == nil {
+			t.Skip("backend is writable")
+		}
+		err := p.Put("githelper/test/readonly", map[string]interface{}{"token": "x"})
+		if !stderrors.Is(err, ErrReadOnly) {
+			t.Errorf("Put() error = %v, want ErrReadOnly", err)
+		}
+		if err := p.Delete("githelper/test/readonly"); !stderrors.Is(err, ErrReadOnly) {
+			t.Errorf("Delete() error = %v, want ErrReadOnly", err)
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		layout := DefaultLayout()
+		put(t, DefaultConfigPath, map[string]interface{}{
+			"github_username":    "alice",
+			"bare_repo_pattern":  "git@example.com:/srv/git/{repo}.git",
+			"auto_create_github": "true",
+		})
+		put(t, DefaultPATPath, map[string]interface{}{"token": "default-token"})
+		put(t, "githelper/github/special/pat", map[string]interface{}{"token": "special-token"})
+		put(t, DefaultSSHKeyPath, map[string]interface{}{"private_key": "PRIVATE", "public_key": "PUBLIC"})
+
+		cfg, err := GetConfig(p, layout)
+		if err != nil {
+			t.Fatalf("GetConfig() error = %v", err)
+		}
+		if cfg.GitHubUsername != "alice" || !cfg.AutoCreateGitHub {
+			t.Errorf("GetConfig() = %+v", cfg)
+		}
+
+		for repo, want := range map[string]string{"special": "special-token", "other": "default-token"} {
+			pat, err := GetPAT(p, layout, Vars{Repo: repo})
+			if err != nil {
+				t.Fatalf("GetPAT(%s) error = %v", repo, err)
+			}
+			if pat != want {
+				t.Errorf("GetPAT(%s) = %q, want %q", repo, pat, want)
+			}
+		}
+
+		keyPath, err := DownloadSSHKey(p, layout, Vars{Repo: "myrepo"}, t.TempDir())
+		if err != nil {
+			t.Fatalf("DownloadSSHKey() error = %v", err)
+		}
+		if got, _ := os.ReadFile(keyPath); string(got) != "PRIVATE" {
+			t.Errorf("private key = %q, want PRIVATE", got)
+		}
+		info, err := os.Stat(keyPath)
+		if err != nil {
+			t.Fatalf("private key not written: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("private key mode = %v, want 0600", info.Mode().Perm())
+		}
+	})
+
+	t.Run("custom layout", func(t *testing.T) {
+		layout := Layout{
+			Config:     "teams/{user}/githelper",
+			PAT:        "orgs/{org}/repos/{repo}/pat",
+			DefaultPAT: "orgs/{org}/pat",
+		}
+		vars := Vars{Repo: "api", Org: "acme", User: "alice"}
+
+		put(t, "orgs/acme/pat", map[string]interface{}{"token": "acme-token"})
+
+		path, err := ResolvePATPath(p, layout, vars)
+		if err != nil {
+			t.Fatalf("ResolvePATPath() error = %v", err)
+		}
+		if path != "orgs/acme/pat" {
+			t.Errorf("ResolvePATPath() = %s, want orgs/acme/pat", path)
+		}
+
+		// No org means the per-org default can't be resolved at all
+		if _, err := GetPAT(p, layout, Vars{Repo: "api"}); err == nil || !strings.Contains(err.Error(), "{org}") {
+			t.Errorf("GetPAT() without org error = %v, want missing {org}", err)
+		}
+	})
+}
+
+func TestFileProvider_Age(t *testing.T) {
+	dir := t.TempDir()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	identityPath := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(identityPath, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write identity: %v", err)
+	}
+
+	path := filepath.Join(dir, "secrets.age")
+	p, err := NewFileProvider(FileOptions{Path: path, Identity: identityPath})
+	if err != nil {
+		t.Fatalf("NewFileProvider() error = %v", err)
+	}
+
+	testProvider(t, p, nil)
+
+	ciphertext, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read secrets file: %v", err)
+	}
+	if strings.Contains(string(ciphertext), "default-token") {
+		t.Error("secrets file contains plaintext")
+	}
+
+	// A different identity can't read the file
+	other, _ := age.GenerateX25519Identity()
+	otherPath := filepath.Join(dir, "other.txt")
+	if err := os.WriteFile(otherPath, []byte(other.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write identity: %v", err)
+	}
+	wrong, err := NewFileProvider(FileOptions{Path: path, Identity: otherPath})
+	if err != nil {
+		t.Fatalf("NewFileProvider() error = %v", err)
+	}
+	if wrong.IsReachable() {
+		t.Error("IsReachable() = true with the wrong identity")
+	}
+}
+
+func TestFileProvider_GPG(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("GNUPGHOME", home)
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	})
+
+	gen := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "githelper-test@example.com", "default", "default", "never")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Skipf("failed to generate gpg key: %v: %s", err, out)
+	}
+
+	p, err := NewFileProvider(FileOptions{
+		Path:       filepath.Join(t.TempDir(), "secrets.gpg"),
+		Encryption: EncryptionGPG,
+		Recipients: []string{"githelper-test@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewFileProvider() error = %v", err)
+	}
+
+	testProvider(t, p, nil)
+}
+
+func TestNewFileProvider_UnknownEncryption(t *testing.T) {
+	if _, err := NewFileProvider(FileOptions{Encryption: "rot13"}); err == nil {
+		t.Error("NewFileProvider() error = nil, want error")
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	p := NewEnvProvider(EnvOptions{Prefix: "GHTEST_"})
+
+	var mu sync.Mutex
+	env := map[string]string{"UNRELATED": "x"}
+	p.environ = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var kv []string
+		for k, v := range env {
+			kv = append(kv, k+"="+v)
+		}
+		return kv
+	}
+
+	seed := func(t *testing.T, path string, data map[string]interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		for field, value := range data {
+			env[p.EnvVar(path, field)] = value.(string)
+		}
+	}
+
+	testProvider(t, p, seed)
+
+	if got := p.EnvVar("githelper/github/default_pat", "token"); got != "GHTEST_GITHELPER_GITHUB_DEFAULT_PAT__TOKEN" {
+		t.Errorf("EnvVar() = %s", got)
+	}
+}
+
+func TestKeyringProvider_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	p, err := NewKeyringProvider(KeyringOptions{FileOnly: true, FallbackPath: path})
+	if err != nil {
+		t.Fatalf("NewKeyringProvider() error = %v", err)
+	}
+
+	testProvider(t, p, nil)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("keyring file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("keyring file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+// fakeVault serves the KV v2 and health endpoints the Vault client uses
+func fakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	kv := make(map[string]map[string]interface{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/sys/health" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"initialized": true, "sealed": false})
+			return
+		}
+
+		path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := kv[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     data,
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			})
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			kv[path] = body.Data
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"version": 1},
+			})
+		case http.MethodDelete:
+			delete(kv, path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := fakeVault(t)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	p, err := NewVaultProvider(context.Background(), vault.Options{})
+	if err != nil {
+		t.Fatalf("NewVaultProvider() error = %v", err)
+	}
+
+	testProvider(t, p, nil)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		wantType string
+		wantErr  bool
+	}{
+		{name: "env", options: Options{Backend: BackendEnv}, wantType: "*secrets.EnvProvider"},
+		{name: "keyring", options: Options{Backend: BackendKeyring, Keyring: KeyringOptions{FileOnly: true, FallbackPath: filepath.Join(t.TempDir(), "k.json")}}, wantType: "*secrets.KeyringProvider"},
+		{name: "default is vault", options: Options{}, wantType: "*secrets.VaultProvider"},
+		{name: "unknown", options: Options{Backend: "s3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(context.Background(), tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := fmt.Sprintf("%T", p); got != tt.wantType {
+				t.Errorf("New() type = %s, want %s", got, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	stderrors "errors"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/lcgerke/githelper/internal/vault"
+)
+
+// VaultProvider reads secrets from a Vault KV v2 engine
+type VaultProvider struct {
+	client *vault.Client
+}
+
+// NewVaultProvider creates a provider for the server at VAULT_ADDR, using the
+// auth method and KV mount in options
+func NewVaultProvider(ctx context.Context, options vault.Options) (*VaultProvider, error) {
+	client, err := vault.NewClientWithOptions(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return &VaultProvider{client: client}, nil
+}
+
+func (p *VaultProvider) Name() string {
+	return "Vault"
+}
+
+// Mount returns the KV engine mount and version in use
+func (p *VaultProvider) Mount() (string, int) {
+	return p.client.Mount()
+}
+
+func (p *VaultProvider) Get(path string) (map[string]interface{}, error) {
+	data, err := p.client.GetSecret(path)
+	if err != nil {
+		if stderrors.Is(err, vaultapi.ErrSecretNotFound) || stderrors.Is(err, vault.ErrNoData) {
+			return nil, notFound(path)
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p *VaultProvider) Put(path string, data map[string]interface{}) error {
+	return p.client.PutSecret(path, data)
+}
+
+func (p *VaultProvider) Delete(path string) error {
+	return p.client.DeleteSecret(path)
+}
+
+func (p *VaultProvider) IsReachable() bool {
+	return p.client.IsReachable()
+}
+
+// Close stops background token renewal
+func (p *VaultProvider) Close() {
+	p.client.Close()
+}
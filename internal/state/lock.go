@@ -0,0 +1,50 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockPollInterval is how often a contended lock is retried
+const lockPollInterval = 10 * time.Millisecond
+
+// fileLock is an advisory lock on a file shared by all githelper processes
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock opens path and locks it, shared or exclusive, waiting up to timeout.
+// The lock is released by Unlock or when the process exits.
+func acquireLock(path string, exclusive bool, timeout time.Duration) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			return &fileLock{file: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for lock %s", timeout, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *fileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to unlock: %w", err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package state
+
+import "os"
+
+// tryLock is a no-op where flock is unavailable; writes are still atomic
+// via rename, but concurrent read-modify-write transactions are not serialized.
+func tryLock(f *os.File, exclusive bool) (bool, error) {
+	return true, nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
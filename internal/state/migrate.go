@@ -0,0 +1,181 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the schema version written by this build.
+// Bump it together with a new entry in migrations whenever the on-disk layout
+// changes in a way older files need upgrading for.
+const CurrentVersion = 1
+
+// Migration upgrades a raw state document from version From to From+1.
+// Migrations work on the decoded YAML rather than State, because older
+// layouts may not fit the current structs.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// migrations is the ordered registry, one entry per schema version
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "add schema version, drop empty repository entries, default unset GitHub sync_status to unknown",
+		Apply:       migrateV0,
+	},
+}
+
+// MigrationReport describes an upgrade of the state file
+type MigrationReport struct {
+	FromVersion int      `json:"from_version"`
+	ToVersion   int      `json:"to_version"`
+	Applied     []string `json:"applied"`
+	Before      string   `json:"before"`
+	After       string   `json:"after"`
+	BackupPath  string   `json:"backup_path,omitempty"`
+}
+
+// Needed reports whether the file was (or would be) changed
+func (r *MigrationReport) Needed() bool {
+	return r.FromVersion < r.ToVersion
+}
+
+// Migrate upgrades the state file to CurrentVersion, keeping a backup of the
+// original. With dryRun nothing is written and the report shows the change.
+func (s *yamlStore) Migrate(dryRun bool) (*MigrationReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := acquireLock(s.lockFile(), true, constants.StateLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	state, report, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || !report.Needed() {
+		return report, nil
+	}
+
+	if err := s.backup(report); err != nil {
+		return nil, err
+	}
+	if err := s.write(state); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// backup copies the pre-migration file next to the state file
+func (s *yamlStore) backup(report *MigrationReport) error {
+	path := fmt.Sprintf("%s.v%d-%s.bak", s.stateFile, report.FromVersion, time.Now().Format("20060102T150405"))
+	if err := os.WriteFile(path, []byte(report.Before), 0644); err != nil {
+		return fmt.Errorf("failed to back up state file: %w", err)
+	}
+	report.BackupPath = path
+	return nil
+}
+
+// decodeState parses a state document, applying any migrations it needs
+func decodeState(data []byte) (*State, *MigrationReport, error) {
+	report := &MigrationReport{ToVersion: CurrentVersion, Before: string(data), Applied: []string{}}
+
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	report.FromVersion = header.Version
+
+	if header.Version > CurrentVersion {
+		return nil, nil, fmt.Errorf("state file has schema version %d, but this githelper supports up to %d; upgrade githelper", header.Version, CurrentVersion)
+	}
+
+	if header.Version < CurrentVersion {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal state: %w", err)
+		}
+		if doc == nil {
+			doc = make(map[string]interface{})
+		}
+
+		for _, mig := range migrations {
+			if mig.From < header.Version {
+				continue
+			}
+			if err := mig.Apply(doc); err != nil {
+				return nil, nil, fmt.Errorf("state migration from version %d failed: %w", mig.From, err)
+			}
+			doc["version"] = mig.From + 1
+			report.Applied = append(report.Applied, fmt.Sprintf("v%d → v%d: %s", mig.From, mig.From+1, mig.Description))
+		}
+
+		migrated, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal migrated state: %w", err)
+		}
+		data = migrated
+	}
+
+	var state State
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+
+	// Initialize map if nil
+	if state.Repositories == nil {
+		state.Repositories = make(map[string]*Repository)
+	}
+	state.Version = CurrentVersion
+
+	if report.Needed() {
+		after, err := yaml.Marshal(&state)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal state: %w", err)
+		}
+		report.After = string(after)
+	}
+
+	return &state, report, nil
+}
+
+// migrateV0 upgrades files written before the schema was versioned
+func migrateV0(doc map[string]interface{}) error {
+	repos, ok := doc["repositories"].(map[string]interface{})
+	if !ok {
+		if doc["repositories"] != nil {
+			return fmt.Errorf("repositories is %T, want a mapping", doc["repositories"])
+		}
+		return nil
+	}
+
+	for name, value := range repos {
+		if value == nil {
+			// An empty entry holds nothing worth keeping
+			delete(repos, name)
+			continue
+		}
+		repo, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("repository %q is %T, want a mapping", name, value)
+		}
+		if gh, ok := repo["github"].(map[string]interface{}); ok {
+			if status, _ := gh["sync_status"].(string); status == "" {
+				gh["sync_status"] = "unknown"
+			}
+		}
+	}
+	return nil
+}
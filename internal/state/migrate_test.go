@@ -0,0 +1,168 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// legacyState is a state file written before the schema was versioned
+const legacyState = `repositories:
+    demo:
+        path: /src/demo
+        remote: git@core:demo.git
+        created: 2025-01-01T00:00:00Z
+        github:
+            enabled: true
+            user: me
+            repo: demo
+            sync_status: ""
+            needs_retry: false
+    broken: null
+`
+
+func writeStateFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, defaultStateFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, defaultStateFile+".v*.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestMigrate_DryRunWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	writeStateFile(t, dir, legacyState)
+	mgr, _ := NewManager(dir)
+
+	report, err := mgr.Migrate(true)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if !report.Needed() || report.FromVersion != 0 || report.ToVersion != CurrentVersion {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.Applied) != CurrentVersion {
+		t.Errorf("Applied = %v, want %d steps", report.Applied, CurrentVersion)
+	}
+	if !strings.Contains(report.After, "version: 1") || !strings.Contains(report.After, "sync_status: unknown") {
+		t.Errorf("After missing migrated fields:\n%s", report.After)
+	}
+	if strings.Contains(report.After, "broken") {
+		t.Errorf("After still contains null repository:\n%s", report.After)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, defaultStateFile))
+	if string(data) != legacyState {
+		t.Errorf("dry run modified state file:\n%s", data)
+	}
+	if b := backups(t, dir); len(b) != 0 {
+		t.Errorf("dry run wrote backups: %v", b)
+	}
+}
+
+func TestLoad_UpgradesLegacyFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	writeStateFile(t, dir, legacyState)
+	mgr, _ := NewManager(dir)
+
+	st, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if st.Version != CurrentVersion || len(st.Repositories) != 1 {
+		t.Errorf("Load() = version %d with %d repositories", st.Version, len(st.Repositories))
+	}
+	if got := st.Repositories["demo"].GitHub.SyncStatus; got != "unknown" {
+		t.Errorf("SyncStatus = %q, want unknown", got)
+	}
+
+	b := backups(t, dir)
+	if len(b) != 1 || !strings.Contains(b[0], ".v0-") {
+		t.Fatalf("backups = %v, want one v0 backup", b)
+	}
+	if data, _ := os.ReadFile(b[0]); string(data) != legacyState {
+		t.Errorf("backup does not match original:\n%s", data)
+	}
+
+	// Already current: nothing further to do
+	report, err := mgr.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if report.Needed() {
+		t.Errorf("second migration reported needed: %+v", report)
+	}
+	if b := backups(t, dir); len(b) != 1 {
+		t.Errorf("backups = %v, want still one", b)
+	}
+}
+
+func TestUpdate_UpgradesLegacyFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	writeStateFile(t, dir, legacyState)
+	mgr, _ := NewManager(dir)
+
+	if err := mgr.AddRepository("other", &Repository{Path: "/src/other"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	if b := backups(t, dir); len(b) != 1 {
+		t.Errorf("backups = %v, want one", b)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, defaultStateFile))
+	if !strings.Contains(string(data), "version: 1") {
+		t.Errorf("state file not stamped with version:\n%s", data)
+	}
+}
+
+func TestMigrate_RejectsMalformedRepository(t *testing.T) {
+	dir := t.TempDir()
+	content := legacyState + "    typo: /src/typo\n"
+	writeStateFile(t, dir, content)
+	mgr, _ := NewManager(dir)
+
+	if _, err := mgr.Migrate(false); err == nil || !strings.Contains(err.Error(), `"typo"`) {
+		t.Errorf("Migrate() error = %v, want one naming the typo entry", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, defaultStateFile))
+	if string(data) != content {
+		t.Errorf("failed migration modified state file:\n%s", data)
+	}
+}
+
+func TestLoad_RejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	writeStateFile(t, dir, "version: 99\nrepositories: {}\n")
+	mgr, _ := NewManager(dir)
+
+	if _, err := mgr.Load(); err == nil || !strings.Contains(err.Error(), "upgrade githelper") {
+		t.Errorf("Load() error = %v, want newer-schema error", err)
+	}
+}
+
+func TestNewFile_IsCurrentVersion(t *testing.T) {
+	dir := t.TempDir()
+	mgr, _ := NewManager(dir)
+
+	if err := mgr.AddRepository("demo", &Repository{}); err != nil {
+		t.Fatal(err)
+	}
+	if b := backups(t, dir); len(b) != 0 {
+		t.Errorf("new file produced backups: %v", b)
+	}
+	st, _ := mgr.Load()
+	if st.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", st.Version, CurrentVersion)
+	}
+}
@@ -0,0 +1,287 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lcgerke/githelper/internal/constants"
+	"gopkg.in/yaml.v3"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver is the database/sql driver name registered by modernc.org/sqlite,
+// a pure-Go SQLite, so the backend needs no cgo or system library
+const sqliteDriver = "sqlite"
+
+// sqliteSchema stores each repository as one row. The full repository is kept
+// as YAML in data; the GitHub sync fields are copied into indexed columns so
+// they can be queried without decoding every row.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS repositories (
+		name        TEXT PRIMARY KEY,
+		path        TEXT NOT NULL DEFAULT '',
+		sync_status TEXT NOT NULL DEFAULT '',
+		needs_retry INTEGER NOT NULL DEFAULT 0,
+		last_sync   INTEGER NOT NULL DEFAULT 0,
+		data        TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS repositories_retry ON repositories (needs_retry, last_sync)`,
+	`CREATE INDEX IF NOT EXISTS repositories_sync_status ON repositories (sync_status, last_sync)`,
+	`CREATE INDEX IF NOT EXISTS repositories_path ON repositories (path)`,
+}
+
+// sqliteStore keeps state in an embedded SQLite database, updating only the
+// rows that change
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// openSQLiteStore opens or creates the database at path
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	// Immediate transactions take the write lock up front, so concurrent
+	// processes queue on busy_timeout instead of failing mid-transaction
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		path, constants.StateLockTimeout.Milliseconds())
+
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	// One connection per process; cross-process safety comes from SQLite locking
+	db.SetMaxOpenConns(1)
+
+	if err := initSQLiteSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+// initSQLiteSchema creates the tables and checks the schema version
+func initSQLiteSchema(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read state database version: %w", err)
+	}
+	if version > CurrentVersion {
+		return fmt.Errorf("state database has schema version %d, but this githelper supports up to %d; upgrade githelper", version, CurrentVersion)
+	}
+
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create state database schema: %w", err)
+		}
+	}
+
+	if version < CurrentVersion {
+		// PRAGMA does not accept bound parameters
+		if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, CurrentVersion)); err != nil {
+			return fmt.Errorf("failed to set state database version: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Load() (*State, error) {
+	repos, err := loadRows(s.db, "")
+	if err != nil {
+		return nil, err
+	}
+	return &State{Version: CurrentVersion, Repositories: repos}, nil
+}
+
+func (s *sqliteStore) Save(state *State) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return replaceRows(tx, state.Repositories)
+	})
+}
+
+func (s *sqliteStore) Update(fn func(*State) error) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		repos, err := loadRows(tx, "")
+		if err != nil {
+			return err
+		}
+
+		state := &State{Version: CurrentVersion, Repositories: repos}
+		if err := fn(state); err != nil {
+			return err
+		}
+
+		return replaceRows(tx, state.Repositories)
+	})
+}
+
+func (s *sqliteStore) Get(name string) (*Repository, error) {
+	repos, err := loadRows(s.db, "WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, exists := repos[name]
+	if !exists {
+		return nil, fmt.Errorf("repository %s not found in state", name)
+	}
+	return repo, nil
+}
+
+func (s *sqliteStore) Put(name string, repo *Repository) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return putRow(tx, name, repo)
+	})
+}
+
+func (s *sqliteStore) Delete(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM repositories WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete repository %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) UpdateRepositories(names []string, fn func(name string, repo *Repository) error) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, name := range names {
+			repos, err := loadRows(tx, "WHERE name = ?", name)
+			if err != nil {
+				return err
+			}
+			repo, exists := repos[name]
+			if !exists {
+				continue
+			}
+
+			if err := fn(name, repo); err != nil {
+				return err
+			}
+			if err := putRow(tx, name, repo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Query filters on the indexed columns
+func (s *sqliteStore) Query(q Query) (map[string]*Repository, error) {
+	var where []string
+	var args []any
+
+	if q.NeedsRetry {
+		where = append(where, "needs_retry = 1")
+	}
+	if q.SyncStatus != "" {
+		where = append(where, "sync_status = ?")
+		args = append(args, q.SyncStatus)
+	}
+	if !q.LastSyncBefore.IsZero() {
+		where = append(where, "(last_sync = 0 OR last_sync < ?)")
+		args = append(args, q.LastSyncBefore.UnixNano())
+	}
+
+	clause := ""
+	if len(where) > 0 {
+		clause = "WHERE " + strings.Join(where, " AND ")
+	}
+	return loadRows(s.db, clause, args...)
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *sqliteStore) withTx(fn func(*sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin state transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit state transaction: %w", err)
+	}
+	return nil
+}
+
+// loadRows decodes the repositories selected by clause
+func loadRows(q rowQuerier, clause string, args ...any) (map[string]*Repository, error) {
+	rows, err := q.Query(`SELECT name, data FROM repositories `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state database: %w", err)
+	}
+	defer rows.Close()
+
+	repos := make(map[string]*Repository)
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to read state database: %w", err)
+		}
+
+		var repo Repository
+		if err := yaml.Unmarshal([]byte(data), &repo); err != nil {
+			return nil, fmt.Errorf("failed to decode repository %s: %w", name, err)
+		}
+		repos[name] = &repo
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read state database: %w", err)
+	}
+
+	return repos, nil
+}
+
+// replaceRows makes the table contain exactly repos
+func replaceRows(tx *sql.Tx, repos map[string]*Repository) error {
+	if _, err := tx.Exec(`DELETE FROM repositories`); err != nil {
+		return fmt.Errorf("failed to clear state database: %w", err)
+	}
+	for name, repo := range repos {
+		if err := putRow(tx, name, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putRow inserts or replaces one repository
+func putRow(tx *sql.Tx, name string, repo *Repository) error {
+	if repo == nil {
+		return fmt.Errorf("cannot store nil repository %s", name)
+	}
+
+	data, err := yaml.Marshal(repo)
+	if err != nil {
+		return fmt.Errorf("failed to encode repository %s: %w", name, err)
+	}
+
+	var syncStatus string
+	var needsRetry bool
+	var lastSync int64
+	if repo.GitHub != nil {
+		syncStatus = repo.GitHub.SyncStatus
+		needsRetry = repo.GitHub.NeedsRetry
+		if !repo.GitHub.LastSync.IsZero() {
+			lastSync = repo.GitHub.LastSync.UnixNano()
+		}
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO repositories (name, path, sync_status, needs_retry, last_sync, data)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		name, repo.Path, syncStatus, needsRetry, lastSync, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to store repository %s: %w", name, err)
+	}
+	return nil
+}
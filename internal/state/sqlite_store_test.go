@@ -0,0 +1,53 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	testBackend(t, BackendSQLite)
+}
+
+func TestSQLiteStore_ConcurrentManagers(t *testing.T) {
+	const workers, writes = 4, 10
+
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			mgr, err := OpenManager(dir, BackendSQLite)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer mgr.Close()
+			for i := 0; i < writes; i++ {
+				if err := mgr.AddRepository(fmt.Sprintf("w%d-%d", w, i), &Repository{}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+	if mgr.Backend() != BackendSQLite {
+		t.Errorf("Backend() = %s, want sqlite", mgr.Backend())
+	}
+	repos, err := mgr.ListRepositories()
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != workers*writes {
+		t.Errorf("got %d repositories, want %d", len(repos), workers*writes)
+	}
+}
@@ -4,36 +4,77 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/lcgerke/githelper/internal/constants"
 )
 
 const (
 	defaultStateFile = "state.yaml"
+
+	// DefaultHistoryLimit bounds how many snapshots are kept per repository
+	DefaultHistoryLimit = 200
 )
 
-// Manager handles the state file
+// Manager handles repository state, stored in a YAML file or SQLite database
 type Manager struct {
-	stateFile string
-	mu        sync.RWMutex
+	store   Store
+	backend string
 }
 
 // State represents the entire state file
 type State struct {
+	Version      int                    `yaml:"version"` // Schema version, see CurrentVersion
 	Repositories map[string]*Repository `yaml:"repositories"`
 }
 
 // Repository represents a single repository's state
 type Repository struct {
-	Path    string    `yaml:"path"`
-	Remote  string    `yaml:"remote"`
-	Created time.Time `yaml:"created"`
-	Type    string    `yaml:"type,omitempty"`
-	GitHub  *GitHub   `yaml:"github,omitempty"`
+	Path    string     `yaml:"path"`
+	Remote  string     `yaml:"remote"`
+	Created time.Time  `yaml:"created"`
+	Type    string     `yaml:"type,omitempty"`
+	GitHub  *GitHub    `yaml:"github,omitempty"`
+	Mirrors []Mirror   `yaml:"mirrors,omitempty"`
+	History []Snapshot `yaml:"history,omitempty"` // Oldest first, bounded by DefaultHistoryLimit
 }
 
+// Snapshot is a compact record of one classification run
+type Snapshot struct {
+	Time time.Time `yaml:"time" json:"time"`
+
+	// Scenario IDs per dimension
+	Existence   string   `yaml:"existence" json:"existence"`
+	Sync        string   `yaml:"sync" json:"sync"`
+	WorkingTree string   `yaml:"working_tree,omitempty" json:"working_tree,omitempty"`
+	Corruption  string   `yaml:"corruption,omitempty" json:"corruption,omitempty"`
+	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"` // Distinct non-synced tag scenarios
+
+	// Default branch hashes
+	Branch     string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	LocalHash  string `yaml:"local_hash,omitempty" json:"local_hash,omitempty"`
+	CoreHash   string `yaml:"core_hash,omitempty" json:"core_hash,omitempty"`
+	GitHubHash string `yaml:"github_hash,omitempty" json:"github_hash,omitempty"`
+
+	CoreReachable   bool `yaml:"core_reachable" json:"core_reachable"`
+	GitHubReachable bool `yaml:"github_reachable" json:"github_reachable"`
+
+	Warnings []string `yaml:"warnings,omitempty" json:"warnings,omitempty"` // Warning codes
+}
+
+// Mirror is one destination in a repository's mirror set
+type Mirror struct {
+	Name string `yaml:"name" json:"name"`                     // git remote name used to fetch from the mirror
+	URL  string `yaml:"url" json:"url"`                       // push URL
+	Role string `yaml:"role,omitempty" json:"role,omitempty"` // "core", "github", or empty for additional mirrors
+}
+
+// Mirror roles
+const (
+	MirrorRoleCore   = "core"
+	MirrorRoleGitHub = "github"
+)
+
 // GitHub represents GitHub integration state
 type GitHub struct {
 	Enabled    bool      `yaml:"enabled"`
@@ -45,99 +86,112 @@ type GitHub struct {
 	LastError  string    `yaml:"last_error,omitempty"`
 }
 
-// NewManager creates a new state manager
+// MirrorSet returns the repository's mirrors.
+// Repositories recorded before mirror sets existed get one derived from
+// Remote (core) and GitHub, so callers can treat every repository the same way.
+func (r *Repository) MirrorSet() []Mirror {
+	if len(r.Mirrors) > 0 {
+		return r.Mirrors
+	}
+
+	var mirrors []Mirror
+	if r.Remote != "" {
+		mirrors = append(mirrors, Mirror{Name: constants.DefaultCoreRemote, URL: r.Remote, Role: MirrorRoleCore})
+	}
+	if r.GitHub != nil && r.GitHub.Enabled && r.GitHub.User != "" && r.GitHub.Repo != "" {
+		mirrors = append(mirrors, Mirror{
+			Name: constants.DefaultGitHubRemote,
+			URL:  fmt.Sprintf("git@github.com:%s/%s.git", r.GitHub.User, r.GitHub.Repo),
+			Role: MirrorRoleGitHub,
+		})
+	}
+	return mirrors
+}
+
+// NewManager creates a new state manager using the backend already in use in
+// stateDir (see DetectBackend)
 func NewManager(stateDir string) (*Manager, error) {
 	if stateDir == "" {
-		home, err := os.UserHomeDir()
+		dir, err := DefaultDir()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+			return nil, err
 		}
-		stateDir = filepath.Join(home, ".githelper")
+		stateDir = dir
 	}
 
+	return OpenManager(stateDir, DetectBackend(stateDir))
+}
+
+// OpenManager creates a state manager for a specific backend
+func OpenManager(stateDir, backend string) (*Manager, error) {
 	// Ensure state directory exists
 	if err := os.MkdirAll(stateDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create state directory: %w", err)
 	}
 
-	stateFile := filepath.Join(stateDir, defaultStateFile)
+	store, err := openStore(stateDir, backend)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Manager{
-		stateFile: stateFile,
+		store:   store,
+		backend: backend,
 	}, nil
 }
 
-// Load loads the state from file
-func (m *Manager) Load() (*State, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	// If file doesn't exist, return empty state
-	if _, err := os.Stat(m.stateFile); os.IsNotExist(err) {
-		return &State{
-			Repositories: make(map[string]*Repository),
-		}, nil
-	}
-
-	data, err := os.ReadFile(m.stateFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
-	}
-
-	var state State
-	if err := yaml.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
-	}
+// Backend returns the name of the storage backend in use
+func (m *Manager) Backend() string {
+	return m.backend
+}
 
-	// Initialize map if nil
-	if state.Repositories == nil {
-		state.Repositories = make(map[string]*Repository)
-	}
+// Close releases the underlying store
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
 
-	return &state, nil
+// Load loads the whole state
+func (m *Manager) Load() (*State, error) {
+	return m.store.Load()
 }
 
-// Save saves the state to file
+// Save replaces the whole state.
+// Prefer Update for read-modify-write changes; Save alone can overwrite
+// changes another process made after this one loaded.
 func (m *Manager) Save(state *State) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.store.Save(state)
+}
 
-	data, err := yaml.Marshal(state)
-	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
-	}
+// Update loads the state, applies fn, and saves the result as one transaction,
+// so concurrent githelper processes cannot interleave and lose each other's
+// changes. If fn returns an error nothing is written.
+func (m *Manager) Update(fn func(*State) error) error {
+	return m.store.Update(fn)
+}
 
-	if err := os.WriteFile(m.stateFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+// Migrate upgrades stored state to CurrentVersion, keeping a backup of the
+// original. With dryRun nothing is written and the report shows the change.
+// The SQLite backend versions its schema when opened, so it never needs this.
+func (m *Manager) Migrate(dryRun bool) (*MigrationReport, error) {
+	if ys, ok := m.store.(*yamlStore); ok {
+		return ys.Migrate(dryRun)
 	}
+	return &MigrationReport{FromVersion: CurrentVersion, ToVersion: CurrentVersion, Applied: []string{}}, nil
+}
 
-	return nil
+// Query returns the repositories matching q
+func (m *Manager) Query(q Query) (map[string]*Repository, error) {
+	return m.store.Query(q)
 }
 
 // AddRepository adds or updates a repository in state
 func (m *Manager) AddRepository(name string, repo *Repository) error {
-	state, err := m.Load()
-	if err != nil {
-		return err
-	}
-
-	state.Repositories[name] = repo
-	return m.Save(state)
+	return m.store.Put(name, repo)
 }
 
 // GetRepository retrieves a repository from state
 func (m *Manager) GetRepository(name string) (*Repository, error) {
-	state, err := m.Load()
-	if err != nil {
-		return nil, err
-	}
-
-	repo, exists := state.Repositories[name]
-	if !exists {
-		return nil, fmt.Errorf("repository %s not found in state", name)
-	}
-
-	return repo, nil
+	return m.store.Get(name)
 }
 
 // ListRepositories returns all repositories
@@ -150,37 +204,96 @@ func (m *Manager) ListRepositories() (map[string]*Repository, error) {
 	return state.Repositories, nil
 }
 
-// DeleteRepository removes a repository from state
-func (m *Manager) DeleteRepository(name string) error {
-	state, err := m.Load()
+// UpdateRepository applies fn to one repository in a single transaction.
+// Use it instead of GetRepository followed by AddRepository, which can drop
+// changes another process made to the repository in between.
+func (m *Manager) UpdateRepository(name string, fn func(*Repository) error) error {
+	found := false
+	err := m.store.UpdateRepositories([]string{name}, func(_ string, repo *Repository) error {
+		found = true
+		return fn(repo)
+	})
 	if err != nil {
 		return err
 	}
+	if !found {
+		return fmt.Errorf("repository %s not found", name)
+	}
+	return nil
+}
 
-	delete(state.Repositories, name)
-	return m.Save(state)
+// DeleteRepository removes a repository from state
+func (m *Manager) DeleteRepository(name string) error {
+	return m.store.Delete(name)
 }
 
 // UpdateGitHubStatus updates the GitHub sync status for a repository
 func (m *Manager) UpdateGitHubStatus(name, syncStatus string, lastError string) error {
-	state, err := m.Load()
-	if err != nil {
-		return err
+	return m.UpdateRepository(name, func(repo *Repository) error {
+		if repo.GitHub == nil {
+			repo.GitHub = &GitHub{}
+		}
+
+		repo.GitHub.SyncStatus = syncStatus
+		repo.GitHub.LastSync = time.Now()
+		repo.GitHub.LastError = lastError
+		repo.GitHub.NeedsRetry = (lastError != "")
+		return nil
+	})
+}
+
+// RecordSnapshot appends a classification snapshot to a repository's history
+func (m *Manager) RecordSnapshot(name string, snapshot Snapshot) error {
+	return m.UpdateRepository(name, func(repo *Repository) error {
+		repo.appendSnapshot(snapshot)
+		return nil
+	})
+}
+
+// RecordSnapshots appends snapshots for several repositories in one transaction.
+// Names not present in state are skipped.
+func (m *Manager) RecordSnapshots(snapshots map[string]Snapshot) error {
+	names := make([]string, 0, len(snapshots))
+	for name := range snapshots {
+		names = append(names, name)
 	}
 
-	repo, exists := state.Repositories[name]
-	if !exists {
-		return fmt.Errorf("repository %s not found", name)
+	return m.store.UpdateRepositories(names, func(name string, repo *Repository) error {
+		repo.appendSnapshot(snapshots[name])
+		return nil
+	})
+}
+
+// appendSnapshot adds a snapshot, dropping the oldest beyond DefaultHistoryLimit
+func (r *Repository) appendSnapshot(snapshot Snapshot) {
+	r.History = append(r.History, snapshot)
+	if len(r.History) > DefaultHistoryLimit {
+		r.History = r.History[len(r.History)-DefaultHistoryLimit:]
+	}
+}
+
+// FindByPath returns the name of the repository whose Path is path.
+// Both sides are compared as absolute, cleaned paths.
+func (m *Manager) FindByPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
 	}
 
-	if repo.GitHub == nil {
-		repo.GitHub = &GitHub{}
+	repos, err := m.ListRepositories()
+	if err != nil {
+		return "", err
 	}
 
-	repo.GitHub.SyncStatus = syncStatus
-	repo.GitHub.LastSync = time.Now()
-	repo.GitHub.LastError = lastError
-	repo.GitHub.NeedsRetry = (lastError != "")
+	for name, repo := range repos {
+		repoAbs, err := filepath.Abs(repo.Path)
+		if err != nil {
+			continue
+		}
+		if repoAbs == abs {
+			return name, nil
+		}
+	}
 
-	return m.Save(state)
+	return "", fmt.Errorf("no repository at %s in state", abs)
 }
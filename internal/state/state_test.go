@@ -0,0 +1,181 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Environment used when the test binary is re-executed as a helper process
+const (
+	helperDirEnv    = "GITHELPER_STATE_HELPER_DIR"
+	helperIDEnv     = "GITHELPER_STATE_HELPER_ID"
+	helperWritesEnv = "GITHELPER_STATE_HELPER_WRITES"
+)
+
+// TestMain lets the test binary act as a separate githelper process writing state
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(helperDirEnv); dir != "" {
+		if err := runHelper(dir, os.Getenv(helperIDEnv), os.Getenv(helperWritesEnv)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper adds repositories one transaction at a time and records a
+// snapshot on the shared repository after each, interleaving with its peers
+func runHelper(dir, id, writes string) error {
+	n, err := strconv.Atoi(writes)
+	if err != nil {
+		return err
+	}
+
+	mgr, err := NewManager(dir)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		if err := mgr.AddRepository(fmt.Sprintf("%s-%d", id, i), &Repository{Path: dir}); err != nil {
+			return err
+		}
+		if err := mgr.RecordSnapshot("shared", Snapshot{Sync: id}); err != nil {
+			return err
+		}
+		if _, err := mgr.Load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUpdate_ConcurrentProcesses(t *testing.T) {
+	const processes, writes = 4, 15
+
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := mgr.AddRepository("shared", &Repository{Path: dir}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, processes)
+	for p := 0; p < processes; p++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^$")
+			cmd.Env = append(os.Environ(),
+				helperDirEnv+"="+dir,
+				helperIDEnv+"="+id,
+				helperWritesEnv+"="+strconv.Itoa(writes),
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("process %s: %v\n%s", id, err, out)
+			}
+		}(fmt.Sprintf("p%d", p))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	st, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// No process may lose another's update
+	if got, want := len(st.Repositories), processes*writes+1; got != want {
+		t.Errorf("got %d repositories, want %d", got, want)
+	}
+	if got, want := len(st.Repositories["shared"].History), processes*writes; got != want {
+		t.Errorf("got %d snapshots, want %d", got, want)
+	}
+
+	// Temp files are renamed or cleaned up
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("leftover temp file %s", e.Name())
+		}
+	}
+}
+
+func TestUpdate_ConcurrentGoroutines(t *testing.T) {
+	const workers, writes = 8, 10
+
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Separate managers behave like separate processes sharing the file
+			mgr, err := NewManager(dir)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for i := 0; i < writes; i++ {
+				if err := mgr.AddRepository(fmt.Sprintf("w%d-%d", w, i), &Repository{}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	mgr, _ := NewManager(dir)
+	repos, err := mgr.ListRepositories()
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != workers*writes {
+		t.Errorf("got %d repositories, want %d", len(repos), workers*writes)
+	}
+}
+
+func TestUpdate_ErrorLeavesStateUntouched(t *testing.T) {
+	dir := t.TempDir()
+	mgr, _ := NewManager(dir)
+	if err := mgr.AddRepository("demo", &Repository{Path: "/a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := os.ReadFile(filepath.Join(dir, defaultStateFile))
+
+	err := mgr.Update(func(st *State) error {
+		st.Repositories["demo"].Path = "/b"
+		return fmt.Errorf("abort")
+	})
+	if err == nil {
+		t.Fatal("Update() error = nil, want abort")
+	}
+
+	after, _ := os.ReadFile(filepath.Join(dir, defaultStateFile))
+	if string(before) != string(after) {
+		t.Errorf("state changed after failed Update:\n%s", after)
+	}
+}
+
+func TestUpdateRepository_NotFound(t *testing.T) {
+	mgr, _ := NewManager(t.TempDir())
+	err := mgr.UpdateRepository("missing", func(*Repository) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("UpdateRepository() error = %v, want not found", err)
+	}
+}
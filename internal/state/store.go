@@ -0,0 +1,129 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Storage backends
+const (
+	BackendYAML   = "yaml"
+	BackendSQLite = "sqlite"
+)
+
+const defaultSQLiteFile = "state.db"
+
+// Store persists repository state. Implementations must make Update and the
+// per-repository methods safe against concurrent githelper processes.
+type Store interface {
+	// Load returns the whole state
+	Load() (*State, error)
+	// Save replaces the whole state
+	Save(state *State) error
+	// Update applies fn to the whole state in one transaction
+	Update(fn func(*State) error) error
+
+	// Get returns one repository, or an error if it is not present
+	Get(name string) (*Repository, error)
+	// Put adds or replaces one repository
+	Put(name string, repo *Repository) error
+	// Delete removes one repository; deleting a missing name is not an error
+	Delete(name string) error
+	// UpdateRepositories applies fn to each named repository in one
+	// transaction, skipping names that are not present
+	UpdateRepositories(names []string, fn func(name string, repo *Repository) error) error
+
+	// Query returns the repositories matching q
+	Query(q Query) (map[string]*Repository, error)
+
+	Close() error
+}
+
+// Query selects repositories by GitHub sync state. Zero fields match everything.
+type Query struct {
+	NeedsRetry     bool      // Only repositories flagged for retry
+	SyncStatus     string    // Only repositories with this GitHub sync status
+	LastSyncBefore time.Time // Only repositories last synced before this time, or never
+}
+
+// Matches reports whether repo satisfies q
+func (q Query) Matches(repo *Repository) bool {
+	gh := repo.GitHub
+	if gh == nil {
+		gh = &GitHub{}
+	}
+
+	if q.NeedsRetry && !gh.NeedsRetry {
+		return false
+	}
+	if q.SyncStatus != "" && gh.SyncStatus != q.SyncStatus {
+		return false
+	}
+	if !q.LastSyncBefore.IsZero() && !gh.LastSync.IsZero() && !gh.LastSync.Before(q.LastSyncBefore) {
+		return false
+	}
+	return true
+}
+
+// DefaultDir returns ~/.githelper
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper"), nil
+}
+
+// DetectBackend returns the backend in use in stateDir: SQLite if state.db
+// exists, otherwise YAML
+func DetectBackend(stateDir string) string {
+	if _, err := os.Stat(filepath.Join(stateDir, defaultSQLiteFile)); err == nil {
+		return BackendSQLite
+	}
+	return BackendYAML
+}
+
+// openStore opens the named backend in stateDir
+func openStore(stateDir, backend string) (Store, error) {
+	switch backend {
+	case BackendYAML:
+		return newYAMLStore(filepath.Join(stateDir, defaultStateFile)), nil
+	case BackendSQLite:
+		return openSQLiteStore(filepath.Join(stateDir, defaultSQLiteFile))
+	default:
+		return nil, fmt.Errorf("unknown state backend %q (want %s or %s)", backend, BackendYAML, BackendSQLite)
+	}
+}
+
+// RetireBackend moves a backend's file aside so DetectBackend no longer picks
+// it. Returns the new path, or "" if the backend had no file.
+func RetireBackend(stateDir, backend string) (string, error) {
+	var file string
+	switch backend {
+	case BackendYAML:
+		file = defaultStateFile
+	case BackendSQLite:
+		file = defaultSQLiteFile
+	default:
+		return "", fmt.Errorf("unknown state backend %q", backend)
+	}
+
+	path := filepath.Join(stateDir, file)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	retired := fmt.Sprintf("%s.retired-%s.bak", path, time.Now().Format("20060102T150405"))
+	if err := os.Rename(path, retired); err != nil {
+		return "", fmt.Errorf("failed to move %s aside: %w", path, err)
+	}
+	return retired, nil
+}
+
+// ParseState decodes an exported YAML state document, migrating older schemas
+func ParseState(data []byte) (*State, error) {
+	state, _, err := decodeState(data)
+	return state, err
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBackend exercises the Store contract through a Manager
+func testBackend(t *testing.T, backend string) {
+	dir := t.TempDir()
+	mgr, err := OpenManager(dir, backend)
+	if err != nil {
+		t.Fatalf("OpenManager(%s) error = %v", backend, err)
+	}
+	defer mgr.Close()
+
+	now := time.Now()
+	repos := map[string]*Repository{
+		"fresh":   {Path: "/src/fresh", GitHub: &GitHub{SyncStatus: "synced", LastSync: now}},
+		"stale":   {Path: "/src/stale", GitHub: &GitHub{SyncStatus: "behind", NeedsRetry: true, LastSync: now.Add(-48 * time.Hour)}},
+		"retry":   {Path: "/src/retry", GitHub: &GitHub{SyncStatus: "behind", NeedsRetry: true, LastSync: now}},
+		"never":   {Path: "/src/never", GitHub: &GitHub{SyncStatus: "unknown", NeedsRetry: true}},
+		"nogithb": {Path: "/src/nogithub"},
+	}
+	for name, repo := range repos {
+		if err := mgr.AddRepository(name, repo); err != nil {
+			t.Fatalf("AddRepository(%s) error = %v", name, err)
+		}
+	}
+
+	got, err := mgr.GetRepository("stale")
+	if err != nil || got.Path != "/src/stale" || !got.GitHub.NeedsRetry {
+		t.Errorf("GetRepository() = %+v, %v", got, err)
+	}
+	if _, err := mgr.GetRepository("missing"); err == nil {
+		t.Error("GetRepository(missing) error = nil")
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"fresh", "never", "nogithb", "retry", "stale"}},
+		{"needs retry", Query{NeedsRetry: true}, []string{"never", "retry", "stale"}},
+		{"needs retry and stale", Query{NeedsRetry: true, LastSyncBefore: now.Add(-24 * time.Hour)}, []string{"never", "stale"}},
+		{"sync status", Query{SyncStatus: "behind"}, []string{"retry", "stale"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mgr.Query(tt.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if names := sortedNames(result); strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Query() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// Row-level updates
+	if err := mgr.UpdateGitHubStatus("stale", "synced", ""); err != nil {
+		t.Fatalf("UpdateGitHubStatus() error = %v", err)
+	}
+	if err := mgr.RecordSnapshots(map[string]Snapshot{"stale": {Sync: "S1"}, "missing": {Sync: "S1"}}); err != nil {
+		t.Fatalf("RecordSnapshots() error = %v", err)
+	}
+	got, _ = mgr.GetRepository("stale")
+	if got.GitHub.NeedsRetry || got.GitHub.SyncStatus != "synced" || len(got.History) != 1 {
+		t.Errorf("after updates = %+v, history %d", got.GitHub, len(got.History))
+	}
+	if err := mgr.UpdateRepository("missing", func(*Repository) error { return nil }); err == nil {
+		t.Error("UpdateRepository(missing) error = nil")
+	}
+
+	if err := mgr.DeleteRepository("never"); err != nil {
+		t.Fatalf("DeleteRepository() error = %v", err)
+	}
+
+	// Whole-state transaction
+	err = mgr.Update(func(st *State) error {
+		delete(st.Repositories, "fresh")
+		st.Repositories["added"] = &Repository{Path: "/src/added"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	st, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if names := strings.Join(sortedNames(st.Repositories), ","); names != "added,nogithb,retry,stale" {
+		t.Errorf("repositories after Update = %s", names)
+	}
+	if st.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", st.Version, CurrentVersion)
+	}
+}
+
+func TestYAMLStore(t *testing.T) {
+	testBackend(t, BackendYAML)
+}
+
+func TestOpenManager_UnknownBackend(t *testing.T) {
+	if _, err := OpenManager(t.TempDir(), "xml"); err == nil {
+		t.Error("OpenManager(xml) error = nil")
+	}
+}
+
+func TestDetectAndRetireBackend(t *testing.T) {
+	dir := t.TempDir()
+
+	if got := DetectBackend(dir); got != BackendYAML {
+		t.Errorf("DetectBackend(empty) = %s, want yaml", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, defaultSQLiteFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectBackend(dir); got != BackendSQLite {
+		t.Errorf("DetectBackend(state.db) = %s, want sqlite", got)
+	}
+
+	retired, err := RetireBackend(dir, BackendSQLite)
+	if err != nil || !strings.Contains(retired, defaultSQLiteFile+".retired-") {
+		t.Fatalf("RetireBackend() = %q, %v", retired, err)
+	}
+	if got := DetectBackend(dir); got != BackendYAML {
+		t.Errorf("DetectBackend after retire = %s, want yaml", got)
+	}
+
+	// Nothing left to retire
+	if retired, err := RetireBackend(dir, BackendSQLite); retired != "" || err != nil {
+		t.Errorf("RetireBackend(missing) = %q, %v", retired, err)
+	}
+}
+
+func TestParseState_MigratesExport(t *testing.T) {
+	st, err := ParseState([]byte(legacyState))
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	if st.Version != CurrentVersion || len(st.Repositories) != 1 {
+		t.Errorf("ParseState() = version %d, %d repositories", st.Version, len(st.Repositories))
+	}
+}
+
+func sortedNames(repos map[string]*Repository) []string {
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,224 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lcgerke/githelper/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlStore keeps the whole state in one YAML file, rewritten on every change
+type yamlStore struct {
+	stateFile string
+	mu        sync.RWMutex
+}
+
+func newYAMLStore(stateFile string) *yamlStore {
+	return &yamlStore{stateFile: stateFile}
+}
+
+// Load loads the state from file.
+// A shared lock is held while reading so a concurrent Save is never observed half-way.
+// Files written with an older schema are upgraded in place, keeping a backup.
+func (s *yamlStore) Load() (*State, error) {
+	state, report, err := s.readShared()
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Needed() {
+		if _, err := s.Migrate(false); err != nil {
+			return nil, err
+		}
+		state, _, err = s.readShared()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// readShared reads the state file under a shared lock
+func (s *yamlStore) readShared() (*State, *MigrationReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := acquireLock(s.lockFile(), false, constants.StateLockTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lock.Unlock()
+
+	return s.read()
+}
+
+// Save replaces the state file
+func (s *yamlStore) Save(state *State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := acquireLock(s.lockFile(), true, constants.StateLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return s.write(state)
+}
+
+// Update holds an exclusive lock across load, fn, and save
+func (s *yamlStore) Update(fn func(*State) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := acquireLock(s.lockFile(), true, constants.StateLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	state, report, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	if report.Needed() {
+		if err := s.backup(report); err != nil {
+			return err
+		}
+	}
+	return s.write(state)
+}
+
+func (s *yamlStore) Get(name string) (*Repository, error) {
+	state, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, exists := state.Repositories[name]
+	if !exists {
+		return nil, fmt.Errorf("repository %s not found in state", name)
+	}
+	return repo, nil
+}
+
+func (s *yamlStore) Put(name string, repo *Repository) error {
+	return s.Update(func(state *State) error {
+		state.Repositories[name] = repo
+		return nil
+	})
+}
+
+func (s *yamlStore) Delete(name string) error {
+	return s.Update(func(state *State) error {
+		delete(state.Repositories, name)
+		return nil
+	})
+}
+
+func (s *yamlStore) UpdateRepositories(names []string, fn func(name string, repo *Repository) error) error {
+	return s.Update(func(state *State) error {
+		for _, name := range names {
+			repo, exists := state.Repositories[name]
+			if !exists {
+				continue
+			}
+			if err := fn(name, repo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Query filters every repository in memory; the YAML file has no indexes
+func (s *yamlStore) Query(q Query) (map[string]*Repository, error) {
+	state, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make(map[string]*Repository)
+	for name, repo := range state.Repositories {
+		if q.Matches(repo) {
+			repos[name] = repo
+		}
+	}
+	return repos, nil
+}
+
+func (s *yamlStore) Close() error {
+	return nil
+}
+
+// lockFile is the path of the lock guarding the state file.
+// A separate file is used because the state file itself is replaced on every write.
+func (s *yamlStore) lockFile() string {
+	return s.stateFile + ".lock"
+}
+
+// read reads the state file, migrating it in memory if it uses an older
+// schema; the caller must hold the lock
+func (s *yamlStore) read() (*State, *MigrationReport, error) {
+	data, err := os.ReadFile(s.stateFile)
+	if os.IsNotExist(err) {
+		// If file doesn't exist, return empty state
+		return &State{
+			Version:      CurrentVersion,
+			Repositories: make(map[string]*Repository),
+		}, &MigrationReport{FromVersion: CurrentVersion, ToVersion: CurrentVersion, Applied: []string{}}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	return decodeState(data)
+}
+
+// write replaces the state file atomically by writing a temp file in the same
+// directory and renaming it over the original; the caller must hold the lock
+func (s *yamlStore) write(state *State) error {
+	state.Version = CurrentVersion
+
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.stateFile), filepath.Base(s.stateFile)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return fmt.Errorf("failed to set state file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpName, s.stateFile); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lcgerke/githelper/internal/git"
+)
+
+// Config is the templates section of ~/.githelper/config.yaml
+type Config struct {
+	// Dir holds personal templates, one per subdirectory
+	// (default: ~/.githelper/templates)
+	Dir string `yaml:"dir"`
+
+	// Remote is a git repository shared between machines, one template per
+	// top-level directory. It is cloned into the cache and refreshed on use.
+	Remote string `yaml:"remote"`
+
+	// Ref is the branch of Remote to use (default: its HEAD)
+	Ref string `yaml:"ref"`
+}
+
+// DefaultDir returns ~/.githelper/templates
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", "templates"), nil
+}
+
+// DefaultCacheDir returns ~/.githelper/cache/templates, where the shared
+// repository is cloned
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", "cache", "templates"), nil
+}
+
+// Discover finds every available template. cacheDir is where the shared
+// repository is kept (DefaultCacheDir if empty). A shared repository that
+// can't be refreshed falls back to the cached copy with a warning.
+func Discover(cfg Config, cacheDir string) (*Registry, error) {
+	r := &Registry{templates: make(map[string]*Template)}
+
+	if err := r.addBuiltin(); err != nil {
+		return nil, fmt.Errorf("failed to load builtin templates: %w", err)
+	}
+
+	if cfg.Remote != "" {
+		if cacheDir == "" {
+			var err error
+			cacheDir, err = DefaultCacheDir()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := SyncRemote(cfg.Remote, cfg.Ref, cacheDir); err != nil {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("could not refresh shared templates from %s: %v", cfg.Remote, err))
+		}
+		if err := r.addDir(cacheDir, SourceRemote); err != nil {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("failed to read shared templates: %v", err))
+		}
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		var err error
+		dir, err = DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.addDir(dir, SourceLocal); err != nil {
+		return nil, fmt.Errorf("failed to read templates in %s: %w", dir, err)
+	}
+
+	return r, nil
+}
+
+// SyncRemote clones url into dir, or brings an existing clone up to date
+// with ref (the remote's HEAD if empty). Local changes in dir are discarded.
+func SyncRemote(url, ref, dir string) error {
+	client := git.NewClient(dir)
+
+	if !isCloneRoot(client, dir) {
+		// Whatever is in dir isn't our clone. Checking the toplevel matters:
+		// git would otherwise find an enclosing repository (e.g. dotfiles
+		// tracking ~) and the reset below would wipe its work.
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clear cache directory: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		if err := git.Clone(url, dir); err != nil {
+			return err
+		}
+	} else {
+		if err := client.SetURL("origin", url); err != nil {
+			return fmt.Errorf("failed to update remote URL: %w", err)
+		}
+		if err := client.FetchRemote("origin"); err != nil {
+			return fmt.Errorf("fetch failed: %w", err)
+		}
+	}
+
+	target := "origin/HEAD"
+	if ref != "" {
+		target = "origin/" + ref
+	}
+	if err := client.ResetToRef(target); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", target, err)
+	}
+	return nil
+}
+
+// isCloneRoot reports whether dir is the top level of a git work tree, as
+// opposed to a plain directory inside some other repository
+func isCloneRoot(client *git.Client, dir string) bool {
+	exists, root := client.LocalExists()
+	if !exists {
+		return false
+	}
+
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	got, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	return got == want
+}
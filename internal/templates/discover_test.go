@@ -0,0 +1,161 @@
+package templates
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRun runs git in dir with a fixed identity
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	// Shared repository with a python template and its own go template
+	remote := t.TempDir()
+	writeFiles(t, remote, map[string]string{
+		"python/template.yaml":       "description: Python package\n",
+		"python/pyproject.toml.tmpl": "name = \"{{.Name}}\"\n",
+		"go/template.yaml":           "description: Team Go layout\n",
+		"README.md":                  "not a template\n",
+	})
+	gitRun(t, remote, "init", "--quiet")
+	gitRun(t, remote, "add", ".")
+	gitRun(t, remote, "commit", "--quiet", "-m", "templates")
+
+	// Personal templates override shared ones
+	local := t.TempDir()
+	writeFiles(t, local, map[string]string{
+		"python/template.yaml":  "description: My Python layout\n",
+		"empty/.keep":           "",
+		".hidden/template.yaml": "description: skipped\n",
+	})
+
+	cache := filepath.Join(t.TempDir(), "templates")
+	cfg := Config{Dir: local, Remote: remote}
+
+	registry, err := Discover(cfg, cache)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(registry.Warnings) != 0 {
+		t.Errorf("Warnings = %v", registry.Warnings)
+	}
+	if got := strings.Join(registry.Names(), ","); got != "empty,go,python" {
+		t.Errorf("Names() = %s, want empty,go,python", got)
+	}
+
+	if tmpl, _ := registry.Get("go"); tmpl.Source != SourceRemote || tmpl.Manifest.Description != "Team Go layout" {
+		t.Errorf("go = %+v, want the shared template", tmpl)
+	}
+	if tmpl, _ := registry.Get("python"); tmpl.Source != SourceLocal || tmpl.Location != filepath.Join(local, "python") {
+		t.Errorf("python = %+v, want the personal template", tmpl)
+	}
+
+	// New commits in the shared repository are picked up
+	writeFiles(t, remote, map[string]string{"rust/Cargo.toml.tmpl": "[package]\nname = \"{{.Name}}\"\n"})
+	gitRun(t, remote, "add", ".")
+	gitRun(t, remote, "commit", "--quiet", "-m", "rust")
+
+	registry, err = Discover(cfg, cache)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if _, ok := registry.Get("rust"); !ok {
+		t.Errorf("rust template not synced, have %v", registry.Names())
+	}
+
+	// An unreachable shared repository falls back to the cached copy
+	cfg.Remote = filepath.Join(t.TempDir(), "missing.git")
+	registry, err = Discover(cfg, cache)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(registry.Warnings) == 0 {
+		t.Error("Discover() gave no warning for an unreachable shared repository")
+	}
+	if _, ok := registry.Get("rust"); !ok {
+		t.Errorf("cached shared templates lost, have %v", registry.Names())
+	}
+}
+
+func TestDiscover_InvalidManifest(t *testing.T) {
+	local := t.TempDir()
+	writeFiles(t, local, map[string]string{"broken/template.yaml": "post_create: [\n"})
+
+	registry, err := Discover(Config{Dir: local}, "")
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if _, ok := registry.Get("broken"); ok {
+		t.Error("template with an invalid manifest was loaded")
+	}
+	if len(registry.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one for the broken manifest", registry.Warnings)
+	}
+	if _, ok := registry.Get("go"); !ok {
+		t.Error("builtin templates missing")
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, err := DefaultDir()
+	if err != nil || dir != filepath.Join(home, ".githelper", "templates") {
+		t.Errorf("DefaultDir() = %s, %v", dir, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("DefaultDir() should not create the directory")
+	}
+}
+
+func TestSyncRemote_CacheInsideAnotherRepository(t *testing.T) {
+	remote := t.TempDir()
+	writeFiles(t, remote, map[string]string{"python/template.yaml": "description: Python\n"})
+	gitRun(t, remote, "init", "--quiet")
+	gitRun(t, remote, "add", ".")
+	gitRun(t, remote, "commit", "--quiet", "-m", "templates")
+
+	// A home directory tracked by a dotfiles repository, with a stale,
+	// non-git cache directory inside it
+	home := t.TempDir()
+	writeFiles(t, home, map[string]string{".bashrc": "export EDITOR=vi\n"})
+	gitRun(t, home, "init", "--quiet")
+	gitRun(t, home, "add", ".")
+	gitRun(t, home, "commit", "--quiet", "-m", "dotfiles")
+	writeFiles(t, home, map[string]string{".bashrc": "export EDITOR=nano\n"})
+
+	cache := filepath.Join(home, ".githelper", "cache", "templates")
+	writeFiles(t, cache, map[string]string{"leftover": "partial download\n"})
+
+	if err := SyncRemote(remote, "", cache); err != nil {
+		t.Fatalf("SyncRemote() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(cache, "python", "template.yaml")); err != nil {
+		t.Errorf("templates not cloned into the cache: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "leftover")); !os.IsNotExist(err) {
+		t.Error("stale cache contents kept")
+	}
+
+	// The enclosing repository is untouched
+	bashrc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+	if err != nil || string(bashrc) != "export EDITOR=nano\n" {
+		t.Errorf("~/.bashrc = %q, %v, want the uncommitted change kept", bashrc, err)
+	}
+	out, err := exec.Command("git", "-C", home, "remote").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "" {
+		t.Errorf("enclosing repository remotes = %q, %v, want none", out, err)
+	}
+}
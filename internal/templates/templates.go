@@ -0,0 +1,302 @@
+// Package templates provides the starter files `githelper repo create --type`
+// writes into a new repository.
+//
+// A template is a directory of files. Files ending in .tmpl are rendered with
+// text/template (and lose the suffix); everything else is copied as is. File
+// and directory names may contain placeholders too. An optional template.yaml
+// at the root describes the template and lists post-create commands.
+//
+// Templates come from three places, later ones overriding earlier ones with
+// the same name: the templates built into githelper, a shared git repository
+// holding one template per top-level directory, and ~/.githelper/templates.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the optional file describing a template
+const ManifestFile = "template.yaml"
+
+const renderSuffix = ".tmpl"
+
+//go:embed all:builtin
+var builtinFS embed.FS
+
+// Source says where a template was found
+type Source string
+
+const (
+	SourceBuiltin Source = "builtin"
+	SourceRemote  Source = "remote"
+	SourceLocal   Source = "local"
+)
+
+// Manifest is the contents of template.yaml
+type Manifest struct {
+	Description string `yaml:"description"`
+
+	// PostCreate commands run through sh in the new repository after the
+	// files are written, before the initial commit. They are rendered with
+	// the same placeholders as the files.
+	PostCreate []string `yaml:"post_create"`
+}
+
+// Data holds the values available to placeholders
+type Data struct {
+	Name       string // Repository name
+	ModulePath string // e.g. github.com/owner/name
+	Owner      string // GitHub user or organization
+	Year       int    // Current year, for licence headers
+}
+
+// NewData returns placeholder values for a repository. An empty modulePath
+// defaults to github.com/<owner>/<name>, or just the name without an owner.
+func NewData(name, owner, modulePath string) Data {
+	if modulePath == "" {
+		modulePath = name
+		if owner != "" {
+			modulePath = fmt.Sprintf("github.com/%s/%s", owner, name)
+		}
+	}
+	return Data{Name: name, ModulePath: modulePath, Owner: owner, Year: time.Now().Year()}
+}
+
+// Template is a discovered template
+type Template struct {
+	Name     string
+	Source   Source
+	Location string // Directory the template was read from (empty for builtin)
+	Manifest Manifest
+
+	fsys fs.FS
+}
+
+// load reads the template rooted at fsys
+func load(name string, source Source, location string, fsys fs.FS) (*Template, error) {
+	t := &Template{Name: name, Source: source, Location: location, fsys: fsys}
+
+	data, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		return nil, fmt.Errorf("failed to read %s of template %s: %w", ManifestFile, name, err)
+	}
+	if err := yaml.Unmarshal(data, &t.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s of template %s: %w", ManifestFile, name, err)
+	}
+	return t, nil
+}
+
+// Render writes the template's files into dir and returns their paths
+// relative to dir. Existing files are never overwritten.
+func (t *Template) Render(dir string, data Data) ([]string, error) {
+	var written []string
+
+	err := fs.WalkDir(t.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if p == ManifestFile {
+			return nil
+		}
+
+		rel, err := expand(p, p, data)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		target = strings.TrimSuffix(target, renderSuffix)
+
+		content, err := fs.ReadFile(t.fsys, p)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(p, renderSuffix) {
+			rendered, err := expand(p, string(content), data)
+			if err != nil {
+				return err
+			}
+			content = []byte(rendered)
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := d.Info(); err == nil && info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		relTarget, _ := filepath.Rel(dir, target)
+		written = append(written, relTarget)
+		return nil
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to render template %s: %w", t.Name, err)
+	}
+
+	return written, nil
+}
+
+// RunPostCreate runs the manifest's post-create commands in dir, stopping at
+// the first failure. The placeholder values are also exported to the
+// commands as GITHELPER_* environment variables.
+func (t *Template) RunPostCreate(dir string, data Data, stdout, stderr io.Writer) error {
+	env := append(os.Environ(),
+		"GITHELPER_REPO_NAME="+data.Name,
+		"GITHELPER_MODULE_PATH="+data.ModulePath,
+		"GITHELPER_OWNER="+data.Owner,
+		"GITHELPER_TEMPLATE="+t.Name,
+	)
+
+	for i, raw := range t.Manifest.PostCreate {
+		command, err := expand(fmt.Sprintf("post_create[%d]", i), raw, data)
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("post-create command %q of template %s failed: %w", command, t.Name, err)
+		}
+	}
+	return nil
+}
+
+// expand renders text as a template named name
+func expand(name, text string, data Data) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Registry holds the templates available by name
+type Registry struct {
+	templates map[string]*Template
+
+	// Warnings lists problems that didn't stop discovery, like an
+	// unreachable shared repository or an unreadable template
+	Warnings []string
+}
+
+// Get returns the named template
+func (r *Registry) Get(name string) (*Template, bool) {
+	t, ok := r.templates[name]
+	return t, ok
+}
+
+// List returns every template sorted by name
+func (r *Registry) List() []*Template {
+	list := make([]*Template, 0, len(r.templates))
+	for _, t := range r.templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Names returns the names of every template, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.templates))
+	for _, t := range r.List() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// addDir adds each top-level directory of root as a template
+func (r *Registry) addDir(root string, source Source) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		t, err := load(entry.Name(), source, dir, os.DirFS(dir))
+		if err != nil {
+			r.Warnings = append(r.Warnings, err.Error())
+			continue
+		}
+		r.templates[t.Name] = t
+	}
+	return nil
+}
+
+// addBuiltin adds the templates compiled into githelper
+func (r *Registry) addBuiltin() error {
+	entries, err := fs.ReadDir(builtinFS, "builtin")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		sub, err := fs.Sub(builtinFS, path.Join("builtin", entry.Name()))
+		if err != nil {
+			return err
+		}
+		t, err := load(entry.Name(), SourceBuiltin, "", sub)
+		if err != nil {
+			return err
+		}
+		r.templates[t.Name] = t
+	}
+	return nil
+}
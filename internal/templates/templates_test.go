@@ -0,0 +1,191 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles creates files (relative path → content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewData(t *testing.T) {
+	tests := []struct {
+		name, owner, modulePath string
+		want                    string
+	}{
+		{name: "tool", owner: "alice", want: "github.com/alice/tool"},
+		{name: "tool", want: "tool"},
+		{name: "tool", owner: "alice", modulePath: "example.com/tool", want: "example.com/tool"},
+	}
+
+	for _, tt := range tests {
+		data := NewData(tt.name, tt.owner, tt.modulePath)
+		if data.ModulePath != tt.want {
+			t.Errorf("NewData(%q, %q, %q).ModulePath = %q, want %q", tt.name, tt.owner, tt.modulePath, data.ModulePath, tt.want)
+		}
+		if data.Year != time.Now().Year() {
+			t.Errorf("Year = %d", data.Year)
+		}
+	}
+}
+
+func TestBuiltinGoTemplate(t *testing.T) {
+	registry, err := Discover(Config{Dir: t.TempDir()}, "")
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	tmpl, ok := registry.Get("go")
+	if !ok {
+		t.Fatalf("builtin go template missing, have %v", registry.Names())
+	}
+	if tmpl.Source != SourceBuiltin || tmpl.Manifest.Description == "" {
+		t.Errorf("go template = %+v", tmpl)
+	}
+
+	dir := t.TempDir()
+	files, err := tmpl.Render(dir, NewData("tool", "alice", ""))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Render() wrote %v, want go.mod and .gitignore", files)
+	}
+
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(goMod) != "module github.com/alice/tool\n\ngo 1.21\n" {
+		t.Errorf("go.mod = %q", goMod)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+		t.Errorf(".gitignore not written: %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		ManifestFile:                 "description: Test\n",
+		"README.md.tmpl":             "# {{.Name}}\n\nOwned by {{.Owner}}\n",
+		"LICENSE.tmpl":               "Copyright {{.Year}} {{.Owner}}\n",
+		"cmd/{{.Name}}/main.go.tmpl": "package main // {{.ModulePath}}\n",
+		"docs/raw.txt":               "{{ not rendered }}\n",
+		".git/config":                "ignored\n",
+		"scripts/bootstrap.sh":       "#!/bin/sh\n",
+	})
+	if err := os.Chmod(filepath.Join(src, "scripts", "bootstrap.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := load("test", SourceLocal, src, os.DirFS(src))
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	data := NewData("tool", "alice", "")
+	if _, err := tmpl.Render(dir, data); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := map[string]string{
+		"README.md":        "# tool\n\nOwned by alice\n",
+		"LICENSE":          fmt.Sprintf("Copyright %d alice\n", data.Year),
+		"cmd/tool/main.go": "package main // github.com/alice/tool\n",
+		"docs/raw.txt":     "{{ not rendered }}\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not written: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	for _, name := range []string{ManifestFile, ".git"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be copied", name)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "scripts", "bootstrap.sh"))
+	if err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("bootstrap.sh lost its executable bit: %v, %v", info, err)
+	}
+
+	// Existing files are never overwritten
+	if _, err := tmpl.Render(dir, data); err == nil {
+		t.Error("Render() overwrote existing files")
+	}
+}
+
+func TestRender_UnknownPlaceholder(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"README.md.tmpl": "{{.Licence}}\n"})
+
+	tmpl, err := load("test", SourceLocal, src, os.DirFS(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpl.Render(t.TempDir(), NewData("tool", "", "")); err == nil {
+		t.Error("Render() accepted an unknown placeholder")
+	}
+}
+
+func TestRunPostCreate(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		ManifestFile: `post_create:
+  - echo "{{.ModulePath}}" > module.txt
+  - echo "$GITHELPER_REPO_NAME from $GITHELPER_TEMPLATE"
+`,
+	})
+
+	tmpl, err := load("scripted", SourceLocal, src, os.DirFS(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	if err := tmpl.RunPostCreate(dir, NewData("tool", "alice", ""), &stdout, &stdout); err != nil {
+		t.Fatalf("RunPostCreate() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "module.txt"))
+	if err != nil || strings.TrimSpace(string(got)) != "github.com/alice/tool" {
+		t.Errorf("module.txt = %q, %v", got, err)
+	}
+	if strings.TrimSpace(stdout.String()) != "tool from scripted" {
+		t.Errorf("output = %q", stdout.String())
+	}
+
+	tmpl.Manifest.PostCreate = []string{"exit 3", "touch never"}
+	if err := tmpl.RunPostCreate(dir, NewData("tool", "", ""), &stdout, &stdout); err == nil {
+		t.Error("RunPostCreate() ignored a failing command")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never")); !os.IsNotExist(err) {
+		t.Error("RunPostCreate() kept going after a failure")
+	}
+}
@@ -0,0 +1,220 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/lcgerke/githelper/internal/constants"
+	"github.com/lcgerke/githelper/internal/errors"
+)
+
+// Auth methods
+const (
+	AuthToken      = "token"      // VAULT_TOKEN (default)
+	AuthTokenFile  = "token_file" // Token read from a file, e.g. a Vault Agent sink
+	AuthAppRole    = "approle"    // AppRole role ID and secret ID
+	AuthKubernetes = "kubernetes" // Kubernetes service account JWT
+)
+
+// DefaultKubernetesJWTFile is where Kubernetes mounts the service account token
+const DefaultKubernetesJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// AuthConfig selects how the client obtains its token. Every field can be
+// overridden from the environment (see AuthConfigFromEnv); secret IDs are
+// only ever read from the environment or a file, never from config.
+type AuthConfig struct {
+	Method string `yaml:"method"` // token (default), token_file, approle, or kubernetes
+	Mount  string `yaml:"mount"`  // Auth mount path, defaults to the method name
+
+	TokenFile string `yaml:"token_file"` // token_file: path of the token
+
+	RoleID       string `yaml:"role_id"`        // approle
+	RoleIDFile   string `yaml:"role_id_file"`   // approle: read the role ID from a file instead
+	SecretID     string `yaml:"-"`              // approle: from VAULT_SECRET_ID only
+	SecretIDFile string `yaml:"secret_id_file"` // approle
+
+	Role    string `yaml:"role"`     // kubernetes: Vault role to log in as
+	JWTFile string `yaml:"jwt_file"` // kubernetes: defaults to DefaultKubernetesJWTFile
+}
+
+// AuthConfigFromEnv returns base with any VAULT_AUTH_METHOD, VAULT_AUTH_MOUNT,
+// VAULT_TOKEN_FILE, VAULT_ROLE_ID, VAULT_ROLE_ID_FILE, VAULT_SECRET_ID,
+// VAULT_SECRET_ID_FILE, VAULT_K8S_ROLE, and VAULT_K8S_JWT_FILE settings applied
+func AuthConfigFromEnv(base AuthConfig) AuthConfig {
+	set := func(field *string, name string) {
+		if v := os.Getenv(name); v != "" {
+			*field = v
+		}
+	}
+
+	cfg := base
+	set(&cfg.Method, "VAULT_AUTH_METHOD")
+	set(&cfg.Mount, "VAULT_AUTH_MOUNT")
+	set(&cfg.TokenFile, "VAULT_TOKEN_FILE")
+	set(&cfg.RoleID, "VAULT_ROLE_ID")
+	set(&cfg.RoleIDFile, "VAULT_ROLE_ID_FILE")
+	set(&cfg.SecretID, "VAULT_SECRET_ID")
+	set(&cfg.SecretIDFile, "VAULT_SECRET_ID_FILE")
+	set(&cfg.Role, "VAULT_K8S_ROLE")
+	set(&cfg.JWTFile, "VAULT_K8S_JWT_FILE")
+	return cfg
+}
+
+// Validate checks that the settings the method needs are present
+func (a AuthConfig) Validate() error {
+	switch a.Method {
+	case "", AuthToken:
+	case AuthTokenFile:
+		if a.TokenFile == "" {
+			return errors.InvalidConfiguration("vault token_file", "required for token_file auth (or set VAULT_TOKEN_FILE)")
+		}
+	case AuthAppRole:
+		if a.RoleID == "" && a.RoleIDFile == "" {
+			return errors.InvalidConfiguration("vault role_id", "required for approle auth (or set VAULT_ROLE_ID)")
+		}
+		if a.SecretID == "" && a.SecretIDFile == "" {
+			return errors.InvalidConfiguration("vault secret_id", "required for approle auth (set VAULT_SECRET_ID or secret_id_file)")
+		}
+	case AuthKubernetes:
+		if a.Role == "" {
+			return errors.InvalidConfiguration("vault role", "required for kubernetes auth (or set VAULT_K8S_ROLE)")
+		}
+	default:
+		return errors.InvalidConfiguration("vault auth method",
+			fmt.Sprintf("unknown method %q (want %s, %s, %s, or %s)", a.Method, AuthToken, AuthTokenFile, AuthAppRole, AuthKubernetes))
+	}
+	return nil
+}
+
+// mount returns the auth mount path for the method
+func (a AuthConfig) mount() string {
+	if a.Mount != "" {
+		return strings.Trim(a.Mount, "/")
+	}
+	return a.Method
+}
+
+// login obtains a token and sets it on client. The returned secret describes
+// the token's lease for renewal; it is nil if the token can't be renewed.
+func (a AuthConfig) login(ctx context.Context, client *vault.Client) (*vault.Secret, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultOperationTimeout)
+	defer cancel()
+
+	switch a.Method {
+	case "", AuthToken:
+		if client.Token() == "" {
+			return nil, errors.WithHint(
+				errors.New(errors.ErrorTypeVault, "no Vault token configured"),
+				"Set VAULT_TOKEN, or configure another auth method with VAULT_AUTH_METHOD",
+			)
+		}
+		return lookupSelf(ctx, client), nil
+
+	case AuthTokenFile:
+		token, err := readCredentialFile(a.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		client.SetToken(token)
+		return lookupSelf(ctx, client), nil
+
+	case AuthAppRole:
+		roleID, err := valueOrFile(a.RoleID, a.RoleIDFile)
+		if err != nil {
+			return nil, err
+		}
+		secretID, err := valueOrFile(a.SecretID, a.SecretIDFile)
+		if err != nil {
+			return nil, err
+		}
+		return a.write(ctx, client, map[string]interface{}{
+			"role_id":   roleID,
+			"secret_id": secretID,
+		})
+
+	case AuthKubernetes:
+		jwtFile := a.JWTFile
+		if jwtFile == "" {
+			jwtFile = DefaultKubernetesJWTFile
+		}
+		jwt, err := readCredentialFile(jwtFile)
+		if err != nil {
+			return nil, err
+		}
+		return a.write(ctx, client, map[string]interface{}{
+			"role": a.Role,
+			"jwt":  jwt,
+		})
+	}
+
+	return nil, a.Validate()
+}
+
+// write performs a login against the method's mount and adopts the token
+func (a AuthConfig) write(ctx context.Context, client *vault.Client, data map[string]interface{}) (*vault.Secret, error) {
+	path := fmt.Sprintf("auth/%s/login", a.mount())
+
+	// Login must not send whatever token the environment holds
+	login, err := client.Clone()
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeVault, "failed to prepare Vault login", err)
+	}
+	login.ClearToken()
+
+	secret, err := login.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("%s login failed", a.Method), err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, errors.New(errors.ErrorTypeVault, fmt.Sprintf("%s login returned no token", a.Method))
+	}
+
+	client.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
+
+// lookupSelf describes the client's current token as a renewable lease, or
+// returns nil if it can't be looked up or doesn't expire
+func lookupSelf(ctx context.Context, client *vault.Client) *vault.Secret {
+	self, err := client.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil || self == nil {
+		return nil
+	}
+
+	renewable, _ := self.TokenIsRenewable()
+	ttl, _ := self.TokenTTL()
+	if !renewable || ttl <= 0 {
+		return nil
+	}
+
+	return &vault.Secret{
+		Auth: &vault.SecretAuth{
+			ClientToken:   client.Token(),
+			Renewable:     true,
+			LeaseDuration: int(ttl.Seconds()),
+		},
+	}
+}
+
+// valueOrFile returns value, or the trimmed contents of file if value is empty
+func valueOrFile(value, file string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	return readCredentialFile(file)
+}
+
+func readCredentialFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to read %s", path), err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", errors.New(errors.ErrorTypeVault, fmt.Sprintf("%s is empty", path))
+	}
+	return value, nil
+}
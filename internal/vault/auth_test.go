@@ -0,0 +1,354 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault is a minimal Vault server: AppRole and Kubernetes logins, token
+// lookup and renewal, and a KV v2 secret at secret/data/githelper/config
+type fakeVault struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	tokens    map[string]bool // issued tokens
+	logins    map[string]int  // logins per mount
+	renewals  int
+	loginTTL  int
+	renewTTL  int
+	renewable bool
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	return &fakeVault{
+		t:         t,
+		tokens:    map[string]bool{"root-token": true},
+		logins:    make(map[string]int),
+		loginTTL:  3600,
+		renewTTL:  3600,
+		renewable: true,
+	}
+}
+
+// start serves the fake and points the Vault client at it
+func (f *fakeVault) start() {
+	srv := httptest.NewServer(f)
+	f.t.Cleanup(srv.Close)
+	f.t.Setenv("VAULT_ADDR", srv.URL)
+	f.t.Setenv("VAULT_TOKEN", "")
+	for _, name := range []string{"VAULT_AUTH_METHOD", "VAULT_AUTH_MOUNT", "VAULT_TOKEN_FILE", "VAULT_ROLE_ID",
+		"VAULT_ROLE_ID_FILE", "VAULT_SECRET_ID", "VAULT_SECRET_ID_FILE", "VAULT_K8S_ROLE", "VAULT_K8S_JWT_FILE", "VAULT_KV_MOUNT", "VAULT_KV_VERSION"} {
+		f.t.Setenv(name, "")
+	}
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token := r.Header.Get("X-Vault-Token")
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	switch {
+	case strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login"):
+		mount := strings.TrimSuffix(strings.TrimPrefix(path, "auth/"), "/login")
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		ok := false
+		switch mount {
+		case "approle":
+			ok = body["role_id"] == "role-1" && body["secret_id"] == "secret-1"
+		case "kubernetes", "k8s-cluster":
+			ok = body["role"] == "githelper" && body["jwt"] == "service-account-jwt"
+		}
+		if !ok || token != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":["invalid credentials"]}`)
+			return
+		}
+
+		f.logins[mount]++
+		issued := fmt.Sprintf("%s-token-%d", mount, f.logins[mount])
+		f.tokens[issued] = true
+		f.writeAuth(w, issued, f.loginTTL, f.renewable)
+
+	case path == "auth/token/lookup-self":
+		if !f.tokens[token] {
+			f.deny(w)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": token, "ttl": f.loginTTL, "renewable": f.renewable},
+		})
+
+	case path == "auth/token/renew-self":
+		if !f.tokens[token] {
+			f.deny(w)
+			return
+		}
+		f.renewals++
+		f.writeAuth(w, token, f.renewTTL, f.renewable)
+
+	case path == "secret/data/githelper/config":
+		if !f.tokens[token] {
+			f.deny(w)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"github_username": "alice"},
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeVault) writeAuth(w http.ResponseWriter, token string, ttl int, renewable bool) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   token,
+			"lease_duration": ttl,
+			"renewable":      renewable,
+		},
+	})
+}
+
+func (f *fakeVault) deny(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	fmt.Fprint(w, `{"errors":["permission denied"]}`)
+}
+
+func (f *fakeVault) counts(mount string) (logins, renewals int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins[mount], f.renewals
+}
+
+// eventually polls cond until it holds or the deadline passes
+func eventually(t *testing.T, timeout time.Duration, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func readConfig(t *testing.T, c *Client) {
+	t.Helper()
+	data, err := c.GetSecret("githelper/config")
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if data["github_username"] != "alice" {
+		t.Errorf("GetSecret() = %v", data)
+	}
+}
+
+func TestAppRoleLogin(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{
+		Method:       AuthAppRole,
+		RoleID:       "role-1",
+		SecretIDFile: writeFile(t, "secret-id", "secret-1\n"),
+	}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	defer c.Close()
+
+	if logins, _ := fake.counts("approle"); logins != 0 {
+		t.Errorf("logged in before the first request (%d logins)", logins)
+	}
+
+	readConfig(t, c)
+	readConfig(t, c)
+
+	if logins, _ := fake.counts("approle"); logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+}
+
+func TestAppRoleLogin_FromEnv(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+	t.Setenv("VAULT_AUTH_METHOD", AuthAppRole)
+	t.Setenv("VAULT_ROLE_ID", "role-1")
+	t.Setenv("VAULT_SECRET_ID", "secret-1")
+
+	c, err := NewClient(context.Background())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	readConfig(t, c)
+}
+
+func TestAppRoleLogin_BadCredentials(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{Method: AuthAppRole, RoleID: "role-1", SecretID: "wrong"}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.GetSecret("githelper/config"); err == nil || !strings.Contains(err.Error(), "approle login failed") {
+		t.Errorf("GetSecret() error = %v, want approle login failure", err)
+	}
+}
+
+func TestKubernetesLogin(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{
+		Method:  AuthKubernetes,
+		Mount:   "/k8s-cluster/",
+		Role:    "githelper",
+		JWTFile: writeFile(t, "token", "service-account-jwt"),
+	}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	defer c.Close()
+
+	readConfig(t, c)
+
+	if logins, _ := fake.counts("k8s-cluster"); logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+}
+
+func TestTokenFile(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{
+		Method:    AuthTokenFile,
+		TokenFile: writeFile(t, "vault-token", "root-token\n"),
+	}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	defer c.Close()
+
+	readConfig(t, c)
+}
+
+func TestToken_Missing(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.start()
+
+	c, err := NewClient(context.Background())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.GetSecret("githelper/config"); err == nil || !strings.Contains(err.Error(), "no Vault token") {
+		t.Errorf("GetSecret() error = %v, want missing token error", err)
+	}
+}
+
+func TestRenewal(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.loginTTL = 3
+	fake.renewTTL = 3
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{Method: AuthAppRole, RoleID: "role-1", SecretID: "secret-1"}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+
+	readConfig(t, c)
+
+	eventually(t, 5*time.Second, func() bool {
+		_, renewals := fake.counts("approle")
+		return renewals >= 2
+	}, "token was not renewed in the background")
+
+	c.Close()
+	_, stopped := fake.counts("approle")
+	time.Sleep(2500 * time.Millisecond)
+	if _, renewals := fake.counts("approle"); renewals > stopped+1 {
+		t.Errorf("renewals continued after Close: %d -> %d", stopped, renewals)
+	}
+}
+
+func TestRenewal_ReloginAtMaxTTL(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.loginTTL = 3
+	fake.renewTTL = 1 // Renewal no longer extends the lease
+	fake.start()
+
+	c, err := NewClientWithOptions(context.Background(), Options{Auth: AuthConfig{Method: AuthAppRole, RoleID: "role-1", SecretID: "secret-1"}})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	defer c.Close()
+
+	readConfig(t, c)
+
+	eventually(t, 5*time.Second, func() bool {
+		logins, _ := fake.counts("approle")
+		return logins >= 2
+	}, "client did not log in again when the token stopped extending")
+
+	readConfig(t, c)
+}
+
+func TestAuthConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr bool
+	}{
+		{name: "default token", auth: AuthConfig{}},
+		{name: "token file", auth: AuthConfig{Method: AuthTokenFile, TokenFile: "/run/vault-token"}},
+		{name: "token file missing path", auth: AuthConfig{Method: AuthTokenFile}, wantErr: true},
+		{name: "approle", auth: AuthConfig{Method: AuthAppRole, RoleID: "r", SecretIDFile: "/run/secret-id"}},
+		{name: "approle missing secret id", auth: AuthConfig{Method: AuthAppRole, RoleID: "r"}, wantErr: true},
+		{name: "approle missing role id", auth: AuthConfig{Method: AuthAppRole, SecretID: "s"}, wantErr: true},
+		{name: "kubernetes", auth: AuthConfig{Method: AuthKubernetes, Role: "githelper"}},
+		{name: "kubernetes missing role", auth: AuthConfig{Method: AuthKubernetes}, wantErr: true},
+		{name: "unknown", auth: AuthConfig{Method: "ldap"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.auth.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,25 +2,72 @@ package vault
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/lcgerke/githelper/internal/constants"
 	"github.com/lcgerke/githelper/internal/errors"
 	vault "github.com/hashicorp/vault/api"
 )
 
+// ErrNoData is wrapped by GetSecret when a secret exists but holds no data
+var ErrNoData = stderrors.New("secret has no data")
+
+// Default KV engine settings
+const (
+	DefaultMount     = "secret"
+	DefaultKVVersion = 2
+)
+
+// Options configures the client beyond what VAULT_ADDR provides
+type Options struct {
+	Auth      AuthConfig `yaml:"auth"`
+	Mount     string     `yaml:"mount"`      // KV engine mount, defaults to "secret" (or VAULT_KV_MOUNT)
+	KVVersion int        `yaml:"kv_version"` // 1 or 2, defaults to 2 (or VAULT_KV_VERSION)
+}
+
 // Client wraps the Vault API client
 type Client struct {
-	client *vault.Client
-	ctx    context.Context
+	client    *vault.Client
+	ctx       context.Context
+	auth      AuthConfig
+	mount     string
+	kvVersion int
+
+	mu            sync.Mutex
+	authenticated bool
+	stopRenewal   context.CancelFunc
 }
 
 // NewClient creates a new Vault client
 // It uses environment variables for configuration:
 // - VAULT_ADDR: Vault server address
 // - VAULT_TOKEN: Authentication token
+// - VAULT_AUTH_METHOD and friends: see AuthConfigFromEnv
 func NewClient(ctx context.Context) (*Client, error) {
+	return NewClientWithOptions(ctx, Options{})
+}
+
+// NewClientWithOptions creates a Vault client that logs in with options.Auth,
+// after applying any overrides from the environment. Login happens on the
+// first request, so creating a client never needs Vault to be reachable.
+// Renewable tokens are then renewed in the background until ctx is done or
+// Close is called, and logins are repeated when a token reaches its maximum TTL.
+func NewClientWithOptions(ctx context.Context, options Options) (*Client, error) {
+	auth := AuthConfigFromEnv(options.Auth)
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+
+	mount, kvVersion, err := kvFromEnv(options.Mount, options.KVVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	config := vault.DefaultConfig()
 	if config == nil {
 		return nil, errors.New(errors.ErrorTypeVault, "failed to create default Vault configuration")
@@ -36,159 +83,194 @@ func NewClient(ctx context.Context) (*Client, error) {
 	}
 
 	return &Client{
-		client: client,
-		ctx:    ctx,
+		client:    client,
+		ctx:       ctx,
+		auth:      auth,
+		mount:     mount,
+		kvVersion: kvVersion,
 	}, nil
 }
 
-// GetSecret retrieves a secret from Vault
-func (c *Client) GetSecret(path string) (map[string]interface{}, error) {
-	secret, err := c.client.KVv2("secret").Get(c.ctx, path)
-	if err != nil {
-		return nil, errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to read secret at %s", path), err)
+// kvFromEnv applies VAULT_KV_MOUNT and VAULT_KV_VERSION and fills in defaults
+func kvFromEnv(mount string, version int) (string, int, error) {
+	if v := os.Getenv("VAULT_KV_MOUNT"); v != "" {
+		mount = v
+	}
+	if v := os.Getenv("VAULT_KV_VERSION"); v != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "v"))
+		if err != nil {
+			return "", 0, errors.InvalidConfiguration("VAULT_KV_VERSION", fmt.Sprintf("%q is not a number", v))
+		}
+		version = n
 	}
 
-	if secret == nil || secret.Data == nil {
-		return nil, errors.WithHint(
-			errors.New(errors.ErrorTypeVault, fmt.Sprintf("no data found at secret path: %s", path)),
-			"Check that the secret exists in Vault and you have permission to read it",
-		)
+	mount = strings.Trim(mount, "/")
+	if mount == "" {
+		mount = DefaultMount
+	}
+	switch version {
+	case 0:
+		version = DefaultKVVersion
+	case 1, 2:
+	default:
+		return "", 0, errors.InvalidConfiguration("vault kv_version", fmt.Sprintf("%d is not 1 or 2", version))
 	}
+	return mount, version, nil
+}
 
-	return secret.Data, nil
+// Mount returns the KV engine mount and version in use
+func (c *Client) Mount() (string, int) {
+	return c.mount, c.kvVersion
 }
 
-// PutSecret stores a secret in Vault
-func (c *Client) PutSecret(path string, data map[string]interface{}) error {
-	_, err := c.client.KVv2("secret").Put(c.ctx, path, data)
-	if err != nil {
-		return errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to write secret at %s", path), err)
-	}
+// Close stops background token renewal
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return nil
+	if c.stopRenewal != nil {
+		c.stopRenewal()
+		c.stopRenewal = nil
+	}
 }
 
-// IsReachable checks if Vault server is reachable
-func (c *Client) IsReachable() bool {
-	ctx, cancel := context.WithTimeout(c.ctx, constants.BranchOperationTimeout)
-	defer cancel()
+// authenticate logs in once and starts renewal of the resulting token
+func (c *Client) authenticate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	_, err := c.client.Sys().HealthWithContext(ctx)
-	return err == nil
-}
+	if c.authenticated {
+		return nil
+	}
 
-// GetConfig retrieves githelper configuration from Vault
-func (c *Client) GetConfig() (*Config, error) {
-	data, err := c.GetSecret("githelper/config")
+	lease, err := c.auth.login(c.ctx, c.client)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	c.authenticated = true
 
-	cfg := &Config{}
-
-	if v, ok := data["github_username"].(string); ok {
-		cfg.GitHubUsername = v
-	}
-	if v, ok := data["bare_repo_pattern"].(string); ok {
-		cfg.BareRepoPattern = v
+	if lease != nil {
+		ctx, cancel := context.WithCancel(c.ctx)
+		c.stopRenewal = cancel
+		go c.renew(ctx, lease)
 	}
-	if v, ok := data["default_visibility"].(string); ok {
-		cfg.DefaultVisibility = v
-	}
-	if v, ok := data["auto_create_github"].(bool); ok {
-		cfg.AutoCreateGitHub = v
-	}
-	if v, ok := data["test_before_push"].(bool); ok {
-		cfg.TestBeforePush = v
-	}
-	if v, ok := data["sync_on_setup"].(bool); ok {
-		cfg.SyncOnSetup = v
-	}
-	if v, ok := data["retry_on_partial_failure"].(bool); ok {
-		cfg.RetryOnPartialFailure = v
-	}
-
-	return cfg, nil
+	return nil
 }
 
-// GetSSHKey retrieves SSH key from Vault
-// Tries repo-specific path first, falls back to default
-func (c *Client) GetSSHKey(repoName string) (*SSHKey, error) {
-	var data map[string]interface{}
-	var err error
+// renew keeps the token alive. When the watcher gives up, because the token
+// hit its maximum TTL or renewal kept failing, it logs in again; if that
+// fails the next request retries the login.
+func (c *Client) renew(ctx context.Context, lease *vault.Secret) {
+	for {
+		watcher, err := c.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: lease})
+		if err != nil {
+			c.expire()
+			return
+		}
+		go watcher.Start()
+
+		select {
+		case <-ctx.Done():
+			watcher.Stop()
+			return
+		case <-watcher.DoneCh():
+			watcher.Stop()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
 
-	// Try repo-specific key first
-	if repoName != "" {
-		path := fmt.Sprintf("githelper/github/%s/ssh", repoName)
-		data, err = c.GetSecret(path)
-		if err == nil {
-			return parseSSHKey(data)
+		lease, err = c.auth.login(ctx, c.client)
+		if err != nil || lease == nil {
+			c.expire()
+			return
 		}
 	}
+}
+
+// expire forces the next request to log in again
+func (c *Client) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authenticated = false
+	if c.stopRenewal != nil {
+		c.stopRenewal()
+		c.stopRenewal = nil
+	}
+}
 
-	// Fall back to default key
-	data, err = c.GetSecret("githelper/github/default_ssh")
+// GetSecret retrieves a secret from Vault
+func (c *Client) GetSecret(path string) (map[string]interface{}, error) {
+	if err := c.authenticate(); err != nil {
+		return nil, err
+	}
+
+	var secret *vault.KVSecret
+	var err error
+	if c.kvVersion == 1 {
+		secret, err = c.client.KVv1(c.mount).Get(c.ctx, path)
+	} else {
+		secret, err = c.client.KVv2(c.mount).Get(c.ctx, path)
+	}
 	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to read secret at %s/%s", c.mount, path), err)
+	}
+
+	if secret == nil || secret.Data == nil {
 		return nil, errors.WithHint(
-			errors.Wrap(errors.ErrorTypeVault, "no SSH key found in Vault", err),
-			"Add an SSH key to Vault at secret/githelper/github/default_ssh with fields 'private_key' and 'public_key'",
+			errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("no data found at secret path: %s/%s", c.mount, path), ErrNoData),
+			"Check that the secret exists in Vault and you have permission to read it",
 		)
 	}
 
-	return parseSSHKey(data)
+	return secret.Data, nil
 }
 
-// GetPAT retrieves GitHub Personal Access Token from Vault
-// Tries repo-specific path first, falls back to default
-func (c *Client) GetPAT(repoName string) (string, error) {
-	var data map[string]interface{}
-	var err error
-
-	// Try repo-specific PAT first
-	if repoName != "" {
-		path := fmt.Sprintf("githelper/github/%s/pat", repoName)
-		data, err = c.GetSecret(path)
-		if err == nil {
-			if token, ok := data["token"].(string); ok {
-				return token, nil
-			}
-		}
+// PutSecret stores a secret in Vault
+func (c *Client) PutSecret(path string, data map[string]interface{}) error {
+	if err := c.authenticate(); err != nil {
+		return err
 	}
 
-	// Fall back to default PAT
-	data, err = c.GetSecret("githelper/github/default_pat")
-	if err != nil {
-		return "", errors.WithHint(
-			errors.Wrap(errors.ErrorTypeVault, "no GitHub PAT found in Vault", err),
-			"Add a GitHub Personal Access Token to Vault at secret/githelper/github/default_pat with field 'token'",
-		)
+	var err error
+	if c.kvVersion == 1 {
+		err = c.client.KVv1(c.mount).Put(c.ctx, path, data)
+	} else {
+		_, err = c.client.KVv2(c.mount).Put(c.ctx, path, data)
 	}
-
-	if token, ok := data["token"].(string); ok {
-		return token, nil
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to write secret at %s/%s", c.mount, path), err)
 	}
 
-	return "", errors.WithHint(
-		errors.New(errors.ErrorTypeVault, "PAT secret missing required 'token' field"),
-		"Ensure the Vault secret has a 'token' field with your GitHub Personal Access Token",
-	)
+	return nil
 }
 
-func parseSSHKey(data map[string]interface{}) (*SSHKey, error) {
-	key := &SSHKey{}
-
-	privateKey, ok := data["private_key"].(string)
-	if !ok {
-		return nil, errors.WithHint(
-			errors.New(errors.ErrorTypeVault, "SSH key secret missing required 'private_key' field"),
-			"Ensure the Vault secret has a 'private_key' field with your SSH private key",
-		)
+// DeleteSecret removes a secret from Vault. On KV v2 only the latest version
+// is deleted, so it can still be recovered with "vault kv undelete".
+func (c *Client) DeleteSecret(path string) error {
+	if err := c.authenticate(); err != nil {
+		return err
 	}
-	key.PrivateKey = privateKey
 
-	if publicKey, ok := data["public_key"].(string); ok {
-		key.PublicKey = publicKey
+	var err error
+	if c.kvVersion == 1 {
+		err = c.client.KVv1(c.mount).Delete(c.ctx, path)
+	} else {
+		err = c.client.KVv2(c.mount).Delete(c.ctx, path)
 	}
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeVault, fmt.Sprintf("failed to delete secret at %s/%s", c.mount, path), err)
+	}
+
+	return nil
+}
+
+// IsReachable checks if Vault server is reachable
+func (c *Client) IsReachable() bool {
+	ctx, cancel := context.WithTimeout(c.ctx, constants.BranchOperationTimeout)
+	defer cancel()
 
-	return key, nil
+	_, err := c.client.Sys().HealthWithContext(ctx)
+	return err == nil
 }
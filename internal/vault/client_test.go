@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// fakeKV serves a KV engine at mount, in either version's URL and body shape
+func fakeKV(t *testing.T, mount string, version int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	kv := make(map[string]map[string]interface{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/v1/" + mount + "/"
+		if version == 2 {
+			prefix += "data/"
+		}
+		path, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := kv[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			body := map[string]interface{}{"data": data}
+			if version == 2 {
+				body = map[string]interface{}{"data": map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": 1}}}
+			}
+			_ = json.NewEncoder(w).Encode(body)
+		case http.MethodPut, http.MethodPost:
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if version == 2 {
+				body, _ = body["data"].(map[string]interface{})
+			}
+			kv[path] = body
+			if version == 2 {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"version": 1}})
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+	t.Setenv("VAULT_KV_MOUNT", "")
+	t.Setenv("VAULT_KV_VERSION", "")
+}
+
+func TestClient_KVMount(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		env     map[string]string
+		mount   string
+		version int
+	}{
+		{name: "v2 custom mount", options: Options{Mount: "/team-kv/", KVVersion: 2}, mount: "team-kv", version: 2},
+		{name: "v1 custom mount", options: Options{Mount: "team-kv", KVVersion: 1}, mount: "team-kv", version: 1},
+		{name: "env overrides", options: Options{}, env: map[string]string{"VAULT_KV_MOUNT": "ops", "VAULT_KV_VERSION": "v1"}, mount: "ops", version: 1},
+		{name: "defaults", options: Options{}, mount: DefaultMount, version: DefaultKVVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKV(t, tt.mount, tt.version)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			c, err := NewClientWithOptions(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("NewClientWithOptions() error = %v", err)
+			}
+			defer c.Close()
+
+			if mount, version := c.Mount(); mount != tt.mount || version != tt.version {
+				t.Errorf("Mount() = %s, %d, want %s, %d", mount, version, tt.mount, tt.version)
+			}
+
+			if err := c.PutSecret("acme/githelper/pat", map[string]interface{}{"token": "abc"}); err != nil {
+				t.Fatalf("PutSecret() error = %v", err)
+			}
+			data, err := c.GetSecret("acme/githelper/pat")
+			if err != nil {
+				t.Fatalf("GetSecret() error = %v", err)
+			}
+			if data["token"] != "abc" {
+				t.Errorf("GetSecret() = %v", data)
+			}
+
+			if _, err := c.GetSecret("acme/missing"); !stderrors.Is(err, vault.ErrSecretNotFound) {
+				t.Errorf("GetSecret() missing error = %v, want ErrSecretNotFound", err)
+			}
+		})
+	}
+}
+
+func TestClient_InvalidKVVersion(t *testing.T) {
+	t.Setenv("VAULT_KV_VERSION", "")
+	if _, err := NewClientWithOptions(context.Background(), Options{KVVersion: 3}); err == nil {
+		t.Error("NewClientWithOptions() error = nil, want invalid kv_version")
+	}
+
+	t.Setenv("VAULT_KV_VERSION", "two")
+	if _, err := NewClientWithOptions(context.Background(), Options{}); err == nil {
+		t.Error("NewClientWithOptions() error = nil, want invalid VAULT_KV_VERSION")
+	}
+}
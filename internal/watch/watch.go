@@ -0,0 +1,373 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/git"
+	"github.com/lcgerke/githelper/internal/history"
+	"github.com/lcgerke/githelper/internal/scenarios"
+	"github.com/lcgerke/githelper/internal/state"
+)
+
+// Options configures the watch loop
+type Options struct {
+	// Interval is the time between sync cycles
+	Interval time.Duration
+
+	// MaxBackoff caps how long a failing repository is skipped
+	MaxBackoff time.Duration
+
+	// Detection configures the classifier run for each repository
+	Detection scenarios.DetectionOptions
+}
+
+// DefaultOptions returns sensible defaults for a background watcher
+func DefaultOptions() Options {
+	detection := scenarios.DefaultDetectionOptions()
+	detection.SkipCorruption = true // Expensive and irrelevant to mirror convergence
+	detection.SkipBranches = true   // Branches are covered by the sync plan
+	detection.SkipTags = true       // Tags are covered by the sync plan
+
+	return Options{
+		Interval:   5 * time.Minute,
+		MaxBackoff: time.Hour,
+		Detection:  detection,
+	}
+}
+
+// Repository result statuses
+const (
+	ResultSynced   = "synced"   // Every mirror matches Core
+	ResultPushed   = "pushed"   // Safe updates were pushed this cycle
+	ResultDiverged = "diverged" // Some refs need manual resolution
+	ResultFailed   = "failed"   // Detection, fetch, or push failed
+	ResultSkipped  = "skipped"  // Not checked this cycle (backoff or no local clone)
+)
+
+// MirrorResult is the outcome of syncing Core to one mirror
+type MirrorResult struct {
+	Remote  string                    `json:"remote"`
+	Summary scenarios.SyncPlanSummary `json:"summary"`
+	Error   string                    `json:"error,omitempty"`
+}
+
+// RepoResult is the outcome of one watch cycle for one repository
+type RepoResult struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"`
+	ScenarioID string         `json:"scenario_id,omitempty"` // Sync scenario (S1-S13) from the classifier
+	Mirrors    []MirrorResult `json:"mirrors,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	NextCheck  time.Time      `json:"next_check,omitempty"` // Set when the repository is backing off
+}
+
+// Watcher keeps every repository's mirrors converged with its Core remote.
+// Only refs that fast-forward (or do not exist yet) on a mirror are pushed;
+// nothing is ever force-pushed.
+type Watcher struct {
+	stateMgr *state.Manager
+	options  Options
+
+	mu       sync.Mutex
+	failures map[string]int       // Consecutive failures per repository
+	nextTry  map[string]time.Time // Earliest next attempt for failing repositories
+	now      func() time.Time
+}
+
+// NewWatcher creates a watcher over the repositories in stateMgr
+func NewWatcher(stateMgr *state.Manager, options Options) *Watcher {
+	return &Watcher{
+		stateMgr: stateMgr,
+		options:  options,
+		failures: make(map[string]int),
+		nextTry:  make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Run executes sync cycles every Interval until ctx is cancelled.
+// report is called with the results of each cycle. A cycle in progress
+// finishes its current repository before Run returns.
+func (w *Watcher) Run(ctx context.Context, report func([]RepoResult)) error {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := w.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(results)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs a single sync cycle over every repository in state.
+// Repositories are processed in name order; cancellation stops the cycle
+// between repositories.
+func (w *Watcher) RunOnce(ctx context.Context) ([]RepoResult, error) {
+	repos, err := w.stateMgr.ListRepositories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []RepoResult
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if next, backingOff := w.backoffUntil(name); backingOff {
+			results = append(results, RepoResult{
+				Name:      name,
+				Status:    ResultSkipped,
+				Error:     "backing off after failure",
+				NextCheck: next,
+			})
+			continue
+		}
+
+		result := w.syncRepository(name, repos[name])
+		if result.Status == ResultFailed {
+			result.NextCheck = w.recordFailure(name)
+		} else {
+			w.recordSuccess(name)
+		}
+
+		if result.Status != ResultSkipped {
+			if err := w.saveResult(name, result); err != nil {
+				result.Error = fmt.Sprintf("failed to update state: %v", err)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// syncRepository classifies one repository and pushes safe updates from Core to every mirror
+func (w *Watcher) syncRepository(name string, repo *state.Repository) RepoResult {
+	result := RepoResult{Name: name}
+
+	mirrors := repo.MirrorSet()
+	if len(mirrors) < 2 {
+		result.Status = ResultSkipped
+		result.Error = "no mirrors configured"
+		return result
+	}
+
+	gitClient := git.NewClient(repo.Path)
+	if !gitClient.IsRepository() {
+		result.Status = ResultSkipped
+		result.Error = fmt.Sprintf("no local clone at %s", repo.Path)
+		return result
+	}
+
+	remotes, cleanup, err := fetchRemotes(gitClient, mirrors)
+	if err != nil {
+		result.Status = ResultFailed
+		result.Error = err.Error()
+		return result
+	}
+	defer cleanup()
+
+	// Classifier fetches every mirror and reports the default-branch scenario
+	repoState, err := scenarios.NewMirrorClassifier(gitClient, remotes, w.options.Detection).Detect()
+	if err != nil {
+		result.Status = ResultFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.ScenarioID = repoState.Sync.ID
+	_ = w.stateMgr.RecordSnapshot(name, history.NewSnapshot(repoState))
+
+	if !repoState.Existence.CoreReachable {
+		result.Status = ResultFailed
+		result.Error = fmt.Sprintf("core remote %s is not reachable", remotes[0])
+		return result
+	}
+
+	pushed, manual, failed := 0, 0, 0
+	for i, mirror := range remotes[1:] {
+		mr := MirrorResult{Remote: mirrors[i+1].Name}
+
+		plan, err := scenarios.BuildSyncPlan(gitClient, remotes[0], mirror, nil)
+		if err != nil {
+			mr.Error = err.Error()
+			failed++
+			result.Mirrors = append(result.Mirrors, mr)
+			continue
+		}
+
+		if len(plan.Pending()) > 0 {
+			if err := scenarios.ApplySyncPlan(gitClient, plan); err != nil {
+				mr.Error = err.Error()
+				failed++
+			}
+		}
+
+		mr.Summary = plan.Summary()
+		pushed += mr.Summary.Pushed
+		manual += mr.Summary.Manual
+		result.Mirrors = append(result.Mirrors, mr)
+	}
+
+	switch {
+	case failed > 0:
+		result.Status = ResultFailed
+		result.Error = fmt.Sprintf("%d mirror(s) failed to sync", failed)
+	case manual > 0:
+		result.Status = ResultDiverged
+		result.Error = fmt.Sprintf("%d ref(s) need manual resolution", manual)
+	case pushed > 0:
+		result.Status = ResultPushed
+	default:
+		result.Status = ResultSynced
+	}
+
+	return result
+}
+
+// tempRemotePrefix names the fetch remotes added for mirrors that have none
+const tempRemotePrefix = "githelper-watch-"
+
+// fetchRemotes returns the git remote to fetch each mirror from. Mirrors
+// without a remote of their own (e.g. GitHub in a clone set up before mirrors
+// got fetch remotes) get a temporary one pointing at their URL, which the
+// returned cleanup removes again.
+func fetchRemotes(gitClient *git.Client, mirrors []state.Mirror) ([]string, func(), error) {
+	configured, err := gitClient.ListRemotes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	exists := make(map[string]bool, len(configured))
+	for _, r := range configured {
+		exists[r] = true
+	}
+
+	var temporary []string
+	cleanup := func() {
+		for _, r := range temporary {
+			_ = gitClient.RemoveRemote(r)
+		}
+	}
+
+	remotes := make([]string, len(mirrors))
+	for i, m := range mirrors {
+		if exists[m.Name] || i == 0 {
+			remotes[i] = m.Name
+			continue
+		}
+
+		temp := tempRemotePrefix + m.Name
+		if err := gitClient.EnsureRemote(temp, m.URL); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to add temporary remote for mirror %s: %w", m.Name, err)
+		}
+		temporary = append(temporary, temp)
+		remotes[i] = temp
+	}
+
+	return remotes, cleanup, nil
+}
+
+// saveResult writes the cycle outcome into the repository's GitHub sync status
+func (w *Watcher) saveResult(name string, result RepoResult) error {
+	return w.stateMgr.UpdateRepository(name, func(repo *state.Repository) error {
+		if repo.GitHub == nil {
+			return nil
+		}
+
+		switch result.Status {
+		case ResultSynced, ResultPushed:
+			repo.GitHub.SyncStatus = "synced"
+			repo.GitHub.NeedsRetry = false
+			repo.GitHub.LastError = ""
+		case ResultDiverged:
+			repo.GitHub.SyncStatus = "diverged"
+			repo.GitHub.NeedsRetry = false
+			repo.GitHub.LastError = result.Error
+		default:
+			repo.GitHub.SyncStatus = "unknown"
+			repo.GitHub.NeedsRetry = true
+			repo.GitHub.LastError = result.Error
+		}
+		repo.GitHub.LastSync = w.now()
+		return nil
+	})
+}
+
+// backoffUntil reports whether a repository is still backing off and until when.
+// Retry times are rounded to the nearest cycle, so a retry due within half an
+// interval is attempted now rather than a full interval late.
+func (w *Watcher) backoffUntil(name string) (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, ok := w.nextTry[name]
+	if !ok || !w.now().Add(w.options.Interval/2).Before(next) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// recordFailure increments the failure count and schedules the next attempt
+func (w *Watcher) recordFailure(name string) time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.failures[name]++
+	next := w.now().Add(Backoff(w.options.Interval, w.options.MaxBackoff, w.failures[name]))
+	w.nextTry[name] = next
+	return next
+}
+
+// recordSuccess clears any backoff for a repository
+func (w *Watcher) recordSuccess(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.failures, name)
+	delete(w.nextTry, name)
+}
+
+// Backoff returns the delay before retrying after the given number of
+// consecutive failures: interval doubled per failure, capped at max
+func Backoff(interval, max time.Duration, failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := interval
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if max > 0 && delay >= max {
+			return max
+		}
+	}
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,255 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcgerke/githelper/internal/state"
+)
+
+// runGit runs a git command in dir and returns trimmed output, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupWatchedRepo creates core and github bare repos, a managed local clone
+// with only a core remote (as clones set up before mirrors got fetch remotes
+// have), and a second clone that pushes only to core (simulating another machine)
+func setupWatchedRepo(t *testing.T) (tmp, local, other string, stateMgr *state.Manager) {
+	t.Helper()
+
+	tmp = t.TempDir()
+	local = filepath.Join(tmp, "local")
+	other = filepath.Join(tmp, "other")
+	core := filepath.Join(tmp, "core.git")
+	github := filepath.Join(tmp, "github.git")
+
+	runGit(t, tmp, "init", "--bare", "-b", "main", core)
+	runGit(t, tmp, "init", "--bare", "-b", "main", github)
+
+	runGit(t, tmp, "init", "-b", "main", local)
+	runGit(t, local, "remote", "add", "origin", core)
+	runGit(t, local, "commit", "--allow-empty", "-m", "first")
+	runGit(t, local, "push", "origin", "main")
+	runGit(t, local, "push", github, "main")
+
+	runGit(t, tmp, "clone", "-q", core, other)
+
+	stateMgr, err := state.NewManager(filepath.Join(tmp, "state"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	err = stateMgr.AddRepository("demo", &state.Repository{
+		Path:   local,
+		Remote: core,
+		GitHub: &state.GitHub{Enabled: true, User: "user", Repo: "demo", SyncStatus: "unknown"},
+		Mirrors: []state.Mirror{
+			{Name: "origin", URL: core, Role: state.MirrorRoleCore},
+			{Name: "github", URL: github, Role: state.MirrorRoleGitHub},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	return tmp, local, other, stateMgr
+}
+
+func testOptions() Options {
+	options := DefaultOptions()
+	options.Interval = 10 * time.Millisecond
+	options.MaxBackoff = 40 * time.Millisecond
+	return options
+}
+
+func TestRunOnce_PropagatesCorePushes(t *testing.T) {
+	tmp, local, other, stateMgr := setupWatchedRepo(t)
+
+	// Another machine pushes a commit, a new branch, and a tag to core only
+	runGit(t, other, "commit", "--allow-empty", "-m", "from elsewhere")
+	runGit(t, other, "tag", "v1.0")
+	runGit(t, other, "push", "-q", "origin", "main", "v1.0")
+	runGit(t, other, "push", "-q", "origin", "main:feature")
+
+	watcher := NewWatcher(stateMgr, testOptions())
+	results, err := watcher.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1: %+v", len(results), results)
+	}
+	if results[0].Status != ResultPushed {
+		t.Fatalf("Status = %s, want %s: %+v", results[0].Status, ResultPushed, results[0])
+	}
+	if got := results[0].Mirrors[0].Summary.Pushed; got != 3 {
+		t.Errorf("pushed %d refs, want 3", got)
+	}
+
+	// The temporary remote used to fetch GitHub is gone again
+	if remotes := runGit(t, local, "remote"); remotes != "origin" {
+		t.Errorf("remotes after cycle = %q, want only origin", remotes)
+	}
+
+	want := runGit(t, other, "rev-parse", "HEAD")
+	github := filepath.Join(tmp, "github.git")
+	for _, ref := range []string{"refs/heads/main", "refs/heads/feature", "refs/tags/v1.0"} {
+		if got := runGit(t, github, "rev-parse", ref); got != want {
+			t.Errorf("github %s = %s, want %s", ref, got, want)
+		}
+	}
+
+	repo, err := stateMgr.GetRepository("demo")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if repo.GitHub.SyncStatus != "synced" || repo.GitHub.LastSync.IsZero() {
+		t.Errorf("GitHub state = %+v, want synced with LastSync", repo.GitHub)
+	}
+	if len(repo.History) != 1 || repo.History[0].Existence != "E1" {
+		t.Errorf("History = %+v, want one E1 snapshot", repo.History)
+	}
+
+	// Second cycle has nothing to do
+	results, err = watcher.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if results[0].Status != ResultSynced {
+		t.Errorf("second cycle Status = %s, want %s", results[0].Status, ResultSynced)
+	}
+}
+
+func TestRunOnce_NeverForcesDivergedRefs(t *testing.T) {
+	tmp, local, other, stateMgr := setupWatchedRepo(t)
+
+	// GitHub gets a commit core doesn't have, then core moves on separately
+	runGit(t, local, "commit", "--allow-empty", "-m", "github only")
+	runGit(t, local, "push", "-q", filepath.Join(tmp, "github.git"), "main")
+	githubHead := runGit(t, local, "rev-parse", "HEAD")
+
+	runGit(t, other, "commit", "--allow-empty", "-m", "core only")
+	runGit(t, other, "push", "-q", "origin", "main")
+
+	results, err := NewWatcher(stateMgr, testOptions()).RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if results[0].Status != ResultDiverged {
+		t.Fatalf("Status = %s, want %s: %+v", results[0].Status, ResultDiverged, results[0])
+	}
+
+	if got := runGit(t, filepath.Join(tmp, "github.git"), "rev-parse", "refs/heads/main"); got != githubHead {
+		t.Errorf("github main was overwritten: %s, want %s", got, githubHead)
+	}
+
+	repo, _ := stateMgr.GetRepository("demo")
+	if repo.GitHub.SyncStatus != "diverged" {
+		t.Errorf("SyncStatus = %s, want diverged", repo.GitHub.SyncStatus)
+	}
+}
+
+func TestRunOnce_BacksOffFailingRepository(t *testing.T) {
+	tmp, _, _, stateMgr := setupWatchedRepo(t)
+
+	// Core disappears
+	if err := os.RemoveAll(filepath.Join(tmp, "core.git")); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	watcher := NewWatcher(stateMgr, testOptions())
+	watcher.now = func() time.Time { return now }
+
+	results, err := watcher.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if results[0].Status != ResultFailed {
+		t.Fatalf("Status = %s, want %s", results[0].Status, ResultFailed)
+	}
+
+	repo, _ := stateMgr.GetRepository("demo")
+	if !repo.GitHub.NeedsRetry || repo.GitHub.LastError == "" {
+		t.Errorf("GitHub state = %+v, want NeedsRetry with LastError", repo.GitHub)
+	}
+
+	// Two failures back off for 2 intervals; the next cycle is skipped
+	watcher.now = func() time.Time { return now.Add(10 * time.Millisecond) }
+	results, _ = watcher.RunOnce(context.Background())
+	if results[0].Status != ResultFailed {
+		t.Fatalf("retry Status = %s, want %s", results[0].Status, ResultFailed)
+	}
+	watcher.now = func() time.Time { return now.Add(20 * time.Millisecond) }
+	results, _ = watcher.RunOnce(context.Background())
+	if results[0].Status != ResultSkipped {
+		t.Errorf("Status during backoff = %s, want %s", results[0].Status, ResultSkipped)
+	}
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	_, _, _, stateMgr := setupWatchedRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cycles := 0
+	done := make(chan error, 1)
+
+	go func() {
+		done <- NewWatcher(stateMgr, testOptions()).Run(ctx, func(results []RepoResult) {
+			cycles++
+			if cycles == 2 {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("Run() did not stop after cancel")
+	}
+
+	if cycles != 2 {
+		t.Errorf("ran %d cycles, want 2", cycles)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(time.Minute, 10*time.Minute, tt.failures); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -81,7 +81,6 @@ func BenchmarkFetchOperation(b *testing.B) {
 	// Add a remote pointing to a small public repo
 	// (We'll use a local bare repo for consistent benchmarks)
 	bareDir := filepath.Join(os.TempDir(), "bench-bare")
-	os.MkdirAll(bareDir, 0755)
 	defer os.RemoveAll(bareDir)
 
 	git.InitBareRepo(bareDir)